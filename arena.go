@@ -0,0 +1,74 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+
+package gedcom
+
+// arenaChunkSize is the maximum number of records allocated together in each arena chunk.
+// Chunks start small and double in size up to this limit so that small files do not
+// allocate much more than they need.
+const (
+	arenaChunkSize    = 1024
+	arenaMinChunkSize = 16
+)
+
+// WithArenaAllocation configures the decoder to allocate the most common records, such
+// as individuals, families, events, names, notes and citations, in large chunks rather
+// than individually. This greatly reduces the number of allocations made while decoding
+// and the work the garbage collector must do to track them. The chunks belonging to a
+// decoded Gedcom are released together once none of its records are referenced, so
+// retaining a single record retains the whole chunk it was allocated from.
+func WithArenaAllocation() DecoderOption {
+	return decoderOptionFunc(func(d *Decoder) {
+		d.arena.chunkSize = arenaChunkSize
+	})
+}
+
+// slab allocates values of a single type from a chunk when chunkSize is non-zero and
+// from the heap otherwise
+type slab[T any] struct {
+	chunk     []T
+	chunkSize int
+}
+
+func (s *slab[T]) new(v T) *T {
+	if s.chunkSize == 0 {
+		p := new(T)
+		*p = v
+		return p
+	}
+	if len(s.chunk) == cap(s.chunk) {
+		n := min(max(cap(s.chunk)*2, arenaMinChunkSize), s.chunkSize)
+		s.chunk = make([]T, 0, n)
+	}
+	s.chunk = append(s.chunk, v)
+	return &s.chunk[len(s.chunk)-1]
+}
+
+// decodeArena holds the slabs used to allocate records while decoding a single Gedcom
+type decodeArena struct {
+	chunkSize   int
+	individuals slab[IndividualRecord]
+	families    slab[FamilyRecord]
+	sources     slab[SourceRecord]
+	events      slab[EventRecord]
+	names       slab[NameRecord]
+	notes       slab[NoteRecord]
+	citations   slab[CitationRecord]
+	familyLinks slab[FamilyLinkRecord]
+}
+
+// reset discards the current chunks so that records of the next Gedcom are not allocated
+// alongside those of the previous one
+func (a *decodeArena) reset() {
+	*a = decodeArena{chunkSize: a.chunkSize}
+	a.individuals.chunkSize = a.chunkSize
+	a.families.chunkSize = a.chunkSize
+	a.sources.chunkSize = a.chunkSize
+	a.events.chunkSize = a.chunkSize
+	a.names.chunkSize = a.chunkSize
+	a.notes.chunkSize = a.chunkSize
+	a.citations.chunkSize = a.chunkSize
+	a.familyLinks.chunkSize = a.chunkSize
+}
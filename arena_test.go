@@ -0,0 +1,63 @@
+package gedcom
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestArenaAllocation(t *testing.T) {
+	encode := func(g *Gedcom) string {
+		var buf bytes.Buffer
+		if err := NewEncoder(&buf).Encode(g); err != nil {
+			t.Fatalf("unexpected encode error: %v", err)
+		}
+		return buf.String()
+	}
+
+	plain, err := NewDecoder(bytes.NewReader(data)).Decode()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	d := NewDecoder(bytes.NewReader(data), WithArenaAllocation())
+	arena, err := d.Decode()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if encode(plain) != encode(arena) {
+		t.Errorf("arena decode produced different output to plain decode")
+	}
+
+	fam := arena.Family[0]
+	if fam.Husband != nil && fam.Husband != arena.Individual[indexOfIndividual(arena, fam.Husband.Xref)] {
+		t.Errorf("family husband does not refer to individual record")
+	}
+}
+
+func indexOfIndividual(g *Gedcom, xref string) int {
+	for i, r := range g.Individual {
+		if r.Xref == xref {
+			return i
+		}
+	}
+	return -1
+}
+
+func BenchmarkDecode(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := NewDecoder(bytes.NewReader(data)).Decode(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecodeArena(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := NewDecoder(bytes.NewReader(data), WithArenaAllocation()).Decode(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+
+package gedcom
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// BibliographyFormat selects the output format of WriteBibliography.
+type BibliographyFormat int
+
+const (
+	BibliographyText     BibliographyFormat = iota // one plain text entry per line
+	BibliographyMarkdown                           // a Markdown list with titles in italics
+	BibliographyCSV                                // CSV with a header row
+)
+
+// A BibliographyEntry describes one distinct source cited in a Gedcom.
+type BibliographyEntry struct {
+	Title        string
+	Author       string
+	Publication  string
+	Repositories []string        // names of the repositories holding the source
+	Sources      []*SourceRecord // the source records described by the entry
+}
+
+// Bibliography returns an entry for each distinct source in g, sorted alphabetically by
+// author, or by title for sources with no author. Sources with the same title, author and
+// publication facts, ignoring case and whitespace, are combined into a single entry.
+// Sources with none of these are omitted.
+func Bibliography(g *Gedcom) []*BibliographyEntry {
+	var entries []*BibliographyEntry
+	byKey := make(map[string]*BibliographyEntry)
+	for _, s := range g.Source {
+		if s == nil {
+			continue
+		}
+		e := &BibliographyEntry{
+			Title:       collapseSpace(s.Title),
+			Author:      collapseSpace(s.Originator),
+			Publication: collapseSpace(s.PublicationFacts),
+		}
+		if e.Title == "" && e.Author == "" && e.Publication == "" {
+			continue
+		}
+
+		key := normalizeLinkText(e.Title) + "\x00" + normalizeLinkText(e.Author) + "\x00" + normalizeLinkText(e.Publication)
+		if existing, ok := byKey[key]; ok {
+			e = existing
+		} else {
+			byKey[key] = e
+			entries = append(entries, e)
+		}
+		e.Sources = append(e.Sources, s)
+		if s.Repository != nil && s.Repository.Repository != nil {
+			name := collapseSpace(s.Repository.Repository.Name)
+			if name != "" && !slices.Contains(e.Repositories, name) {
+				e.Repositories = append(e.Repositories, name)
+			}
+		}
+	}
+
+	sortKey := func(e *BibliographyEntry) string {
+		if e.Author != "" {
+			return normalizeLinkText(e.Author + " " + e.Title)
+		}
+		return normalizeLinkText(e.Title)
+	}
+	slices.SortStableFunc(entries, func(a, b *BibliographyEntry) int {
+		return strings.Compare(sortKey(a), sortKey(b))
+	})
+	return entries
+}
+
+// WriteBibliography writes the bibliography of the sources in g to w in the given format.
+func WriteBibliography(w io.Writer, g *Gedcom, f BibliographyFormat) error {
+	entries := Bibliography(g)
+
+	if f == BibliographyCSV {
+		cw := csv.NewWriter(w)
+		if err := cw.Write([]string{"title", "author", "publication", "repositories"}); err != nil {
+			return fmt.Errorf("write bibliography: %w", err)
+		}
+		for _, e := range entries {
+			if err := cw.Write([]string{e.Title, e.Author, e.Publication, strings.Join(e.Repositories, "; ")}); err != nil {
+				return fmt.Errorf("write bibliography: %w", err)
+			}
+		}
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return fmt.Errorf("write bibliography: %w", err)
+		}
+		return nil
+	}
+
+	item := func(s string) string {
+		s = strings.TrimSuffix(s, ".")
+		if f == BibliographyMarkdown {
+			s = escapeMarkdown(s)
+		}
+		return s
+	}
+	for _, e := range entries {
+		var parts []string
+		if e.Author != "" {
+			parts = append(parts, item(e.Author))
+		}
+		if e.Title != "" {
+			if f == BibliographyMarkdown {
+				parts = append(parts, "*"+item(e.Title)+"*")
+			} else {
+				parts = append(parts, item(e.Title))
+			}
+		}
+		if e.Publication != "" {
+			parts = append(parts, item(e.Publication))
+		}
+		if len(e.Repositories) > 0 {
+			parts = append(parts, "Held by "+item(strings.Join(e.Repositories, "; ")))
+		}
+
+		line := strings.Join(parts, ". ") + "."
+		if f == BibliographyMarkdown {
+			line = "- " + line
+		}
+		if _, err := io.WriteString(w, line+"\n"); err != nil {
+			return fmt.Errorf("write bibliography: %w", err)
+		}
+	}
+	return nil
+}
+
+// collapseSpace replaces runs of whitespace, including newlines, with a single space
+func collapseSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// escapeMarkdown escapes characters that would end an emphasised span
+func escapeMarkdown(s string) string {
+	return strings.NewReplacer("*", `\*`, "_", `\_`).Replace(s)
+}
@@ -0,0 +1,80 @@
+package gedcom
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWriteBibliography(t *testing.T) {
+	input := `0 @R1@ REPO
+1 NAME National Archives
+0 @R2@ REPO
+1 NAME County Record Office
+0 @S1@ SOUR
+1 TITL Parish registers of St Mary
+1 AUTH Smith, John
+1 PUBL London, 1901.
+1 REPO @R1@
+0 @S2@ SOUR
+1 TITL Parish  Registers of St Mary
+1 AUTH smith, john
+1 PUBL London, 1901.
+1 REPO @R2@
+0 @S3@ SOUR
+1 TITL Census of_England
+0 @S4@ SOUR
+1 TITL A history
+1 AUTH Brown, Ann
+0 @S5@ SOUR
+1 NOTE no details
+0 TRLR
+`
+	g, err := NewDecoder(strings.NewReader(input)).Decode()
+	if err != nil {
+		t.Fatalf("unexpected decode error: %v", err)
+	}
+
+	entries := Bibliography(g)
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries, wanted 3", len(entries))
+	}
+	if len(entries[2].Sources) != 2 {
+		t.Errorf("got %d sources for merged entry, wanted 2", len(entries[2].Sources))
+	}
+
+	testCases := []struct {
+		format BibliographyFormat
+		want   string
+	}{
+		{
+			format: BibliographyText,
+			want: "Brown, Ann. A history.\n" +
+				"Census of_England.\n" +
+				"Smith, John. Parish registers of St Mary. London, 1901. Held by National Archives; County Record Office.\n",
+		},
+		{
+			format: BibliographyMarkdown,
+			want: "- Brown, Ann. *A history*.\n" +
+				"- *Census of\\_England*.\n" +
+				"- Smith, John. *Parish registers of St Mary*. London, 1901. Held by National Archives; County Record Office.\n",
+		},
+		{
+			format: BibliographyCSV,
+			want: "title,author,publication,repositories\n" +
+				"A history,\"Brown, Ann\",,\n" +
+				"Census of_England,,,\n" +
+				"Parish registers of St Mary,\"Smith, John\",\"London, 1901.\",National Archives; County Record Office\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		buf := new(bytes.Buffer)
+		if err := WriteBibliography(buf, g, tc.format); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := buf.String(); got != tc.want {
+			t.Errorf("format %d: got:\n%s\nwant:\n%s", tc.format, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,251 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+
+package gedcom
+
+import "time"
+
+// Dates are converted between calendars by way of Julian day numbers, which count days
+// from 1 January 4713 B.C. in the proleptic Julian calendar. Years passed to the functions
+// in this file use astronomical numbering, in which 1 B.C. is year 0.
+
+// Gregorian returns the date converted to the proleptic Gregorian calendar. Only complete
+// dates with a day, month and year can be converted from other calendars since a month or
+// year of one calendar does not correspond to a single month or year of another. It
+// reports false if the date cannot be converted.
+func (d Date) Gregorian() (Date, bool) {
+	if d.Calendar == "" || d.Calendar == CalendarGregorian {
+		return d, !d.IsZero()
+	}
+	if d.Day == 0 {
+		return Date{}, false
+	}
+	day, _, ok := d.dayRange()
+	if !ok {
+		return Date{}, false
+	}
+	return dayDate(day), true
+}
+
+// JulianToGregorian converts a date in the proleptic Julian calendar to the proleptic
+// Gregorian calendar. Years use astronomical numbering, in which 1 B.C. is year 0.
+func JulianToGregorian(year int, month time.Month, day int) (int, time.Month, int) {
+	y, m, d := gregorianFromDay(julianDay(year, int(month), day))
+	return y, time.Month(m), d
+}
+
+// GregorianToJulian converts a date in the proleptic Gregorian calendar to the proleptic
+// Julian calendar. Years use astronomical numbering, in which 1 B.C. is year 0.
+func GregorianToJulian(year int, month time.Month, day int) (int, time.Month, int) {
+	y, m, d := julianFromDay(gregorianDay(year, int(month), day))
+	return y, time.Month(m), d
+}
+
+// A Changeover is the first day on which a region used the Gregorian calendar, given in
+// the Gregorian calendar. Dates recorded in the region before that day were written in the
+// Julian calendar even when they carry no calendar escape.
+type Changeover struct {
+	Year  int
+	Month time.Month
+	Day   int
+}
+
+var (
+	// ChangeoverCatholic is the changeover adopted by Spain, Portugal and the Italian
+	// states, where 4 October 1582 was followed by 15 October 1582.
+	ChangeoverCatholic = Changeover{Year: 1582, Month: time.October, Day: 15}
+
+	// ChangeoverBritain is the changeover adopted by Great Britain and its colonies, where
+	// 2 September 1752 was followed by 14 September 1752.
+	ChangeoverBritain = Changeover{Year: 1752, Month: time.September, Day: 14}
+)
+
+// Gregorian returns the date d converted to the proleptic Gregorian calendar for a region
+// that adopted the Gregorian calendar at changeover c. Dates in the Julian calendar, and
+// dates with no calendar escape that fall before the changeover when read as Julian dates,
+// are converted from the Julian calendar using the new style year of any dual year. Other
+// dates are converted as by Date.Gregorian. Only complete dates can be converted from the
+// Julian calendar. It reports false if d cannot be converted.
+func (c Changeover) Gregorian(d Date) (Date, bool) {
+	if d.Calendar != "" && d.Calendar != CalendarGregorian && d.Calendar != CalendarJulian {
+		return d.Gregorian()
+	}
+	d = d.Resolve(NewStyle)
+	if d.Calendar == CalendarJulian {
+		return d.Gregorian()
+	}
+
+	julian := d
+	julian.Calendar = CalendarJulian
+	_, last, ok := julian.dayRange()
+	if !ok {
+		return Date{}, false
+	}
+	if last < gregorianDay(c.Year, int(c.Month), c.Day) {
+		return julian.Gregorian()
+	}
+	return d, true
+}
+
+// calendarDay returns the Julian day number of a day of a calendar. Months are numbered
+// as in a Date. It reports false if the month is not a month of the year.
+func calendarDay(cal string, y, m, d int) (int, bool) {
+	switch cal {
+	case CalendarGregorian:
+		return gregorianDay(y, m, d), m >= 1 && m <= 12
+	case CalendarJulian:
+		return julianDay(y, m, d), m >= 1 && m <= 12
+	case CalendarHebrew:
+		return hebrewDay(y, m, d)
+	case CalendarFrench:
+		return frenchDay(y, m, d), m >= 1 && m <= 13 && y >= 1
+	}
+	return 0, false
+}
+
+// nextMonth returns the year and month that follow a month of a calendar
+func nextMonth(cal string, y, m int) (int, int) {
+	switch cal {
+	case CalendarHebrew:
+		switch {
+		case m == 13:
+			return y + 1, 1
+		case m == 6 && !hebrewLeap(y):
+			// ADS, the second month of Adar, occurs only in leap years
+			return y, 8
+		}
+		return y, m + 1
+	case CalendarFrench:
+		if m == 13 {
+			return y + 1, 1
+		}
+		return y, m + 1
+	}
+	if m == 12 {
+		return y + 1, 1
+	}
+	return y, m + 1
+}
+
+// gregorianDay returns the Julian day number of a date in the proleptic Gregorian calendar
+func gregorianDay(y, m, d int) int {
+	a := (14 - m) / 12
+	y += 4800 - a
+	m += 12*a - 3
+	return d + (153*m+2)/5 + 365*y + y/4 - y/100 + y/400 - 32045
+}
+
+// gregorianFromDay returns the proleptic Gregorian date of a Julian day number
+func gregorianFromDay(jd int) (int, int, int) {
+	l := jd + 68569
+	n := 4 * l / 146097
+	l -= (146097*n + 3) / 4
+	i := 4000 * (l + 1) / 1461001
+	l = l - 1461*i/4 + 31
+	j := 80 * l / 2447
+	d := l - 2447*j/80
+	l = j / 11
+	m := j + 2 - 12*l
+	y := 100*(n-49) + i + l
+	return y, m, d
+}
+
+// julianDay returns the Julian day number of a date in the proleptic Julian calendar
+func julianDay(y, m, d int) int {
+	a := (14 - m) / 12
+	y += 4800 - a
+	m += 12*a - 3
+	return d + (153*m+2)/5 + 365*y + y/4 - 32083
+}
+
+// julianFromDay returns the proleptic Julian date of a Julian day number
+func julianFromDay(jd int) (int, int, int) {
+	c := jd + 32082
+	d := (4*c + 3) / 1461
+	e := c - 1461*d/4
+	m := (5*e + 2) / 153
+	return d - 4800 + m/10, m + 3 - 12*(m/10), e - (153*m+2)/5 + 1
+}
+
+// frenchDay returns the Julian day number of a date in the French Republican calendar,
+// whose year 1 began on 22 September 1792. Every fourth year, starting with year 3, has a
+// sixth complementary day, matching the leap years of the calendar while it was in use.
+func frenchDay(y, m, d int) int {
+	return 2375474 + 1461*y/4 + 30*(m-1) + d
+}
+
+// hebrewEpoch is the Julian day number of 1 Tishri of year 1 of the Hebrew calendar
+const hebrewEpoch = 347998
+
+// hebrewLeap reports whether a year of the Hebrew calendar has a thirteenth month
+func hebrewLeap(y int) bool {
+	return (7*y+1)%19 < 7
+}
+
+// hebrewElapsedDays returns the number of days from the epoch to the molad of Tishri of a
+// year, postponed if it falls on a Sunday, Wednesday or Friday
+func hebrewElapsedDays(y int) int {
+	months := (235*y - 234) / 19
+	parts := 12084 + 13753*months
+	day := 29*months + parts/25920
+	if (3*(day+1))%7 < 3 {
+		day++
+	}
+	return day
+}
+
+// hebrewNewYear returns the Julian day number of 1 Tishri of a year of the Hebrew calendar
+func hebrewNewYear(y int) int {
+	prev, this, next := hebrewElapsedDays(y-1), hebrewElapsedDays(y), hebrewElapsedDays(y+1)
+	delay := 0
+	switch {
+	case next-this == 356:
+		delay = 2
+	case this-prev == 382:
+		delay = 1
+	}
+	return hebrewEpoch + this + delay
+}
+
+// hebrewMonthDays returns the number of days in a month of a year of the Hebrew calendar,
+// with months numbered from TSH as in a Date
+func hebrewMonthDays(y, m int) int {
+	yearDays := hebrewNewYear(y+1) - hebrewNewYear(y)
+	switch m {
+	case 2: // CSH is long in complete years
+		if yearDays%10 == 5 {
+			return 30
+		}
+		return 29
+	case 3: // KSL is short in deficient years
+		if yearDays%10 == 3 {
+			return 29
+		}
+		return 30
+	case 6: // ADR has 30 days as the first month of Adar in leap years
+		if hebrewLeap(y) {
+			return 30
+		}
+		return 29
+	case 4, 7, 9, 11, 13:
+		return 29
+	}
+	return 30
+}
+
+// hebrewDay returns the Julian day number of a date in the Hebrew calendar. It reports
+// false if the month does not occur in the year.
+func hebrewDay(y, m, d int) (int, bool) {
+	if y < 1 || m < 1 || m > 13 || (m == 7 && !hebrewLeap(y)) {
+		return 0, false
+	}
+	jd := hebrewNewYear(y) + d - 1
+	for mm := 1; mm < m; mm++ {
+		if mm != 7 || hebrewLeap(y) {
+			jd += hebrewMonthDays(y, mm)
+		}
+	}
+	return jd, true
+}
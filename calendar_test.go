@@ -0,0 +1,122 @@
+package gedcom
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDateGregorian(t *testing.T) {
+	testCases := []struct {
+		in   string
+		want string
+		ok   bool
+	}{
+		{in: "2 MAR 1900", want: "2 MAR 1900", ok: true},
+		{in: "@#DJULIAN@ 11 FEB 1731", want: "22 FEB 1731", ok: true},
+		{in: "@#DJULIAN@ 2 SEP 1752", want: "13 SEP 1752", ok: true},
+		{in: "@#DJULIAN@ 1 JAN 1 B.C.", want: "30 DEC 2 B.C.", ok: true},
+		{in: "@#DHEBREW@ 1 TSH 5760", want: "11 SEP 1999", ok: true},
+		{in: "@#DHEBREW@ 15 NSN 5784", want: "23 APR 2024", ok: true},
+		{in: "@#DHEBREW@ 14 ADS 5784", want: "24 MAR 2024", ok: true},
+		{in: "@#DHEBREW@ 14 ADR 5783", want: "7 MAR 2023", ok: true},
+		{in: "@#DFRENCH R@ 1 VEND 1", want: "22 SEP 1792", ok: true},
+		{in: "@#DFRENCH R@ 18 BRUM 8", want: "9 NOV 1799", ok: true},
+		{in: "@#DFRENCH R@ 6 COMP 3", want: "22 SEP 1795", ok: true},
+		{in: "@#DJULIAN@ 1731", ok: false},
+		{in: "@#DJULIAN@ 29 FEB 1731", ok: false},
+		{in: "@#DHEBREW@ 1 ADS 5783", ok: false},
+		{in: "@#DFRENCH R@ 6 COMP 4", ok: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.in, func(t *testing.T) {
+			dv, ok := ParseDate(tc.in)
+			if !ok {
+				t.Fatalf("could not parse %q", tc.in)
+			}
+			got, ok := dv.Date1.Gregorian()
+			if ok != tc.ok {
+				t.Fatalf("got ok %v, wanted %v", ok, tc.ok)
+			}
+			if ok && got.String() != tc.want {
+				t.Errorf("got %q, wanted %q", got.String(), tc.want)
+			}
+		})
+	}
+}
+
+func TestCompareCalendars(t *testing.T) {
+	ordered := []string{
+		"@#DGREGORIAN@ 21 FEB 1731",
+		"@#DJULIAN@ 11 FEB 1731",
+		"23 FEB 1731",
+		"@#DFRENCH R@ BRUM 8",
+		"@#DHEBREW@ ADR 5560",
+		"@#DHEBREW@ 5760",
+		"10 DEC 1999",
+	}
+	for i := 0; i < len(ordered)-1; i++ {
+		a, b := ordered[i], ordered[i+1]
+		if got := CompareDates(a, b); got != -1 {
+			t.Errorf("CompareDates(%q, %q) = %d, wanted -1", a, b, got)
+		}
+	}
+}
+
+func TestJulianGregorian(t *testing.T) {
+	testCases := []struct {
+		jy int
+		jm time.Month
+		jd int
+		gy int
+		gm time.Month
+		gd int
+	}{
+		{1582, time.October, 4, 1582, time.October, 14},
+		{1582, time.October, 5, 1582, time.October, 15},
+		{1752, time.September, 2, 1752, time.September, 13},
+		{1700, time.February, 29, 1700, time.March, 11},
+		{1, time.January, 1, 0, time.December, 30},
+		{-44, time.March, 15, -44, time.March, 13},
+	}
+	for _, tc := range testCases {
+		if y, m, d := JulianToGregorian(tc.jy, tc.jm, tc.jd); y != tc.gy || m != tc.gm || d != tc.gd {
+			t.Errorf("JulianToGregorian(%d, %s, %d) = %d %s %d, wanted %d %s %d", tc.jy, tc.jm, tc.jd, y, m, d, tc.gy, tc.gm, tc.gd)
+		}
+		if y, m, d := GregorianToJulian(tc.gy, tc.gm, tc.gd); y != tc.jy || m != tc.jm || d != tc.jd {
+			t.Errorf("GregorianToJulian(%d, %s, %d) = %d %s %d, wanted %d %s %d", tc.gy, tc.gm, tc.gd, y, m, d, tc.jy, tc.jm, tc.jd)
+		}
+	}
+}
+
+func TestChangeover(t *testing.T) {
+	testCases := []struct {
+		in   string
+		c    Changeover
+		want string
+		ok   bool
+	}{
+		{in: "2 SEP 1752", c: ChangeoverBritain, want: "13 SEP 1752", ok: true},
+		{in: "14 SEP 1752", c: ChangeoverBritain, want: "14 SEP 1752", ok: true},
+		{in: "11 FEB 1731/32", c: ChangeoverBritain, want: "22 FEB 1732", ok: true},
+		{in: "11 FEB 1731/32", c: ChangeoverCatholic, want: "11 FEB 1732", ok: true},
+		{in: "@#DJULIAN@ 11 FEB 1800", c: ChangeoverBritain, want: "22 FEB 1800", ok: true},
+		{in: "1800", c: ChangeoverBritain, want: "1800", ok: true},
+		{in: "1700", c: ChangeoverBritain, ok: false},
+		{in: "@#DFRENCH R@ 1 VEND 1", c: ChangeoverBritain, want: "22 SEP 1792", ok: true},
+	}
+	for _, tc := range testCases {
+		dv, ok := ParseDate(tc.in)
+		if !ok {
+			t.Fatalf("could not parse %q", tc.in)
+		}
+		got, ok := tc.c.Gregorian(dv.Date1)
+		if ok != tc.ok {
+			t.Errorf("%s: got ok %v, wanted %v", tc.in, ok, tc.ok)
+			continue
+		}
+		if ok && got.String() != tc.want {
+			t.Errorf("%s: got %q, wanted %q", tc.in, got, tc.want)
+		}
+	}
+}
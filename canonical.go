@@ -0,0 +1,170 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+
+package gedcom
+
+import (
+	"cmp"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// WithCanonicalOrder configures the encoder to write records and their substructures in a
+// canonical order, so that encoding the same logical tree always produces identical
+// output however its lists were ordered, which suits diffing and version control. Records
+// of each type are sorted by xref, comparing any numbers in xrefs numerically so that I2
+// precedes I10. Events and attributes are sorted by date, then by tag, type and value,
+// then by the text of the date, the place and their other values; children by birth
+// date, then by xref; and links to families by the family's xref. Other lists, such as
+// names whose first entry is the preferred name, keep their order. The Gedcom being
+// encoded is not modified.
+func WithCanonicalOrder() EncoderOption {
+	return encoderOptionFunc(func(e *Encoder) {
+		e.canonical = true
+	})
+}
+
+// compareXrefs compares two xrefs, comparing runs of digits by their numeric value
+func compareXrefs(a, b string) int {
+	for a != "" && b != "" {
+		da, db := leadingDigits(a), leadingDigits(b)
+		switch {
+		case da > 0 && db > 0:
+			na, nb := strings.TrimLeft(a[:da], "0"), strings.TrimLeft(b[:db], "0")
+			if c := cmp.Compare(len(na), len(nb)); c != 0 {
+				return c
+			}
+			if c := strings.Compare(na, nb); c != 0 {
+				return c
+			}
+			a, b = a[da:], b[db:]
+		default:
+			if c := cmp.Compare(a[0], b[0]); c != 0 {
+				return c
+			}
+			a, b = a[1:], b[1:]
+		}
+	}
+	return cmp.Compare(len(a), len(b))
+}
+
+// leadingDigits returns the number of ASCII digits at the start of s
+func leadingDigits(s string) int {
+	n := 0
+	for n < len(s) && s[n] >= '0' && s[n] <= '9' {
+		n++
+	}
+	return n
+}
+
+// canonicalRecords returns records sorted by xref when the encoder writes in canonical
+// order, leaving rs unchanged
+func canonicalRecords[T Record](e *Encoder, rs []T) []T {
+	if !e.canonical {
+		return rs
+	}
+	rs = slices.Clone(rs)
+	slices.SortStableFunc(rs, func(a, b T) int {
+		return compareXrefs(e.xref(a, xrefOf(a)), e.xref(b, xrefOf(b)))
+	})
+	return rs
+}
+
+// xrefOf returns the xref of a record, or an empty string for a nil record or one without
+// an xref
+func xrefOf(r Record) string {
+	v := reflect.ValueOf(r)
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return ""
+	}
+	xref, _ := recordXref(v)
+	return xref
+}
+
+// canonicalEvents returns evs in canonical order when the encoder writes in canonical
+// order, leaving evs unchanged
+func (e *Encoder) canonicalEvents(evs []*EventRecord) []*EventRecord {
+	if !e.canonical {
+		return evs
+	}
+	evs = slices.Clone(evs)
+	slices.SortStableFunc(evs, func(a, b *EventRecord) int {
+		if a == nil || b == nil {
+			return cmp.Compare(boolRank(a == nil), boolRank(b == nil))
+		}
+		return cmp.Or(
+			CompareDates(a.Date, b.Date),
+			cmp.Compare(a.Tag, b.Tag),
+			cmp.Compare(a.Type, b.Type),
+			cmp.Compare(a.Value, b.Value),
+			cmp.Compare(a.Date, b.Date),
+			cmp.Compare(a.Place.Name, b.Place.Name),
+			cmp.Compare(a.Place.Latitude, b.Place.Latitude),
+			cmp.Compare(a.Place.Longitude, b.Place.Longitude),
+			cmp.Compare(a.Age, b.Age),
+			cmp.Compare(a.HusbandAge, b.HusbandAge),
+			cmp.Compare(a.WifeAge, b.WifeAge),
+			cmp.Compare(a.ResponsibleAgency, b.ResponsibleAgency),
+			cmp.Compare(a.ReligiousAffiliation, b.ReligiousAffiliation),
+			cmp.Compare(a.Cause, b.Cause),
+			cmp.Compare(a.RestrictionNotice, b.RestrictionNotice),
+			cmp.Compare(a.AdoptedByParent, b.AdoptedByParent),
+			compareXrefs(e.familyXref(a.ChildInFamily), e.familyXref(b.ChildInFamily)),
+		)
+	})
+	return evs
+}
+
+// canonicalChildren returns the children of a family in canonical order when the encoder
+// writes in canonical order, leaving rs unchanged
+func (e *Encoder) canonicalChildren(rs []*IndividualRecord) []*IndividualRecord {
+	if !e.canonical {
+		return rs
+	}
+	rs = slices.Clone(rs)
+	slices.SortStableFunc(rs, func(a, b *IndividualRecord) int {
+		if c := CompareDates(birthDate(a), birthDate(b)); c != 0 {
+			return c
+		}
+		return compareXrefs(e.xref(a, xrefOf(a)), e.xref(b, xrefOf(b)))
+	})
+	return rs
+}
+
+// canonicalFamilyLinks returns links in canonical order when the encoder writes in
+// canonical order, leaving links unchanged
+func (e *Encoder) canonicalFamilyLinks(links []*FamilyLinkRecord) []*FamilyLinkRecord {
+	if !e.canonical {
+		return links
+	}
+	links = slices.Clone(links)
+	slices.SortStableFunc(links, func(a, b *FamilyLinkRecord) int {
+		return compareXrefs(e.linkedFamilyXref(a), e.linkedFamilyXref(b))
+	})
+	return links
+}
+
+func (e *Encoder) linkedFamilyXref(l *FamilyLinkRecord) string {
+	if l == nil {
+		return ""
+	}
+	return e.familyXref(l.Family)
+}
+
+func (e *Encoder) familyXref(f *FamilyRecord) string {
+	if f == nil {
+		return ""
+	}
+	return e.xref(f, f.Xref)
+}
+
+// boolRank returns 1 for true and 0 for false, for ordering
+func boolRank(v bool) int {
+	if v {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,141 @@
+package gedcom
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestCompareXrefs(t *testing.T) {
+	testCases := []struct {
+		a, b string
+		want int
+	}{
+		{a: "I2", b: "I10", want: -1},
+		{a: "I10", b: "I2", want: 1},
+		{a: "I10", b: "I10", want: 0},
+		{a: "F1", b: "I1", want: -1},
+		{a: "I002", b: "I10", want: -1},
+		{a: "I1", b: "I1a", want: -1},
+		{a: "", b: "I1", want: -1},
+	}
+	for _, tc := range testCases {
+		if got := compareXrefs(tc.a, tc.b); got != tc.want {
+			t.Errorf("compareXrefs(%q, %q) = %d, wanted %d", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
+
+func TestEncodeCanonicalOrder(t *testing.T) {
+	inputs := []string{`0 HEAD
+0 @I10@ INDI
+1 NAME Ann /Smith/
+1 DEAT
+2 DATE 1900
+1 BIRT
+2 DATE 1850
+1 FAMS @F2@
+1 FAMS @F1@
+0 @I2@ INDI
+1 NAME John /Smith/
+0 @F1@ FAM
+1 CHIL @I10@
+1 CHIL @I2@
+0 @F2@ FAM
+0 TRLR
+`, `0 HEAD
+0 @F2@ FAM
+0 @F1@ FAM
+1 CHIL @I2@
+1 CHIL @I10@
+0 @I2@ INDI
+1 NAME John /Smith/
+0 @I10@ INDI
+1 NAME Ann /Smith/
+1 BIRT
+2 DATE 1850
+1 FAMS @F1@
+1 FAMS @F2@
+1 DEAT
+2 DATE 1900
+0 TRLR
+`}
+
+	var outputs []string
+	for _, input := range inputs {
+		g, err := NewDecoder(strings.NewReader(input)).Decode()
+		if err != nil {
+			t.Fatalf("unexpected decode error: %v", err)
+		}
+		first := g.Individual[0]
+
+		buf := new(bytes.Buffer)
+		if err := NewEncoder(buf, WithCanonicalOrder()).Encode(g); err != nil {
+			t.Fatalf("unexpected encode error: %v", err)
+		}
+		outputs = append(outputs, buf.String())
+
+		if g.Individual[0] != first {
+			t.Errorf("encoding modified the order of individuals")
+		}
+	}
+
+	if outputs[0] != outputs[1] {
+		t.Errorf("outputs differ:\n%s\n---\n%s", outputs[0], outputs[1])
+	}
+
+	want := `0 HEAD
+1 SOUR
+0 @I2@ INDI
+1 NAME John /Smith/
+0 @I10@ INDI
+1 NAME Ann /Smith/
+1 BIRT
+2 DATE 1850
+1 DEAT
+2 DATE 1900
+1 FAMS @F1@
+1 FAMS @F2@
+0 @F1@ FAM
+1 CHIL @I10@
+1 CHIL @I2@
+0 @F2@ FAM
+0 TRLR
+`
+	if outputs[0] != want {
+		t.Errorf("got output:\n%s\nwanted:\n%s", outputs[0], want)
+	}
+}
+
+func TestEncodeCanonicalEventTies(t *testing.T) {
+	events := []string{
+		"1 RESI\n2 PLAC London\n",
+		"1 RESI\n2 PLAC Leeds\n",
+		"1 BIRT\n2 DATE ABT 1900\n",
+		"1 BIRT\n2 DATE 1900\n",
+		"1 DEAT\n2 DATE 1950\n2 CAUS Fever\n",
+		"1 DEAT\n2 DATE 1950\n2 AGE 50y\n",
+	}
+
+	var outputs []string
+	for _, order := range [][]int{{0, 1, 2, 3, 4, 5}, {1, 0, 3, 2, 5, 4}} {
+		input := "0 @I1@ INDI\n"
+		for _, i := range order {
+			input += events[i]
+		}
+		g, err := NewDecoder(strings.NewReader(input)).Decode()
+		if err != nil {
+			t.Fatalf("unexpected decode error: %v", err)
+		}
+
+		buf := new(bytes.Buffer)
+		if err := NewEncoder(buf, WithCanonicalOrder()).Encode(g); err != nil {
+			t.Fatalf("unexpected encode error: %v", err)
+		}
+		outputs = append(outputs, buf.String())
+	}
+
+	if outputs[0] != outputs[1] {
+		t.Errorf("outputs differ:\n%s\n---\n%s", outputs[0], outputs[1])
+	}
+}
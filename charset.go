@@ -0,0 +1,440 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+
+package gedcom
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Character sets that may be named in the CHAR tag of a header.
+const (
+	CharsetUTF8    = "UTF-8"
+	CharsetUnicode = "UNICODE" // UTF-16, big or little endian
+	CharsetANSEL   = "ANSEL"
+	CharsetASCII   = "ASCII"
+	CharsetANSI    = "ANSI" // Windows code page 1252, not part of the standard but widely used
+)
+
+// WithCharset configures the decoder to read its input using the given character set,
+// one of the Charset constants, ignoring the CHAR tag of the header. By default the
+// decoder detects UTF-16 from the start of the input and otherwise reads the header as
+// UTF-8, switching to the character set named by its CHAR tag for the rest of the input.
+// Input in every character set is converted to UTF-8. WithCharset is needed to decode
+// input that is not UTF-8 from a start offset, since the header is not read.
+func WithCharset(name string) DecoderOption {
+	return decoderOptionFunc(func(d *Decoder) {
+		d.charset = name
+	})
+}
+
+// charsetReader is an io.RuneScanner that converts its input from a GEDCOM character set
+// to runes. The size returned by ReadRune is the number of bytes of input consumed so that
+// the scanner's offsets remain byte offsets into the input.
+type charsetReader struct {
+	r         *bufio.Reader
+	decode    func() (rune, int, error)
+	utf16     bool
+	pending   []decodedRune // runes decoded but not yet returned
+	last      decodedRune
+	canUnread bool
+	unread    bool
+	started   bool
+}
+
+type decodedRune struct {
+	r    rune
+	size int
+}
+
+// newCharsetReader returns a reader for r, which is read as UTF-16 if it begins with a
+// byte order mark or the pattern of zero bytes UTF-16 gives a level number, and as the
+// named character set otherwise.
+func newCharsetReader(r *bufio.Reader, charset string) *charsetReader {
+	c := &charsetReader{r: r}
+	if b, _ := r.Peek(4); len(b) >= 2 {
+		switch {
+		case b[0] == 0xFF && b[1] == 0xFE, len(b) == 4 && b[0] != 0 && b[1] == 0 && b[3] == 0:
+			c.utf16 = true
+			c.decode = c.readUTF16(false)
+			return c
+		case b[0] == 0xFE && b[1] == 0xFF, len(b) == 4 && b[0] == 0 && b[1] != 0 && b[2] == 0:
+			c.utf16 = true
+			c.decode = c.readUTF16(true)
+			return c
+		}
+	}
+	c.setCharset(charset)
+	return c
+}
+
+// setCharset switches the character set used for the rest of the input. It has no effect
+// on input read as UTF-16.
+func (c *charsetReader) setCharset(name string) {
+	if c.utf16 {
+		return
+	}
+	switch strings.ToUpper(strings.TrimSpace(name)) {
+	case CharsetANSEL:
+		c.decode = c.readANSEL
+	case CharsetASCII, CharsetANSI, "WINDOWS-1252", "CP1252":
+		c.decode = c.readCP1252
+	default:
+		c.decode = c.r.ReadRune
+	}
+}
+
+func (c *charsetReader) ReadRune() (rune, int, error) {
+	if c.unread {
+		c.unread = false
+		c.canUnread = true
+		return c.last.r, c.last.size, nil
+	}
+	c.canUnread = false
+
+	var dr decodedRune
+	if len(c.pending) > 0 {
+		dr = c.pending[0]
+		c.pending = c.pending[1:]
+	} else {
+		r, size, err := c.decode()
+		if err != nil {
+			return r, size, err
+		}
+		dr = decodedRune{r: r, size: size}
+	}
+
+	if !c.started {
+		c.started = true
+		if dr.r == 0xFEFF {
+			// Skip a byte order mark, counting its bytes with the following rune
+			r, size, err := c.ReadRune()
+			if err != nil {
+				return r, size, err
+			}
+			dr = decodedRune{r: r, size: size + dr.size}
+		}
+	}
+
+	c.last = dr
+	c.canUnread = true
+	return dr.r, dr.size, nil
+}
+
+func (c *charsetReader) UnreadRune() error {
+	if !c.canUnread {
+		return errors.New("invalid use of UnreadRune")
+	}
+	c.canUnread = false
+	c.unread = true
+	return nil
+}
+
+// readUTF16 returns a function that reads a rune encoded as UTF-16
+func (c *charsetReader) readUTF16(bigEndian bool) func() (rune, int, error) {
+	unit := func() (rune, error) {
+		b0, err := c.r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		b1, err := c.r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		if bigEndian {
+			return rune(b0)<<8 | rune(b1), nil
+		}
+		return rune(b1)<<8 | rune(b0), nil
+	}
+
+	return func() (rune, int, error) {
+		r1, err := unit()
+		if err != nil {
+			return 0, 0, err
+		}
+		if !utf16.IsSurrogate(r1) {
+			return r1, 2, nil
+		}
+		r2, err := unit()
+		if err != nil {
+			return utf8.RuneError, 2, nil
+		}
+		return utf16.DecodeRune(r1, r2), 4, nil
+	}
+}
+
+// readCP1252 reads a rune encoded as Windows code page 1252, which is compatible with
+// ASCII and ISO 8859-1 except for the range 0x80 to 0x9F
+func (c *charsetReader) readCP1252() (rune, int, error) {
+	b, err := c.r.ReadByte()
+	if err != nil {
+		return 0, 0, err
+	}
+	if b >= 0x80 && b < 0xA0 {
+		return cp1252[b-0x80], 1, nil
+	}
+	return rune(b), 1, nil
+}
+
+// readANSEL reads a rune encoded as ANSEL. ANSEL places combining diacritics before the
+// character they modify; they are returned after it, composed with it where Unicode has
+// a precomposed character.
+func (c *charsetReader) readANSEL() (rune, int, error) {
+	var marks []rune
+	size := 0
+	for {
+		b, err := c.r.ReadByte()
+		if err != nil {
+			if size > 0 {
+				// Diacritics with no following character
+				for _, m := range marks[1:] {
+					c.pending = append(c.pending, decodedRune{r: m})
+				}
+				return marks[0], size, nil
+			}
+			return 0, 0, err
+		}
+		size++
+		if m, ok := anselCombining[b]; ok {
+			marks = append(marks, m)
+			continue
+		}
+
+		r := rune(b)
+		if b >= 0x80 {
+			if ar, ok := ansel[b]; ok {
+				r = ar
+			} else {
+				r = utf8.RuneError
+			}
+		}
+		for i, m := range marks {
+			if cr, ok := anselComposed[[2]rune{r, m}]; ok && i == 0 {
+				r = cr
+				continue
+			}
+			c.pending = append(c.pending, decodedRune{r: m})
+		}
+		return r, size, nil
+	}
+}
+
+// cp1252 maps the bytes 0x80 to 0x9F of Windows code page 1252 to runes
+var cp1252 = [32]rune{
+	'€', utf8.RuneError, '‚', 'ƒ', '„', '…', '†', '‡', 'ˆ', '‰', 'Š', '‹', 'Œ', utf8.RuneError, 'Ž', utf8.RuneError,
+	utf8.RuneError, '‘', '’', '“', '”', '•', '–', '—', '˜', '™', 'š', '›', 'œ', utf8.RuneError, 'ž', 'Ÿ',
+}
+
+// ansel maps the spacing characters of ANSEL, including the GEDCOM extensions, to runes
+var ansel = map[byte]rune{
+	0xA1: 'Ł', 0xA2: 'Ø', 0xA3: 'Đ', 0xA4: 'Þ', 0xA5: 'Æ', 0xA6: 'Œ', 0xA7: 'ʹ', 0xA8: '·',
+	0xA9: '♭', 0xAA: '®', 0xAB: '±', 0xAC: 'Ơ', 0xAD: 'Ư', 0xAE: 'ʼ', 0xB0: 'ʻ', 0xB1: 'ł',
+	0xB2: 'ø', 0xB3: 'đ', 0xB4: 'þ', 0xB5: 'æ', 0xB6: 'œ', 0xB7: 'ʺ', 0xB8: 'ı', 0xB9: '£',
+	0xBA: 'ð', 0xBC: 'ơ', 0xBD: 'ư', 0xBE: '□', 0xBF: '■', 0xC0: '°', 0xC1: 'ℓ', 0xC2: '℗',
+	0xC3: '©', 0xC4: '♯', 0xC5: '¿', 0xC6: '¡', 0xC7: 'ß', 0xC8: '€', 0xCF: 'ß',
+}
+
+// anselCombining maps the combining diacritics of ANSEL to runes
+var anselCombining = map[byte]rune{
+	0xE0: '\u0309', 0xE1: '\u0300', 0xE2: '\u0301', 0xE3: '\u0302', 0xE4: '\u0303', 0xE5: '\u0304',
+	0xE6: '\u0306', 0xE7: '\u0307', 0xE8: '\u0308', 0xE9: '\u030C', 0xEA: '\u030A', 0xEB: '\uFE20',
+	0xEC: '\uFE21', 0xED: '\u0315', 0xEE: '\u030B', 0xEF: '\u0310', 0xF0: '\u0327', 0xF1: '\u0328',
+	0xF2: '\u0323', 0xF3: '\u0324', 0xF4: '\u0325', 0xF5: '\u0333', 0xF6: '\u0332', 0xF7: '\u0326',
+	0xF8: '\u031C', 0xF9: '\u032E', 0xFA: '\uFE22', 0xFB: '\uFE23', 0xFE: '\u0313',
+}
+
+// anselComposed maps a letter followed by a combining diacritic to the precomposed rune
+var anselComposed = func() map[[2]rune]rune {
+	letters := map[rune]struct{ base, composed string }{
+		0x0300: {"aeinouwyAEINOUWY", "àèìǹòùẁỳÀÈÌǸÒÙẀỲ"},
+		0x0301: {"acegiklmnoprsuwyzACEGIKLMNOPRSUWYZ", "áćéǵíḱĺḿńóṕŕśúẃýźÁĆÉǴÍḰĹḾŃÓṔŔŚÚẂÝŹ"},
+		0x0302: {"aceghijosuwyzACEGHIJOSUWYZ", "âĉêĝĥîĵôŝûŵŷẑÂĈÊĜĤÎĴÔŜÛŴŶẐ"},
+		0x0303: {"aeinouvyAEINOUVY", "ãẽĩñõũṽỹÃẼĨÑÕŨṼỸ"},
+		0x0304: {"aegiouyAEGIOUY", "āēḡīōūȳĀĒḠĪŌŪȲ"},
+		0x0306: {"aegiouAEGIOU", "ăĕğĭŏŭĂĔĞĬŎŬ"},
+		0x0307: {"abcdefghmnoprstwxyzABCDEFGHIMNOPRSTWXYZ", "ȧḃċḋėḟġḣṁṅȯṗṙṡṫẇẋẏżȦḂĊḊĖḞĠḢİṀṄȮṖṘṠṪẆẊẎŻ"},
+		0x0308: {"aehiotuwxyAEHIOUWXY", "äëḧïöẗüẅẍÿÄËḦÏÖÜẄẌŸ"},
+		0x0309: {"aeiouyAEIOUY", "ảẻỉỏủỷẢẺỈỎỦỶ"},
+		0x030A: {"auwyAU", "åůẘẙÅŮ"},
+		0x030B: {"ouOU", "őűŐŰ"},
+		0x030C: {"acdeghijklnorstuzACDEGHIKLNORSTUZ", "ǎčďěǧȟǐǰǩľňǒřšťǔžǍČĎĚǦȞǏǨĽŇǑŘŠŤǓŽ"},
+		0x0323: {"abdehiklmnorstuvwyzABDEHIKLMNORSTUVWYZ", "ạḅḍẹḥịḳḷṃṇọṛṣṭụṿẉỵẓẠḄḌẸḤỊḲḶṂṆỌṚṢṬỤṾẈỴẒ"},
+		0x0324: {"uU", "ṳṲ"},
+		0x0325: {"aA", "ḁḀ"},
+		0x0326: {"stST", "șțȘȚ"},
+		0x0327: {"cdeghklnrstCDEGHKLNRST", "çḑȩģḩķļņŗşţÇḐȨĢḨĶĻŅŖŞŢ"},
+		0x0328: {"aeiouAEIOU", "ąęįǫųĄĘĮǪŲ"},
+		0x032E: {"hH", "ḫḪ"},
+	}
+
+	m := make(map[[2]rune]rune)
+	for mark, l := range letters {
+		composed := []rune(l.composed)
+		for i, base := range []rune(l.base) {
+			m[[2]rune{base, mark}] = composed[i]
+		}
+	}
+	return m
+}()
+
+// WithOutputCharset configures the encoder to write its output in the given character
+// set, one of CharsetUTF8, CharsetANSEL or CharsetUnicode, and to name it in the CHAR tag
+// of the header, for older programs that do not read UTF-8. ANSEL output places
+// combining diacritics before the character they modify. Characters that ANSEL cannot
+// represent are written without their accents where possible and otherwise as a
+// question mark. Unicode output is little endian UTF-16 beginning with a byte order
+// mark. The default is UTF-8. GEDCOM 7 is always written as UTF-8.
+func WithOutputCharset(name string) EncoderOption {
+	return encoderOptionFunc(func(e *Encoder) {
+		e.charset = strings.ToUpper(strings.TrimSpace(name))
+	})
+}
+
+// charsetWriter is an io.Writer that converts UTF-8 to a GEDCOM character set. Data is
+// converted a line at a time so that an ANSEL character is never separated from the
+// diacritics that follow it in Unicode; Flush converts any incomplete final line.
+type charsetWriter struct {
+	w       io.Writer
+	encode  func(dst []byte, src []byte) []byte
+	bom     []byte // byte order mark written before the first data
+	pending []byte // data following the last line ending, not yet converted
+	buf     []byte
+}
+
+// newCharsetWriter returns a writer that converts UTF-8 to the named character set, or
+// nil if the character set is UTF-8 or not supported, reporting which
+func newCharsetWriter(w io.Writer, charset string) (*charsetWriter, bool) {
+	switch charset {
+	case "", CharsetUTF8:
+		return nil, true
+	case CharsetANSEL:
+		return &charsetWriter{w: w, encode: appendANSEL}, true
+	case CharsetUnicode:
+		return &charsetWriter{w: w, encode: appendUTF16, bom: []byte{0xFF, 0xFE}}, true
+	}
+	return nil, false
+}
+
+func (c *charsetWriter) Write(p []byte) (int, error) {
+	c.pending = append(c.pending, p...)
+	i := bytes.LastIndexAny(c.pending, "\r\n")
+	if i < 0 {
+		return len(p), nil
+	}
+	if err := c.write(c.pending[:i+1]); err != nil {
+		return 0, err
+	}
+	c.pending = append(c.pending[:0], c.pending[i+1:]...)
+	return len(p), nil
+}
+
+// Flush converts and writes any data held back waiting for the end of a line
+func (c *charsetWriter) Flush() error {
+	err := c.write(c.pending)
+	c.pending = c.pending[:0]
+	return err
+}
+
+func (c *charsetWriter) write(p []byte) error {
+	c.buf = append(c.buf[:0], c.bom...)
+	c.bom = nil
+	c.buf = c.encode(c.buf, p)
+	if len(c.buf) == 0 {
+		return nil
+	}
+	_, err := c.w.Write(c.buf)
+	return err
+}
+
+// appendUTF16 appends the little endian UTF-16 encoding of src to dst
+func appendUTF16(dst []byte, src []byte) []byte {
+	var units []uint16
+	for _, r := range string(src) {
+		units = utf16.AppendRune(units[:0], r)
+		for _, u := range units {
+			dst = append(dst, byte(u), byte(u>>8))
+		}
+	}
+	return dst
+}
+
+// appendANSEL appends the ANSEL encoding of src to dst, writing the diacritics of each
+// character before it
+func appendANSEL(dst []byte, src []byte) []byte {
+	enc := anselEncoding()
+	runes := []rune(string(src))
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		i++
+		var marks []byte
+		if d, ok := enc.decomposed[r]; ok {
+			r = d[0]
+			marks = append(marks, enc.bytes[d[1]])
+		}
+		for i < len(runes) {
+			m, ok := enc.bytes[runes[i]]
+			if !ok || m < 0xE0 {
+				break
+			}
+			marks = append(marks, m)
+			i++
+		}
+		dst = append(dst, marks...)
+
+		switch b, ok := enc.bytes[r]; {
+		case r < utf8.RuneSelf:
+			dst = append(dst, byte(r))
+		case ok:
+			dst = append(dst, b)
+		default:
+			if s := StripDiacritics(string(r)); s != string(r) && isASCII(s) {
+				dst = append(dst, s...)
+			} else {
+				dst = append(dst, '?')
+			}
+		}
+	}
+	return dst
+}
+
+// anselEncoding returns the tables used to convert runes to ANSEL: the byte of each
+// spacing character and combining diacritic, and the letter and diacritic of each
+// precomposed character
+var anselEncoding = sync.OnceValue(func() (enc struct {
+	bytes      map[rune]byte
+	decomposed map[rune][2]rune
+}) {
+	enc.bytes = make(map[rune]byte)
+	for b, r := range ansel {
+		enc.bytes[r] = b
+	}
+	// ß has two codes; 0xCF is the standard one
+	enc.bytes['ß'] = 0xCF
+	for b, r := range anselCombining {
+		enc.bytes[r] = b
+	}
+	enc.decomposed = make(map[rune][2]rune)
+	for d, r := range anselComposed {
+		enc.decomposed[r] = d
+	}
+	return enc
+})
+
+// isASCII reports whether s contains only ASCII characters
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,211 @@
+package gedcom
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"unicode/utf16"
+)
+
+func encodeUTF16(s string, bigEndian bool, bom bool) []byte {
+	units := utf16.Encode([]rune(s))
+	if bom {
+		units = append([]uint16{0xFEFF}, units...)
+	}
+	buf := new(bytes.Buffer)
+	var order binary.ByteOrder = binary.LittleEndian
+	if bigEndian {
+		order = binary.BigEndian
+	}
+	binary.Write(buf, order, units)
+	return buf.Bytes()
+}
+
+func TestDecodeCharset(t *testing.T) {
+	const name = "José /Müller/ Łukasz"
+	utf8Input := "0 HEAD\n1 CHAR UTF-8\n0 @I1@ INDI\n1 NAME " + name + "\n0 TRLR\n"
+	unicodeInput := "0 HEAD\n1 CHAR UNICODE\n0 @I1@ INDI\n1 NAME " + name + "\n0 TRLR\n"
+
+	testCases := []struct {
+		name  string
+		input []byte
+		opts  []DecoderOption
+		want  string
+	}{
+		{
+			name:  "utf-8",
+			input: []byte(utf8Input),
+			want:  name,
+		},
+		{
+			name:  "utf-8 bom",
+			input: append([]byte("\xef\xbb\xbf"), utf8Input...),
+			want:  name,
+		},
+		{
+			name:  "utf-16le bom",
+			input: encodeUTF16(unicodeInput, false, true),
+			want:  name,
+		},
+		{
+			name:  "utf-16be bom",
+			input: encodeUTF16(unicodeInput, true, true),
+			want:  name,
+		},
+		{
+			name:  "utf-16le",
+			input: encodeUTF16(unicodeInput, false, false),
+			want:  name,
+		},
+		{
+			name:  "utf-16be",
+			input: encodeUTF16(unicodeInput, true, false),
+			want:  name,
+		},
+		{
+			name:  "ansel",
+			input: []byte("0 HEAD\n1 CHAR ANSEL\n0 @I1@ INDI\n1 NAME Jos\xe2e /M\xe8uller/ \xa1ukasz\n0 TRLR\n"),
+			want:  name,
+		},
+		{
+			name:  "ansel uncomposed",
+			input: []byte("0 HEAD\n1 CHAR ANSEL\n0 @I1@ INDI\n1 NAME \xe2\xe8q \xa1\n0 TRLR\n"),
+			want:  "q́̈ Ł",
+		},
+		{
+			name:  "ansi",
+			input: []byte("0 HEAD\n1 CHAR ANSI\n0 @I1@ INDI\n1 NAME Jos\xe9 /M\xfcller/ \x80\n0 TRLR\n"),
+			want:  "José /Müller/ €",
+		},
+		{
+			name:  "ascii with high bytes",
+			input: []byte("0 HEAD\n1 CHAR ASCII\n0 @I1@ INDI\n1 NAME Jos\xe9\n0 TRLR\n"),
+			want:  "José",
+		},
+		{
+			name:  "forced charset",
+			input: []byte("0 HEAD\n1 CHAR UTF-8\n0 @I1@ INDI\n1 NAME Jos\xe9\n0 TRLR\n"),
+			opts:  []DecoderOption{WithCharset(CharsetANSI)},
+			want:  "José",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g, err := NewDecoder(bytes.NewReader(tc.input), tc.opts...).Decode()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(g.Individual) != 1 || len(g.Individual[0].Name) != 1 {
+				t.Fatalf("individual name was not decoded")
+			}
+			if got := g.Individual[0].Name[0].Name; got != tc.want {
+				t.Errorf("got name %q, wanted %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestDecodeCharsetOffsets(t *testing.T) {
+	// Offsets are byte offsets into the input even when characters are converted
+	input := []byte("0 HEAD\n1 CHAR ANSEL\n0 @I1@ INDI\n1 NAME Jos\xe2e\n0 @I2@ INDI\n1 NAME Ann\n0 TRLR\n")
+	d := NewDecoder(bytes.NewReader(input))
+	var offsets []int64
+	for {
+		r, err := d.Next()
+		if err != nil {
+			break
+		}
+		if _, ok := r.(*IndividualRecord); ok {
+			offsets = append(offsets, d.RecordOffset())
+		}
+	}
+	want := int64(bytes.Index(input, []byte("0 @I2@")))
+	if len(offsets) != 2 || offsets[1] != want {
+		t.Errorf("got offsets %v, wanted second offset %d", offsets, want)
+	}
+}
+
+func TestEncodeCharset(t *testing.T) {
+	g := &Gedcom{
+		Header: &Header{CharacterSet: CharsetUTF8, Version: "5.5.1"},
+		Individual: []*IndividualRecord{
+			{Xref: "I1", Name: []*NameRecord{{Name: "José /Müller/"}}},
+			{Xref: "I2", Name: []*NameRecord{{Name: "Łukasz /Søren/"}}},
+		},
+	}
+
+	testCases := []struct {
+		charset string
+		prefix  []byte
+		want    []byte
+	}{
+		{
+			charset: CharsetANSEL,
+			prefix:  []byte("0 HEAD\n1 CHAR ANSEL\n"),
+			want:    []byte("1 NAME Jos\xe2e /M\xe8uller/\n"),
+		},
+		{
+			charset: CharsetUnicode,
+			prefix:  []byte{0xFF, 0xFE, '0', 0, ' ', 0, 'H', 0, 'E', 0, 'A', 0, 'D', 0, '\n', 0},
+			want:    []byte{'J', 0, 'o', 0, 's', 0, 0xE9, 0},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.charset, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			if err := NewEncoder(buf, WithOutputCharset(tc.charset)).Encode(g); err != nil {
+				t.Fatalf("unexpected encode error: %v", err)
+			}
+			out := buf.Bytes()
+			if !bytes.HasPrefix(out, tc.prefix) {
+				t.Errorf("output does not begin with %q: %q", tc.prefix, out)
+			}
+			if !bytes.Contains(out, tc.want) {
+				t.Errorf("output does not contain %q: %q", tc.want, out)
+			}
+
+			got, err := NewDecoder(bytes.NewReader(out)).Decode()
+			if err != nil {
+				t.Fatalf("unexpected decode error: %v", err)
+			}
+			if got.Header.CharacterSet != tc.charset {
+				t.Errorf("got CHAR %q, wanted %q", got.Header.CharacterSet, tc.charset)
+			}
+			for i, ind := range g.Individual {
+				if name := got.Individual[i].Name[0].Name; name != ind.Name[0].Name {
+					t.Errorf("got name %q, wanted %q", name, ind.Name[0].Name)
+				}
+			}
+		})
+	}
+
+	if g.Header.CharacterSet != CharsetUTF8 {
+		t.Errorf("encoding modified the header")
+	}
+}
+
+func TestEncodeANSELUnmapped(t *testing.T) {
+	testCases := []struct {
+		in   string
+		want string
+	}{
+		{in: "Jose\u0301", want: "Jos\xe2e"},               // decomposed accent
+		{in: "Ærø ß", want: "\xa5r\xb2 \xcf"},              // spacing characters
+		{in: "Nguye\u0302\u0303n", want: "Nguy\xe3\xe4en"}, // two diacritics on one letter
+		{in: "Nguyễn 日本", want: "Nguy?n ??"},               // not representable
+	}
+	for _, tc := range testCases {
+		if got := string(appendANSEL(nil, []byte(tc.in))); got != tc.want {
+			t.Errorf("appendANSEL(%q) = %q, wanted %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestEncodeUnsupportedCharset(t *testing.T) {
+	g := &Gedcom{Header: &Header{}}
+	if err := NewEncoder(new(bytes.Buffer), WithOutputCharset("EBCDIC")).Encode(g); err == nil {
+		t.Errorf("expected an error for an unsupported character set")
+	}
+}
@@ -0,0 +1,56 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+
+package gedcom
+
+// A ResolvedCitation is a flattened view of a source citation combined with the details
+// of the source it cites and the repository holding that source.
+type ResolvedCitation struct {
+	Page           string
+	Quay           string
+	DataDate       string
+	DataText       []string
+	SourceXref     string
+	Title          string
+	Author         string
+	Abbreviation   string
+	Publication    string
+	RepositoryXref string
+	RepositoryName string
+	CallNumbers    []*SourceCallNumberRecord
+}
+
+// Resolve follows the chain from the citation to its source and the source's repository,
+// returning the combined details. Any missing links in the chain are left empty.
+func (c *CitationRecord) Resolve() ResolvedCitation {
+	rc := ResolvedCitation{
+		Page:     c.Page,
+		Quay:     c.Quay,
+		DataDate: c.Data.Date,
+		DataText: c.Data.Text,
+	}
+
+	s := c.Source
+	if s == nil {
+		return rc
+	}
+	rc.SourceXref = s.Xref
+	rc.Title = s.Title
+	rc.Author = s.Originator
+	rc.Abbreviation = s.FiledBy
+	rc.Publication = s.PublicationFacts
+
+	if s.Repository == nil {
+		return rc
+	}
+	rc.CallNumbers = s.Repository.CallNumber
+
+	if r := s.Repository.Repository; r != nil {
+		rc.RepositoryXref = r.Xref
+		rc.RepositoryName = r.Name
+	}
+
+	return rc
+}
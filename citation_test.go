@@ -0,0 +1,86 @@
+package gedcom
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCitationResolve(t *testing.T) {
+	input := `
+0 @I1@ INDI
+1 NAME John /Smith/
+1 SOUR @S1@
+2 PAGE Folio 12
+2 QUAY 3
+2 DATA
+3 DATE 1 JAN 1850
+3 TEXT Baptised John son of William
+1 SOUR @S2@
+0 @S1@ SOUR
+1 TITL Parish Register of St Mary
+1 AUTH Church of England
+1 ABBR St Mary PR
+1 PUBL Manuscript
+1 REPO @R1@
+2 CALN P12/1/3
+3 MEDI Microfilm
+0 @R1@ REPO
+1 NAME County Record Office
+0 @S2@ SOUR
+1 TITL Family Bible
+`
+	g, err := NewDecoder(strings.NewReader(input)).Decode()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	testCases := []struct {
+		name string
+		c    *CitationRecord
+		want ResolvedCitation
+	}{
+		{
+			name: "full chain",
+			c:    g.Individual[0].Citation[0],
+			want: ResolvedCitation{
+				Page:           "Folio 12",
+				Quay:           "3",
+				DataDate:       "1 JAN 1850",
+				DataText:       []string{"Baptised John son of William"},
+				SourceXref:     "S1",
+				Title:          "Parish Register of St Mary",
+				Author:         "Church of England",
+				Abbreviation:   "St Mary PR",
+				Publication:    "Manuscript",
+				RepositoryXref: "R1",
+				RepositoryName: "County Record Office",
+				CallNumbers:    []*SourceCallNumberRecord{{CallNumber: "P12/1/3", MediaType: "Microfilm"}},
+			},
+		},
+		{
+			name: "no repository",
+			c:    g.Individual[0].Citation[1],
+			want: ResolvedCitation{
+				SourceXref: "S2",
+				Title:      "Family Bible",
+			},
+		},
+		{
+			name: "no source",
+			c:    &CitationRecord{Page: "p. 4"},
+			want: ResolvedCitation{
+				Page: "p. 4",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, tc.c.Resolve()); diff != "" {
+				t.Errorf("resolved citation mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+
+package gedcom
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Compact reduces the memory held by the Gedcom after decoding. Identical strings are
+// made to share storage, slices are trimmed to their length and substructures that hold
+// no data, such as an empty NoteRecord, are dropped. The top-level record lists, Header
+// and Trailer are always retained. Compact does not change the meaning of the data.
+func (g *Gedcom) Compact() {
+	c := &compactor{
+		strs:    make(map[string]string),
+		visited: make(map[uintptr]bool),
+	}
+
+	gv := reflect.ValueOf(g).Elem()
+	for i := 0; i < gv.NumField(); i++ {
+		f := gv.Field(i)
+		switch f.Kind() {
+		case reflect.Pointer:
+			c.walk(f)
+		case reflect.Slice:
+			wasSet := !f.IsNil()
+			c.slice(f)
+			if wasSet && f.IsNil() {
+				f.Set(reflect.MakeSlice(f.Type(), 0, 0))
+			}
+		}
+	}
+}
+
+type compactor struct {
+	strs    map[string]string
+	visited map[uintptr]bool
+}
+
+// walk compacts the value held by v, which must be addressable
+func (c *compactor) walk(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.String:
+		s := v.String()
+		if s == "" {
+			return
+		}
+		if is, ok := c.strs[s]; ok {
+			v.SetString(is)
+			return
+		}
+		// The string may be a substring of a larger one, such as the text of the line it
+		// was read from, which a copy does not keep alive
+		is := strings.Clone(s)
+		c.strs[is] = is
+		v.SetString(is)
+	case reflect.Pointer:
+		if v.IsNil() {
+			return
+		}
+		// Records are shared between many references, so visit each only once
+		if c.visited[v.Pointer()] {
+			return
+		}
+		c.visited[v.Pointer()] = true
+		c.walk(v.Elem())
+	case reflect.Slice:
+		c.slice(v)
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if !v.Type().Field(i).IsExported() {
+				continue
+			}
+			f := v.Field(i)
+			c.walk(f)
+			if f.Kind() == reflect.Pointer && !f.IsNil() && f.Elem().Kind() == reflect.Struct && f.Elem().IsZero() {
+				f.Set(reflect.Zero(f.Type()))
+			}
+		}
+	}
+}
+
+// slice compacts each element of the slice held by v, removes elements that point to
+// empty structures and trims the capacity of the slice to its length
+func (c *compactor) slice(v reflect.Value) {
+	if v.IsNil() {
+		return
+	}
+	n := 0
+	for i := 0; i < v.Len(); i++ {
+		e := v.Index(i)
+		c.walk(e)
+		if e.Kind() == reflect.Pointer && (e.IsNil() || (e.Elem().Kind() == reflect.Struct && e.Elem().IsZero())) {
+			continue
+		}
+		if n != i {
+			v.Index(n).Set(e)
+		}
+		n++
+	}
+
+	if n == 0 {
+		v.Set(reflect.Zero(v.Type()))
+		return
+	}
+	if v.Cap() == n {
+		return
+	}
+	trimmed := reflect.MakeSlice(v.Type(), n, n)
+	reflect.Copy(trimmed, v.Slice(0, n))
+	v.Set(trimmed)
+}
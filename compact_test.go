@@ -0,0 +1,86 @@
+package gedcom
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"unsafe"
+)
+
+func TestCompact(t *testing.T) {
+	g, err := NewDecoder(bytes.NewReader(data)).Decode()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var before bytes.Buffer
+	if err := NewEncoder(&before).Encode(g); err != nil {
+		t.Fatalf("unexpected encode error: %v", err)
+	}
+
+	g.Compact()
+
+	var after bytes.Buffer
+	if err := NewEncoder(&after).Encode(g); err != nil {
+		t.Fatalf("unexpected encode error: %v", err)
+	}
+	if before.String() != after.String() {
+		t.Errorf("encoded output changed after compaction")
+	}
+
+	if cap(g.Individual) != len(g.Individual) {
+		t.Errorf("got individual capacity %d, wanted %d", cap(g.Individual), len(g.Individual))
+	}
+}
+
+func TestCompactDropsEmpty(t *testing.T) {
+	input := `
+0 @I1@ INDI
+1 NAME John /Smith/
+1 NOTE
+1 SEX M
+0 @I2@ INDI
+1 NAME Mary /Smith/
+1 SEX M
+0 @F1@ FAM
+1 HUSB @I1@
+`
+	g, err := NewDecoder(strings.NewReader(input)).Decode()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(g.Individual[0].Note) != 1 {
+		t.Fatalf("got %d notes before compaction, wanted 1", len(g.Individual[0].Note))
+	}
+
+	g.Compact()
+
+	if g.Individual[0].Note != nil {
+		t.Errorf("got %d notes, wanted empty note to be dropped", len(g.Individual[0].Note))
+	}
+	if g.Media == nil || g.Source == nil {
+		t.Errorf("top-level lists should remain non-nil")
+	}
+	if g.Family[0].Husband != g.Individual[0] {
+		t.Errorf("family husband no longer refers to individual record")
+	}
+
+	s1, s2 := g.Individual[0].Sex, g.Individual[1].Sex
+	if unsafe.StringData(s1) != unsafe.StringData(s2) {
+		t.Errorf("identical strings do not share storage")
+	}
+}
+
+func TestCompactCopiesStrings(t *testing.T) {
+	line := "1 NAME John /Smith/"
+	g := &Gedcom{Individual: []*IndividualRecord{{Name: []*NameRecord{{Name: line[7:]}}}}}
+	g.Compact()
+
+	name := g.Individual[0].Name[0].Name
+	if name != "John /Smith/" {
+		t.Fatalf("got name %q, wanted %q", name, "John /Smith/")
+	}
+	if unsafe.StringData(name) == unsafe.StringData(line[7:]) {
+		t.Errorf("compacted name shares storage with the line it was taken from")
+	}
+}
@@ -0,0 +1,266 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+
+package gedcom
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// A CSVMapping names the CSV columns that hold each field of an individual. Columns are
+// matched case-insensitively against the header row. Fields mapped to an empty column
+// name are not imported.
+type CSVMapping struct {
+	ID         string // unique identifier of the row, used as the individual's xref
+	Name       string // full name in GEDCOM format, e.g. "John /Smith/"
+	Given      string // given names, used when Name is not mapped or empty
+	Surname    string // surname, used when Name is not mapped or empty
+	Sex        string
+	BirthDate  string
+	BirthPlace string
+	DeathDate  string
+	DeathPlace string
+	Father     string // ID of the individual's father
+	Mother     string // ID of the individual's mother
+	Spouse     string // IDs of the individual's spouses, separated by semicolons
+}
+
+// DefaultCSVMapping returns a CSVMapping using lower case column names such as "id",
+// "given", "surname" and "birth_date".
+func DefaultCSVMapping() CSVMapping {
+	return CSVMapping{
+		ID:         "id",
+		Name:       "name",
+		Given:      "given",
+		Surname:    "surname",
+		Sex:        "sex",
+		BirthDate:  "birth_date",
+		BirthPlace: "birth_place",
+		DeathDate:  "death_date",
+		DeathPlace: "death_place",
+		Father:     "father",
+		Mother:     "mother",
+		Spouse:     "spouse",
+	}
+}
+
+// ImportCSV reads individuals from CSV data whose first row is a header and builds a Gedcom
+// containing an IndividualRecord per row. Families are created for each distinct pair of
+// parents and each pair of spouses, and linked to their members. A couple named as each
+// other's spouses forms one family. The father and mother named by a child are the
+// husband and wife of their family; in other families a male partner is the husband and
+// a female partner the wife. Columns named in the mapping but missing from the header
+// are ignored.
+//
+// Each individual's xref is its ID with any character that is not a letter or digit
+// replaced by an underscore. An error is returned if two IDs give the same xref. Rows
+// with no ID and families are given xrefs such as I1 and F1, skipping any already used.
+func ImportCSV(r io.Reader, m CSVMapping) (*Gedcom, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("read csv header: missing header row")
+		}
+		return nil, fmt.Errorf("read csv header: %w", err)
+	}
+	cols := make(map[string]int, len(header))
+	for i, h := range header {
+		cols[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+
+	g := &Gedcom{
+		Header: &Header{
+			CharacterSet: "UTF-8",
+			Version:      "5.5.1",
+			Form:         "LINEAGE-LINKED",
+		},
+		Family:     make([]*FamilyRecord, 0),
+		Individual: make([]*IndividualRecord, 0),
+		Trailer:    &Trailer{},
+	}
+
+	type links struct {
+		row    int
+		father string
+		mother string
+		spouse string
+	}
+
+	byID := make(map[string]*IndividualRecord)
+	used := make(map[string]string) // ID that each xref was made from
+	var pending []links
+
+	for row := 2; ; row++ {
+		rec, err := cr.Read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("read csv row %d: %w", row, err)
+		}
+		field := func(col string) string {
+			if col == "" {
+				return ""
+			}
+			i, ok := cols[strings.ToLower(col)]
+			if !ok || i >= len(rec) {
+				return ""
+			}
+			return strings.TrimSpace(rec[i])
+		}
+
+		indi := &IndividualRecord{
+			Sex: strings.ToUpper(field(m.Sex)),
+		}
+
+		// Rows without an ID are given an xref once all the IDs are known
+		if id := field(m.ID); id != "" {
+			if _, exists := byID[id]; exists {
+				return nil, fmt.Errorf("csv row %d: duplicate id %q", row, id)
+			}
+			indi.Xref = csvXref(id)
+			if other, exists := used[indi.Xref]; exists {
+				return nil, fmt.Errorf("csv row %d: id %q gives the same xref as id %q", row, id, other)
+			}
+			used[indi.Xref] = id
+			byID[id] = indi
+		}
+
+		name := field(m.Name)
+		given, surname := field(m.Given), field(m.Surname)
+		if name == "" && (given != "" || surname != "") {
+			name = strings.TrimSpace(given + " /" + surname + "/")
+		}
+		if name != "" {
+			indi.Name = append(indi.Name, &NameRecord{Name: name, NamePieceGiven: given, NamePieceSurname: surname})
+		}
+
+		if date, place := field(m.BirthDate), field(m.BirthPlace); date != "" || place != "" {
+			indi.Event = append(indi.Event, &EventRecord{Tag: "BIRT", Date: date, Place: PlaceRecord{Name: place}})
+		}
+		if date, place := field(m.DeathDate), field(m.DeathPlace); date != "" || place != "" {
+			indi.Event = append(indi.Event, &EventRecord{Tag: "DEAT", Date: date, Place: PlaceRecord{Name: place}})
+		}
+
+		g.Individual = append(g.Individual, indi)
+		pending = append(pending, links{row: row, father: field(m.Father), mother: field(m.Mother), spouse: field(m.Spouse)})
+	}
+
+	next := make(map[string]int)
+	newXref := func(prefix string) string {
+		for {
+			next[prefix]++
+			xref := prefix + strconv.Itoa(next[prefix])
+			if _, exists := used[xref]; !exists {
+				used[xref] = ""
+				return xref
+			}
+		}
+	}
+	for _, indi := range g.Individual {
+		if indi.Xref == "" {
+			indi.Xref = newXref("I")
+		}
+	}
+
+	// Families are keyed by the unordered pair of partners so that a couple named in
+	// both of their rows forms one family. The roles of partners named by the father and
+	// mother columns are known; the others are decided by sex once all rows are linked.
+	pos := make(map[*IndividualRecord]int, len(g.Individual))
+	for i, indi := range g.Individual {
+		pos[indi] = i
+	}
+	families := make(map[[2]*IndividualRecord]*FamilyRecord)
+	roles := make(map[*FamilyRecord]bool)
+	family := func(husb, wife *IndividualRecord, known bool) *FamilyRecord {
+		key := [2]*IndividualRecord{husb, wife}
+		if husb != nil && wife != nil && pos[wife] < pos[husb] {
+			key = [2]*IndividualRecord{wife, husb}
+		}
+		if f, ok := families[key]; ok {
+			if known && !roles[f] {
+				f.Husband, f.Wife = husb, wife
+				roles[f] = true
+			}
+			return f
+		}
+		f := &FamilyRecord{Xref: newXref("F"), Husband: husb, Wife: wife}
+		families[key] = f
+		roles[f] = known
+		g.Family = append(g.Family, f)
+		for _, p := range key {
+			if p != nil {
+				p.Family = append(p.Family, &FamilyLinkRecord{Family: f})
+			}
+		}
+		return f
+	}
+	lookup := func(row int, id string) (*IndividualRecord, error) {
+		if id == "" {
+			return nil, nil
+		}
+		indi, ok := byID[id]
+		if !ok {
+			return nil, fmt.Errorf("csv row %d: unknown individual %q", row, id)
+		}
+		return indi, nil
+	}
+
+	for i, l := range pending {
+		indi := g.Individual[i]
+
+		father, err := lookup(l.row, l.father)
+		if err != nil {
+			return nil, err
+		}
+		mother, err := lookup(l.row, l.mother)
+		if err != nil {
+			return nil, err
+		}
+		if father != nil || mother != nil {
+			f := family(father, mother, true)
+			f.Child = append(f.Child, indi)
+			indi.Parents = append(indi.Parents, &FamilyLinkRecord{Family: f})
+		}
+
+		for _, sid := range strings.Split(l.spouse, ";") {
+			spouse, err := lookup(l.row, strings.TrimSpace(sid))
+			if err != nil {
+				return nil, err
+			}
+			if spouse == nil {
+				continue
+			}
+			family(indi, spouse, false)
+		}
+	}
+
+	for _, f := range g.Family {
+		if !roles[f] && (f.Husband.Sex == "F" || f.Wife.Sex == "M") {
+			f.Husband, f.Wife = f.Wife, f.Husband
+		}
+	}
+
+	return g, nil
+}
+
+// csvXref converts an identifier into a form that is valid as a GEDCOM cross reference
+func csvXref(id string) string {
+	return strings.Map(func(r rune) rune {
+		if isAlphaNumeric(r) {
+			return r
+		}
+		return '_'
+	}, id)
+}
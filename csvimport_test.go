@@ -0,0 +1,132 @@
+package gedcom
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestImportCSV(t *testing.T) {
+	input := `id,given,surname,sex,birth_date,birth_place,father,mother,spouse
+p1,John,Smith,M,1 JAN 1850,London,,,p2
+p2,Mary,Jones,F,1852,,,,p1
+p3,William,Smith,M,3 MAR 1875,Leeds,p1,p2,
+p4,Ann,Smith,F,1877,,p1,p2,
+`
+	g, err := ImportCSV(strings.NewReader(input), DefaultCSVMapping())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(g.Individual) != 4 {
+		t.Fatalf("got %d individuals, wanted 4", len(g.Individual))
+	}
+	if len(g.Family) != 1 {
+		t.Fatalf("got %d families, wanted 1", len(g.Family))
+	}
+
+	f := g.Family[0]
+	if f.Husband == nil || f.Husband.Xref != "p1" {
+		t.Errorf("got husband %v, wanted p1", f.Husband)
+	}
+	if f.Wife == nil || f.Wife.Xref != "p2" {
+		t.Errorf("got wife %v, wanted p2", f.Wife)
+	}
+	if len(f.Child) != 2 {
+		t.Errorf("got %d children, wanted 2", len(f.Child))
+	}
+
+	john := g.Individual[0]
+	if john.Name[0].Name != "John /Smith/" {
+		t.Errorf("got name %q, wanted %q", john.Name[0].Name, "John /Smith/")
+	}
+	if len(john.Family) != 1 {
+		t.Errorf("got %d spouse family links, wanted 1", len(john.Family))
+	}
+	if john.Event[0].Date != "1 JAN 1850" || john.Event[0].Place.Name != "London" {
+		t.Errorf("got birth %q at %q, wanted 1 JAN 1850 at London", john.Event[0].Date, john.Event[0].Place.Name)
+	}
+
+	// The imported tree should encode and decode cleanly
+	buf := new(bytes.Buffer)
+	if err := NewEncoder(buf).Encode(g); err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	g2, err := NewDecoder(buf).Decode()
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(g2.Individual) != 4 || len(g2.Family) != 1 {
+		t.Errorf("got %d individuals and %d families after round trip, wanted 4 and 1", len(g2.Individual), len(g2.Family))
+	}
+	if len(g2.Individual[2].Parents) != 1 || g2.Individual[2].Parents[0].Family.Xref != "F1" {
+		t.Errorf("child was not linked to parents after round trip")
+	}
+}
+
+func TestImportCSVUnknownReference(t *testing.T) {
+	input := "id,name,father\np1,John /Smith/,p9\n"
+	_, err := ImportCSV(strings.NewReader(input), DefaultCSVMapping())
+	if err == nil {
+		t.Fatalf("got no error, wanted error for unknown reference")
+	}
+}
+
+func TestImportCSVXrefs(t *testing.T) {
+	input := `id,name,sex,spouse
+,Anne /Brown/,F,
+I1,John /Smith/,M,F1
+F1,Mary /Jones/,F,I1
+`
+	g, err := ImportCSV(strings.NewReader(input), DefaultCSVMapping())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got []string
+	for _, indi := range g.Individual {
+		got = append(got, indi.Xref)
+	}
+	for _, f := range g.Family {
+		got = append(got, f.Xref)
+	}
+	if want := "I2,I1,F1,F2"; strings.Join(got, ",") != want {
+		t.Errorf("got xrefs %s, wanted %s", strings.Join(got, ","), want)
+	}
+}
+
+func TestImportCSVXrefCollision(t *testing.T) {
+	input := "id,name\na-1,John /Smith/\na_1,Mary /Smith/\n"
+	_, err := ImportCSV(strings.NewReader(input), DefaultCSVMapping())
+	if err == nil {
+		t.Fatalf("got no error, wanted error for ids giving the same xref")
+	}
+}
+
+func TestImportCSVReciprocalSpouses(t *testing.T) {
+	input := `id,name,sex,spouse,father,mother
+a,Alex /Smith/,U,b,,
+b,Sam /Smith/,U,a,,
+c,Jo /Smith/,,,b,a
+`
+	g, err := ImportCSV(strings.NewReader(input), DefaultCSVMapping())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(g.Family) != 1 {
+		t.Fatalf("got %d families, wanted 1", len(g.Family))
+	}
+	f := g.Family[0]
+	if f.Husband == nil || f.Husband.Xref != "b" || f.Wife == nil || f.Wife.Xref != "a" {
+		t.Errorf("got husband %v and wife %v, wanted b and a as named by the father and mother columns", f.Husband, f.Wife)
+	}
+	if len(f.Child) != 1 {
+		t.Errorf("got %d children, wanted 1", len(f.Child))
+	}
+	for _, indi := range g.Individual[:2] {
+		if len(indi.Family) != 1 {
+			t.Errorf("got %d spouse family links for %s, wanted 1", len(indi.Family), indi.Xref)
+		}
+	}
+}
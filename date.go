@@ -0,0 +1,799 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+
+package gedcom
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Calendars of a Date.
+const (
+	CalendarGregorian = "GREGORIAN"
+	CalendarJulian    = "JULIAN"
+	CalendarHebrew    = "HEBREW"
+	CalendarFrench    = "FRENCH R" // French Republican
+)
+
+// A DateValue holds the components of a date value such as "ABT 1850", "BET 1 JAN 1900
+// AND 1905" or "INT 1900 (about the turn of the century)".
+type DateValue struct {
+	// Qualifier is one of ABT, CAL, EST, BEF, AFT, BET, FROM, TO or INT, or empty for an
+	// exact date or a date phrase with no date.
+	Qualifier string
+
+	Date1 Date // the date, or the first date of a BET or FROM date
+	Date2 Date // the second date of a BET date or a FROM date with a TO part, otherwise zero
+
+	// Phrase is the text of a date phrase, given in parentheses after an INT date or on
+	// its own, without the parentheses.
+	Phrase string
+}
+
+// A Date is a single calendar date within a date value. Month and Day are zero when they
+// were not given. Months are numbered from 1 in the order of the calendar's month names,
+// so the months of the Hebrew calendar run from TSH to ELL and of the French Republican
+// calendar from VEND to COMP.
+type Date struct {
+	Calendar string // one of the Calendar constants
+	Year     int
+	Month    int
+	Day      int
+	BC       bool // the year is before the common era
+
+	// DualYear is the second year of a dual year such as 1732/33, given in full as 1733,
+	// or zero if the year is not a dual year. Dual years record dates between 1 January
+	// and 24 March in the years when the year was still reckoned in Britain and its
+	// colonies to begin on 25 March. Year holds the first, old style, year, which is kept
+	// for display; the date is placed in time, compared and sorted using DualYear, the
+	// year in which the day fell when the year is reckoned from 1 January.
+	DualYear int
+}
+
+// A DualYearStyle selects which year of a dual year is used.
+type DualYearStyle int
+
+const (
+	OldStyle DualYearStyle = iota // the first year, in which the year began on 25 March
+	NewStyle                      // the second year, in which the year began on 1 January
+)
+
+// Resolve returns d with a dual year replaced by the year of the given style. Dates that
+// do not have a dual year are returned unchanged. Resolving to OldStyle is intended for
+// display, since the resulting date is placed in time using the old style year.
+func (d Date) Resolve(style DualYearStyle) Date {
+	if d.DualYear == 0 {
+		return d
+	}
+	if style == NewStyle {
+		d.Year = d.DualYear
+	}
+	d.DualYear = 0
+	return d
+}
+
+// Resolve returns dv with the dual years of its dates replaced by the years of the given
+// style.
+func (dv DateValue) Resolve(style DualYearStyle) DateValue {
+	dv.Date1 = dv.Date1.Resolve(style)
+	dv.Date2 = dv.Date2.Resolve(style)
+	return dv
+}
+
+// IsZero reports whether d holds no date, as for the Date2 of a date value that has only
+// one date or the Date1 of a date phrase.
+func (d Date) IsZero() bool {
+	return d == Date{}
+}
+
+// A DateOption configures how ParseDate interprets a date value.
+type DateOption func(*dateConfig)
+
+type dateConfig struct {
+	months map[string]int // additional month names of the Gregorian and Julian calendars
+}
+
+// WithMonthNames configures ParseDate to accept the given names for the months of the
+// Gregorian and Julian calendars in addition to the GEDCOM names, mapping each name to
+// its month number from 1 to 12. Names are not case sensitive and may be followed by a
+// full stop.
+func WithMonthNames(names map[string]int) DateOption {
+	return func(c *dateConfig) {
+		if c.months == nil {
+			c.months = make(map[string]int)
+		}
+		for name, m := range names {
+			c.months[strings.ToUpper(strings.TrimSuffix(name, "."))] = m
+		}
+	}
+}
+
+// WithDateLocale configures ParseDate to accept the month names and common abbreviations
+// used by a language, given by its ISO 639-1 code: one of de (German), es (Spanish), fr
+// (French), it (Italian), nl (Dutch) or sv (Swedish). Some genealogy programs write these
+// names in place of the GEDCOM month names. Unknown languages are ignored.
+func WithDateLocale(lang string) DateOption {
+	return WithMonthNames(localeMonths[strings.ToLower(lang)])
+}
+
+// localeMonths gives the month names written by genealogy programs in each language
+var localeMonths = map[string]map[string]int{
+	"de": {
+		"JANUAR": 1, "JÄN": 1, "JÄNNER": 1, "FEBRUAR": 2, "MRZ": 3, "MÄR": 3, "MÄRZ": 3, "APRIL": 4, "MAI": 5,
+		"JUNI": 6, "JULI": 7, "AUGUST": 8, "SEPT": 9, "SEPTEMBER": 9, "OKT": 10, "OKTOBER": 10, "NOVEMBER": 11,
+		"DEZ": 12, "DEZEMBER": 12,
+	},
+	"es": {
+		"ENE": 1, "ENERO": 1, "FEBRERO": 2, "MARZO": 3, "ABR": 4, "ABRIL": 4, "MAYO": 5, "JUNIO": 6, "JULIO": 7,
+		"AGO": 8, "AGOSTO": 8, "SEPT": 9, "SEPTIEMBRE": 9, "OCTUBRE": 10, "NOVIEMBRE": 11, "DIC": 12, "DICIEMBRE": 12,
+	},
+	"fr": {
+		"JANV": 1, "JANVIER": 1, "FÉVR": 2, "FEVR": 2, "FÉV": 2, "FEV": 2, "FÉVRIER": 2, "FEVRIER": 2, "MARS": 3,
+		"AVR": 4, "AVRIL": 4, "MAI": 5, "JUIN": 6, "JUIL": 7, "JUILLET": 7, "AOÛT": 8, "AOUT": 8, "SEPT": 9,
+		"SEPTEMBRE": 9, "OCTOBRE": 10, "NOVEMBRE": 11, "DÉC": 12, "DÉCEMBRE": 12, "DECEMBRE": 12,
+	},
+	"it": {
+		"GEN": 1, "GENNAIO": 1, "FEBBRAIO": 2, "MARZO": 3, "APRILE": 4, "MAG": 5, "MAGGIO": 5, "GIU": 6,
+		"GIUGNO": 6, "LUG": 7, "LUGLIO": 7, "AGO": 8, "AGOSTO": 8, "SETT": 9, "SETTEMBRE": 9, "OTT": 10,
+		"OTTOBRE": 10, "NOVEMBRE": 11, "DIC": 12, "DICEMBRE": 12,
+	},
+	"nl": {
+		"JANUARI": 1, "FEBRUARI": 2, "MRT": 3, "MAART": 3, "APRIL": 4, "MEI": 5, "JUNI": 6, "JULI": 7,
+		"AUGUSTUS": 8, "SEPT": 9, "SEPTEMBER": 9, "OKT": 10, "OKTOBER": 10, "NOVEMBER": 11, "DECEMBER": 12,
+	},
+	"sv": {
+		"JANUARI": 1, "FEBRUARI": 2, "MARS": 3, "APRIL": 4, "MAJ": 5, "JUNI": 6, "JULI": 7, "AUGUSTI": 8,
+		"SEPT": 9, "SEPTEMBER": 9, "OKT": 10, "OKTOBER": 10, "NOVEMBER": 11, "DECEMBER": 12,
+	},
+}
+
+// ParseDate parses a date value as found in the DATE tag of an event. Calendars may be
+// given by a GEDCOM 5.5.1 escape such as @#DJULIAN@ or by a GEDCOM 7 calendar name such as
+// JULIAN, and years before the common era by B.C. or BCE. Keywords and month names are not
+// case sensitive. It reports false if the value is not a valid date.
+func ParseDate(s string, opts ...DateOption) (DateValue, bool) {
+	var cfg dateConfig
+	for _, o := range opts {
+		o(&cfg)
+	}
+
+	var dv DateValue
+	s = strings.TrimSpace(s)
+	if i := strings.IndexByte(s, '('); i >= 0 {
+		j := strings.LastIndexByte(s, ')')
+		if j < i || strings.TrimSpace(s[j+1:]) != "" {
+			return DateValue{}, false
+		}
+		dv.Phrase = s[i+1 : j]
+		s = s[:i]
+	}
+	s = strings.Join(strings.Fields(strings.ToUpper(s)), " ")
+	if s == "" {
+		return dv, dv.Phrase != ""
+	}
+
+	var ok bool
+	kw, rest, _ := strings.Cut(s, " ")
+	switch kw {
+	case "ABT", "CAL", "EST", "BEF", "AFT", "TO", "INT":
+		dv.Qualifier = kw
+		dv.Date1, ok = cfg.parseDate(rest)
+	case "BET":
+		dv.Qualifier = kw
+		first, second, found := strings.Cut(rest, " AND ")
+		if !found {
+			return DateValue{}, false
+		}
+		if dv.Date1, ok = cfg.parseDate(first); ok {
+			dv.Date2, ok = cfg.parseDate(second)
+		}
+	case "FROM":
+		dv.Qualifier = kw
+		first, second, found := strings.Cut(rest, " TO ")
+		if dv.Date1, ok = cfg.parseDate(first); ok && found {
+			dv.Date2, ok = cfg.parseDate(second)
+		}
+	default:
+		dv.Date1, ok = cfg.parseDate(s)
+	}
+	if !ok {
+		return DateValue{}, false
+	}
+
+	// Only an interpreted date may be followed by a date phrase
+	if dv.Phrase != "" && dv.Qualifier != "INT" {
+		return DateValue{}, false
+	}
+	return dv, true
+}
+
+// calendars7 maps the calendar names of GEDCOM 7 to the Calendar constants
+var calendars7 = map[string]string{
+	"GREGORIAN": CalendarGregorian,
+	"JULIAN":    CalendarJulian,
+	"HEBREW":    CalendarHebrew,
+	"FRENCH_R":  CalendarFrench,
+}
+
+// calendarMonths gives the month names of each calendar in order
+var calendarMonths = map[string][]string{
+	CalendarGregorian: {"JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"},
+	CalendarJulian:    {"JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"},
+	CalendarHebrew:    {"TSH", "CSH", "KSL", "TVT", "SHV", "ADR", "ADS", "NSN", "IYR", "SVN", "TMZ", "AAV", "ELL"},
+	CalendarFrench:    {"VEND", "BRUM", "FRIM", "NIVO", "PLUV", "VENT", "GERM", "FLOR", "PRAI", "MESS", "THER", "FRUC", "COMP"},
+}
+
+// parseDate parses a single date of the form [calendar] [[day] month] year [epoch] from
+// an upper case date value
+func (cfg *dateConfig) parseDate(s string) (Date, bool) {
+	d := Date{Calendar: CalendarGregorian}
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "@#D") {
+		end := strings.IndexByte(s[3:], '@')
+		if end < 0 {
+			return Date{}, false
+		}
+		d.Calendar, s = s[3:3+end], s[3+end+1:]
+	} else if name, rest, ok := strings.Cut(s, " "); ok && calendars7[name] != "" {
+		d.Calendar, s = calendars7[name], rest
+	}
+	months, ok := calendarMonths[d.Calendar]
+	if !ok {
+		return Date{}, false
+	}
+
+	fs := strings.Fields(s)
+	if n := len(fs); n > 0 && (fs[n-1] == "B.C." || fs[n-1] == "BCE") {
+		d.BC = true
+		fs = fs[:n-1]
+	}
+	if len(fs) == 0 || len(fs) > 3 {
+		return Date{}, false
+	}
+
+	ystr, dual, isDual := strings.Cut(fs[len(fs)-1], "/")
+	year, err := strconv.Atoi(ystr)
+	if err != nil || year < 1 {
+		return Date{}, false
+	}
+	d.Year = year
+	if isDual {
+		if d.DualYear, ok = parseDualYear(year, dual); !ok || d.BC || (d.Calendar != CalendarGregorian && d.Calendar != CalendarJulian) {
+			return Date{}, false
+		}
+	}
+
+	if len(fs) > 1 {
+		for i, m := range months {
+			if fs[len(fs)-2] == m {
+				d.Month = i + 1
+				break
+			}
+		}
+		if d.Month == 0 && (d.Calendar == CalendarGregorian || d.Calendar == CalendarJulian) {
+			d.Month = cfg.months[strings.TrimSuffix(fs[len(fs)-2], ".")]
+		}
+		if d.Month == 0 {
+			return Date{}, false
+		}
+	}
+
+	if len(fs) > 2 {
+		day, err := strconv.Atoi(fs[0])
+		if err != nil || day < 1 || day > 31 {
+			return Date{}, false
+		}
+		d.Day = day
+	}
+	return d, true
+}
+
+// parseDualYear returns the second year of a dual year in full, given the first year and
+// the text following the slash, which is either the last two digits of the second year
+// or the whole year. It reports false if the second year does not follow the first.
+func parseDualYear(year int, s string) (int, bool) {
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 || (len(s) != 2 && len(s) != len(strconv.Itoa(year))) {
+		return 0, false
+	}
+	if len(s) == 2 {
+		n += year - year%100
+		if n <= year {
+			n += 100
+		}
+	}
+	return n, n == year+1
+}
+
+// String returns the date value in GEDCOM 5.5.1 syntax.
+func (dv DateValue) String() string {
+	return dv.format(false)
+}
+
+// format returns the date value in GEDCOM 5.5.1 syntax, or GEDCOM 7 syntax if v7 is true.
+// GEDCOM 7 has no date phrases within date values, so any phrase is omitted.
+func (dv DateValue) format(v7 bool) string {
+	var parts []string
+	if dv.Qualifier != "" {
+		parts = append(parts, dv.Qualifier)
+	}
+	if !dv.Date1.IsZero() {
+		parts = append(parts, dv.Date1.format(v7))
+	}
+	if !dv.Date2.IsZero() {
+		if dv.Qualifier == "BET" {
+			parts = append(parts, "AND")
+		} else {
+			parts = append(parts, "TO")
+		}
+		parts = append(parts, dv.Date2.format(v7))
+	}
+	if dv.Phrase != "" && !v7 {
+		parts = append(parts, "("+dv.Phrase+")")
+	}
+	return strings.Join(parts, " ")
+}
+
+// String returns the date in GEDCOM 5.5.1 syntax, using a calendar escape for calendars
+// other than the Gregorian.
+func (d Date) String() string {
+	return d.format(false)
+}
+
+// format returns the date in GEDCOM 5.5.1 syntax, or GEDCOM 7 syntax if v7 is true
+func (d Date) format(v7 bool) string {
+	if d.IsZero() {
+		return ""
+	}
+	cal := d.Calendar
+	if cal == "" {
+		cal = CalendarGregorian
+	}
+	var parts []string
+	switch {
+	case cal == CalendarGregorian:
+	case v7:
+		parts = append(parts, strings.ReplaceAll(cal, " ", "_"))
+	default:
+		parts = append(parts, "@#D"+cal+"@")
+	}
+	if d.Day > 0 {
+		parts = append(parts, strconv.Itoa(d.Day))
+	}
+	if months := calendarMonths[cal]; d.Month > 0 && d.Month <= len(months) {
+		parts = append(parts, months[d.Month-1])
+	}
+	if d.DualYear != 0 {
+		parts = append(parts, fmt.Sprintf("%d/%02d", d.Year, d.DualYear%100))
+	} else {
+		parts = append(parts, strconv.Itoa(d.Year))
+	}
+	if d.BC {
+		if v7 {
+			parts = append(parts, "BCE")
+		} else {
+			parts = append(parts, "B.C.")
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// Compare compares dv with other by the earliest day each could refer to, returning -1 if
+// dv is earlier, +1 if it is later and 0 if they start on the same day and end on the same
+// day. Dates with no lower bound, such as "BEF 1900" or "TO 1900", are placed at their
+// upper bound, so "BEF 1900" sorts before "1900" and "AFT 1900" sorts after it. Date values
+// that cannot be placed in time, such as date phrases with no date, sort after all others.
+func (dv DateValue) Compare(other DateValue) int {
+	alo, ahi, aok := dv.bounds()
+	blo, bhi, bok := other.bounds()
+	switch {
+	case !aok && !bok:
+		return 0
+	case !aok:
+		return 1
+	case !bok:
+		return -1
+	}
+	if c := cmp.Compare(sortDay(alo, ahi), sortDay(blo, bhi)); c != 0 {
+		return c
+	}
+	return cmp.Compare(ahi, bhi)
+}
+
+// Before reports whether dv sorts before other according to Compare.
+func (dv DateValue) Before(other DateValue) bool {
+	return dv.Compare(other) < 0
+}
+
+// After reports whether dv sorts after other according to Compare.
+func (dv DateValue) After(other DateValue) bool {
+	return dv.Compare(other) > 0
+}
+
+// CompareDates compares two date values given as strings according to DateValue.Compare.
+// Values that cannot be parsed sort after all others.
+func CompareDates(a, b string) int {
+	da, _ := ParseDate(a)
+	db, _ := ParseDate(b)
+	return da.Compare(db)
+}
+
+// SortEvents sorts events into chronological order by their dates, keeping the original
+// order of events whose dates compare as equal. Events with no date or a date that cannot
+// be interpreted are placed last.
+func SortEvents(evs []*EventRecord) {
+	slices.SortStableFunc(evs, func(a, b *EventRecord) int {
+		return CompareDates(eventDate(a), eventDate(b))
+	})
+}
+
+// SortChildren sorts the children of a family into order of birth, using christening or
+// baptism dates for children with no birth event. Children with no usable date are
+// placed last.
+func (r *FamilyRecord) SortChildren() {
+	slices.SortStableFunc(r.Child, func(a, b *IndividualRecord) int {
+		return CompareDates(birthDate(a), birthDate(b))
+	})
+}
+
+func eventDate(ev *EventRecord) string {
+	if ev == nil {
+		return ""
+	}
+	return ev.Date
+}
+
+func birthDate(r *IndividualRecord) string {
+	if r == nil {
+		return ""
+	}
+	return eventDate(firstEvent(r.Event, "BIRT", "CHR", "BAPM"))
+}
+
+// IsRange reports whether dv is a date range, which gives bounds for a single day that is
+// not known exactly: a BET, BEF or AFT date.
+func (dv DateValue) IsRange() bool {
+	switch dv.Qualifier {
+	case "BET", "BEF", "AFT":
+		return true
+	}
+	return false
+}
+
+// IsPeriod reports whether dv is a date period, which covers all the days during which
+// something lasted: a FROM date, with or without a TO part, or a TO date.
+func (dv DateValue) IsPeriod() bool {
+	return dv.Qualifier == "FROM" || dv.Qualifier == "TO"
+}
+
+// Span returns the first and last days that dv could refer to, or that it covers if it is
+// a period, as dates in the proleptic Gregorian calendar. A date with only a year or a
+// month spans the whole year or month. The first or last day is zero if dv has no lower
+// or upper bound, as for "BEF 1900" or "FROM 1900". It reports false if dv cannot be
+// placed in time.
+func (dv DateValue) Span() (Date, Date, bool) {
+	lo, hi, ok := dv.bounds()
+	if !ok {
+		return Date{}, Date{}, false
+	}
+	return dayDate(lo), dayDate(hi), true
+}
+
+// Overlaps reports whether dv and other could refer to a common day, which for periods
+// means that they share at least one day. It reports false if either cannot be placed in
+// time.
+func (dv DateValue) Overlaps(other DateValue) bool {
+	alo, ahi, aok := dv.bounds()
+	blo, bhi, bok := other.bounds()
+	return aok && bok && alo <= bhi && blo <= ahi
+}
+
+// Contains reports whether every day that other could refer to falls within the span of
+// dv. For example "FROM 1850 TO 1900" contains "ABT 1870" and "BET 1860 AND 1880" but not
+// "AFT 1870". It reports false if either cannot be placed in time.
+func (dv DateValue) Contains(other DateValue) bool {
+	alo, ahi, aok := dv.bounds()
+	blo, bhi, bok := other.bounds()
+	return aok && bok && alo <= blo && bhi <= ahi
+}
+
+// Days returns the number of days in the span of dv, so a single day gives 1 and "1900"
+// gives 365. It reports false if dv has no lower or upper bound or cannot be placed in
+// time.
+func (dv DateValue) Days() (int, bool) {
+	lo, hi, ok := dv.bounds()
+	if !ok || lo == openLo || hi == openHi {
+		return 0, false
+	}
+	return hi - lo + 1, true
+}
+
+// A CivilDate is a day in the proleptic Gregorian calendar without a time or location,
+// using astronomical year numbering in which 1 B.C. is year 0.
+type CivilDate struct {
+	Year  int
+	Month time.Month
+	Day   int
+}
+
+// String returns the date in ISO 8601 format, such as 1900-03-02.
+func (c CivilDate) String() string {
+	if c.Year < 0 {
+		return fmt.Sprintf("-%04d-%02d-%02d", -c.Year, c.Month, c.Day)
+	}
+	return fmt.Sprintf("%04d-%02d-%02d", c.Year, c.Month, c.Day)
+}
+
+// Time returns the start of the day in the given location.
+func (c CivilDate) Time(loc *time.Location) time.Time {
+	return time.Date(c.Year, c.Month, c.Day, 0, 0, 0, 0, loc)
+}
+
+// Civil returns the day used to place dv in time, converted to the proleptic Gregorian
+// calendar if it is given in another calendar. This is the same day used by Compare: the
+// first day dv could refer to, so "1900" and "MAR 1900" give the first day of the year and
+// of the month, or the last day for values with no lower bound such as "BEF 1900", which
+// gives 31 December 1899. Use Span for both bounds. It reports false if dv cannot be placed
+// in time, as for a date phrase.
+func (dv DateValue) Civil() (CivilDate, bool) {
+	lo, hi, ok := dv.bounds()
+	if !ok {
+		return CivilDate{}, false
+	}
+	y, m, d := gregorianFromDay(sortDay(lo, hi))
+	return CivilDate{Year: y, Month: time.Month(m), Day: d}, true
+}
+
+// Time returns midnight UTC at the start of the day given by Civil. It reports false if
+// dv cannot be placed in time.
+func (dv DateValue) Time() (time.Time, bool) {
+	c, ok := dv.Civil()
+	if !ok {
+		return time.Time{}, false
+	}
+	return c.Time(time.UTC), true
+}
+
+// dayDate returns the proleptic Gregorian date of a Julian day number, or a zero date for
+// an open bound
+func dayDate(jd int) Date {
+	if jd == openLo || jd == openHi {
+		return Date{}
+	}
+	y, m, d := gregorianFromDay(jd)
+	if y < 1 {
+		return Date{Calendar: CalendarGregorian, Year: 1 - y, Month: m, Day: d, BC: true}
+	}
+	return Date{Calendar: CalendarGregorian, Year: y, Month: m, Day: d}
+}
+
+// Open bounds of a date value
+const (
+	openLo = math.MinInt
+	openHi = math.MaxInt
+)
+
+// sortDay returns the day used to place a date value with the given bounds in time
+func sortDay(lo, hi int) int {
+	if lo == openLo {
+		return hi
+	}
+	return lo
+}
+
+// bounds returns the first and last days the date value could refer to as Julian day
+// numbers. A bound is openLo or openHi if the value has no lower or upper bound. It
+// reports false if the value cannot be placed in time.
+func (dv DateValue) bounds() (int, int, bool) {
+	lo, hi, ok := dv.Date1.dayRange()
+	if !ok {
+		return 0, 0, false
+	}
+	switch dv.Qualifier {
+	case "BEF":
+		return openLo, lo - 1, true
+	case "AFT":
+		return hi + 1, openHi, true
+	case "TO":
+		return openLo, hi, true
+	case "BET", "FROM":
+		if dv.Date2.IsZero() {
+			if dv.Qualifier == "BET" {
+				return 0, 0, false
+			}
+			return lo, openHi, true
+		}
+		_, hi2, ok := dv.Date2.dayRange()
+		if !ok {
+			return 0, 0, false
+		}
+		return lo, hi2, true
+	}
+	return lo, hi, true
+}
+
+// dayRange returns the first and last days of the period the date refers to as Julian
+// day numbers. It reports false if the date is zero or is not a valid date of its
+// calendar.
+func (d Date) dayRange() (int, int, bool) {
+	if d.IsZero() {
+		return 0, 0, false
+	}
+	cal := d.Calendar
+	if cal == "" {
+		cal = CalendarGregorian
+	}
+	y := d.Year
+	if d.DualYear != 0 {
+		y = d.DualYear
+	}
+	if d.BC {
+		if cal != CalendarGregorian && cal != CalendarJulian {
+			return 0, 0, false
+		}
+		// Astronomical year numbering has a year 0, which is 1 B.C.
+		y = 1 - y
+	}
+
+	if d.Month == 0 {
+		first, ok := calendarDay(cal, y, 1, 1)
+		next, _ := calendarDay(cal, y+1, 1, 1)
+		return first, next - 1, ok
+	}
+	first, ok := calendarDay(cal, y, d.Month, 1)
+	if !ok {
+		return 0, 0, false
+	}
+	ny, nm := nextMonth(cal, y, d.Month)
+	next, _ := calendarDay(cal, ny, nm, 1)
+	if d.Day == 0 {
+		return first, next - 1, true
+	}
+	day := first + d.Day - 1
+	if day >= next {
+		return 0, 0, false
+	}
+	return day, day, true
+}
+
+// NormalizeDate rewrites a date value written in the style of a genealogy program in
+// GEDCOM 5.5.1 date syntax. It accepts keywords written as words or abbreviations with
+// or without a full stop, such as "Abt.", "circa", "before" or "Bet.", full English month
+// names, dates written month first such as "March 3, 1900", ordinal days such as "3rd",
+// ISO 8601 dates such as "1900-03-03", year ranges such as "1850-1860" or "Bet. 1850–1860"
+// and decades such as "1900s", which become "BET 1900 AND 1909". Values that are already
+// valid are returned in canonical form. It reports false if the value cannot be
+// interpreted.
+func NormalizeDate(s string, opts ...DateOption) (string, bool) {
+	dv, ok := parseVendorDate(s, opts...)
+	if !ok {
+		return "", false
+	}
+	return dv.String(), true
+}
+
+// vendorDateWords maps the words and abbreviations written by genealogy programs to
+// date keywords and month names
+var vendorDateWords = map[string]string{
+	"ABOUT": "ABT", "CIRCA": "ABT", "CA": "ABT", "C": "ABT", "APPROX": "ABT", "APPROXIMATELY": "ABT",
+	"ESTIMATED": "EST", "CALC": "CAL", "CALCULATED": "CAL", "BEFORE": "BEF", "AFTER": "AFT",
+	"BETWEEN": "BET", "BTW": "BET", "&": "AND", "UNTIL": "TO",
+	"JANUARY": "JAN", "FEBRUARY": "FEB", "MARCH": "MAR", "APRIL": "APR", "JUNE": "JUN", "JULY": "JUL",
+	"AUGUST": "AUG", "SEPTEMBER": "SEP", "SEPT": "SEP", "OCTOBER": "OCT", "NOVEMBER": "NOV", "DECEMBER": "DEC",
+}
+
+// parseVendorDate parses a date value written in GEDCOM syntax or in the style of a
+// genealogy program
+func parseVendorDate(s string, opts ...DateOption) (DateValue, bool) {
+	if dv, ok := ParseDate(s, opts...); ok || strings.ContainsRune(s, '(') {
+		return dv, ok
+	}
+
+	s = strings.NewReplacer("–", " - ", "—", " - ", ",", " ").Replace(s)
+	var fs []string
+	for _, f := range strings.Fields(s) {
+		// ISO 8601 dates and ranges of years joined by a hyphen
+		if y, m, d, ok := isoDate(f); ok {
+			fs = append(fs, strconv.Itoa(d), calendarMonths[CalendarGregorian][m-1], strconv.Itoa(y))
+			continue
+		}
+		if a, b, ok := strings.Cut(f, "-"); ok && isDigits(a) && isDigits(b) {
+			fs = append(fs, a, "-", b)
+			continue
+		}
+
+		f = strings.ToUpper(strings.TrimSuffix(f, "."))
+		if w, ok := vendorDateWords[f]; ok {
+			f = w
+		}
+		// Ordinal days such as 3rd
+		for _, suffix := range []string{"ST", "ND", "RD", "TH"} {
+			if n := strings.TrimSuffix(f, suffix); n != f && isDigits(n) {
+				f = n
+			}
+		}
+		fs = append(fs, f)
+	}
+	if len(fs) == 0 {
+		return DateValue{}, false
+	}
+
+	// Decades such as 1900s or 1900's
+	if len(fs) == 1 {
+		if y := strings.TrimSuffix(strings.TrimSuffix(fs[0], "S"), "'"); y != fs[0] && isDigits(y) && strings.HasSuffix(y, "0") {
+			n, _ := strconv.Atoi(y)
+			fs = []string{"BET", y, "AND", strconv.Itoa(n + 9)}
+		}
+	}
+
+	// A hyphen separates the dates of a range or period
+	if i := slices.Index(fs, "-"); i >= 0 {
+		switch fs[0] {
+		case "BET":
+			fs[i] = "AND"
+		case "FROM":
+			fs[i] = "TO"
+		default:
+			fs[i] = "AND"
+			fs = append([]string{"BET"}, fs...)
+		}
+	}
+
+	// Dates written month first, such as MAR 3 1900
+	for i := 0; i+2 < len(fs); i++ {
+		if isMonthName(fs[i]) && isDigits(fs[i+1]) && len(fs[i+1]) <= 2 && isDigits(fs[i+2]) {
+			fs[i], fs[i+1] = fs[i+1], fs[i]
+		}
+	}
+
+	return ParseDate(strings.Join(fs, " "), opts...)
+}
+
+// isoDate parses a date in the form YYYY-MM-DD
+func isoDate(s string) (int, int, int, bool) {
+	parts := strings.Split(s, "-")
+	if len(parts) != 3 || len(parts[0]) != 4 || len(parts[1]) != 2 || len(parts[2]) != 2 {
+		return 0, 0, 0, false
+	}
+	var n [3]int
+	for i, p := range parts {
+		v, err := strconv.Atoi(p)
+		if err != nil || !isDigits(p) {
+			return 0, 0, 0, false
+		}
+		n[i] = v
+	}
+	if n[1] < 1 || n[1] > 12 || n[2] < 1 || n[2] > 31 {
+		return 0, 0, 0, false
+	}
+	return n[0], n[1], n[2], true
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func isMonthName(s string) bool {
+	for _, m := range calendarMonths[CalendarGregorian] {
+		if s == m {
+			return true
+		}
+	}
+	return false
+}
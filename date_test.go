@@ -0,0 +1,343 @@
+package gedcom
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseDate(t *testing.T) {
+	greg := func(day, month, year int) Date {
+		return Date{Calendar: CalendarGregorian, Year: year, Month: month, Day: day}
+	}
+
+	testCases := []struct {
+		in     string
+		want   DateValue
+		ok     bool
+		format string // canonical form, if different from in
+	}{
+		{in: "2 MAR 1900", want: DateValue{Date1: greg(2, 3, 1900)}, ok: true},
+		{in: "mar 1900", want: DateValue{Date1: greg(0, 3, 1900)}, ok: true, format: "MAR 1900"},
+		{in: "1900", want: DateValue{Date1: greg(0, 0, 1900)}, ok: true},
+		{in: "ABT 1850", want: DateValue{Qualifier: "ABT", Date1: greg(0, 0, 1850)}, ok: true},
+		{in: "CAL 1850", want: DateValue{Qualifier: "CAL", Date1: greg(0, 0, 1850)}, ok: true},
+		{in: "EST 1850", want: DateValue{Qualifier: "EST", Date1: greg(0, 0, 1850)}, ok: true},
+		{in: "BEF 1 JAN 1900", want: DateValue{Qualifier: "BEF", Date1: greg(1, 1, 1900)}, ok: true},
+		{in: "AFT 1900", want: DateValue{Qualifier: "AFT", Date1: greg(0, 0, 1900)}, ok: true},
+		{in: "BET 1850 AND JUN 1855", want: DateValue{Qualifier: "BET", Date1: greg(0, 0, 1850), Date2: greg(0, 6, 1855)}, ok: true},
+		{in: "FROM 1850 TO 1855", want: DateValue{Qualifier: "FROM", Date1: greg(0, 0, 1850), Date2: greg(0, 0, 1855)}, ok: true},
+		{in: "FROM 1850", want: DateValue{Qualifier: "FROM", Date1: greg(0, 0, 1850)}, ok: true},
+		{in: "TO 1855", want: DateValue{Qualifier: "TO", Date1: greg(0, 0, 1855)}, ok: true},
+		{in: "INT 1900 (about the turn of the century)", want: DateValue{Qualifier: "INT", Date1: greg(0, 0, 1900), Phrase: "about the turn of the century"}, ok: true},
+		{in: "(Easter, before the war)", want: DateValue{Phrase: "Easter, before the war"}, ok: true},
+		{in: "@#DJULIAN@ 11 FEB 1731", want: DateValue{Date1: Date{Calendar: CalendarJulian, Year: 1731, Month: 2, Day: 11}}, ok: true},
+		{in: "@#DHEBREW@ 1 TSH 5760", want: DateValue{Date1: Date{Calendar: CalendarHebrew, Year: 5760, Month: 1, Day: 1}}, ok: true},
+		{in: "@#DFRENCH R@ VEND 10", want: DateValue{Date1: Date{Calendar: CalendarFrench, Year: 10, Month: 1}}, ok: true},
+		{in: "JULIAN 1 JAN 1700", want: DateValue{Date1: Date{Calendar: CalendarJulian, Year: 1700, Month: 1, Day: 1}}, ok: true, format: "@#DJULIAN@ 1 JAN 1700"},
+		{in: "44 B.C.", want: DateValue{Date1: Date{Calendar: CalendarGregorian, Year: 44, BC: true}}, ok: true},
+		{in: "44 BCE", want: DateValue{Date1: Date{Calendar: CalendarGregorian, Year: 44, BC: true}}, ok: true, format: "44 B.C."},
+		{in: "2 FEB 1732/33", want: DateValue{Date1: Date{Calendar: CalendarGregorian, Year: 1732, Month: 2, Day: 2, DualYear: 1733}}, ok: true},
+		{in: "1699/00", want: DateValue{Date1: Date{Calendar: CalendarGregorian, Year: 1699, DualYear: 1700}}, ok: true},
+		{in: "MAR 1732/1733", want: DateValue{Date1: Date{Calendar: CalendarGregorian, Year: 1732, Month: 3, DualYear: 1733}}, ok: true, format: "MAR 1732/33"},
+		{in: "", ok: false},
+		{in: "1732/34", ok: false},
+		{in: "@#DHEBREW@ 5760/61", ok: false},
+		{in: "BET 1850", ok: false},
+		{in: "32 JAN 1900", ok: false},
+		{in: "1 FOO 1900", ok: false},
+		{in: "1 TSH 1900", ok: false},
+		{in: "1900 (phrase)", ok: false},
+		{in: "@#DROMAN@ 1900", ok: false},
+		{in: "about 1900", ok: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.in, func(t *testing.T) {
+			got, ok := ParseDate(tc.in)
+			if ok != tc.ok {
+				t.Fatalf("got ok %v, wanted %v", ok, tc.ok)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("date mismatch (-want +got):\n%s", diff)
+			}
+			if !ok {
+				return
+			}
+			format := tc.format
+			if format == "" {
+				format = tc.in
+			}
+			if got.String() != format {
+				t.Errorf("got string %q, wanted %q", got.String(), format)
+			}
+		})
+	}
+}
+
+func TestCompareDates(t *testing.T) {
+	// Each date sorts strictly before the next
+	ordered := []string{
+		"BEF 1850",
+		"1850",
+		"BET 1850 AND 1855",
+		"FROM 1850",
+		"TO 1850",
+		"1 JAN 1851",
+		"MAR 1851",
+		"2 MAR 1851",
+		"AFT 1851",
+		"(unknown)",
+	}
+	for i := 0; i < len(ordered)-1; i++ {
+		a, b := ordered[i], ordered[i+1]
+		if got := CompareDates(a, b); got != -1 {
+			t.Errorf("CompareDates(%q, %q) = %d, wanted -1", a, b, got)
+		}
+		if got := CompareDates(b, a); got != 1 {
+			t.Errorf("CompareDates(%q, %q) = %d, wanted 1", b, a, got)
+		}
+	}
+
+	equal := [][2]string{
+		{"1850", "ABT 1850"},
+		{"1850", "BET JAN 1850 AND DEC 1850"},
+		{"44 B.C.", "44 BCE"},
+		{"(unknown)", "not a date"},
+	}
+	for _, p := range equal {
+		if got := CompareDates(p[0], p[1]); got != 0 {
+			t.Errorf("CompareDates(%q, %q) = %d, wanted 0", p[0], p[1], got)
+		}
+	}
+
+	bc, _ := ParseDate("10 B.C.")
+	ad, _ := ParseDate("1")
+	if !bc.Before(ad) || !ad.After(bc) {
+		t.Errorf("10 B.C. does not sort before 1")
+	}
+}
+
+func TestSortChildren(t *testing.T) {
+	child := func(xref string, tag string, date string) *IndividualRecord {
+		return &IndividualRecord{Xref: xref, Event: []*EventRecord{{Tag: tag, Date: date}}}
+	}
+	f := &FamilyRecord{Child: []*IndividualRecord{
+		child("I1", "BIRT", "1905"),
+		{Xref: "I2"},
+		child("I3", "CHR", "ABT 1900"),
+		child("I4", "BIRT", "3 MAR 1902"),
+		child("I5", "BIRT", "1905"),
+	}}
+	f.SortChildren()
+
+	var got []string
+	for _, c := range f.Child {
+		got = append(got, c.Xref)
+	}
+	want := []string{"I3", "I4", "I1", "I5", "I2"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("order mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestDualYear(t *testing.T) {
+	dv, ok := ParseDate("BET 2 FEB 1732/33 AND 1740")
+	if !ok {
+		t.Fatalf("could not parse date")
+	}
+	other, _ := ParseDate("1 JUN 1732")
+
+	// The date is placed in time using the new style year, resolved or not
+	if !dv.After(other) {
+		t.Errorf("dual year date does not sort after %s", other)
+	}
+	ns := dv.Resolve(NewStyle)
+	if !ns.After(other) {
+		t.Errorf("new style date does not sort after %s", other)
+	}
+	if got, want := ns.String(), "BET 2 FEB 1733 AND 1740"; got != want {
+		t.Errorf("got %q, wanted %q", got, want)
+	}
+	if got, want := dv.Resolve(OldStyle).String(), "BET 2 FEB 1732 AND 1740"; got != want {
+		t.Errorf("got %q, wanted %q", got, want)
+	}
+
+	if got := CompareDates("2 FEB 1732/33", "1 DEC 1732"); got != 1 {
+		t.Errorf("got CompareDates(2 FEB 1732/33, 1 DEC 1732) = %d, wanted 1", got)
+	}
+	if got := CompareDates("2 FEB 1732/33", "2 FEB 1733"); got != 0 {
+		t.Errorf("got CompareDates(2 FEB 1732/33, 2 FEB 1733) = %d, wanted 0", got)
+	}
+}
+
+func TestDateSpans(t *testing.T) {
+	parse := func(s string) DateValue {
+		t.Helper()
+		dv, ok := ParseDate(s)
+		if !ok {
+			t.Fatalf("could not parse %q", s)
+		}
+		return dv
+	}
+
+	life := parse("FROM 12 MAR 1820 TO 1881")
+	if !life.IsPeriod() || life.IsRange() {
+		t.Errorf("FROM...TO is not a period")
+	}
+	if bet := parse("BET 1850 AND 1855"); !bet.IsRange() || bet.IsPeriod() {
+		t.Errorf("BET...AND is not a range")
+	}
+
+	testCases := []struct {
+		other    string
+		overlaps bool
+		contains bool
+	}{
+		{other: "1851", overlaps: true, contains: true},
+		{other: "ABT 1870", overlaps: true, contains: true},
+		{other: "BET 1860 AND 1880", overlaps: true, contains: true},
+		{other: "AFT 1870", overlaps: true, contains: false},
+		{other: "BEF 1820", overlaps: false, contains: false},
+		{other: "MAR 1820", overlaps: true, contains: false},
+		{other: "1 JAN 1882", overlaps: false, contains: false},
+		{other: "FROM 1800", overlaps: true, contains: false},
+		{other: "(unknown)", overlaps: false, contains: false},
+	}
+	for _, tc := range testCases {
+		other, _ := ParseDate(tc.other)
+		if got := life.Overlaps(other); got != tc.overlaps {
+			t.Errorf("Overlaps(%q) = %v, wanted %v", tc.other, got, tc.overlaps)
+		}
+		if got := other.Overlaps(life); got != tc.overlaps {
+			t.Errorf("%q Overlaps = %v, wanted %v", tc.other, got, tc.overlaps)
+		}
+		if got := life.Contains(other); got != tc.contains {
+			t.Errorf("Contains(%q) = %v, wanted %v", tc.other, got, tc.contains)
+		}
+	}
+
+	first, last, ok := life.Span()
+	if !ok || first.String() != "12 MAR 1820" || last.String() != "31 DEC 1881" {
+		t.Errorf("got span %s to %s, wanted 12 MAR 1820 to 31 DEC 1881", first, last)
+	}
+	if first, last, _ := parse("AFT 1900").Span(); !last.IsZero() || first.String() != "1 JAN 1901" {
+		t.Errorf("got span %s to %s for AFT 1900", first, last)
+	}
+
+	for in, want := range map[string]int{"1 JAN 1900": 1, "1900": 365, "1904": 366, "FEB 1900": 28, "BET 1 JAN 1900 AND 10 JAN 1900": 10} {
+		if got, ok := parse(in).Days(); !ok || got != want {
+			t.Errorf("Days(%q) = %d, %v, wanted %d", in, got, ok, want)
+		}
+	}
+	if _, ok := parse("BEF 1900").Days(); ok {
+		t.Errorf("Days of an open range reported ok")
+	}
+}
+
+func TestDateTime(t *testing.T) {
+	testCases := []struct {
+		in   string
+		want string
+		ok   bool
+	}{
+		{in: "2 MAR 1900", want: "1900-03-02", ok: true},
+		{in: "MAR 1900", want: "1900-03-01", ok: true},
+		{in: "ABT 1900", want: "1900-01-01", ok: true},
+		{in: "BEF 1900", want: "1899-12-31", ok: true},
+		{in: "TO 1900", want: "1900-12-31", ok: true},
+		{in: "BET 1850 AND 1860", want: "1850-01-01", ok: true},
+		{in: "@#DJULIAN@ 11 FEB 1731", want: "1731-02-22", ok: true},
+		{in: "2 B.C.", want: "-0001-01-01", ok: true},
+		{in: "(unknown)", ok: false},
+	}
+	for _, tc := range testCases {
+		dv, _ := ParseDate(tc.in)
+		c, ok := dv.Civil()
+		if ok != tc.ok {
+			t.Errorf("Civil(%q) got ok %v, wanted %v", tc.in, ok, tc.ok)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if c.String() != tc.want {
+			t.Errorf("Civil(%q) = %s, wanted %s", tc.in, c, tc.want)
+		}
+		tm, ok := dv.Time()
+		if !ok || tm.Format("2006-01-02") != tc.want || tm.Location() != time.UTC {
+			t.Errorf("Time(%q) = %v, wanted %s UTC", tc.in, tm, tc.want)
+		}
+	}
+}
+
+func TestParseDateLocale(t *testing.T) {
+	testCases := []struct {
+		in   string
+		opts []DateOption
+		want string
+	}{
+		{in: "3 MRZ 1850", opts: []DateOption{WithDateLocale("de")}, want: "3 MAR 1850"},
+		{in: "ABT 12 Dez 1850", opts: []DateOption{WithDateLocale("de")}, want: "ABT 12 DEC 1850"},
+		{in: "1 janv. 1790", opts: []DateOption{WithDateLocale("fr")}, want: "1 JAN 1790"},
+		{in: "BET 1 août 1790 AND 2 DÉC 1790", opts: []DateOption{WithDateLocale("FR")}, want: "BET 1 AUG 1790 AND 2 DEC 1790"},
+		{in: "@#DJULIAN@ 5 mrt 1700", opts: []DateOption{WithDateLocale("nl")}, want: "@#DJULIAN@ 5 MAR 1700"},
+		{in: "7 KUU 1900", opts: []DateOption{WithMonthNames(map[string]int{"kuu": 7})}, want: "7 JUL 1900"},
+	}
+	for _, tc := range testCases {
+		if _, ok := ParseDate(tc.in); ok {
+			t.Errorf("%q parsed without a locale", tc.in)
+		}
+		dv, ok := ParseDate(tc.in, tc.opts...)
+		if !ok {
+			t.Errorf("could not parse %q", tc.in)
+			continue
+		}
+		if got := dv.String(); got != tc.want {
+			t.Errorf("got %q, wanted %q", got, tc.want)
+		}
+	}
+
+	// Localized names are not month names of other calendars
+	if _, ok := ParseDate("@#DHEBREW@ 1 MRZ 5760", WithDateLocale("de")); ok {
+		t.Errorf("parsed a localized month name in the Hebrew calendar")
+	}
+}
+
+func TestNormalizeDate(t *testing.T) {
+	testCases := []struct {
+		in   string
+		want string
+		ok   bool
+	}{
+		{in: "2 mar 1900", want: "2 MAR 1900", ok: true},
+		{in: "Abt. 1900", want: "ABT 1900", ok: true},
+		{in: "circa 1900", want: "ABT 1900", ok: true},
+		{in: "Before 3 March 1900", want: "BEF 3 MAR 1900", ok: true},
+		{in: "aft. Sept 1900", want: "AFT SEP 1900", ok: true},
+		{in: "Bet. 1850–1860", want: "BET 1850 AND 1860", ok: true},
+		{in: "between 1850 & 1860", want: "BET 1850 AND 1860", ok: true},
+		{in: "1850-1860", want: "BET 1850 AND 1860", ok: true},
+		{in: "from 1850 - 1860", want: "FROM 1850 TO 1860", ok: true},
+		{in: "1900s", want: "BET 1900 AND 1909", ok: true},
+		{in: "1850's", want: "BET 1850 AND 1859", ok: true},
+		{in: "March 3, 1900", want: "3 MAR 1900", ok: true},
+		{in: "abt March 3rd, 1900", want: "ABT 3 MAR 1900", ok: true},
+		{in: "1900-03-03", want: "3 MAR 1900", ok: true},
+		{in: "INT 1900 (about then)", want: "INT 1900 (about then)", ok: true},
+		{in: "sometime in spring", ok: false},
+		{in: "1900-13-01", ok: false},
+		{in: "1905s", ok: false},
+	}
+	for _, tc := range testCases {
+		got, ok := NormalizeDate(tc.in)
+		if ok != tc.ok {
+			t.Errorf("NormalizeDate(%q) got ok %v, wanted %v", tc.in, ok, tc.ok)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("NormalizeDate(%q) = %q, wanted %q", tc.in, got, tc.want)
+		}
+	}
+}
@@ -8,36 +8,158 @@ package gedcom
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"strings"
 )
 
 // A Decoder reads and decodes GEDCOM objects from an input stream.
 type Decoder struct {
-	r         *bufio.Reader
-	parsers   []parser
-	refs      map[string]interface{}
-	line      int
-	tagLogger *log.Logger
+	src          io.Reader
+	r            *bufio.Reader
+	parsers      []parser
+	refs         map[string]interface{}
+	line         int
+	logger       *slog.Logger
+	gedcom       *Gedcom // the Gedcom being decoded
+	record       string  // tag of the current level 0 record
+	recordXref   string  // xref of the current level 0 record
+	startOffset  int64
+	recordOffset int64
+	arena        decodeArena
+	extensions   *ExtensionRegistry
+	fixups       map[string]bool // names of enabled fixups
+	strict       bool
+	skipRecords  map[string]bool // tags of records to skip
+	onlyRecords  map[string]bool // tags of the only records to decode, if not nil
+	warnings     []Warning
+	recover      bool   // skip lines that cannot be scanned
+	charset      string // character set of the input, if not detected
+	skippedLines []SkippedLines
+	stream       *scanState // state of decoding with Next
+	current      Record     // the record begun by the most recent level 0 line
+	metrics      Metrics
+	version7     bool              // the input is GEDCOM 7, detected from the header
+	schema       map[string]string // URIs of extension tags declared by the header
+	levelJumps   LevelJumpPolicy
+	extendedTags bool // accept punctuation in tags
+	lineLength   LineLengthMode
+}
+
+// A DecoderOption configures a Decoder.
+type DecoderOption interface {
+	applyDecoder(*Decoder)
+}
+
+type decoderOptionFunc func(*Decoder)
+
+func (f decoderOptionFunc) applyDecoder(d *Decoder) { f(d) }
+
+// An Option configures both a Decoder and an Encoder.
+type Option interface {
+	DecoderOption
+	EncoderOption
+}
+
+type option struct {
+	decoder func(*Decoder)
+	encoder func(*Encoder)
+}
+
+func (o option) applyDecoder(d *Decoder) {
+	if o.decoder != nil {
+		o.decoder(d)
+	}
+}
+
+func (o option) applyEncoder(e *Encoder) {
+	if o.encoder != nil {
+		o.encoder(e)
+	}
 }
 
 // NewDecoder returns a new decoder that reads r.
-func NewDecoder(r io.Reader) *Decoder {
+func NewDecoder(r io.Reader, opts ...DecoderOption) *Decoder {
 	br := bufio.NewReader(r)
-	return &Decoder{
-		r: br,
+	d := &Decoder{
+		src:    r,
+		r:      br,
+		fixups: make(map[string]bool),
 	}
+	for _, name := range ProfileDefault.Fixups {
+		d.fixups[name] = true
+	}
+	for _, o := range opts {
+		o.applyDecoder(d)
+	}
+	return d
+}
+
+// WithStartOffset configures the decoder to begin decoding at the given byte offset in the
+// input, which must be the start of a level 0 record such as one reported by RecordOffset.
+// If the input is an io.Seeker the decoder seeks to the offset, otherwise the preceding
+// bytes are read and discarded. Line numbers reported by the decoder are counted from
+// the offset.
+func WithStartOffset(offset int64) DecoderOption {
+	return decoderOptionFunc(func(d *Decoder) {
+		d.startOffset = offset
+	})
+}
+
+// WithNoteFixup configures whether the decoder repairs NOTE values that contain unescaped
+// newlines, as written by some Ancestry exports, by joining following lines that do not
+// begin with a level number. The fixup is enabled by default.
+func WithNoteFixup(enabled bool) DecoderOption {
+	return decoderOptionFunc(func(d *Decoder) {
+		d.fixups[FixupNoteNewline] = enabled
+	})
+}
+
+// WithExtendedTags configures the decoder to accept tags containing ASCII punctuation
+// other than @, such as _FOO-BAR, which some programs write for their own extensions.
+// By default such tags are rejected by the scanner. User defined tags that begin with an
+// underscore are decoded into the UserDefined field of the enclosing structure, like any
+// other user defined tag.
+func WithExtendedTags() DecoderOption {
+	return decoderOptionFunc(func(d *Decoder) {
+		d.extendedTags = true
+	})
+}
+
+// RecordOffset returns the byte offset of the start of the level 0 record most recently
+// read by the decoder. If decoding fails, this is the offset of the record that was being
+// decoded, which may be passed to WithStartOffset to resume decoding from that record.
+func (d *Decoder) RecordOffset() int64 {
+	return d.recordOffset
 }
 
+// LogUnhandledTags configures the decoder to log tags it does not handle to w.
+//
+// Deprecated: Use WithLogger, which allows the log records to be filtered and aggregated.
 func (d *Decoder) LogUnhandledTags(w io.Writer) {
-	d.tagLogger = log.New(w, "", log.Lshortfile)
+	d.logger = slog.New(slog.NewTextHandler(w, nil))
+}
+
+// WithLogger configures the decoder to log tags it does not handle to l. Each log record
+// carries the line number, level, tag, value and xref of the line together with the tag
+// and xref of the level 0 record that contains it.
+func WithLogger(l *slog.Logger) DecoderOption {
+	return decoderOptionFunc(func(d *Decoder) {
+		d.logger = l
+	})
 }
 
 // Decode reads GEDCOM-encoded data from its
 // input and parses it into a Gedcom structure.
 func (d *Decoder) Decode() (*Gedcom, error) {
+	return d.DecodeContext(context.Background())
+}
+
+// DecodeContext is like Decode but checks ctx at the start of each level 0 record,
+// returning the context's error if it has been cancelled or its deadline has passed.
+func (d *Decoder) DecodeContext(ctx context.Context) (*Gedcom, error) {
 	g := &Gedcom{
 		Family:     make([]*FamilyRecord, 0),
 		Individual: make([]*IndividualRecord, 0),
@@ -47,31 +169,240 @@ func (d *Decoder) Decode() (*Gedcom, error) {
 		Submitter:  make([]*SubmitterRecord, 0),
 	}
 
+	d.gedcom = g
+	d.warnings = nil
+	d.skippedLines = nil
 	d.refs = make(map[string]interface{})
+	d.arena.reset()
 	d.parsers = []parser{makeRootParser(d, g)}
-	if err := d.scan(g); err != nil {
+	if err := d.seek(); err != nil {
 		return nil, err
 	}
+	if err := d.scan(ctx, g); err != nil {
+		return nil, err
+	}
+	d.finish()
 
 	return g, nil
 }
 
-func (d *Decoder) scan(g *Gedcom) error {
-	s := NewScanner(d.r)
+// finish pops any parsers remaining when the input ends without a trailer
+func (d *Decoder) finish() {
+	if len(d.parsers) > 1 {
+		d.parsers[len(d.parsers)-1](-1, "", "", "")
+	}
+}
+
+// seek positions the input at the configured start offset
+func (d *Decoder) seek() error {
+	d.recordOffset = d.startOffset
+	if d.startOffset == 0 {
+		return nil
+	}
+	if sk, ok := d.src.(io.Seeker); ok && d.r.Buffered() == 0 {
+		if _, err := sk.Seek(d.startOffset, io.SeekStart); err != nil {
+			return fmt.Errorf("seek to offset %d: %w", d.startOffset, err)
+		}
+		d.r.Reset(d.src)
+		return nil
+	}
+	if _, err := io.CopyN(io.Discard, d.r, d.startOffset); err != nil {
+		return fmt.Errorf("skip to offset %d: %w", d.startOffset, err)
+	}
+	return nil
+}
+
+func (d *Decoder) scan(ctx context.Context, g *Gedcom) error {
+	st, err := d.newScan()
+	if err != nil {
+		return err
+	}
 	for {
-		if !s.Next() {
-			if s.Err() != nil {
-				return s.Err()
-			}
+		ok, err := d.nextLine(st)
+		if err != nil {
+			return err
+		}
+		if !ok {
 			break
 		}
-		d.line = s.line
-		d.parsers[len(d.parsers)-1](s.level, s.tag, s.value, s.xref)
+		if err := d.decodeLine(ctx, st); err != nil {
+			return err
+		}
+	}
+	return d.endScan(st, g)
+}
+
+// scanState holds the state of a scan of the decoder's input
+type scanState struct {
+	s         *Scanner
+	cr        *charsetReader
+	reported  metricsMark
+	strict    strictChecker
+	fix       fixupLines
+	levels    []levelMapping // levels of the open structures, outermost first
+	readLevel int            // level of the current line as read
+	jumpFrom  int            // level of the previous line if the current line jumps from it, or -1
+	skipLevel int            // level of the structure being skipped, or -1
+	headTag   string         // tag of the current level 1 line of the header
+	pending   bool           // the current line has been read but not decoded
+	done      bool           // the end of the input has been reached
+}
+
+// newScan prepares to scan the decoder's input from its current position
+func (d *Decoder) newScan() (*scanState, error) {
+	lineFixups, err := d.lineFixups()
+	if err != nil {
+		return nil, err
+	}
+	cr := newCharsetReader(d.r, d.charset)
+	s := NewScanner(cr)
+	s.pos = d.startOffset
+	s.noNoteFixup = !d.fixups[FixupNoteNewline] || d.strict
+	s.extendedTags = d.extendedTags
+	d.version7 = false
+	d.schema = nil
+	return &scanState{
+		s:         s,
+		cr:        cr,
+		reported:  metricsMark{pos: d.startOffset},
+		fix:       fixupLines{fixups: lineFixups},
+		jumpFrom:  -1,
+		skipLevel: -1,
+	}, nil
+}
+
+// nextLine reads the next line of input, checking it in strict mode and applying any
+// fixups. It returns false at the end of the input.
+func (d *Decoder) nextLine(st *scanState) (bool, error) {
+	s := st.s
+	if !s.Next() {
+		if s.Err() == nil {
+			return false, nil
+		}
+		if !d.recover {
+			return false, s.Err()
+		}
+		if ok, err := d.skipToRecord(s); !ok || err != nil {
+			return false, err
+		}
+	}
+	d.line = s.line
+	if d.strict {
+		if err := st.strict.check(s, d.startOffset == 0); err != nil {
+			return false, err
+		}
+	}
+	if err := d.checkLevel(st); err != nil {
+		return false, err
+	}
+	if len(st.fix.fixups) > 0 {
+		st.fix.apply(s, d.metrics)
+	}
+	return true, nil
+}
+
+// decodeLine passes the line most recently read to the current parser
+func (d *Decoder) decodeLine(ctx context.Context, st *scanState) error {
+	s := st.s
+	if st.skipLevel >= 0 {
+		if s.level > st.skipLevel {
+			return nil
+		}
+		st.skipLevel = -1
+	}
+	tag, value := s.tag, s.value
+	if d.version7 {
+		tag, value = translate7(tag, value)
+	}
+	value = unescapeAt(value, d.version7)
+	if d.skipped(s.level, tag) {
+		st.skipLevel = s.level
+		if s.level == 0 {
+			d.current = nil
+		}
+		return nil
+	}
+	if s.level == 0 {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("decode: %w", err)
+		}
+		d.recordOffset = s.start
+		d.record, d.recordXref = s.tag, s.xref
+		d.reportMetrics(s, &st.reported)
+		if d.metrics != nil {
+			d.metrics.AddRecord(s.tag)
+		}
+	} else if s.line == 1 && d.startOffset != 0 {
+		return fmt.Errorf("offset %d is not the start of a level 0 record", d.startOffset)
+	} else if d.record == "HEAD" {
+		d.headerLine(st)
+	}
+	d.warnLevel(st)
+	if err := d.checkLineLength(s); err != nil {
+		return err
+	}
+	d.checkLine(s)
+	if err := d.parsers[len(d.parsers)-1](s.level, tag, value, s.xref); err != nil {
+		d.warn(WarningParseError, s.tag, s.value, "%v", err)
+	}
+	return nil
+}
+
+// headerLine detects the character set and GEDCOM version of the input from the current
+// line, which is part of the header. GEDCOM 7 data is always UTF-8.
+func (d *Decoder) headerLine(st *scanState) {
+	s := st.s
+	switch {
+	case s.level == 1:
+		st.headTag = s.tag
+		if s.tag == "CHAR" && d.charset == "" && !d.version7 {
+			st.cr.setCharset(s.value)
+		}
+	case s.level == 2 && s.tag == "VERS" && st.headTag == "GEDC":
+		d.version7 = isVersion7(s.value)
+		st.strict.version7 = d.version7
+		if d.version7 && d.charset == "" {
+			st.cr.setCharset(CharsetUTF8)
+		}
 	}
+}
 
+// endScan completes a scan once the end of the input has been reached
+func (d *Decoder) endScan(st *scanState, g *Gedcom) error {
+	d.reportMetrics(st.s, &st.reported)
+	if d.strict {
+		if err := st.strict.finish(); err != nil {
+			return err
+		}
+	}
+	g.LineEnding = st.s.LineEnding()
 	return nil
 }
 
+// metricsMark records the scanner counts last reported to the decoder's Metrics
+type metricsMark struct {
+	line   int
+	pos    int64
+	fixups int
+}
+
+// reportMetrics reports the lines, bytes and fixups scanned since the last report
+func (d *Decoder) reportMetrics(s *Scanner, reported *metricsMark) {
+	if d.metrics == nil {
+		return
+	}
+	if n := d.line - reported.line; n > 0 {
+		d.metrics.AddLines(n)
+	}
+	if n := s.pos - reported.pos; n > 0 {
+		d.metrics.AddBytes(n)
+	}
+	for i := reported.fixups; i < s.noteFixups; i++ {
+		d.metrics.AddFixup(FixupNoteNewline)
+	}
+	*reported = metricsMark{line: d.line, pos: s.pos, fixups: s.noteFixups}
+}
+
 type parser func(level int, tag string, value string, xref string) error
 
 func (d *Decoder) pushParser(p parser) {
@@ -90,12 +421,12 @@ func (d *Decoder) popParser(level int, tag string, value string, xref string) er
 
 func (d *Decoder) individual(xref string) *IndividualRecord {
 	if xref == "" {
-		return &IndividualRecord{}
+		return d.arena.individuals.new(IndividualRecord{})
 	}
 
 	ref, found := d.refs[xref].(*IndividualRecord)
 	if !found {
-		rec := &IndividualRecord{Xref: xref}
+		rec := d.arena.individuals.new(IndividualRecord{Xref: xref})
 		d.refs[rec.Xref] = rec
 		return rec
 	}
@@ -104,12 +435,12 @@ func (d *Decoder) individual(xref string) *IndividualRecord {
 
 func (d *Decoder) family(xref string) *FamilyRecord {
 	if xref == "" {
-		return &FamilyRecord{}
+		return d.arena.families.new(FamilyRecord{})
 	}
 
 	ref, found := d.refs[xref].(*FamilyRecord)
 	if !found {
-		rec := &FamilyRecord{Xref: xref}
+		rec := d.arena.families.new(FamilyRecord{Xref: xref})
 		d.refs[rec.Xref] = rec
 		return rec
 	}
@@ -118,12 +449,12 @@ func (d *Decoder) family(xref string) *FamilyRecord {
 
 func (d *Decoder) source(xref string) *SourceRecord {
 	if xref == "" {
-		return &SourceRecord{}
+		return d.arena.sources.new(SourceRecord{})
 	}
 
 	ref, found := d.refs[xref].(*SourceRecord)
 	if !found {
-		rec := &SourceRecord{Xref: xref}
+		rec := d.arena.sources.new(SourceRecord{Xref: xref})
 		d.refs[rec.Xref] = rec
 		return rec
 	}
@@ -144,6 +475,34 @@ func (d *Decoder) submitter(xref string) *SubmitterRecord {
 	return ref
 }
 
+func (d *Decoder) note(xref string) *NoteRecord {
+	if xref == "" {
+		return d.arena.notes.new(NoteRecord{})
+	}
+
+	ref, found := d.refs[xref].(*NoteRecord)
+	if !found {
+		rec := d.arena.notes.new(NoteRecord{Xref: xref})
+		d.refs[rec.Xref] = rec
+		return rec
+	}
+	return ref
+}
+
+// noteStructure returns the note for a NOTE tag with the given value, which is either the
+// text of the note or a pointer to a shared note record, and pushes a parser for the
+// subordinate tags of the note. Subordinate tags of a pointer are unhandled since they
+// would otherwise modify the shared record.
+func (d *Decoder) noteStructure(value string, level int) *NoteRecord {
+	if isPointer(value) {
+		d.pushParser(makeUnhandledParser(d, level))
+		return d.note(stripXref(value))
+	}
+	n := d.arena.notes.new(NoteRecord{Note: value})
+	d.pushParser(makeNoteParser(d, n, level))
+	return n
+}
+
 func (d *Decoder) submission(xref string) *SubmissionRecord {
 	if xref == "" {
 		return &SubmissionRecord{}
@@ -186,45 +545,101 @@ func (d *Decoder) media(xref string) *MediaRecord {
 	return ref
 }
 
+// mediaStructure returns the multimedia object for an OBJE tag with the given value, which
+// is either empty for an inline object or a pointer to a shared media record, and pushes a
+// parser for the subordinate tags of the object. Subordinate tags of a pointer are
+// unhandled since they would otherwise modify the shared record.
+func (d *Decoder) mediaStructure(value string, level int) *MediaRecord {
+	if isPointer(value) {
+		d.pushParser(makeUnhandledParser(d, level))
+		return d.media(stripXref(value))
+	}
+	m := &MediaRecord{}
+	d.pushParser(makeMediaParser(d, m, level))
+	return m
+}
+
+// unhandledTag records a tag that has no place in the structure being parsed in the
+// Gedcom's Unhandled list, along with its subordinate tags
 func (d *Decoder) unhandledTag(level int, tag string, value string, xref string) {
-	if d.tagLogger == nil {
-		return
+	d.warn(WarningUnhandledTag, tag, value, "%s is not handled here", tag)
+	if d.logger != nil {
+		d.logger.LogAttrs(context.Background(), slog.LevelWarn, "unhandled tag",
+			slog.Int("line", d.line),
+			slog.Int("tag_level", level),
+			slog.String("tag", tag),
+			slog.String("value", value),
+			slog.String("xref", xref),
+			slog.String("record", d.record),
+			slog.String("record_xref", d.recordXref),
+		)
 	}
 
-	d.tagLogger.Output(2, fmt.Sprintf("unhandled tag on line %d; level=%d; tag=%s; value=%s; xref=%s", d.line, level, tag, value, xref))
+	g := d.gedcom
+	g.Unhandled = append(g.Unhandled, UnhandledTag{
+		UserDefinedTag: UserDefinedTag{
+			Tag:   tag,
+			Value: value,
+			Xref:  xref,
+			Level: level,
+		},
+		Line:       d.line,
+		Record:     d.record,
+		RecordXref: d.recordXref,
+	})
+	d.pushParser(makeUserDefinedTagParser(d, &g.Unhandled[len(g.Unhandled)-1].UserDefinedTag, level))
 }
 
 func makeRootParser(d *Decoder, g *Gedcom) parser {
 	return func(level int, tag string, value string, xref string) error {
 		if level == 0 {
+			d.current = nil
 			switch tag {
 			case "HEAD":
 				g.Header = &Header{}
+				d.current = g.Header
 				d.pushParser(makeHeaderParser(d, g.Header, level))
 			case "INDI":
 				obj := d.individual(xref)
 				g.Individual = append(g.Individual, obj)
+				d.current = obj
 				d.pushParser(makeIndividualParser(d, obj, level))
 			case "SUBM":
-				// TODO: parse submitters
 				obj := d.submitter(xref)
 				g.Submitter = append(g.Submitter, obj)
+				d.current = obj
+				d.pushParser(makeSubmitterParser(d, obj, level))
 			case "FAM":
 				obj := d.family(xref)
 				g.Family = append(g.Family, obj)
+				d.current = obj
 				d.pushParser(makeFamilyParser(d, obj, level))
 			case "SOUR":
 				obj := d.source(xref)
 				g.Source = append(g.Source, obj)
+				d.current = obj
 				d.pushParser(makeSourceParser(d, obj, level))
 			case "REPO":
 				obj := d.repository(xref)
 				g.Repository = append(g.Repository, obj)
+				d.current = obj
 				d.pushParser(makeRepositoryParser(d, obj, level))
 			case "OBJE":
 				obj := d.media(xref)
 				g.Media = append(g.Media, obj)
+				d.current = obj
 				d.pushParser(makeMediaParser(d, obj, level))
+			case "NOTE":
+				obj := d.note(xref)
+				obj.Note = value
+				g.Note = append(g.Note, obj)
+				d.current = obj
+				d.pushParser(makeNoteParser(d, obj, level))
+			case "SUBN":
+				obj := d.submission(xref)
+				g.Submission = append(g.Submission, obj)
+				d.current = obj
+				d.pushParser(makeSubmissionParser(d, obj, level))
 			case "TRLR":
 				g.Trailer = &Trailer{}
 			default:
@@ -236,6 +651,9 @@ func makeRootParser(d *Decoder, g *Gedcom) parser {
 				})
 				d.pushParser(makeUserDefinedTagParser(d, &g.UserDefined[len(g.UserDefined)-1], level))
 			}
+			if d.current != nil && tag != "HEAD" {
+				g.Order = append(g.Order, d.current)
+			}
 		}
 		return nil
 	}
@@ -248,30 +666,32 @@ func makeIndividualParser(d *Decoder, i *IndividualRecord, minLevel int) parser
 		}
 		switch tag {
 		case "NAME":
-			n := &NameRecord{Name: value}
+			n := d.arena.names.new(NameRecord{Name: value})
 			i.Name = append(i.Name, n)
 			d.pushParser(makeNameParser(d, n, level))
+		case "RESN":
+			i.RestrictionNotice = value
 		case "SEX":
 			i.Sex = value
 		case "BIRT", "CHR", "DEAT", "BURI", "CREM", "ADOP", "BAPM", "BARM", "BASM", "BLES", "CHRA", "CONF", "FCOM", "ORDN", "NATU", "EMIG", "IMMI", "CENS", "PROB", "WILL", "GRAD", "RETI", "EVEN":
-			e := &EventRecord{Tag: tag}
+			e := d.arena.events.new(EventRecord{Tag: tag})
 			if value != "" {
 				if value == "Y" && (tag == "BIRT" || tag == "CHR" || tag == "DEAT") {
 					e.Value = "Y"
 				} else {
 					// event value is invalid and added as a note instead
-					r := &NoteRecord{Note: value}
+					r := d.arena.notes.new(NoteRecord{Note: value})
 					e.Note = append(i.Note, r)
 				}
 			}
 			i.Event = append(i.Event, e)
 			d.pushParser(makeEventParser(d, tag, e, level))
 		case "CAST", "DSCR", "EDUC", "IDNO", "NATI", "NCHI", "NMR", "OCCU", "PROP", "RELI", "RESI", "SSN", "TITL", "FACT":
-			e := &EventRecord{Tag: tag}
+			e := d.arena.events.new(EventRecord{Tag: tag})
 			if value != "" {
 				if tag == "RESI" {
 					// event value is invalid and added as a note instead
-					r := &NoteRecord{Note: value}
+					r := d.arena.notes.new(NoteRecord{Note: value})
 					e.Note = append(i.Note, r)
 				} else {
 					e.Value = value
@@ -279,9 +699,16 @@ func makeIndividualParser(d *Decoder, i *IndividualRecord, minLevel int) parser
 			}
 			i.Attribute = append(i.Attribute, e)
 			d.pushParser(makeEventParser(d, tag, e, level))
+		case "BAPL", "CONL", "ENDL", "SLGC":
+			o := &LdsOrdinanceRecord{Tag: tag}
+			i.Ordinance = append(i.Ordinance, o)
+			d.pushParser(makeLdsOrdinanceParser(d, o, level))
 		case "FAMC":
+			if d.voidPointer(value, level) {
+				break
+			}
 			family := d.family(stripXref(value))
-			f := &FamilyLinkRecord{Family: family}
+			f := d.arena.familyLinks.new(FamilyLinkRecord{Family: family})
 			i.Parents = append(i.Parents, f)
 			d.pushParser(makeFamilyLinkParser(d, f, level))
 		case "SUBM":
@@ -289,13 +716,19 @@ func makeIndividualParser(d *Decoder, i *IndividualRecord, minLevel int) parser
 			i.Submitter = append(i.Submitter, submitter)
 		case "ASSO":
 			a := &AssociationRecord{Xref: stripXref(value)}
+			switch d.refs[a.Xref].(type) {
+			case nil, *IndividualRecord:
+				if a.Xref != "" {
+					a.Individual = d.individual(a.Xref)
+				}
+			}
 			i.Association = append(i.Association, a)
 			d.pushParser(makeAssociationParser(d, a, level))
 		case "ALIA":
 			// ALIA support is broken in the wild and should be deprecated as per https://www.tamurajones.net/GEDCOMALIA.xhtml
 			// Use ALIA as an alternate name
 			if xref == "" && value != "" {
-				n := &NameRecord{Name: value}
+				n := d.arena.names.new(NameRecord{Name: value})
 				i.Name = append(i.Name, n)
 			}
 		case "RFN":
@@ -303,8 +736,11 @@ func makeIndividualParser(d *Decoder, i *IndividualRecord, minLevel int) parser
 		case "AFN":
 			i.AncestralFileNumber = value
 		case "FAMS":
+			if d.voidPointer(value, level) {
+				break
+			}
 			family := d.family(stripXref(value))
-			f := &FamilyLinkRecord{Family: family}
+			f := d.arena.familyLinks.new(FamilyLinkRecord{Family: family})
 			i.Family = append(i.Family, f)
 			d.pushParser(makeFamilyLinkParser(d, f, level))
 		case "REFN":
@@ -315,18 +751,24 @@ func makeIndividualParser(d *Decoder, i *IndividualRecord, minLevel int) parser
 			i.AutomatedRecordId = value
 		case "CHAN":
 			d.pushParser(makeChangeParser(d, &i.Change, level))
+		case "CREA": // 7.0
+			d.pushParser(makeChangeParser(d, &i.Creation, level))
+		case "EXID": // 7.0
+			x := &ExternalIDRecord{ID: value}
+			i.ExternalID = append(i.ExternalID, x)
+			d.pushParser(makeExternalIDParser(d, x, level))
 		case "NOTE":
-			r := &NoteRecord{Note: value}
-			i.Note = append(i.Note, r)
-			d.pushParser(makeNoteParser(d, r, level))
+			i.Note = append(i.Note, d.noteStructure(value, level))
 		case "SOUR":
-			c := &CitationRecord{Source: d.source(stripXref(value))}
+			c := d.arena.citations.new(CitationRecord{Source: d.source(stripXref(value))})
 			i.Citation = append(i.Citation, c)
 			d.pushParser(makeCitationParser(d, c, level))
 		case "OBJE":
-			m := &MediaRecord{Xref: stripXref(value)}
-			i.Media = append(i.Media, m)
-			d.pushParser(makeMediaParser(d, m, level))
+			i.Media = append(i.Media, d.mediaStructure(value, level))
+		case "_DNA", "_MTDNA", "_YDNA":
+			r := &DNARecord{Tag: tag, Value: value}
+			i.DNA = append(i.DNA, r)
+			d.pushParser(makeDNAParser(d, r, level))
 		default:
 			i.UserDefined = append(i.UserDefined, UserDefinedTag{
 				Tag:   tag,
@@ -340,6 +782,36 @@ func makeIndividualParser(d *Decoder, i *IndividualRecord, minLevel int) parser
 	}
 }
 
+func makeDNAParser(d *Decoder, r *DNARecord, minLevel int) parser {
+	return func(level int, tag string, value string, xref string) error {
+		if level <= minLevel {
+			return d.popParser(level, tag, value, xref)
+		}
+		switch tag {
+		case "CONT":
+			r.Value = r.Value + "\n" + value
+		case "CONC":
+			r.Value = r.Value + value
+		case "TYPE":
+			r.Type = value
+		case "DATE":
+			r.Date = value
+		case "NOTE":
+			r.Note = append(r.Note, d.noteStructure(value, level))
+		default:
+			r.UserDefined = append(r.UserDefined, UserDefinedTag{
+				Tag:   tag,
+				Value: value,
+				Xref:  xref,
+				Level: level,
+			})
+			d.pushParser(makeUserDefinedTagParser(d, &r.UserDefined[len(r.UserDefined)-1], level))
+		}
+
+		return nil
+	}
+}
+
 func makeNameParser(d *Decoder, n *NameRecord, minLevel int) parser {
 	return func(level int, tag string, value string, xref string) error {
 		if level <= minLevel {
@@ -369,13 +841,11 @@ func makeNameParser(d *Decoder, n *NameRecord, minLevel int) parser {
 			n.Romanized = append(n.Romanized, c)
 			d.pushParser(makeVariantNameParser(d, c, level))
 		case "SOUR":
-			c := &CitationRecord{Source: d.source(stripXref(value))}
+			c := d.arena.citations.new(CitationRecord{Source: d.source(stripXref(value))})
 			n.Citation = append(n.Citation, c)
 			d.pushParser(makeCitationParser(d, c, level))
 		case "NOTE":
-			r := &NoteRecord{Note: value}
-			n.Note = append(n.Note, r)
-			d.pushParser(makeNoteParser(d, r, level))
+			n.Note = append(n.Note, d.noteStructure(value, level))
 		default:
 			n.UserDefined = append(n.UserDefined, UserDefinedTag{
 				Tag:   tag,
@@ -411,15 +881,19 @@ func makeVariantNameParser(d *Decoder, n *VariantNameRecord, minLevel int) parse
 		case "NSFX":
 			n.NamePieceSuffix = value
 		case "SOUR":
-			c := &CitationRecord{Source: d.source(stripXref(value))}
+			c := d.arena.citations.new(CitationRecord{Source: d.source(stripXref(value))})
 			n.Citation = append(n.Citation, c)
 			d.pushParser(makeCitationParser(d, c, level))
 		case "NOTE":
-			r := &NoteRecord{Note: value}
-			n.Note = append(n.Note, r)
-			d.pushParser(makeNoteParser(d, r, level))
+			n.Note = append(n.Note, d.noteStructure(value, level))
 		default:
-			d.unhandledTag(level, tag, value, xref)
+			n.UserDefined = append(n.UserDefined, UserDefinedTag{
+				Tag:   tag,
+				Value: value,
+				Xref:  xref,
+				Level: level,
+			})
+			d.pushParser(makeUserDefinedTagParser(d, &n.UserDefined[len(n.UserDefined)-1], level))
 		}
 
 		return nil
@@ -465,14 +939,16 @@ func makeSourceParser(d *Decoder, s *SourceRecord, minLevel int) parser {
 			s.AutomatedRecordId = value
 		case "CHAN":
 			d.pushParser(makeChangeParser(d, &s.Change, level))
+		case "CREA": // 7.0
+			d.pushParser(makeChangeParser(d, &s.Creation, level))
+		case "EXID": // 7.0
+			x := &ExternalIDRecord{ID: value}
+			s.ExternalID = append(s.ExternalID, x)
+			d.pushParser(makeExternalIDParser(d, x, level))
 		case "NOTE":
-			r := &NoteRecord{Note: value}
-			s.Note = append(s.Note, r)
-			d.pushParser(makeNoteParser(d, r, level))
+			s.Note = append(s.Note, d.noteStructure(value, level))
 		case "OBJE":
-			m := &MediaRecord{Xref: stripXref(value)}
-			s.Media = append(s.Media, m)
-			d.pushParser(makeMediaParser(d, m, level))
+			s.Media = append(s.Media, d.mediaStructure(value, level))
 		default:
 			s.UserDefined = append(s.UserDefined, UserDefinedTag{
 				Tag:   tag,
@@ -497,8 +973,18 @@ func makeSourceDataParser(d *Decoder, s *SourceDataRecord, minLevel int) parser
 			se := &SourceEventRecord{Kind: value}
 			s.Event = append(s.Event, se)
 			d.pushParser(makeSourceEventParser(d, se, level))
+		case "AGNC":
+			s.Agency = value
+		case "NOTE":
+			s.Note = append(s.Note, d.noteStructure(value, level))
 		default:
-			d.unhandledTag(level, tag, value, xref)
+			s.UserDefined = append(s.UserDefined, UserDefinedTag{
+				Tag:   tag,
+				Value: value,
+				Xref:  xref,
+				Level: level,
+			})
+			d.pushParser(makeUserDefinedTagParser(d, &s.UserDefined[len(s.UserDefined)-1], level))
 		}
 
 		return nil
@@ -516,7 +1002,13 @@ func makeSourceEventParser(d *Decoder, s *SourceEventRecord, minLevel int) parse
 		case "PLAC":
 			s.Place = value
 		default:
-			d.unhandledTag(level, tag, value, xref)
+			s.UserDefined = append(s.UserDefined, UserDefinedTag{
+				Tag:   tag,
+				Value: value,
+				Xref:  xref,
+				Level: level,
+			})
+			d.pushParser(makeUserDefinedTagParser(d, &s.UserDefined[len(s.UserDefined)-1], level))
 		}
 
 		return nil
@@ -530,15 +1022,19 @@ func makeSourceRepositoryParser(d *Decoder, s *SourceRepositoryRecord, minLevel
 		}
 		switch tag {
 		case "NOTE":
-			r := &NoteRecord{Note: value}
-			s.Note = append(s.Note, r)
-			d.pushParser(makeNoteParser(d, r, level))
+			s.Note = append(s.Note, d.noteStructure(value, level))
 		case "CALN":
 			r := &SourceCallNumberRecord{CallNumber: value}
 			s.CallNumber = append(s.CallNumber, r)
 			d.pushParser(makeSourceCallNumberParser(d, r, level))
 		default:
-			d.unhandledTag(level, tag, value, xref)
+			s.UserDefined = append(s.UserDefined, UserDefinedTag{
+				Tag:   tag,
+				Value: value,
+				Xref:  xref,
+				Level: level,
+			})
+			d.pushParser(makeUserDefinedTagParser(d, &s.UserDefined[len(s.UserDefined)-1], level))
 		}
 
 		return nil
@@ -554,7 +1050,13 @@ func makeSourceCallNumberParser(d *Decoder, s *SourceCallNumberRecord, minLevel
 		case "MEDI":
 			s.MediaType = value
 		default:
-			d.unhandledTag(level, tag, value, xref)
+			s.UserDefined = append(s.UserDefined, UserDefinedTag{
+				Tag:   tag,
+				Value: value,
+				Xref:  xref,
+				Level: level,
+			})
+			d.pushParser(makeUserDefinedTagParser(d, &s.UserDefined[len(s.UserDefined)-1], level))
 		}
 
 		return nil
@@ -570,13 +1072,14 @@ func makeCitationParser(d *Decoder, c *CitationRecord, minLevel int) parser {
 		case "PAGE":
 			c.Page = value
 			d.pushParser(makeTextParser(d, &c.Page, level))
+		case "EVEN":
+			c.Event = value
+			d.pushParser(makeCitationEventParser(d, c, level))
 		case "QUAY":
 			c.Quay = value
 			d.pushParser(makeTextParser(d, &c.Quay, level))
 		case "NOTE":
-			r := &NoteRecord{Note: value}
-			c.Note = append(c.Note, r)
-			d.pushParser(makeNoteParser(d, r, level))
+			c.Note = append(c.Note, d.noteStructure(value, level))
 		case "DATA":
 			d.pushParser(makeDataParser(d, &c.Data, level))
 		default:
@@ -594,6 +1097,23 @@ func makeCitationParser(d *Decoder, c *CitationRecord, minLevel int) parser {
 	}
 }
 
+func makeCitationEventParser(d *Decoder, c *CitationRecord, minLevel int) parser {
+	return func(level int, tag string, value string, xref string) error {
+		if level <= minLevel {
+			return d.popParser(level, tag, value, xref)
+		}
+
+		switch tag {
+		case "ROLE":
+			c.Role = value
+		default:
+			d.unhandledTag(level, tag, value, xref)
+		}
+
+		return nil
+	}
+}
+
 func makeNoteParser(d *Decoder, n *NoteRecord, minLevel int) parser {
 	return func(level int, tag string, value string, xref string) error {
 		if level <= minLevel {
@@ -605,11 +1125,17 @@ func makeNoteParser(d *Decoder, n *NoteRecord, minLevel int) parser {
 		case "CONC":
 			n.Note = n.Note + value
 		case "SOUR":
-			c := &CitationRecord{Source: d.source(stripXref(value))}
+			c := d.arena.citations.new(CitationRecord{Source: d.source(stripXref(value))})
 			n.Citation = append(n.Citation, c)
 			d.pushParser(makeCitationParser(d, c, level))
 		default:
-			d.unhandledTag(level, tag, value, xref)
+			n.UserDefined = append(n.UserDefined, UserDefinedTag{
+				Tag:   tag,
+				Value: value,
+				Xref:  xref,
+				Level: level,
+			})
+			d.pushParser(makeUserDefinedTagParser(d, &n.UserDefined[len(n.UserDefined)-1], level))
 		}
 
 		return nil
@@ -644,16 +1170,18 @@ func makePublicationFactsParser(d *Decoder, s *string, minLevel int) parser {
 			*s = *s + "\n" + value
 		case "CONC":
 			*s = *s + value
-		case "DATE": // ancestry
-			if *s != "" {
-				*s = *s + ", "
+		case "DATE", "PLAC": // ancestry
+			if !d.fixups[FixupPublicationFacts] {
+				d.unhandledTag(level, tag, value, xref)
+				break
 			}
-			*s = *s + value
-		case "PLAC": // ancestry
 			if *s != "" {
 				*s = *s + ", "
 			}
 			*s = *s + value
+			if d.metrics != nil {
+				d.metrics.AddFixup(FixupPublicationFacts)
+			}
 		default:
 			d.unhandledTag(level, tag, value, xref)
 		}
@@ -697,15 +1225,17 @@ func makeEventParser(d *Decoder, parentTag string, e *EventRecord, minLevel int)
 		switch parentTag {
 		case "BIRT", "CHR":
 			if tag == "FAMC" {
-				family := d.family(stripXref(value))
-				e.ChildInFamily = family
+				if !d.voidPointer(value, level) {
+					e.ChildInFamily = d.family(stripXref(value))
+				}
 				return nil
 			}
 		case "ADOP":
 			if tag == "FAMC" {
-				family := d.family(stripXref(value))
-				e.ChildInFamily = family
-				d.pushParser(makeEventAdoptParser(d, e, level))
+				if !d.voidPointer(value, level) {
+					e.ChildInFamily = d.family(stripXref(value))
+					d.pushParser(makeEventAdoptParser(d, e, level))
+				}
 				return nil
 			}
 		}
@@ -718,6 +1248,12 @@ func makeEventParser(d *Decoder, parentTag string, e *EventRecord, minLevel int)
 		case "PLAC":
 			e.Place.Name = value
 			d.pushParser(makePlaceParser(d, &e.Place, level))
+		case "AGE":
+			e.Age = value
+		case "HUSB": // family events only
+			d.pushParser(makeEventSpouseParser(d, &e.HusbandAge, level))
+		case "WIFE": // family events only
+			d.pushParser(makeEventSpouseParser(d, &e.WifeAge, level))
 		case "AGNC":
 			e.ResponsibleAgency = value
 		case "RELI":
@@ -727,17 +1263,13 @@ func makeEventParser(d *Decoder, parentTag string, e *EventRecord, minLevel int)
 		case "RESN":
 			e.RestrictionNotice = value
 		case "NOTE":
-			r := &NoteRecord{Note: value}
-			e.Note = append(e.Note, r)
-			d.pushParser(makeNoteParser(d, r, level))
+			e.Note = append(e.Note, d.noteStructure(value, level))
 		case "SOUR":
-			c := &CitationRecord{Source: d.source(stripXref(value))}
+			c := d.arena.citations.new(CitationRecord{Source: d.source(stripXref(value))})
 			e.Citation = append(e.Citation, c)
 			d.pushParser(makeCitationParser(d, c, level))
 		case "OBJE":
-			m := &MediaRecord{Xref: stripXref(value)}
-			e.Media = append(e.Media, m)
-			d.pushParser(makeMediaParser(d, m, level))
+			e.Media = append(e.Media, d.mediaStructure(value, level))
 		default:
 
 			if tryAddressTags(d, &e.Address, level, tag, value, xref) {
@@ -774,6 +1306,23 @@ func makeEventAdoptParser(d *Decoder, e *EventRecord, minLevel int) parser {
 	}
 }
 
+func makeEventSpouseParser(d *Decoder, age *string, minLevel int) parser {
+	return func(level int, tag string, value string, xref string) error {
+		if level <= minLevel {
+			return d.popParser(level, tag, value, xref)
+		}
+
+		switch tag {
+		case "AGE":
+			*age = value
+		default:
+			d.unhandledTag(level, tag, value, xref)
+		}
+
+		return nil
+	}
+}
+
 func makePlaceParser(d *Decoder, r *PlaceRecord, minLevel int) parser {
 	return func(level int, tag string, value string, xref string) error {
 		if level <= minLevel {
@@ -791,15 +1340,19 @@ func makePlaceParser(d *Decoder, r *PlaceRecord, minLevel int) parser {
 		case "MAP": // 5.5.1
 			d.pushParser(makePlaceMapParser(d, r, level))
 		case "SOUR":
-			c := &CitationRecord{Source: d.source(stripXref(value))}
+			c := d.arena.citations.new(CitationRecord{Source: d.source(stripXref(value))})
 			r.Citation = append(r.Citation, c)
 			d.pushParser(makeCitationParser(d, c, level))
 		case "NOTE":
-			c := &NoteRecord{Note: value}
-			r.Note = append(r.Note, c)
-			d.pushParser(makeNoteParser(d, c, level))
+			r.Note = append(r.Note, d.noteStructure(value, level))
 		default:
-			d.unhandledTag(level, tag, value, xref)
+			r.UserDefined = append(r.UserDefined, UserDefinedTag{
+				Tag:   tag,
+				Value: value,
+				Xref:  xref,
+				Level: level,
+			})
+			d.pushParser(makeUserDefinedTagParser(d, &r.UserDefined[len(r.UserDefined)-1], level))
 		}
 
 		return nil
@@ -834,7 +1387,13 @@ func makeVariantPlaceNameRecordParser(d *Decoder, r *VariantPlaceNameRecord, min
 		case "TYPE": // 5.5.1
 			r.Type = value
 		default:
-			d.unhandledTag(level, tag, value, xref)
+			r.UserDefined = append(r.UserDefined, UserDefinedTag{
+				Tag:   tag,
+				Value: value,
+				Xref:  xref,
+				Level: level,
+			})
+			d.pushParser(makeUserDefinedTagParser(d, &r.UserDefined[len(r.UserDefined)-1], level))
 		}
 
 		return nil
@@ -850,11 +1409,15 @@ func makeFamilyLinkParser(d *Decoder, f *FamilyLinkRecord, minLevel int) parser
 		case "PEDI":
 			f.Type = value
 		case "NOTE":
-			r := &NoteRecord{Note: value}
-			f.Note = append(f.Note, r)
-			d.pushParser(makeNoteParser(d, r, level))
+			f.Note = append(f.Note, d.noteStructure(value, level))
 		default:
-			d.unhandledTag(level, tag, value, xref)
+			f.UserDefined = append(f.UserDefined, UserDefinedTag{
+				Tag:   tag,
+				Value: value,
+				Xref:  xref,
+				Level: level,
+			})
+			d.pushParser(makeUserDefinedTagParser(d, &f.UserDefined[len(f.UserDefined)-1], level))
 		}
 
 		return nil
@@ -868,23 +1431,35 @@ func makeFamilyParser(d *Decoder, f *FamilyRecord, minLevel int) parser {
 			return d.popParser(level, tag, value, xref)
 		}
 		switch tag {
+		case "RESN":
+			f.RestrictionNotice = value
 		case "HUSB":
-			f.Husband = d.individual(stripXref(value))
+			if !d.voidPointer(value, level) {
+				f.Husband = d.individual(stripXref(value))
+			}
 		case "WIFE":
-			f.Wife = d.individual(stripXref(value))
+			if !d.voidPointer(value, level) {
+				f.Wife = d.individual(stripXref(value))
+			}
 		case "CHIL":
-			f.Child = append(f.Child, d.individual(stripXref(value)))
+			if !d.voidPointer(value, level) {
+				f.Child = append(f.Child, d.individual(stripXref(value)))
+			}
 		case "ANUL", "CENS", "DIV", "DIVF", "ENGA", "MARR", "MARB", "MARC", "MARL", "MARS", "EVEN", "RESI":
-			e := &EventRecord{Tag: tag}
+			e := d.arena.events.new(EventRecord{Tag: tag})
 			if value != "" {
 				// any event other value is invalid and added as a note instead
-				r := &NoteRecord{Note: value}
+				r := d.arena.notes.new(NoteRecord{Note: value})
 				e.Note = append(e.Note, r)
 			}
 			f.Event = append(f.Event, e)
 			d.pushParser(makeEventParser(d, tag, e, level))
 		case "NCHI":
 			f.NumberOfChildren = value
+		case "SLGS":
+			o := &LdsOrdinanceRecord{Tag: tag}
+			f.Ordinance = append(f.Ordinance, o)
+			d.pushParser(makeLdsOrdinanceParser(d, o, level))
 		case "REFN":
 			r := &UserReferenceRecord{Number: value}
 			f.UserReference = append(f.UserReference, r)
@@ -893,18 +1468,20 @@ func makeFamilyParser(d *Decoder, f *FamilyRecord, minLevel int) parser {
 			f.AutomatedRecordId = value
 		case "CHAN":
 			d.pushParser(makeChangeParser(d, &f.Change, level))
+		case "CREA": // 7.0
+			d.pushParser(makeChangeParser(d, &f.Creation, level))
+		case "EXID": // 7.0
+			x := &ExternalIDRecord{ID: value}
+			f.ExternalID = append(f.ExternalID, x)
+			d.pushParser(makeExternalIDParser(d, x, level))
 		case "NOTE":
-			r := &NoteRecord{Note: value}
-			f.Note = append(f.Note, r)
-			d.pushParser(makeNoteParser(d, r, level))
+			f.Note = append(f.Note, d.noteStructure(value, level))
 		case "SOUR":
-			c := &CitationRecord{Source: d.source(stripXref(value))}
+			c := d.arena.citations.new(CitationRecord{Source: d.source(stripXref(value))})
 			f.Citation = append(f.Citation, c)
 			d.pushParser(makeCitationParser(d, c, level))
 		case "OBJE":
-			m := &MediaRecord{Xref: stripXref(value)}
-			f.Media = append(f.Media, m)
-			d.pushParser(makeMediaParser(d, m, level))
+			f.Media = append(f.Media, d.mediaStructure(value, level))
 		default:
 			f.UserDefined = append(f.UserDefined, UserDefinedTag{
 				Tag:   tag,
@@ -918,6 +1495,59 @@ func makeFamilyParser(d *Decoder, f *FamilyRecord, minLevel int) parser {
 	}
 }
 
+func makeLdsOrdinanceParser(d *Decoder, o *LdsOrdinanceRecord, minLevel int) parser {
+	return func(level int, tag string, value string, xref string) error {
+		if level <= minLevel {
+			return d.popParser(level, tag, value, xref)
+		}
+		switch tag {
+		case "DATE":
+			o.Date = value
+		case "TEMP":
+			o.Temple = value
+		case "PLAC":
+			o.Place = value
+		case "STAT":
+			o.Status = value
+			d.pushParser(makeLdsStatusParser(d, o, level))
+		case "FAMC":
+			o.Family = d.family(stripXref(value))
+		case "NOTE":
+			o.Note = append(o.Note, d.noteStructure(value, level))
+		case "SOUR":
+			c := d.arena.citations.new(CitationRecord{Source: d.source(stripXref(value))})
+			o.Citation = append(o.Citation, c)
+			d.pushParser(makeCitationParser(d, c, level))
+		default:
+			o.UserDefined = append(o.UserDefined, UserDefinedTag{
+				Tag:   tag,
+				Value: value,
+				Xref:  xref,
+				Level: level,
+			})
+			d.pushParser(makeUserDefinedTagParser(d, &o.UserDefined[len(o.UserDefined)-1], level))
+		}
+		return nil
+	}
+}
+
+func makeLdsStatusParser(d *Decoder, o *LdsOrdinanceRecord, minLevel int) parser {
+	return func(level int, tag string, value string, xref string) error {
+		if level <= minLevel {
+			return d.popParser(level, tag, value, xref)
+		}
+
+		switch tag {
+		case "DATE":
+			o.StatusDate = value
+		default:
+			d.unhandledTag(level, tag, value, xref)
+		}
+
+		return nil
+	}
+}
+
 func makeMediaParser(d *Decoder, m *MediaRecord, minLevel int) parser {
 	return func(level int, tag string, value string, xref string) error {
 		if level <= minLevel {
@@ -954,15 +1584,19 @@ func makeMediaParser(d *Decoder, m *MediaRecord, minLevel int) parser {
 			m.UserReference = append(m.UserReference, r)
 			d.pushParser(makeUserReferenceParser(d, r, level))
 		case "NOTE":
-			r := &NoteRecord{Note: value}
-			m.Note = append(m.Note, r)
-			d.pushParser(makeNoteParser(d, r, level))
+			m.Note = append(m.Note, d.noteStructure(value, level))
 		case "SOUR":
-			c := &CitationRecord{Source: d.source(stripXref(value))}
+			c := d.arena.citations.new(CitationRecord{Source: d.source(stripXref(value))})
 			m.Citation = append(m.Citation, c)
 			d.pushParser(makeCitationParser(d, c, level))
 		case "CHAN":
 			d.pushParser(makeChangeParser(d, &m.Change, level))
+		case "CREA": // 7.0
+			d.pushParser(makeChangeParser(d, &m.Creation, level))
+		case "EXID": // 7.0
+			x := &ExternalIDRecord{ID: value}
+			m.ExternalID = append(m.ExternalID, x)
+			d.pushParser(makeExternalIDParser(d, x, level))
 
 		default:
 			m.UserDefined = append(m.UserDefined, UserDefinedTag{
@@ -991,7 +1625,13 @@ func makeMediaFileParser(d *Decoder, f *FileRecord, minLevel int) parser {
 			f.Title = value
 			d.pushParser(makeTextParser(d, &f.Title, level))
 		default:
-			d.unhandledTag(level, tag, value, xref)
+			f.UserDefined = append(f.UserDefined, UserDefinedTag{
+				Tag:   tag,
+				Value: value,
+				Xref:  xref,
+				Level: level,
+			})
+			d.pushParser(makeUserDefinedTagParser(d, &f.UserDefined[len(f.UserDefined)-1], level))
 		}
 		return nil
 	}
@@ -1027,7 +1667,34 @@ func makeUserReferenceParser(d *Decoder, r *UserReferenceRecord, minLevel int) p
 		case "TYPE":
 			r.Type = value
 		default:
-			d.unhandledTag(level, tag, value, xref)
+			r.UserDefined = append(r.UserDefined, UserDefinedTag{
+				Tag:   tag,
+				Value: value,
+				Xref:  xref,
+				Level: level,
+			})
+			d.pushParser(makeUserDefinedTagParser(d, &r.UserDefined[len(r.UserDefined)-1], level))
+		}
+		return nil
+	}
+}
+
+func makeExternalIDParser(d *Decoder, x *ExternalIDRecord, minLevel int) parser {
+	return func(level int, tag string, value string, xref string) error {
+		if level <= minLevel {
+			return d.popParser(level, tag, value, xref)
+		}
+		switch tag {
+		case "TYPE":
+			x.Type = value
+		default:
+			x.UserDefined = append(x.UserDefined, UserDefinedTag{
+				Tag:   tag,
+				Value: value,
+				Xref:  xref,
+				Level: level,
+			})
+			d.pushParser(makeUserDefinedTagParser(d, &x.UserDefined[len(x.UserDefined)-1], level))
 		}
 		return nil
 	}
@@ -1079,6 +1746,8 @@ func makeAddressDetailParser(d *Decoder, a *AddressDetail, minLevel int) parser
 			a.PostalCode = value
 		case "CTRY":
 			a.Country = value
+		default:
+			d.unhandledTag(level, tag, value, xref)
 		}
 
 		return nil
@@ -1117,6 +1786,8 @@ func makeHeaderParser(d *Decoder, h *Header, minLevel int) parser {
 		case "CHAR":
 			h.CharacterSet = value
 			d.pushParser(makeHeaderCharacterSetVersionParser(d, h, level))
+		case "SCHMA": // 7.0
+			d.pushParser(makeSchemaParser(d, h, level))
 		default:
 			h.UserDefined = append(h.UserDefined, UserDefinedTag{
 				Tag:   tag,
@@ -1177,6 +1848,27 @@ func makeHeaderCharacterSetVersionParser(d *Decoder, h *Header, minLevel int) pa
 	}
 }
 
+func makeSchemaParser(d *Decoder, h *Header, minLevel int) parser {
+	return func(level int, tag string, value string, xref string) error {
+		if level <= minLevel {
+			return d.popParser(level, tag, value, xref)
+		}
+		switch tag {
+		case "TAG":
+			ext, uri, _ := strings.Cut(strings.TrimSpace(value), " ")
+			uri = strings.TrimSpace(uri)
+			h.Schema = append(h.Schema, SchemaTag{Tag: ext, URI: uri})
+			if d.schema == nil {
+				d.schema = make(map[string]string)
+			}
+			d.schema[ext] = uri
+		default:
+			d.unhandledTag(level, tag, value, xref)
+		}
+		return nil
+	}
+}
+
 func makeSystemParser(d *Decoder, s *SystemRecord, minLevel int) parser {
 	return func(level int, tag string, value string, xref string) error {
 		if level <= minLevel {
@@ -1212,7 +1904,9 @@ func makeCorpParser(d *Decoder, s *SystemRecord, minLevel int) parser {
 			return d.popParser(level, tag, value, xref)
 		}
 
-		tryAddressTags(d, &s.Address, level, tag, value, xref)
+		if !tryAddressTags(d, &s.Address, level, tag, value, xref) {
+			d.unhandledTag(level, tag, value, xref)
+		}
 		return nil
 	}
 }
@@ -1245,11 +1939,15 @@ func makeChangeParser(d *Decoder, c *ChangeRecord, minLevel int) parser {
 			c.Date = value
 			d.pushParser(makeChangeTimeParser(d, c, level))
 		case "NOTE":
-			r := &NoteRecord{Note: value}
-			c.Note = append(c.Note, r)
-			d.pushParser(makeNoteParser(d, r, level))
+			c.Note = append(c.Note, d.noteStructure(value, level))
 		default:
-			d.unhandledTag(level, tag, value, xref)
+			c.UserDefined = append(c.UserDefined, UserDefinedTag{
+				Tag:   tag,
+				Value: value,
+				Xref:  xref,
+				Level: level,
+			})
+			d.pushParser(makeUserDefinedTagParser(d, &c.UserDefined[len(c.UserDefined)-1], level))
 		}
 
 		return nil
@@ -1280,9 +1978,7 @@ func makeRepositoryParser(d *Decoder, r *RepositoryRecord, minLevel int) parser
 		case "NAME":
 			r.Name = value
 		case "NOTE":
-			n := &NoteRecord{Note: value}
-			r.Note = append(r.Note, n)
-			d.pushParser(makeNoteParser(d, n, level))
+			r.Note = append(r.Note, d.noteStructure(value, level))
 		case "RIN":
 			r.AutomatedRecordId = value
 		case "REFN":
@@ -1291,6 +1987,12 @@ func makeRepositoryParser(d *Decoder, r *RepositoryRecord, minLevel int) parser
 			d.pushParser(makeUserReferenceParser(d, u, level))
 		case "CHAN":
 			d.pushParser(makeChangeParser(d, &r.Change, level))
+		case "CREA": // 7.0
+			d.pushParser(makeChangeParser(d, &r.Creation, level))
+		case "EXID": // 7.0
+			x := &ExternalIDRecord{ID: value}
+			r.ExternalID = append(r.ExternalID, x)
+			d.pushParser(makeExternalIDParser(d, x, level))
 		default:
 			if tryAddressTags(d, &r.Address, level, tag, value, xref) {
 				return nil
@@ -1308,35 +2010,150 @@ func makeRepositoryParser(d *Decoder, r *RepositoryRecord, minLevel int) parser
 	}
 }
 
+func makeSubmitterParser(d *Decoder, s *SubmitterRecord, minLevel int) parser {
+	return func(level int, tag string, value string, xref string) error {
+		if level <= minLevel {
+			return d.popParser(level, tag, value, xref)
+		}
+		switch tag {
+		case "NAME":
+			s.Name = value
+		case "OBJE":
+			s.Media = append(s.Media, d.mediaStructure(value, level))
+		case "LANG":
+			s.Language = append(s.Language, value)
+		case "RFN":
+			s.SubmitterRecordFileID = value
+		case "RIN":
+			s.AutomatedRecordId = value
+		case "NOTE":
+			s.Note = append(s.Note, d.noteStructure(value, level))
+		case "CHAN":
+			if s.Change == nil {
+				s.Change = &ChangeRecord{}
+			}
+			d.pushParser(makeChangeParser(d, s.Change, level))
+		case "CREA": // 7.0
+			if s.Creation == nil {
+				s.Creation = &ChangeRecord{}
+			}
+			d.pushParser(makeChangeParser(d, s.Creation, level))
+		case "EXID": // 7.0
+			x := &ExternalIDRecord{ID: value}
+			s.ExternalID = append(s.ExternalID, x)
+			d.pushParser(makeExternalIDParser(d, x, level))
+		default:
+			a := s.Address
+			if a == nil {
+				a = &AddressRecord{}
+			}
+			if tryAddressTags(d, a, level, tag, value, xref) {
+				s.Address = a
+				return nil
+			}
+			s.UserDefined = append(s.UserDefined, UserDefinedTag{
+				Tag:   tag,
+				Value: value,
+				Xref:  xref,
+				Level: level,
+			})
+			d.pushParser(makeUserDefinedTagParser(d, &s.UserDefined[len(s.UserDefined)-1], level))
+		}
+
+		return nil
+	}
+}
+
+func makeSubmissionParser(d *Decoder, s *SubmissionRecord, minLevel int) parser {
+	return func(level int, tag string, value string, xref string) error {
+		if level <= minLevel {
+			return d.popParser(level, tag, value, xref)
+		}
+		switch tag {
+		case "SUBM":
+			s.Submitter = d.submitter(stripXref(value))
+		case "FAMF":
+			s.FamilyFile = value
+		case "TEMP":
+			s.Temple = value
+		case "ANCE":
+			s.Ancestors = value
+		case "DESC":
+			s.Descendants = value
+		case "ORDI":
+			s.Ordinance = value
+		case "RIN":
+			s.AutomatedRecordId = value
+		case "NOTE":
+			s.Note = append(s.Note, d.noteStructure(value, level))
+		case "CHAN":
+			d.pushParser(makeChangeParser(d, &s.Change, level))
+		default:
+			s.UserDefined = append(s.UserDefined, UserDefinedTag{
+				Tag:   tag,
+				Value: value,
+				Xref:  xref,
+				Level: level,
+			})
+			d.pushParser(makeUserDefinedTagParser(d, &s.UserDefined[len(s.UserDefined)-1], level))
+		}
+
+		return nil
+	}
+}
+
 func makeAssociationParser(d *Decoder, a *AssociationRecord, minLevel int) parser {
 	return func(level int, tag string, value string, xref string) error {
 		if level <= minLevel {
 			return d.popParser(level, tag, value, xref)
 		}
 		switch tag {
-		case "RELA":
+		case "RELA", "ROLE": // ROLE replaces RELA in 7.0
 			a.Relation = value
 		case "SOUR":
-			c := &CitationRecord{Source: d.source(stripXref(value))}
+			c := d.arena.citations.new(CitationRecord{Source: d.source(stripXref(value))})
 			a.Citation = append(a.Citation, c)
 			d.pushParser(makeCitationParser(d, c, level))
 		case "NOTE":
-			r := &NoteRecord{Note: value}
-			a.Note = append(a.Note, r)
-			d.pushParser(makeNoteParser(d, r, level))
+			a.Note = append(a.Note, d.noteStructure(value, level))
 		default:
-			d.unhandledTag(level, tag, value, xref)
+			a.UserDefined = append(a.UserDefined, UserDefinedTag{
+				Tag:   tag,
+				Value: value,
+				Xref:  xref,
+				Level: level,
+			})
+			d.pushParser(makeUserDefinedTagParser(d, &a.UserDefined[len(a.UserDefined)-1], level))
 		}
 
 		return nil
 	}
 }
 
-func makeUserDefinedTagParser(d *Decoder, u *UserDefinedTag, minLevel int) parser {
+// makeUnhandledParser returns a parser that records all subordinate tags as unhandled
+func makeUnhandledParser(d *Decoder, minLevel int) parser {
 	return func(level int, tag string, value string, xref string) error {
 		if level <= minLevel {
 			return d.popParser(level, tag, value, xref)
 		}
+		d.unhandledTag(level, tag, value, xref)
+		return nil
+	}
+}
+
+func makeUserDefinedTagParser(d *Decoder, u *UserDefinedTag, minLevel int) parser {
+	return func(level int, tag string, value string, xref string) error {
+		if level <= minLevel {
+			// The tag is complete so it can be decoded by a registered extension
+			if uri, ok := d.schema[u.Tag]; ok {
+				u.URI = uri
+			}
+			err := d.extensions.decode(u)
+			if perr := d.popParser(level, tag, value, xref); perr != nil {
+				return perr
+			}
+			return err
+		}
 		u.UserDefined = append(u.UserDefined, UserDefinedTag{
 			Tag:   tag,
 			Value: value,
@@ -1348,6 +2165,58 @@ func makeUserDefinedTagParser(d *Decoder, u *UserDefinedTag, minLevel int) parse
 	}
 }
 
+// isPointer reports whether value is a pointer to a record, such as @N1@
+func isPointer(value string) bool {
+	return len(value) > 2 && value[0] == '@' && value[len(value)-1] == '@' && value[1] != '@' && value[1] != '#' && !strings.Contains(value, " ")
+}
+
+// unescapeAt returns value with the doubled at signs that represent a literal @ replaced
+// by a single one. GEDCOM 5.5.1 doubles every @ that is not part of a pointer or an escape
+// sequence such as @#DJULIAN@, while GEDCOM 7 doubles only an @ at the start of a value.
+// Single at signs, which many programs write in email addresses, are left unchanged.
+func unescapeAt(value string, version7 bool) string {
+	if version7 {
+		if strings.HasPrefix(value, "@@") {
+			return value[1:]
+		}
+		return value
+	}
+	return strings.ReplaceAll(value, "@@", "@")
+}
+
+// escapeAt returns value with each literal @ doubled as required by GEDCOM 5.5.1, or
+// only a leading @ doubled for GEDCOM 7. Pointers and escape sequences such as
+// @#DJULIAN@ are left unchanged.
+func escapeAt(value string, version7 bool) string {
+	if !strings.Contains(value, "@") || isPointer(value) {
+		return value
+	}
+	if version7 {
+		if value[0] == '@' {
+			return "@" + value
+		}
+		return value
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '@' {
+			b.WriteByte(value[i])
+			continue
+		}
+		if i+1 < len(value) && value[i+1] == '#' {
+			if j := strings.IndexByte(value[i+2:], '@'); j >= 0 {
+				end := i + 2 + j
+				b.WriteString(value[i : end+1])
+				i = end
+				continue
+			}
+		}
+		b.WriteString("@@")
+	}
+	return b.String()
+}
+
 func stripXref(value string) string {
 	return strings.Trim(value, "@")
 }
@@ -7,6 +7,11 @@ package gedcom
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
 	"os"
 	"strings"
 	"testing"
@@ -423,6 +428,57 @@ func TestIndividualDetail(t *testing.T) {
 	}
 }
 
+// allgedSubmitter is the submitter record in testdata/allged.ged
+var allgedSubmitter = &SubmitterRecord{
+	Xref: "SUBMITTER",
+	Name: "/Submitter-Name/",
+	Address: &AddressRecord{
+		Address: []*AddressDetail{
+			{
+				Full:       "Submitter address line 1\nSubmitter address line 2\nSubmitter address line 3\nSubmitter address line 4",
+				Line1:      "Submitter address line 1",
+				Line2:      "Submitter address line 2",
+				City:       "Submitter address city",
+				State:      "Submitter address state",
+				PostalCode: "Submitter address ZIP code",
+				Country:    "Submitter address country",
+			},
+		},
+		Phone: []string{
+			"Submitter phone number 1",
+			"Submitter phone number 2",
+			"Submitter phone number 3 (last one!)",
+		},
+	},
+	Language: []string{"English"},
+	Change: &ChangeRecord{
+		Date: "19 JUN 2000",
+		Time: "12:34:56.789",
+		Note: []*NoteRecord{{Note: "A note\nNote continued here. The word TEST should not be broken!"}},
+	},
+	UserDefined: []UserDefinedTag{
+		{Tag: "_MYOWNTAG", Value: "This is a non-standard tag. Not recommended but allowed", Level: 1},
+	},
+}
+
+// allgedSubmission is the submission record in testdata/allged.ged
+var allgedSubmission = &SubmissionRecord{
+	Xref:        "SUBMISSION",
+	Submitter:   allgedSubmitter,
+	FamilyFile:  "NameOfFamilyFile",
+	Temple:      "Abreviated temple code",
+	Ancestors:   "1",
+	Descendants: "1",
+	Ordinance:   "yes",
+	UserDefined: []UserDefinedTag{
+		{
+			Tag:   "_MYOWNTAG",
+			Value: "SUBN does not allow NOTE tags :-(( so, here is my not: SUBN seems to be LDS internal data. The sample data I put in here are probably nonsence.",
+			Level: 1,
+		},
+	},
+}
+
 func TestSubmitter(t *testing.T) {
 	d := NewDecoder(bytes.NewReader(data))
 
@@ -431,13 +487,34 @@ func TestSubmitter(t *testing.T) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
-	submitters := []*SubmitterRecord{{Xref: "SUBMITTER"}}
+	submitters := []*SubmitterRecord{allgedSubmitter}
 
 	if diff := cmp.Diff(submitters, g.Submitter); diff != "" {
 		t.Errorf("submitter mismatch (-want +got):\n%s", diff)
 	}
 }
 
+func TestSubmission(t *testing.T) {
+	d := NewDecoder(bytes.NewReader(data))
+
+	g, err := d.Decode()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	submissions := []*SubmissionRecord{allgedSubmission}
+
+	if diff := cmp.Diff(submissions, g.Submission); diff != "" {
+		t.Errorf("submission mismatch (-want +got):\n%s", diff)
+	}
+	if g.Header.Submission != g.Submission[0] {
+		t.Errorf("header submission does not refer to the submission record")
+	}
+	if g.Submission[0].Submitter != g.Submitter[0] {
+		t.Errorf("submission submitter does not refer to the submitter record")
+	}
+}
+
 func TestFamily(t *testing.T) {
 	d := NewDecoder(bytes.NewReader(data))
 
@@ -610,6 +687,12 @@ func TestSource(t *testing.T) {
 								Place: "Another place",
 							},
 						},
+						Agency: "Resposible agency",
+						Note: []*NoteRecord{
+							{
+								Note: "A note about whatever\nNote continued here. The word TEST should not be broken!",
+							},
+						},
 					},
 					Title:            "Title of source\nTitle continued here. The word TEST should not be broken!",
 					Originator:       "Author of source\nAuthor continued here. The word TEST should not be broken!",
@@ -728,8 +811,8 @@ func TestHeader(t *testing.T) {
 		Destination:         "Destination of transmission",
 		Date:                "1 JAN 1998",
 		Time:                "13:57:24.80",
-		Submitter:           &SubmitterRecord{Xref: "SUBMITTER"},
-		Submission:          &SubmissionRecord{Xref: "SUBMISSION"},
+		Submitter:           allgedSubmitter,
+		Submission:          allgedSubmission,
 		Filename:            "ALLGED.GED",
 		Copyright:           "(C) 1997-2000 by H. Eichmann. You can use and distribute this file freely as long as you do not charge for it",
 		Version:             "5.5",
@@ -746,12 +829,12 @@ func TestHeader(t *testing.T) {
 			"(in this transmission this is demonstrated with tags: NAME, OCCU, PLACE and NOTE. Seek the word 'another'." + "\n" +
 			"The data transmitted here do not make sence. Just the HEAD.DATE tag contains the date of the creation" + "\n" +
 			"of this file and will change in future Versions!" + "\n" +
-			"This file is created by H. Eichmann: h.eichmann@@gmx.de. Feel free to copy and use it for any " + "\n" +
+			"This file is created by H. Eichmann: h.eichmann@gmx.de. Feel free to copy and use it for any " + "\n" +
 			"non-commercial purpose. For the creation the GEDCOM standard Release 5.5 (2 JAN 1996) has been used." + "\n" +
-			"Copyright: The church of Jesus Christ of latter-day saints, gedcom@@gedcom.org" + "\n" +
+			"Copyright: The church of Jesus Christ of latter-day saints, gedcom@gedcom.org" + "\n" +
 			"Download it (the GEDCOM 5.5 specs) from: ftp.gedcom.com/pub/genealogy/gedcom." + "\n" +
 			"Some Specials: This line is very very very very very very very very very very very very very very very very very very very very very very very very very very very very very very very very very long but not too long (255 caharcters is the limit). " + "\n" +
-			"This @@ (commercial at) character may only appear ONCE!" + "\n" +
+			"This @ (commercial at) character may only appear ONCE!" + "\n" +
 			"Note continued here. The word TEST should not be broken!",
 		UserDefined: []UserDefinedTag{
 			{Tag: "_MYOWNTAG", Value: "This is a non-standard tag. Not recommended but allowed", Level: 1},
@@ -820,7 +903,7 @@ func TestFixupAncestryBadNote(t *testing.T) {
 		PublicationFacts: "Ancestry.com Operations, Inc.",
 		Note: []*NoteRecord{
 			{
-				Note: "Board of Guardian Records and Church of England Parish Registers. London Metropolitan Archives, London.\n<p>Images produced by permission of the City of London Corporation. The City of London gives no warranty as to the accuracy, completeness or fitness for the purpose of the information provided. Images may be used only for purposes of research, private study or education. Applications for any other use should be made to London Metropolitan Archives, 40 Northampton Road, London EC1R 0HB. Email -   ask.lma@@cityoflondon.gov.uk. Infringement of the above condition may result in legal action.</p>",
+				Note: "Board of Guardian Records and Church of England Parish Registers. London Metropolitan Archives, London.\n<p>Images produced by permission of the City of London Corporation. The City of London gives no warranty as to the accuracy, completeness or fitness for the purpose of the information provided. Images may be used only for purposes of research, private study or education. Applications for any other use should be made to London Metropolitan Archives, 40 Northampton Road, London EC1R 0HB. Email -   ask.lma@cityoflondon.gov.uk. Infringement of the above condition may result in legal action.</p>",
 			},
 		},
 		UserDefined: []UserDefinedTag{
@@ -1590,3 +1673,545 @@ func TestFamilyEvent(t *testing.T) {
 		})
 	}
 }
+
+func TestIndividualDNA(t *testing.T) {
+	input := `
+0 @I1@ INDI
+1 NAME John /Smith/
+1 _MTDNA H1a
+2 TYPE HVR1
+2 DATE 12 MAR 2015
+1 _YDNA R1b-L21
+2 NOTE Tested at FTDNA
+1 _DNA Autosomal
+2 _MATCH 42 cM
+`
+	d := NewDecoder(bytes.NewReader([]byte(input)))
+
+	g, err := d.Decode()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []*DNARecord{
+		{Tag: "_MTDNA", Value: "H1a", Type: "HVR1", Date: "12 MAR 2015"},
+		{Tag: "_YDNA", Value: "R1b-L21", Note: []*NoteRecord{{Note: "Tested at FTDNA"}}},
+		{Tag: "_DNA", Value: "Autosomal", UserDefined: []UserDefinedTag{{Tag: "_MATCH", Value: "42 cM", Level: 2}}},
+	}
+
+	indi := g.Individual[0]
+	if diff := cmp.Diff(want, indi.DNA); diff != "" {
+		t.Errorf("dna mismatch (-want +got):\n%s", diff)
+	}
+	if len(indi.UserDefined) != 0 {
+		t.Errorf("got %d user defined tags, wanted none", len(indi.UserDefined))
+	}
+	if got := indi.MtDNAHaplogroup(); got != "H1a" {
+		t.Errorf("got mtDNA haplogroup %q, wanted %q", got, "H1a")
+	}
+	if got := indi.YDNAHaplogroup(); got != "R1b-L21" {
+		t.Errorf("got Y-DNA haplogroup %q, wanted %q", got, "R1b-L21")
+	}
+}
+
+func TestDecodeFromOffset(t *testing.T) {
+	input := "0 HEAD\n1 CHAR UTF-8\n0 @I1@ INDI\n1 NAME John /Smith/\n0 @I2@ INDI\n1 NAME Mary /Jones/\n0 TRLR\n"
+	off := int64(strings.Index(input, "0 @I2@"))
+
+	readers := map[string]func() io.Reader{
+		"seeker":    func() io.Reader { return strings.NewReader(input) },
+		"nonseeker": func() io.Reader { return io.MultiReader(strings.NewReader(input)) },
+	}
+
+	for name, r := range readers {
+		t.Run(name, func(t *testing.T) {
+			d := NewDecoder(r(), WithStartOffset(off))
+			g, err := d.Decode()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(g.Individual) != 1 || g.Individual[0].Xref != "I2" {
+				t.Fatalf("got %d individuals, wanted only I2", len(g.Individual))
+			}
+			if g.Header != nil {
+				t.Errorf("got header, wanted none")
+			}
+			if got, want := d.RecordOffset(), int64(strings.Index(input, "0 TRLR")); got != want {
+				t.Errorf("got record offset %d, wanted %d", got, want)
+			}
+		})
+	}
+
+	t.Run("mid record", func(t *testing.T) {
+		mid := int64(strings.Index(input, "1 NAME John"))
+		if _, err := NewDecoder(strings.NewReader(input), WithStartOffset(mid)).Decode(); err == nil {
+			t.Errorf("got no error, wanted error for offset within a record")
+		}
+	})
+}
+
+func TestDecodeFromOffsetUTF16(t *testing.T) {
+	input := "0 HEAD\r\n1 CHAR UNICODE\r\n0 @I1@ INDI\r\n1 NAME John /Smith/\r\n0 @I2@ INDI\r\n1 NAME Mary /Jones/\r\n0 TRLR\r\n"
+	data := encodeUTF16(input, false, true)
+
+	// Each character of the input is two bytes, following the two byte BOM
+	offsetOf := func(s string) int64 {
+		return 2 + 2*int64(strings.Index(input, s))
+	}
+
+	d := NewDecoder(bytes.NewReader(data))
+	if _, err := d.Decode(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, want := d.RecordOffset(), offsetOf("0 TRLR"); got != want {
+		t.Errorf("got record offset %d, wanted %d", got, want)
+	}
+
+	g, err := NewDecoder(bytes.NewReader(data), WithStartOffset(offsetOf("0 @I2@"))).Decode()
+	if err != nil {
+		t.Fatalf("unexpected error resuming: %v", err)
+	}
+	if len(g.Individual) != 1 || g.Individual[0].Xref != "I2" {
+		t.Errorf("got %d individuals, wanted only I2", len(g.Individual))
+	}
+}
+
+func TestWithLogger(t *testing.T) {
+	input := "0 @I1@ INDI\n1 CHAN\n2 DATE 1 JAN 2000\n3 TIME 10:00\n4 _FOO bar\n0 TRLR\n"
+
+	buf := new(bytes.Buffer)
+	logger := slog.New(slog.NewJSONHandler(buf, nil))
+	if _, err := NewDecoder(strings.NewReader(input), WithLogger(logger)).Decode(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("failed to parse log output %q: %v", buf.String(), err)
+	}
+	want := map[string]any{
+		"level":       "WARN",
+		"msg":         "unhandled tag",
+		"line":        float64(5),
+		"tag":         "_FOO",
+		"value":       "bar",
+		"record":      "INDI",
+		"record_xref": "I1",
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("got %s=%v, wanted %v", k, got[k], v)
+		}
+	}
+}
+
+func TestUnhandledTags(t *testing.T) {
+	input := `0 @I1@ INDI
+1 NOTE A note
+2 _COLOR red
+1 BIRT
+2 PLAC Boston
+3 MAP
+4 LATI N42.36
+4 LONG W71.06
+4 _ACCURACY 10
+5 _UNIT m
+1 CHAN
+2 DATE 1 JAN 2000
+2 _USER jane
+0 TRLR
+`
+	g, err := NewDecoder(strings.NewReader(input)).Decode()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	indi := g.Individual[0]
+	if diff := cmp.Diff([]UserDefinedTag{{Tag: "_COLOR", Value: "red", Level: 2}}, indi.Note[0].UserDefined); diff != "" {
+		t.Errorf("note user defined mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]UserDefinedTag{{Tag: "_USER", Value: "jane", Level: 2}}, indi.Change.UserDefined); diff != "" {
+		t.Errorf("change user defined mismatch (-want +got):\n%s", diff)
+	}
+
+	wantUnhandled := []UnhandledTag{
+		{
+			UserDefinedTag: UserDefinedTag{
+				Tag:         "_ACCURACY",
+				Value:       "10",
+				Level:       4,
+				UserDefined: []UserDefinedTag{{Tag: "_UNIT", Value: "m", Level: 5}},
+			},
+			Line:       9,
+			Record:     "INDI",
+			RecordXref: "I1",
+		},
+	}
+	if diff := cmp.Diff(wantUnhandled, g.Unhandled); diff != "" {
+		t.Errorf("unhandled mismatch (-want +got):\n%s", diff)
+	}
+
+	buf := new(bytes.Buffer)
+	if err := NewEncoder(buf).Encode(g); err != nil {
+		t.Fatalf("unexpected encode error: %v", err)
+	}
+	for _, line := range []string{"2 _COLOR red", "2 _USER jane"} {
+		if !strings.Contains(buf.String(), line+"\n") {
+			t.Errorf("encoded output missing %q", line)
+		}
+	}
+}
+
+func TestSharedNotes(t *testing.T) {
+	input := `0 HEAD
+1 CHAR UTF-8
+1 SOUR test
+0 @I1@ INDI
+1 NAME John /Smith/
+1 BIRT
+2 NOTE @N1@
+2 NOTE An inline note
+1 NOTE @N1@
+0 @S1@ SOUR
+1 TITL Register
+0 @N1@ NOTE A shared note
+1 CONT continued
+1 SOUR @S1@
+0 TRLR
+`
+	g, err := NewDecoder(strings.NewReader(input)).Decode()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(g.Note) != 1 {
+		t.Fatalf("got %d shared notes, wanted 1", len(g.Note))
+	}
+	shared := g.Note[0]
+	if shared.Xref != "N1" || shared.Note != "A shared note\ncontinued" {
+		t.Errorf("got shared note %q with xref %q", shared.Note, shared.Xref)
+	}
+	if len(shared.Citation) != 1 || shared.Citation[0].Source != g.Source[0] {
+		t.Errorf("shared note citation was not linked to the source record")
+	}
+
+	indi := g.Individual[0]
+	if indi.Note[0] != shared {
+		t.Errorf("individual note does not refer to the shared note")
+	}
+	if indi.Event[0].Note[0] != shared {
+		t.Errorf("event note does not refer to the shared note")
+	}
+	if indi.Event[0].Note[1].Note != "An inline note" || indi.Event[0].Note[1].Xref != "" {
+		t.Errorf("got inline note %+v", indi.Event[0].Note[1])
+	}
+
+	buf := new(bytes.Buffer)
+	if err := NewEncoder(buf).Encode(g); err != nil {
+		t.Fatalf("unexpected encode error: %v", err)
+	}
+	if buf.String() != input {
+		t.Errorf("encoded output mismatch:\ngot:\n%s\nwant:\n%s", buf.String(), input)
+	}
+}
+
+func TestLdsOrdinances(t *testing.T) {
+	input := `0 HEAD
+1 CHAR UTF-8
+1 SOUR test
+0 @I1@ INDI
+1 NAME John /Smith/
+1 BAPL
+2 DATE 1 JAN 1950
+2 TEMP SLAKE
+2 STAT COMPLETED
+3 DATE 2 JAN 1950
+1 SLGC
+2 TEMP LOGAN
+2 FAMC @F1@
+2 SOUR @S1@
+1 FAMC @F1@
+0 @F1@ FAM
+1 SLGS
+2 DATE 5 MAY 1930
+2 PLAC Salt Lake City
+2 NOTE Sealed
+0 @S1@ SOUR
+1 TITL Temple records
+0 TRLR
+`
+	g, err := NewDecoder(strings.NewReader(input)).Decode()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	fam := g.Family[0]
+	wantIndi := []*LdsOrdinanceRecord{
+		{Tag: "BAPL", Date: "1 JAN 1950", Temple: "SLAKE", Status: "COMPLETED", StatusDate: "2 JAN 1950"},
+		{Tag: "SLGC", Temple: "LOGAN", Family: fam, Citation: []*CitationRecord{{Source: g.Source[0]}}},
+	}
+	if diff := cmp.Diff(wantIndi, g.Individual[0].Ordinance, familyXrefComparer, sourceXrefComparer); diff != "" {
+		t.Errorf("individual ordinance mismatch (-want +got):\n%s", diff)
+	}
+
+	wantFam := []*LdsOrdinanceRecord{
+		{Tag: "SLGS", Date: "5 MAY 1930", Place: "Salt Lake City", Note: []*NoteRecord{{Note: "Sealed"}}},
+	}
+	if diff := cmp.Diff(wantFam, fam.Ordinance); diff != "" {
+		t.Errorf("family ordinance mismatch (-want +got):\n%s", diff)
+	}
+
+	buf := new(bytes.Buffer)
+	if err := NewEncoder(buf).Encode(g); err != nil {
+		t.Fatalf("unexpected encode error: %v", err)
+	}
+	if buf.String() != input {
+		t.Errorf("encoded output mismatch:\ngot:\n%s\nwant:\n%s", buf.String(), input)
+	}
+}
+
+func TestSharedMedia(t *testing.T) {
+	input := `0 @I1@ INDI
+1 OBJE @M1@
+1 OBJE
+2 FILE photo.jpg
+2 NOTE An inline note
+0 @M1@ OBJE
+1 FILE portrait.jpg
+2 FORM jpg
+0 TRLR
+`
+	g, err := NewDecoder(strings.NewReader(input)).Decode()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	indi := g.Individual[0]
+	if len(indi.Media) != 2 {
+		t.Fatalf("got %d media links, wanted 2", len(indi.Media))
+	}
+	if indi.Media[0] != g.Media[0] {
+		t.Errorf("media pointer does not refer to the shared media record")
+	}
+	if indi.Media[0].File[0].Name != "portrait.jpg" {
+		t.Errorf("got file %q, wanted portrait.jpg", indi.Media[0].File[0].Name)
+	}
+	if indi.Media[1].Xref != "" || indi.Media[1].File[0].Name != "photo.jpg" {
+		t.Errorf("got inline media %+v", indi.Media[1])
+	}
+
+	buf := new(bytes.Buffer)
+	if err := NewEncoder(buf).Encode(g); err != nil {
+		t.Fatalf("unexpected encode error: %v", err)
+	}
+	for _, line := range []string{"1 OBJE @M1@", "2 NOTE An inline note", "0 @M1@ OBJE"} {
+		if !strings.Contains(buf.String(), line+"\n") {
+			t.Errorf("encoded output missing %q", line)
+		}
+	}
+}
+
+func TestCitationEventRole(t *testing.T) {
+	input := `0 @I1@ INDI
+1 BIRT
+2 SOUR @S1@
+3 PAGE 12
+3 EVEN BIRT
+4 ROLE CHIL
+3 QUAY 3
+0 @S1@ SOUR
+1 TITL Baptisms
+0 TRLR
+`
+	g, err := NewDecoder(strings.NewReader(input)).Decode()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []*CitationRecord{{Source: g.Source[0], Page: "12", Event: "BIRT", Role: "CHIL", Quay: "3"}}
+	if diff := cmp.Diff(want, g.Individual[0].Event[0].Citation, sourceXrefComparer); diff != "" {
+		t.Errorf("citation mismatch (-want +got):\n%s", diff)
+	}
+
+	buf := new(bytes.Buffer)
+	if err := NewEncoder(buf).Encode(g); err != nil {
+		t.Fatalf("unexpected encode error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "3 PAGE 12\n3 EVEN BIRT\n4 ROLE CHIL\n3 QUAY 3\n") {
+		t.Errorf("encoded output missing citation event and role:\n%s", buf.String())
+	}
+}
+
+func TestEventAge(t *testing.T) {
+	input := `0 @I1@ INDI
+1 DEAT
+2 DATE 1901
+2 AGE 42y 6m
+0 TRLR
+`
+	g, err := NewDecoder(strings.NewReader(input)).Decode()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ev := g.Individual[0].Event[0]
+	if ev.Age != "42y 6m" {
+		t.Errorf("got age %q, wanted %q", ev.Age, "42y 6m")
+	}
+	if len(ev.UserDefined) != 0 {
+		t.Errorf("got user defined tags %+v, wanted none", ev.UserDefined)
+	}
+
+	buf := new(bytes.Buffer)
+	if err := NewEncoder(buf).Encode(g); err != nil {
+		t.Fatalf("unexpected encode error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "1 DEAT\n2 DATE 1901\n2 AGE 42y 6m\n") {
+		t.Errorf("encoded output missing age:\n%s", buf.String())
+	}
+}
+
+func TestFamilyEventSpouseAge(t *testing.T) {
+	input := `0 @F1@ FAM
+1 CENS
+2 DATE 1901
+2 HUSB
+3 AGE 42y
+2 WIFE
+3 AGE 39y 6m
+0 TRLR
+`
+	g, err := NewDecoder(strings.NewReader(input)).Decode()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ev := g.Family[0].Event[0]
+	if ev.HusbandAge != "42y" {
+		t.Errorf("got husband age %q, wanted %q", ev.HusbandAge, "42y")
+	}
+	if ev.WifeAge != "39y 6m" {
+		t.Errorf("got wife age %q, wanted %q", ev.WifeAge, "39y 6m")
+	}
+	if len(ev.UserDefined) != 0 {
+		t.Errorf("got user defined tags %+v, wanted none", ev.UserDefined)
+	}
+
+	buf := new(bytes.Buffer)
+	if err := NewEncoder(buf).Encode(g); err != nil {
+		t.Fatalf("unexpected encode error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "1 CENS\n2 DATE 1901\n2 HUSB\n3 AGE 42y\n2 WIFE\n3 AGE 39y 6m\n") {
+		t.Errorf("encoded output missing spouse ages:\n%s", buf.String())
+	}
+}
+
+func TestRestrictionNotice(t *testing.T) {
+	input := `0 @I1@ INDI
+1 RESN confidential
+1 NAME Jane /Doe/
+0 @F1@ FAM
+1 RESN locked
+1 WIFE @I1@
+0 TRLR
+`
+	g, err := NewDecoder(strings.NewReader(input)).Decode()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := g.Individual[0].RestrictionNotice; got != "confidential" {
+		t.Errorf("got individual restriction notice %q, wanted %q", got, "confidential")
+	}
+	if got := g.Family[0].RestrictionNotice; got != "locked" {
+		t.Errorf("got family restriction notice %q, wanted %q", got, "locked")
+	}
+	if len(g.Individual[0].UserDefined) != 0 || len(g.Family[0].UserDefined) != 0 {
+		t.Errorf("got unexpected user defined tags")
+	}
+
+	buf := new(bytes.Buffer)
+	if err := NewEncoder(buf).Encode(g); err != nil {
+		t.Fatalf("unexpected encode error: %v", err)
+	}
+	for _, want := range []string{"0 @I1@ INDI\n1 RESN confidential\n", "0 @F1@ FAM\n1 RESN locked\n"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("encoded output missing %q:\n%s", want, buf.String())
+		}
+	}
+}
+
+// lineReader returns one line of its input for each call to Read and calls onLine with
+// the number of lines returned so far
+type lineReader struct {
+	lines  []string
+	n      int
+	onLine func(n int)
+}
+
+func (r *lineReader) Read(p []byte) (int, error) {
+	if r.n >= len(r.lines) {
+		return 0, io.EOF
+	}
+	n := copy(p, r.lines[r.n])
+	r.n++
+	r.onLine(r.n)
+	return n, nil
+}
+
+func TestDecodeContext(t *testing.T) {
+	input := "0 HEAD\n0 @I1@ INDI\n1 NAME Jane /Doe/\n0 @I2@ INDI\n1 NAME John /Doe/\n0 TRLR\n"
+
+	g, err := NewDecoder(strings.NewReader(input)).DecodeContext(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(g.Individual) != 2 {
+		t.Errorf("got %d individuals, wanted 2", len(g.Individual))
+	}
+
+	// Cancel the context once the first individual has been read
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	r := &lineReader{
+		lines: strings.SplitAfter(input, "\n"),
+		onLine: func(n int) {
+			if n == 3 {
+				cancel()
+			}
+		},
+	}
+	_, err = NewDecoder(r).DecodeContext(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("got error %v, wanted %v", err, context.Canceled)
+	}
+}
+
+func TestDecodeExtendedTags(t *testing.T) {
+	input := "0 HEAD\n0 @I1@ INDI\n1 NAME John /Smith/\n1 _FOO-BAR value\n2 _SUB.TAG sub\n0 TRLR\n"
+
+	if _, err := NewDecoder(strings.NewReader(input)).Decode(); err == nil {
+		t.Errorf("got no error, wanted one for a tag containing punctuation")
+	}
+
+	g, err := NewDecoder(strings.NewReader(input), WithExtendedTags()).Decode()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(g.Individual) != 1 {
+		t.Fatalf("got %d individuals, wanted 1", len(g.Individual))
+	}
+	want := []UserDefinedTag{
+		{
+			Tag:   "_FOO-BAR",
+			Value: "value",
+			Level: 1,
+			UserDefined: []UserDefinedTag{
+				{Tag: "_SUB.TAG", Value: "sub", Level: 2},
+			},
+		},
+	}
+	if diff := cmp.Diff(want, g.Individual[0].UserDefined); diff != "" {
+		t.Errorf("user defined mismatch (-want +got):\n%s", diff)
+	}
+}
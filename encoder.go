@@ -7,86 +7,599 @@ package gedcom
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"io"
+	"reflect"
+	"slices"
+	"strconv"
 	"strings"
+	"unicode/utf8"
 )
 
 // An Encoder encodes and writes GEDCOM objects to an input stream.
 type Encoder struct {
-	w   *bufio.Writer
-	err error
+	w             *bufio.Writer
+	out           io.Writer      // writer given to NewEncoder
+	charset       string         // character set of the output, set by WithOutputCharset
+	cw            *charsetWriter // converts the output to charset, if it is not UTF-8
+	err           error
+	continuation  ContinuationMode
+	concSplit     ConcSplitMode
+	maxLine       int // maximum length of a line in bytes, set by WithMaxLineLength
+	hooks         RecordHooks
+	extensions    *ExtensionRegistry
+	cardinality   CardinalityMode
+	checker       *validator
+	lines         int
+	findings      []Finding
+	eol           string            // characters written at the end of each line
+	fixedEOL      bool              // whether eol was set by WithLineEnding
+	version       string            // version set by WithTargetVersion
+	v7            bool              // whether GEDCOM 7 is being written
+	v55           bool              // whether GEDCOM 5.5 is being written
+	declared      []SchemaTag       // extension tags declared in the header
+	dates         bool              // whether DATE values are rewritten in canonical form
+	canonical     bool              // whether records and substructures are written in canonical order
+	headerDefault bool              // whether required header values that are missing are supplied
+	sourceOrder   bool              // whether records are written in the order they were decoded
+	omitEmpty     bool              // whether substructures with no content are omitted
+	generateXrefs bool              // whether records without xrefs are given one
+	xrefs         map[Record]string // xrefs generated for records that have none
+	errs          []error           // problems found in records that could not be encoded
+	path          []string          // tags of the lines enclosing the line being written
+	recordXref    string            // xref of the record being written
+	dateOpts      []DateOption
 }
 
+// An EncodeError describes a problem that prevented a record from being encoded, such
+// as a pointer to a family that has no xref. Encode continues with the next record after
+// such a problem and returns all the problems it found, joined using errors.Join. Use
+// errors.As to find the first, or the Unwrap() []error method of the returned error to
+// list them all.
+type EncodeError struct {
+	Xref string // xref of the record, if it has one
+	Path string // path of the structure from its record, e.g. "INDI.FAMS"
+	Err  error
+}
+
+func (e *EncodeError) Error() string {
+	if e.Xref == "" {
+		return fmt.Sprintf("encode %s: %v", e.Path, e.Err)
+	}
+	return fmt.Sprintf("encode @%s@ %s: %v", e.Xref, e.Path, e.Err)
+}
+
+func (e *EncodeError) Unwrap() error {
+	return e.Err
+}
+
+// An EncoderOption configures an Encoder.
+type EncoderOption interface {
+	applyEncoder(*Encoder)
+}
+
+type encoderOptionFunc func(*Encoder)
+
+func (f encoderOptionFunc) applyEncoder(e *Encoder) { f(e) }
+
 // NewEncoder returns a new encoder that writes to w.
-func NewEncoder(w io.Writer) *Encoder {
+func NewEncoder(w io.Writer, opts ...EncoderOption) *Encoder {
 	bw := bufio.NewWriter(w)
-	return &Encoder{
-		w: bw,
+	e := &Encoder{
+		w:       bw,
+		out:     w,
+		eol:     "\n",
+		maxLine: DefaultMaxLineLength,
+	}
+	for _, o := range opts {
+		o.applyEncoder(e)
 	}
+	return e
 }
 
-func (e *Encoder) Encode(g *Gedcom) error {
-	e.header(g.Header)
+// ContinuationMode controls how the encoder writes text values that are too long to fit
+// on a single line.
+type ContinuationMode int
 
-	for _, r := range g.Individual {
-		e.individual(r)
+const (
+	// ContinueWithConc splits long lines of text using CONC tags. This is the default.
+	ContinueWithConc ContinuationMode = iota
+
+	// ContinueOnlyError never writes CONC tags. Text is split only at newlines, using
+	// CONT, and encoding fails if a single line of text is too long.
+	ContinueOnlyError
+
+	// ContinueOnlyTruncate never writes CONC tags. Text is split only at newlines, using
+	// CONT, and single lines of text that are too long are truncated.
+	ContinueOnlyTruncate
+)
+
+// WithContinuation configures how the encoder writes long lines of text. Some importers,
+// and GEDCOM 7, do not accept CONC tags.
+func WithContinuation(m ContinuationMode) EncoderOption {
+	return encoderOptionFunc(func(e *Encoder) {
+		e.continuation = m
+	})
+}
+
+// DefaultMaxLineLength is the maximum length of a line written by the encoder unless
+// configured otherwise, which is the limit set by the GEDCOM 5.5.1 specification.
+const DefaultMaxLineLength = 255
+
+// WithMaxLineLength configures the encoder to split text so that no line is longer than
+// n bytes, including the level, tag, any xref and the line ending. The default is
+// DefaultMaxLineLength. Some programs read only shorter lines. The limit does not apply
+// when writing GEDCOM 7, which has no maximum line length.
+func WithMaxLineLength(n int) EncoderOption {
+	return encoderOptionFunc(func(e *Encoder) {
+		e.maxLine = n
+	})
+}
+
+// ConcSplitMode controls where the encoder splits text that is continued using CONC.
+type ConcSplitMode int
+
+const (
+	// ConcSplitMidWord splits text between two characters that are not spaces, as
+	// recommended by the GEDCOM 5.5.1 specification, since some programs remove spaces
+	// from the ends of lines. This is the default.
+	ConcSplitMidWord ConcSplitMode = iota
+
+	// ConcSplitWords splits text after a space so that words are not divided between
+	// lines. Each line but the last ends with a space, which programs that remove
+	// trailing spaces will lose.
+	ConcSplitWords
+)
+
+// WithConcSplit configures where the encoder splits long lines of text that are continued
+// using CONC.
+func WithConcSplit(m ConcSplitMode) EncoderOption {
+	return encoderOptionFunc(func(e *Encoder) {
+		e.concSplit = m
+	})
+}
+
+// WithLineEnding configures the encoder to terminate lines with l. By default the encoder
+// uses the line ending recorded in the Gedcom being encoded, so that data is written with
+// the same line endings as it was read.
+func WithLineEnding(l LineEnding) EncoderOption {
+	return encoderOptionFunc(func(e *Encoder) {
+		e.eol = l.chars()
+		e.fixedEOL = true
+	})
+}
+
+// WithTargetVersion configures the encoder to write data conforming to version v of
+// GEDCOM, such as "5.5.1" or "7.0", which is also written in the header. By default the
+// encoder writes the version declared by the header of the Gedcom being encoded.
+//
+// When writing GEDCOM 7 the header begins with the GEDC structure and has no CHAR tag,
+// text is split only at newlines using CONT, shared notes are written as SNOTE records,
+// enumerated values are written in upper case and media formats as media types. Tags
+// that were removed, such as RIN and AFN, are written as user defined tags and
+// submission records are omitted. When writing GEDCOM 5.5 the tags added by 5.5.1, such
+// as EMAIL, WWW and the MAP structure of a place, are written as user defined tags such
+// as _EMAIL.
+func WithTargetVersion(v string) EncoderOption {
+	return encoderOptionFunc(func(e *Encoder) {
+		e.version = v
+	})
+}
+
+// targetVersion returns the version of GEDCOM to write for data with the header h
+func (e *Encoder) targetVersion(h *Header) string {
+	if e.version != "" {
+		return e.version
 	}
+	if h != nil {
+		return h.Version
+	}
+	return ""
+}
 
-	for _, r := range g.Family {
-		e.family(r)
+// WithHeaderDefaults configures the encoder to supply values for the parts of the header
+// required by the specification that the Gedcom being encoded lacks, so that data built
+// in code is accepted by other programs. The default version is 5.5.1, the form
+// LINEAGE-LINKED, the character set UTF-8 and the source system gedcom, this package. A
+// header is written even if the Gedcom has none. The Gedcom being encoded is not modified.
+func WithHeaderDefaults() EncoderOption {
+	return encoderOptionFunc(func(e *Encoder) {
+		e.headerDefault = true
+	})
+}
+
+// Values written by an encoder configured with WithHeaderDefaults
+const (
+	defaultVersion      = "5.5.1"
+	defaultForm         = "LINEAGE-LINKED"
+	defaultCharacterSet = "UTF-8"
+	defaultSourceSystem = "gedcom"
+)
+
+// headerDefaults returns a copy of h with any missing required values supplied
+func (e *Encoder) headerDefaults(h *Header) *Header {
+	var d Header
+	if h != nil {
+		d = *h
 	}
+	if e.targetVersion(&d) == "" {
+		d.Version = defaultVersion
+	}
+	if d.Form == "" {
+		d.Form = defaultForm
+	}
+	if d.CharacterSet == "" {
+		d.CharacterSet = defaultCharacterSet
+	}
+	if d.SourceSystem.Xref == "" {
+		d.SourceSystem.Xref = defaultSourceSystem
+	}
+	return &d
+}
 
-	e.mediaList(0, g.Media)
+// WithOmitEmpty configures the encoder to omit substructures that hold no values, such as
+// the DATA structure of a source with no events or agency, an address with no lines or an
+// inline multimedia link with no file, rather than write them as tags with nothing
+// beneath them. Decoding the output gives the same values, though without the
+// empty structures. Events and attributes are always written since their tag alone
+// records that they occurred.
+func WithOmitEmpty() EncoderOption {
+	return encoderOptionFunc(func(e *Encoder) {
+		e.omitEmpty = true
+	})
+}
 
-	for _, r := range g.Repository {
-		e.repository(r)
+// omit reports whether the structure v should not be written because it holds no values
+func (e *Encoder) omit(v any) bool {
+	return e.omitEmpty && isEmptyValue(reflect.Indirect(reflect.ValueOf(v)))
+}
+
+// isEmptyValue reports whether v holds no values: it is a zero value, a nil pointer, a
+// pointer to or struct of empty values, or a slice of empty values. A pointer to a record
+// is a link to the record and is not empty.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Invalid:
+		return true
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return true
+		}
+		if v.Type().Implements(reflect.TypeFor[Record]()) {
+			return false
+		}
+		return isEmptyValue(v.Elem())
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if !isEmptyValue(v.Index(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() && !isEmptyValue(v.Field(i)) {
+				return false
+			}
+		}
+		return true
 	}
+	return v.IsZero()
+}
 
-	for _, r := range g.Source {
-		e.source(r)
+// WithCanonicalDates configures the encoder to rewrite each DATE value that ParseDate,
+// given opts, can interpret in the canonical syntax of the GEDCOM version being written,
+// with upper case keywords and GEDCOM month names. Combined with WithDateLocale this
+// converts dates written with the month names of another language. Values that cannot be
+// interpreted are written unchanged, as are GEDCOM 7 values that include a date phrase.
+func WithCanonicalDates(opts ...DateOption) EncoderOption {
+	return encoderOptionFunc(func(e *Encoder) {
+		e.dates = true
+		e.dateOpts = opts
+	})
+}
+
+// RecordHooks holds functions called by the Encoder as it writes each top-level record,
+// allowing applications to add extension tags or omit records. Either function may be nil.
+type RecordHooks struct {
+	// Before is called before a record is written. The record is omitted from the output
+	// if Before returns false.
+	Before func(r Record) bool
+
+	// After is called after a record has been written. The returned tags are written as
+	// additional level 1 tags of the record.
+	After func(r Record) []UserDefinedTag
+}
+
+// WithRecordHooks configures the encoder to call the functions in h as it writes each
+// top-level record.
+func WithRecordHooks(h RecordHooks) EncoderOption {
+	return encoderOptionFunc(func(e *Encoder) {
+		e.hooks = h
+	})
+}
+
+// CardinalityMode controls whether the encoder checks that the tags it writes occur no
+// more often than allowed by the GEDCOM 5.5.1 grammar, such as a record having two SEX
+// tags or a header having two GEDC structures.
+type CardinalityMode int
+
+const (
+	// CardinalityIgnore writes tags without checking them. This is the default.
+	CardinalityIgnore CardinalityMode = iota
+
+	// CardinalityWarn writes all tags and records a finding for each tag that occurs too
+	// often. The findings are returned by the Findings method.
+	CardinalityWarn
+
+	// CardinalityError stops encoding with an error when a tag occurs too often.
+	CardinalityError
+)
+
+// WithCardinalityCheck configures whether the encoder checks that singleton tags are not
+// repeated in the output.
+func WithCardinalityCheck(m CardinalityMode) EncoderOption {
+	return encoderOptionFunc(func(e *Encoder) {
+		e.cardinality = m
+	})
+}
+
+// Findings returns the cardinality findings recorded by the most recent call to Encode
+// when the encoder is configured with CardinalityWarn.
+func (e *Encoder) Findings() []Finding {
+	return e.findings
+}
+
+// Encode writes g to the output. A record that cannot be encoded is written only as far
+// as the problem, which is reported as an EncodeError, and encoding continues with the
+// next record. The returned error joins all the problems found.
+func (e *Encoder) Encode(g *Gedcom) error {
+	e.begin(g.Header, g.LineEnding)
+	if e.v7 {
+		e.declared = declaredTags(g)
 	}
+	e.assignXrefs(g)
 
-	for _, r := range g.Submitter {
-		e.submitter(0, r)
+	e.recordXref = ""
+	e.header(g.Header)
+	e.collect()
+
+	for _, r := range e.records(g) {
+		e.record(r)
 	}
 
+	e.recordXref = ""
 	e.userDefinedList(0, g.UserDefined)
+	e.collect()
 	e.trailer(g.Trailer)
 
 	return e.flush()
 }
 
+// begin prepares the encoder to write data with the header h, ending lines with l unless
+// configured otherwise
+func (e *Encoder) begin(h *Header, l LineEnding) {
+	e.lines = 0
+	e.findings = nil
+	e.errs = nil
+	e.xrefs = nil
+	if !e.fixedEOL {
+		e.eol = l.chars()
+	}
+	e.v7 = isVersion7(e.targetVersion(h))
+	e.v55 = versionFamily(e.targetVersion(h)) == "5.5"
+	e.declared = nil
+	if h != nil {
+		e.declared = h.Schema
+	}
+	e.checker = nil
+	if e.cardinality != CardinalityIgnore {
+		e.checker = &validator{}
+		e.checker.push(validateFrame{ctx: "root"})
+	}
+
+	e.cw = nil
+	if e.charset != "" && !e.v7 {
+		cw, ok := newCharsetWriter(e.out, e.charset)
+		if !ok {
+			e.err = fmt.Errorf("unsupported output character set %q", e.charset)
+			return
+		}
+		e.cw = cw
+	}
+	if e.cw != nil {
+		e.w.Reset(e.cw)
+	} else {
+		e.w.Reset(e.out)
+	}
+}
+
+// record writes a top-level record, calling any record hooks
+func (e *Encoder) record(r Record) {
+	if !e.beforeRecord(r) {
+		return
+	}
+	e.recordXref = e.xref(r, xrefOf(r))
+	switch r := r.(type) {
+	case *Header:
+		e.header(r)
+	case *IndividualRecord:
+		e.individual(r)
+	case *FamilyRecord:
+		e.family(r)
+	case *MediaRecord:
+		e.media(0, r)
+	case *RepositoryRecord:
+		e.repository(r)
+	case *SourceRecord:
+		e.source(r)
+	case *SubmitterRecord:
+		e.submitter(0, r)
+	case *SubmissionRecord:
+		e.submission(r)
+	case *NoteRecord:
+		e.note(0, r)
+	}
+	e.afterRecord(r)
+	e.collect()
+}
+
+// fail stops writing the current record because of a problem with the data of the
+// structure with tag at level, which is collected by the caller of the record writer
+func (e *Encoder) fail(level int, tag string, err error) {
+	path := slices.Clone(e.path[:min(level, len(e.path))])
+	if tag != "" {
+		path = append(path, tag)
+	}
+	e.err = &EncodeError{Xref: e.recordXref, Path: strings.Join(path, "."), Err: err}
+}
+
+// collect records any problem that stopped the record just written so that encoding can
+// continue with the next record. Errors writing to the output are not collected.
+func (e *Encoder) collect() {
+	var ee *EncodeError
+	if errors.As(e.err, &ee) {
+		e.errs = append(e.errs, e.err)
+		e.err = nil
+	}
+}
+
+// beforeRecord reports whether the record should be written
+func (e *Encoder) beforeRecord(r Record) bool {
+	if e.err != nil {
+		return false
+	}
+	if reflect.ValueOf(r).IsNil() {
+		return false
+	}
+	if e.hooks.Before == nil {
+		return true
+	}
+	return e.hooks.Before(r)
+}
+
+// afterRecord writes any tags added to the record by the hooks
+func (e *Encoder) afterRecord(r Record) {
+	if e.err != nil {
+		return
+	}
+	if e.hooks.After == nil {
+		return
+	}
+	e.userDefinedList(1, e.hooks.After(r))
+}
+
+// check records the line just written for cardinality checking
+func (e *Encoder) check(level int, tag string, xref string) {
+	e.lines++
+	e.path = append(e.path[:min(level, len(e.path))], tag)
+	if e.checker == nil {
+		return
+	}
+	n := len(e.checker.findings)
+	e.checker.line(Line{Level: level, Tag: tag, Xref: xref, LineNumber: e.lines})
+	for _, f := range e.checker.findings[n:] {
+		if f.Rule != RuleTooMany {
+			continue
+		}
+		if e.cardinality == CardinalityError {
+			e.fail(level, tag, errors.New(f.String()))
+			return
+		}
+		e.findings = append(e.findings, f)
+	}
+}
+
+// flush writes any buffered data and returns the problems found while encoding
 func (e *Encoder) flush() error {
+	if e.err == nil {
+		e.err = e.w.Flush()
+	}
+	if e.err == nil && e.cw != nil {
+		e.err = e.cw.Flush()
+	}
+	return e.result()
+}
+
+// result returns the problems found while encoding, joined with any error writing the
+// output
+func (e *Encoder) result() error {
 	if e.err != nil {
-		return e.err
+		return errors.Join(append(e.errs, e.err)...)
 	}
-	return e.w.Flush()
+	return errors.Join(e.errs...)
 }
 
 func (e *Encoder) tagWithID(level int, tag string, id string) {
+	e.tagWithIDValue(level, tag, id, "")
+}
+
+// tagWithIDValue writes a tag with an id and an optional value
+func (e *Encoder) tagWithIDValue(level int, tag string, id string, value string) {
+	e.writeTagWithID(level, tag, id, escapeAt(value, e.v7))
+}
+
+// writeTagWithID writes a tag with an id and an optional value whose @ characters have
+// already been escaped
+func (e *Encoder) writeTagWithID(level int, tag string, id string, value string) {
 	if e.err != nil {
 		return
 	}
 	if id == "" {
-		e.err = fmt.Errorf("tag %s missing id", tag)
+		e.fail(level, tag, fmt.Errorf("tag %s missing id", tag))
 		return
 	}
-	if _, err := e.w.WriteString(fmt.Sprintf("%d @%s@ %s", level, id, tag)); err != nil {
+	if e.v7 {
+		tag, _ = encode7(tag, "")
+	}
+	line := fmt.Sprintf("%d @%s@ %s", level, id, tag)
+	if value != "" {
+		line += " " + value
+	}
+	if _, err := e.w.WriteString(line); err != nil {
 		e.err = fmt.Errorf("write tag with id %s @%s@: %w", tag, id, err)
 		return
 	}
 
-	if _, err := e.w.WriteString("\n"); err != nil {
+	if _, err := e.w.WriteString(e.eol); err != nil {
 		e.err = fmt.Errorf("write tag %s: %w", tag, err)
 		return
 	}
+	e.check(level, tag, id)
 }
 
 func (e *Encoder) tag(level int, tag string, value string) {
 	if e.err != nil {
 		return
 	}
+	e.writeTag(level, tag, escapeAt(e.value(tag, value), e.v7))
+}
+
+// value returns the value written for tag, with enumerated values converted for GEDCOM 7
+// and dates rewritten in canonical form if configured
+func (e *Encoder) value(tag string, value string) string {
+	if e.v7 {
+		_, value = encode7(tag, value)
+	}
+	if e.dates && tag == "DATE" {
+		if dv, ok := ParseDate(value, e.dateOpts...); ok && !(e.v7 && dv.Phrase != "") {
+			value = dv.format(e.v7)
+		}
+	}
+	return value
+}
+
+// writeTag writes a tag with a value whose @ characters have already been escaped
+func (e *Encoder) writeTag(level int, tag string, value string) {
+	if e.err != nil {
+		return
+	}
+	if e.v7 {
+		tag, _ = encode7(tag, "")
+	} else if e.v55 {
+		tag = encode55(tag)
+	}
 
 	if _, err := e.w.WriteString(fmt.Sprintf("%d %s", level, tag)); err != nil {
 		e.err = fmt.Errorf("write tag %s: %w", tag, err)
@@ -99,10 +612,11 @@ func (e *Encoder) tag(level int, tag string, value string) {
 			return
 		}
 	}
-	if _, err := e.w.WriteString("\n"); err != nil {
+	if _, err := e.w.WriteString(e.eol); err != nil {
 		e.err = fmt.Errorf("write tag %s: %w", tag, err)
 		return
 	}
+	e.check(level, tag, "")
 }
 
 // maybeTag writes a tag with a level if the value is not empty
@@ -121,10 +635,14 @@ func (e *Encoder) tagWithPointer(level int, tag string, xref string) {
 	if e.err != nil {
 		return
 	}
-	if _, err := e.w.WriteString(fmt.Sprintf("%d %s @%s@\n", level, tag, xref)); err != nil {
+	if e.v7 {
+		tag, _ = encode7(tag, "")
+	}
+	if _, err := e.w.WriteString(fmt.Sprintf("%d %s @%s@%s", level, tag, xref, e.eol)); err != nil {
 		e.err = fmt.Errorf("write tag with pointer %s @%s@: %w", tag, xref, err)
 		return
 	}
+	e.check(level, tag, "")
 }
 
 // tagWithOptionalPointer writes a tag with a pointer reference if it is non empty
@@ -145,34 +663,133 @@ func (e *Encoder) tagWithText(level int, tag string, value string) {
 		return
 	}
 
+	e.text(level, "", tag, value)
+}
+
+// tagWithIDAndText writes a tag with an id and text, handling continuations
+func (e *Encoder) tagWithIDAndText(level int, tag string, id string, value string) {
+	if e.err != nil {
+		return
+	}
+	if id == "" {
+		e.fail(level, tag, fmt.Errorf("tag %s missing id", tag))
+		return
+	}
+	e.text(level, id, tag, value)
+}
+
+// text writes a tag, with an id if it is not empty, and text split into continuations
+func (e *Encoder) text(level int, id string, tag string, value string) {
+	value = strings.ReplaceAll(value, "\r\n", "\n")
 	conts := strings.Split(value, "\n")
-	e.textOneLine(level, tag, conts[0])
+	e.textOneLine(level, level+1, id, tag, conts[0])
 
 	for i := 1; i < len(conts); i++ {
-		e.textOneLine(level+1, "CONT", conts[i])
+		e.textOneLine(level+1, level+1, "", "CONT", conts[i])
 	}
 }
 
-func (e *Encoder) textOneLine(level int, tag string, value string) {
+// textOneLine writes a tag with a single line of text, continued using CONC tags at
+// concLevel if it is too long. The text is escaped before it is measured so that doubled
+// @ characters count towards the length of the line and are never split.
+func (e *Encoder) textOneLine(level int, concLevel int, id string, tag string, value string) {
 	if e.err != nil {
 		return
 	}
+	if id == "" {
+		value = e.value(tag, value)
+	}
+	value = escapeAt(value, e.v7)
 
-	if len(value) <= 246 {
-		e.tag(level, tag, value)
+	first := func(v string) {
+		if id != "" {
+			e.writeTagWithID(level, tag, id, v)
+		} else {
+			e.writeTag(level, tag, v)
+		}
+	}
+
+	limit := e.valueLimit(level, id, tag)
+	if len(value) <= limit || e.v7 {
+		// GEDCOM 7 has no limit on the length of a line
+		first(value)
 		return
 	}
-	e.tag(level, tag, value[:246])
+	switch e.continuation {
+	case ContinueOnlyError:
+		e.fail(level, tag, fmt.Errorf("line of %d bytes is too long to write without CONC", len(value)))
+		return
+	case ContinueOnlyTruncate:
+		first(value[:keepEscape(value, runeBoundary(value, limit))])
+		return
+	}
+	n := keepEscape(value, e.splitConc(value, limit))
+	first(value[:n])
 
-	for len(value) > 246 {
-		value = value[246:]
-		if len(value) <= 246 {
-			e.tag(level+1, "CONC", value)
-			return
+	limit = e.valueLimit(concLevel, "", "CONC")
+	for value = value[n:]; value != ""; value = value[n:] {
+		n = len(value)
+		if n > limit {
+			n = keepEscape(value, e.splitConc(value, limit))
+		}
+		e.writeTag(concLevel, "CONC", value[:n])
+	}
+}
+
+// valueLimit returns the maximum length in bytes of the value of a line with the given
+// level, id and tag
+func (e *Encoder) valueLimit(level int, id string, tag string) int {
+	n := len(strconv.Itoa(level)) + len(tag) + len(e.eol) + 2
+	if id != "" {
+		n += len(id) + 3
+	}
+	return max(e.maxLine-n, 1)
+}
+
+// splitConc returns the length of the prefix of value, at most limit bytes, to write before
+// continuing with CONC. The split is always made between characters and otherwise where
+// the encoder's ConcSplitMode prefers.
+func (e *Encoder) splitConc(value string, limit int) int {
+	n := runeBoundary(value, limit)
+	if e.concSplit == ConcSplitWords {
+		if i := strings.LastIndexByte(value[:n], ' '); i > 0 {
+			return i + 1
+		}
+		return n
+	}
+	for i := n; i > limit/2; i-- {
+		if utf8.RuneStart(value[i]) && value[i] != ' ' && value[i-1] != ' ' {
+			return i
 		}
+	}
+	return n
+}
+
+// runeBoundary returns the largest n no greater than limit, which must be less than
+// the length of s, such that s[:n] does not end part way through a character. If there
+// is none, as in invalid UTF-8, it returns limit.
+func runeBoundary(s string, limit int) int {
+	n := limit
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	if n == 0 {
+		return limit
+	}
+	return n
+}
 
-		e.tag(level+1, "CONC", value[:246])
+// keepEscape returns n, the length of a prefix of value, reduced if necessary so that
+// the prefix does not end with the first character of an escaped @ character
+func keepEscape(value string, n int) int {
+	ats := 0
+	for i := n - 1; i >= 0 && value[i] == '@'; i-- {
+		ats++
+	}
+	if ats%2 == 1 && n < len(value) && value[n] == '@' && n > 1 {
+		return n - 1
 	}
+	return n
 }
 
 // maybeTagWithText writes a tag with text only if the text is not empty
@@ -190,10 +807,22 @@ func (e *Encoder) header(h *Header) {
 	if e.err != nil {
 		return
 	}
+	if e.headerDefault {
+		h = e.headerDefaults(h)
+	}
 	if h == nil {
 		return
 	}
+	if e.charset != "" && !e.v7 {
+		hc := *h
+		hc.CharacterSet, hc.CharacterSetVersion = e.charset, ""
+		h = &hc
+	}
 	e.tag(0, "HEAD", "")
+	if e.v7 {
+		e.header7(h)
+		return
+	}
 	e.maybeTag(1, "CHAR", h.CharacterSet)
 	e.maybeTag(2, "VERS", h.CharacterSetVersion)
 	e.sourceSystem(0, h.SourceSystem)
@@ -202,24 +831,26 @@ func (e *Encoder) header(h *Header) {
 	e.maybeTag(2, "TIME", h.Time)
 
 	if h.Submitter != nil {
-		e.tagWithPointer(1, "SUBM", h.Submitter.Xref)
+		e.tagWithPointer(1, "SUBM", e.xref(h.Submitter, h.Submitter.Xref))
 	}
 
 	if h.Submission != nil {
-		e.tagWithPointer(1, "SUBN", h.Submission.Xref)
+		e.tagWithPointer(1, "SUBN", e.xref(h.Submission, h.Submission.Xref))
 	}
 	e.maybeTag(1, "FILE", h.Filename)
 	e.maybeTag(1, "COPR", h.Copyright)
 
-	if h.Version != "" || h.Form != "" {
+	version := e.targetVersion(h)
+	if version != "" || h.Form != "" {
 		e.tag(1, "GEDC", "")
-		if h.Version != "" {
-			e.tag(2, "VERS", h.Version)
+		if version != "" {
+			e.tag(2, "VERS", version)
 		}
 		if h.Form != "" {
 			e.tag(2, "FORM", h.Form)
 		}
 	}
+	e.schema()
 	e.maybeTag(1, "LANG", h.Language)
 	e.maybeTagWithText(1, "NOTE", h.Note)
 	e.userDefinedList(1, h.UserDefined)
@@ -255,6 +886,12 @@ func (e *Encoder) userDefined(level int, r UserDefinedTag) {
 	if e.err != nil {
 		return
 	}
+	tag := r.Tag
+	r, err := e.extensions.encode(r)
+	if err != nil {
+		e.fail(level, tag, err)
+		return
+	}
 	if r.Xref != "" {
 		e.tagWithPointer(level, r.Tag, r.Xref)
 	} else {
@@ -302,7 +939,7 @@ func (e *Encoder) addressDetail(level int, r *AddressDetail) {
 	if e.err != nil {
 		return
 	}
-	if r == nil {
+	if r == nil || e.omit(r) {
 		return
 	}
 	e.tagWithText(level, "ADDR", r.Full)
@@ -322,7 +959,7 @@ func (e *Encoder) place(level int, r *PlaceRecord) {
 	if r == nil {
 		return
 	}
-	if r.Name == "" && len(r.Phonetic) == 0 && len(r.Romanized) == 0 && r.Latitude == "" && r.Longitude == "" && len(r.Note) == 0 && len(r.Citation) == 0 {
+	if r.Name == "" && len(r.Phonetic) == 0 && len(r.Romanized) == 0 && r.Latitude == "" && r.Longitude == "" && len(r.Note) == 0 && len(r.Citation) == 0 && len(r.UserDefined) == 0 {
 		return
 	}
 
@@ -330,11 +967,13 @@ func (e *Encoder) place(level int, r *PlaceRecord) {
 	for _, sr := range r.Phonetic {
 		e.tag(level+1, "FONE", sr.Name)
 		e.maybeTag(level+1, "TYPE", sr.Type)
+		e.userDefinedList(level+2, sr.UserDefined)
 	}
 
 	for _, sr := range r.Romanized {
 		e.tag(level+1, "ROMN", sr.Name)
 		e.maybeTag(level+2, "TYPE", sr.Type)
+		e.userDefinedList(level+2, sr.UserDefined)
 	}
 
 	if r.Latitude != "" || r.Longitude != "" {
@@ -346,6 +985,7 @@ func (e *Encoder) place(level int, r *PlaceRecord) {
 
 	e.noteList(level+1, r.Note)
 	e.citationList(level+1, r.Citation)
+	e.userDefinedList(level+1, r.UserDefined)
 }
 
 func (e *Encoder) individual(r *IndividualRecord) {
@@ -357,33 +997,28 @@ func (e *Encoder) individual(r *IndividualRecord) {
 	}
 
 	level := 0
-	e.tagWithID(level, "INDI", r.Xref)
+	e.tagWithID(level, "INDI", e.xref(r, r.Xref))
+	e.maybeTag(level+1, "RESN", r.RestrictionNotice)
 	for _, v := range r.Name {
 		e.name(level+1, v)
 	}
 	e.maybeTagWithText(level+1, "SEX", r.Sex)
 
-	e.eventList(level+1, r.Event)
-	e.eventList(level+1, r.Attribute)
+	e.eventList(level+1, e.canonicalEvents(r.Event))
+	e.eventList(level+1, e.canonicalEvents(r.Attribute))
+	e.ordinanceList(level+1, r.Ordinance)
 
-	for _, sr := range r.Parents {
+	for _, sr := range e.canonicalFamilyLinks(r.Parents) {
 		e.familyLink(level+1, "FAMC", sr)
 	}
-	for _, sr := range r.Family {
+	for _, sr := range e.canonicalFamilyLinks(r.Family) {
 		e.familyLink(level+1, "FAMS", sr)
 	}
 
-	if len(r.Submitter) > 0 {
-		// Submitter                 []*SubmitterRecord
-		e.err = fmt.Errorf("not implemented: Submitter")
-		return
-	}
-	if len(r.Association) > 0 {
-		// TODO: Association
-		// Association               []*AssociationRecord
-		e.err = fmt.Errorf("not implemented: Association")
-		return
+	for _, sr := range r.Submitter {
+		e.submitterRef(level+1, sr)
 	}
+	e.associationList(level+1, r.Association)
 
 	e.maybeTagWithText(level+1, "RFN", r.PermanentRecordFileNumber)
 	e.maybeTagWithText(level+1, "AFN", r.AncestralFileNumber)
@@ -391,9 +1026,68 @@ func (e *Encoder) individual(r *IndividualRecord) {
 	e.userReferenceList(level+1, r.UserReference)
 	e.maybeTagWithText(level+1, "RIN", r.AutomatedRecordId)
 	e.change(level+1, &r.Change)
+	e.changeWithTag(level+1, "CREA", &r.Creation)
+	e.externalIDList(level+1, r.ExternalID)
 	e.noteList(level+1, r.Note)
 	e.citationList(level+1, r.Citation)
 	e.mediaRefList(level+1, r.Media)
+	e.dnaList(level+1, r.DNA)
+	e.userDefinedList(level+1, r.UserDefined)
+}
+
+func (e *Encoder) associationList(level int, rs []*AssociationRecord) {
+	if e.err != nil {
+		return
+	}
+	for _, r := range rs {
+		e.association(level, r)
+	}
+}
+
+func (e *Encoder) association(level int, r *AssociationRecord) {
+	if e.err != nil {
+		return
+	}
+	if r == nil {
+		return
+	}
+	xref := r.Xref
+	if r.Individual != nil {
+		if x := e.xref(r.Individual, r.Individual.Xref); x != "" {
+			xref = x
+		}
+	}
+	if xref == "" {
+		e.fail(level, "ASSO", fmt.Errorf("association missing xref"))
+		return
+	}
+	e.tagWithPointer(level, "ASSO", xref)
+	e.maybeTag(level+1, "RELA", r.Relation)
+	e.citationList(level+1, r.Citation)
+	e.noteList(level+1, r.Note)
+	e.userDefinedList(level+1, r.UserDefined)
+}
+
+func (e *Encoder) dnaList(level int, rs []*DNARecord) {
+	if e.err != nil {
+		return
+	}
+	for _, r := range rs {
+		e.dna(level, r)
+	}
+}
+
+func (e *Encoder) dna(level int, r *DNARecord) {
+	if e.err != nil {
+		return
+	}
+	if r == nil {
+		return
+	}
+	e.tagWithText(level, r.Tag, r.Value)
+	e.maybeTagWithText(level+1, "TYPE", r.Type)
+	e.maybeTag(level+1, "DATE", r.Date)
+	e.noteList(level+1, r.Note)
 	e.userDefinedList(level+1, r.UserDefined)
 }
 
@@ -406,23 +1100,59 @@ func (e *Encoder) family(r *FamilyRecord) {
 	}
 
 	level := 0
-	e.tagWithID(level, "FAM", r.Xref)
+	e.tagWithID(level, "FAM", e.xref(r, r.Xref))
+	e.maybeTag(level+1, "RESN", r.RestrictionNotice)
 	e.individualRef(level+1, "HUSB", r.Husband)
 	e.individualRef(level+1, "WIFE", r.Wife)
-	for _, sr := range r.Child {
+	for _, sr := range e.canonicalChildren(r.Child) {
 		e.individualRef(level+1, "CHIL", sr)
 	}
-	e.eventList(level+1, r.Event)
+	e.eventList(level+1, e.canonicalEvents(r.Event))
 	e.maybeTag(level+1, "NCHI", r.NumberOfChildren)
+	e.ordinanceList(level+1, r.Ordinance)
 	e.userReferenceList(level+1, r.UserReference)
 	e.maybeTagWithText(level+1, "RIN", r.AutomatedRecordId)
 	e.change(level+1, &r.Change)
+	e.changeWithTag(level+1, "CREA", &r.Creation)
+	e.externalIDList(level+1, r.ExternalID)
 	e.noteList(level+1, r.Note)
 	e.citationList(level+1, r.Citation)
 	e.mediaRefList(level+1, r.Media)
 	e.userDefinedList(level+1, r.UserDefined)
 }
 
+func (e *Encoder) ordinanceList(level int, rs []*LdsOrdinanceRecord) {
+	if e.err != nil {
+		return
+	}
+	for _, r := range rs {
+		e.ordinance(level, r)
+	}
+}
+
+func (e *Encoder) ordinance(level int, r *LdsOrdinanceRecord) {
+	if e.err != nil {
+		return
+	}
+	if r == nil {
+		return
+	}
+	e.tag(level, r.Tag, "")
+	e.maybeTag(level+1, "DATE", r.Date)
+	e.maybeTag(level+1, "TEMP", r.Temple)
+	e.maybeTag(level+1, "PLAC", r.Place)
+	if r.Status != "" {
+		e.tag(level+1, "STAT", r.Status)
+		e.maybeTag(level+2, "DATE", r.StatusDate)
+	}
+	if r.Family != nil {
+		e.familyRef(level+1, "FAMC", r.Family)
+	}
+	e.citationList(level+1, r.Citation)
+	e.noteList(level+1, r.Note)
+	e.userDefinedList(level+1, r.UserDefined)
+}
+
 func (e *Encoder) mediaList(level int, rs []*MediaRecord) {
 	if e.err != nil {
 		return
@@ -440,9 +1170,9 @@ func (e *Encoder) media(level int, r *MediaRecord) {
 		return
 	}
 	if level == 0 {
-		e.tagWithID(level, "OBJE", r.Xref)
+		e.tagWithID(level, "OBJE", e.xref(r, r.Xref))
 	} else {
-		e.tagWithOptionalPointer(level, "OBJE", r.Xref)
+		e.tagWithOptionalPointer(level, "OBJE", e.xref(r, r.Xref))
 	}
 
 	for _, sr := range r.File {
@@ -452,6 +1182,8 @@ func (e *Encoder) media(level int, r *MediaRecord) {
 	e.maybeTagWithText(level+1, "RIN", r.AutomatedRecordId)
 
 	e.change(level+1, &r.Change)
+	e.changeWithTag(level+1, "CREA", &r.Creation)
+	e.externalIDList(level+1, r.ExternalID)
 	e.noteList(level+1, r.Note)
 	e.citationList(level+1, r.Citation)
 	e.userDefinedList(level+1, r.UserDefined)
@@ -466,13 +1198,15 @@ func (e *Encoder) repository(r *RepositoryRecord) {
 	}
 
 	level := 0
-	e.tagWithID(level, "REPO", r.Xref)
+	e.tagWithID(level, "REPO", e.xref(r, r.Xref))
 	e.maybeTag(level+1, "NAME", r.Name)
 	e.address(level+1, &r.Address)
 	e.noteList(level+1, r.Note)
 	e.userReferenceList(level+1, r.UserReference)
 	e.maybeTagWithText(level+1, "RIN", r.AutomatedRecordId)
 	e.change(level+1, &r.Change)
+	e.changeWithTag(level+1, "CREA", &r.Creation)
+	e.externalIDList(level+1, r.ExternalID)
 	e.userDefinedList(level+1, r.UserDefined)
 }
 
@@ -485,16 +1219,19 @@ func (e *Encoder) source(r *SourceRecord) {
 	}
 
 	level := 0
-	e.tagWithID(level, "SOUR", r.Xref)
+	e.tagWithID(level, "SOUR", e.xref(r, r.Xref))
 	e.maybeTagWithText(level+1, "TITL", r.Title)
-	if r.Data != nil {
+	if r.Data != nil && !e.omit(r.Data) {
 		e.tag(level+1, "DATA", "")
 		for _, sr := range r.Data.Event {
 			e.tag(level+2, "EVEN", sr.Kind)
 			e.maybeTag(level+3, "DATE", sr.Date)
 			e.maybeTag(level+3, "PLAC", sr.Place)
+			e.userDefinedList(level+3, sr.UserDefined)
 		}
-
+		e.maybeTag(level+2, "AGNC", r.Data.Agency)
+		e.noteList(level+2, r.Data.Note)
+		e.userDefinedList(level+2, r.Data.UserDefined)
 	}
 
 	e.maybeTagWithText(level+1, "AUTH", r.Originator)
@@ -502,18 +1239,22 @@ func (e *Encoder) source(r *SourceRecord) {
 	e.maybeTagWithText(level+1, "PUBL", r.PublicationFacts)
 	e.maybeTagWithText(level+1, "TEXT", r.Text)
 
-	if r.Repository != nil && r.Repository.Repository != nil && r.Repository.Repository.Xref != "" {
-		e.tagWithPointer(level+1, "REPO", r.Repository.Repository.Xref)
+	if r.Repository != nil && r.Repository.Repository != nil && e.xref(r.Repository.Repository, r.Repository.Repository.Xref) != "" {
+		e.tagWithPointer(level+1, "REPO", e.xref(r.Repository.Repository, r.Repository.Repository.Xref))
 		e.noteList(level+2, r.Repository.Note)
 		for _, sr := range r.Repository.CallNumber {
 			e.tag(level+2, "CALN", sr.CallNumber)
 			e.maybeTag(level+3, "MEDI", sr.MediaType)
+			e.userDefinedList(level+3, sr.UserDefined)
 		}
+		e.userDefinedList(level+2, r.Repository.UserDefined)
 	}
 
 	e.userReferenceList(level+1, r.UserReference)
 	e.maybeTagWithText(level+1, "RIN", r.AutomatedRecordId)
 	e.change(level+1, &r.Change)
+	e.changeWithTag(level+1, "CREA", &r.Creation)
+	e.externalIDList(level+1, r.ExternalID)
 	e.noteList(level+1, r.Note)
 	e.mediaRefList(level+1, r.Media)
 	e.userDefinedList(level+1, r.UserDefined)
@@ -526,7 +1267,7 @@ func (e *Encoder) submitter(level int, r *SubmitterRecord) {
 	if r == nil {
 		return
 	}
-	e.tagWithID(level, "SUBM", r.Xref)
+	e.tagWithID(level, "SUBM", e.xref(r, r.Xref))
 	e.maybeTagWithText(level+1, "NAME", r.Name)
 	e.address(level+1, r.Address)
 	e.mediaRefList(level+1, r.Media)
@@ -538,6 +1279,33 @@ func (e *Encoder) submitter(level int, r *SubmitterRecord) {
 	e.maybeTagWithText(level+1, "RIN", r.AutomatedRecordId)
 	e.noteList(level+1, r.Note)
 	e.change(level+1, r.Change)
+	e.changeWithTag(level+1, "CREA", r.Creation)
+	e.externalIDList(level+1, r.ExternalID)
+	e.userDefinedList(level+1, r.UserDefined)
+}
+
+func (e *Encoder) submission(r *SubmissionRecord) {
+	if e.err != nil {
+		return
+	}
+	if r == nil {
+		return
+	}
+
+	level := 0
+	e.tagWithID(level, "SUBN", e.xref(r, r.Xref))
+	if r.Submitter != nil && e.xref(r.Submitter, r.Submitter.Xref) != "" {
+		e.tagWithPointer(level+1, "SUBM", e.xref(r.Submitter, r.Submitter.Xref))
+	}
+	e.maybeTag(level+1, "FAMF", r.FamilyFile)
+	e.maybeTag(level+1, "TEMP", r.Temple)
+	e.maybeTag(level+1, "ANCE", r.Ancestors)
+	e.maybeTag(level+1, "DESC", r.Descendants)
+	e.maybeTag(level+1, "ORDI", r.Ordinance)
+	e.maybeTagWithText(level+1, "RIN", r.AutomatedRecordId)
+	e.noteList(level+1, r.Note)
+	e.change(level+1, &r.Change)
+	e.userDefinedList(level+1, r.UserDefined)
 }
 
 func (e *Encoder) trailer(r *Trailer) {
@@ -554,7 +1322,12 @@ func (e *Encoder) name(level int, r *NameRecord) {
 	if r == nil {
 		return
 	}
-	e.maybeTagWithText(level, "NAME", r.Name)
+	name := r.Name
+	if name == "" {
+		// A name given only by its pieces still needs a value
+		name = FormatPersonalName(r.Parsed())
+	}
+	e.maybeTagWithText(level, "NAME", name)
 	e.maybeTagWithText(level+1, "TYPE", r.Type)
 	e.maybeTagWithText(level+1, "NPFX", r.NamePiecePrefix)
 	e.maybeTagWithText(level+1, "GIVN", r.NamePieceGiven)
@@ -566,14 +1339,14 @@ func (e *Encoder) name(level int, r *NameRecord) {
 	if len(r.Phonetic) > 0 {
 		// TODO: FONE
 		// Phonetic               []*VariantNameRecord
-		e.err = fmt.Errorf("not implemented: FONE")
+		e.fail(level+1, "FONE", fmt.Errorf("not implemented: FONE"))
 		return
 	}
 
 	if len(r.Romanized) > 0 {
 		// TODO: ROMN
 		// Romanized              []*VariantNameRecord
-		e.err = fmt.Errorf("not implemented: ROMN")
+		e.fail(level+1, "ROMN", fmt.Errorf("not implemented: ROMN"))
 		return
 	}
 
@@ -583,17 +1356,23 @@ func (e *Encoder) name(level int, r *NameRecord) {
 }
 
 func (e *Encoder) change(level int, r *ChangeRecord) {
+	e.changeWithTag(level, "CHAN", r)
+}
+
+// changeWithTag writes a change date structure, such as CHAN or CREA, with the given tag
+func (e *Encoder) changeWithTag(level int, tag string, r *ChangeRecord) {
 	if e.err != nil {
 		return
 	}
-	if r == nil || (r.Date == "" && r.Time == "" && len(r.Note) == 0) {
+	if r == nil || (r.Date == "" && r.Time == "" && len(r.Note) == 0 && len(r.UserDefined) == 0) {
 		return
 	}
-	e.tagWithText(level, "CHAN", "")
+	e.tagWithText(level, tag, "")
 	e.maybeTagWithText(level+1, "DATE", r.Date)
 	e.maybeTagWithText(level+2, "TIME", r.Time)
 
 	e.noteList(level+1, r.Note)
+	e.userDefinedList(level+1, r.UserDefined)
 }
 
 func (e *Encoder) noteList(level int, rs []*NoteRecord) {
@@ -612,8 +1391,22 @@ func (e *Encoder) note(level int, r *NoteRecord) {
 	if r == nil {
 		return
 	}
-	e.tagWithText(level, "NOTE", r.Note)
+	shared := "NOTE"
+	if e.v7 {
+		shared = "SNOTE"
+	}
+	xref := e.xref(r, r.Xref)
+	switch {
+	case level == 0:
+		e.tagWithIDAndText(level, shared, xref, r.Note)
+	case xref != "":
+		e.tagWithPointer(level, shared, xref)
+		return
+	default:
+		e.tagWithText(level, "NOTE", r.Note)
+	}
 	e.citationList(level+1, r.Citation)
+	e.userDefinedList(level+1, r.UserDefined)
 }
 
 func (e *Encoder) citationList(level int, rs []*CitationRecord) {
@@ -633,15 +1426,24 @@ func (e *Encoder) citation(level int, r *CitationRecord) {
 		return
 	}
 	if r.Source == nil {
-		e.err = fmt.Errorf("source missing")
+		e.fail(level, "SOUR", fmt.Errorf("source missing"))
 		return
 	}
-	if r.Source.Xref == "" {
-		e.tag(level, "SOUR", "")
+	if xref := e.xref(r.Source, r.Source.Xref); xref == "" {
+		if e.v7 {
+			// GEDCOM 7 citations must point to a source record
+			e.tag(level, "SOUR", voidXref)
+		} else {
+			e.tag(level, "SOUR", "")
+		}
 	} else {
-		e.tagWithPointer(level, "SOUR", r.Source.Xref)
+		e.tagWithPointer(level, "SOUR", xref)
 	}
 	e.maybeTagWithText(level+1, "PAGE", r.Page)
+	if r.Event != "" || r.Role != "" {
+		e.tag(level+1, "EVEN", r.Event)
+		e.maybeTag(level+2, "ROLE", r.Role)
+	}
 	e.maybeTagWithText(level+1, "QUAY", r.Quay)
 
 	if r.Data.Date != "" || len(r.Data.Text) != 0 || len(r.Data.UserDefined) != 0 {
@@ -657,7 +1459,7 @@ func (e *Encoder) data(level int, r *DataRecord) {
 	if e.err != nil {
 		return
 	}
-	if r == nil {
+	if r == nil || e.omit(r) {
 		return
 	}
 
@@ -677,16 +1479,18 @@ func (e *Encoder) familyLink(level int, tag string, r *FamilyLinkRecord) {
 		return
 	}
 	if r.Family == nil {
-		e.err = fmt.Errorf("family missing")
+		e.fail(level, tag, fmt.Errorf("family missing"))
 		return
 	}
-	if r.Family.Xref == "" {
-		e.err = fmt.Errorf("family missing xref")
+	xref := e.xref(r.Family, r.Family.Xref)
+	if xref == "" {
+		e.fail(level, tag, fmt.Errorf("family missing xref"))
 		return
 	}
-	e.tagWithPointer(level, tag, r.Family.Xref)
+	e.tagWithPointer(level, tag, xref)
 	e.maybeTagWithText(level+1, "PEDI", r.Type)
 	e.noteList(level+1, r.Note)
+	e.userDefinedList(level+1, r.UserDefined)
 }
 
 func (e *Encoder) file(level int, r *FileRecord) {
@@ -697,8 +1501,13 @@ func (e *Encoder) file(level int, r *FileRecord) {
 		return
 	}
 	e.maybeTagWithText(level, "FILE", r.Name)
-	e.maybeTagWithText(level+1, "FORM", r.Format)
-	e.maybeTagWithText(level+2, "TYPE", r.FormatType)
+	if e.v7 {
+		e.maybeTag(level+1, "FORM", mediaType7(r.Format))
+		e.maybeTag(level+2, "MEDI", r.FormatType)
+	} else {
+		e.maybeTagWithText(level+1, "FORM", r.Format)
+		e.maybeTagWithText(level+2, "TYPE", r.FormatType)
+	}
 	e.maybeTagWithText(level+1, "TITL", r.Title)
 	e.userDefinedList(level+1, r.UserDefined)
 }
@@ -721,6 +1530,28 @@ func (e *Encoder) userReference(level int, r *UserReferenceRecord) {
 	}
 	e.maybeTagWithText(level, "REFN", r.Number)
 	e.maybeTagWithText(level+1, "TYPE", r.Type)
+	e.userDefinedList(level+1, r.UserDefined)
+}
+
+func (e *Encoder) externalIDList(level int, rs []*ExternalIDRecord) {
+	if e.err != nil {
+		return
+	}
+	for _, r := range rs {
+		e.externalID(level, r)
+	}
+}
+
+func (e *Encoder) externalID(level int, r *ExternalIDRecord) {
+	if e.err != nil {
+		return
+	}
+	if r == nil {
+		return
+	}
+	e.maybeTag(level, "EXID", r.ID)
+	e.maybeTag(level+1, "TYPE", r.Type)
+	e.userDefinedList(level+1, r.UserDefined)
 }
 
 func (e *Encoder) individualRef(level int, tag string, r *IndividualRecord) {
@@ -730,11 +1561,12 @@ func (e *Encoder) individualRef(level int, tag string, r *IndividualRecord) {
 	if r == nil {
 		return
 	}
-	if r.Xref == "" {
-		e.err = fmt.Errorf("individual missing xref for %s", tag)
+	xref := e.xref(r, r.Xref)
+	if xref == "" {
+		e.fail(level, tag, fmt.Errorf("individual missing xref for %s", tag))
 		return
 	}
-	e.tagWithPointer(level, tag, r.Xref)
+	e.tagWithPointer(level, tag, xref)
 }
 
 func (e *Encoder) familyRef(level int, tag string, r *FamilyRecord) {
@@ -744,11 +1576,27 @@ func (e *Encoder) familyRef(level int, tag string, r *FamilyRecord) {
 	if r == nil {
 		return
 	}
-	if r.Xref == "" {
-		e.err = fmt.Errorf("family missing xref")
+	xref := e.xref(r, r.Xref)
+	if xref == "" {
+		e.fail(level, tag, fmt.Errorf("family missing xref"))
 		return
 	}
-	e.tagWithPointer(level, tag, r.Xref)
+	e.tagWithPointer(level, tag, xref)
+}
+
+func (e *Encoder) submitterRef(level int, r *SubmitterRecord) {
+	if e.err != nil {
+		return
+	}
+	if r == nil {
+		return
+	}
+	xref := e.xref(r, r.Xref)
+	if xref == "" {
+		e.fail(level, "SUBM", fmt.Errorf("submitter missing xref"))
+		return
+	}
+	e.tagWithPointer(level, "SUBM", xref)
 }
 
 func (e *Encoder) mediaRefList(level int, rs []*MediaRecord) {
@@ -767,8 +1615,12 @@ func (e *Encoder) mediaRef(level int, r *MediaRecord) {
 	if r == nil {
 		return
 	}
-	if r.Xref != "" {
-		e.tagWithPointer(level, "OBJE", r.Xref)
+	if xref := e.xref(r, r.Xref); xref != "" {
+		e.tagWithPointer(level, "OBJE", xref)
+		return
+	}
+
+	if e.omit(r) {
 		return
 	}
 
@@ -778,6 +1630,8 @@ func (e *Encoder) mediaRef(level int, r *MediaRecord) {
 		e.file(level+1, sr)
 	}
 	e.maybeTagWithText(level+1, "TITL", r.Title)
+	e.noteList(level+1, r.Note)
+	e.userDefinedList(level+1, r.UserDefined)
 }
 
 func (e *Encoder) eventList(level int, rs []*EventRecord) {
@@ -794,7 +1648,7 @@ func (e *Encoder) event(level int, r *EventRecord) {
 		return
 	}
 	if r == nil {
-		e.err = fmt.Errorf("event not specified")
+		e.fail(level, "", fmt.Errorf("event not specified"))
 		return
 	}
 	e.tag(level, r.Tag, r.Value)
@@ -803,6 +1657,15 @@ func (e *Encoder) event(level int, r *EventRecord) {
 
 	e.address(level+1, &r.Address)
 	e.place(level+1, &r.Place)
+	e.maybeTag(level+1, "AGE", r.Age)
+	if r.HusbandAge != "" {
+		e.tag(level+1, "HUSB", "")
+		e.tag(level+2, "AGE", r.HusbandAge)
+	}
+	if r.WifeAge != "" {
+		e.tag(level+1, "WIFE", "")
+		e.tag(level+2, "AGE", r.WifeAge)
+	}
 	e.maybeTag(level+1, "AGNC", r.ResponsibleAgency)
 	e.maybeTag(level+1, "RELI", r.ReligiousAffiliation)
 	e.maybeTag(level+1, "CAUS", r.Cause)
@@ -2,6 +2,7 @@ package gedcom
 
 import (
 	"bytes"
+	"errors"
 	"os"
 	"strings"
 	"testing"
@@ -66,12 +67,12 @@ func TestEncodeHeader(t *testing.T) {
 					"(in this transmission this is demonstrated with tags: NAME, OCCU, PLACE and NOTE. Seek the word 'another'." + "\n" +
 					"The data transmitted here do not make sence. Just the HEAD.DATE tag contains the date of the creation" + "\n" +
 					"of this file and will change in future Versions!" + "\n" +
-					"This file is created by H. Eichmann: h.eichmann@@gmx.de. Feel free to copy and use it for any " + "\n" +
+					"This file is created by H. Eichmann: h.eichmann@gmx.de. Feel free to copy and use it for any " + "\n" +
 					"non-commercial purpose. For the creation the GEDCOM standard Release 5.5 (2 JAN 1996) has been used." + "\n" +
-					"Copyright: The church of Jesus Christ of latter-day saints, gedcom@@gedcom.org" + "\n" +
+					"Copyright: The church of Jesus Christ of latter-day saints, gedcom@gedcom.org" + "\n" +
 					"Download it (the GEDCOM 5.5 specs) from: ftp.gedcom.com/pub/genealogy/gedcom." + "\n" +
 					"Some Specials: This line is very very very very very very very very very very very very very very very very very very very very very very very very very very very very very very very very very long but not too long (255 caharcters is the limit). " + "\n" +
-					"This @@ (commercial at) character may only appear ONCE!" + "\n" +
+					"This @ (commercial at) character may only appear ONCE!" + "\n" +
 					"Note continued here. The word TEST should not be broken!",
 				UserDefined: []UserDefinedTag{
 					{Tag: "_MYOWNTAG", Value: "This is a non-standard tag. Not recommended but allowed", Level: 1},
@@ -175,17 +176,41 @@ func TestEncodeText(t *testing.T) {
 		},
 		{
 			name: "max length line",
-			text: strings.Repeat("0123456789", 24) + "012345", // 246 characters
+			text: strings.Repeat("0123456789", 24) + "0123456", // 255 bytes with "1 NOTE " and newline
 			want: []string{
-				"1 NOTE " + strings.Repeat("0123456789", 24) + "012345",
+				"1 NOTE " + strings.Repeat("0123456789", 24) + "0123456",
 			},
 		},
 		{
 			name: "long line",
 			text: strings.Repeat("0123456789", 24) + "0123456789",
 			want: []string{
-				"1 NOTE " + strings.Repeat("0123456789", 24) + "012345",
-				"2 CONC 6789",
+				"1 NOTE " + strings.Repeat("0123456789", 24) + "0123456",
+				"2 CONC 789",
+			},
+		},
+		{
+			name: "long line split between characters",
+			text: strings.Repeat("a", 246) + "éa",
+			want: []string{
+				"1 NOTE " + strings.Repeat("a", 246),
+				"2 CONC éa",
+			},
+		},
+		{
+			name: "long line not split at a space",
+			text: "xy" + strings.Repeat("word ", 50) + "end",
+			want: []string{
+				"1 NOTE " + ("xy" + strings.Repeat("word ", 50) + "end")[:245],
+				"2 CONC " + ("xy" + strings.Repeat("word ", 50) + "end")[245:],
+			},
+		},
+		{
+			name: "carriage returns",
+			text: "line 1\r\nline 2",
+			want: []string{
+				"1 NOTE line 1",
+				"2 CONT line 2",
 			},
 		},
 	}
@@ -210,6 +235,890 @@ func TestEncodeText(t *testing.T) {
 	}
 }
 
+func TestEncodeNote(t *testing.T) {
+	text := "First line of a long note " + strings.Repeat("with many words ", 30) + "\nSecond line\n\nLast line"
+	want := &Gedcom{
+		Header: &Header{},
+		Individual: []*IndividualRecord{{
+			Xref: "I1",
+			Note: []*NoteRecord{{
+				Note:     text,
+				Citation: []*CitationRecord{{Page: "p. 12"}},
+			}},
+		}},
+		Source: []*SourceRecord{{Xref: "S1"}},
+	}
+	want.Individual[0].Note[0].Citation[0].Source = want.Source[0]
+
+	buf := new(bytes.Buffer)
+	if err := NewEncoder(buf).Encode(want); err != nil {
+		t.Fatalf("unexpected encode error: %v", err)
+	}
+	got, err := NewDecoder(buf).Decode()
+	if err != nil {
+		t.Fatalf("unexpected decode error: %v", err)
+	}
+
+	n := got.Individual[0].Note[0]
+	if n.Note != text {
+		t.Errorf("got note %q, wanted %q", n.Note, text)
+	}
+	if len(n.Citation) != 1 || n.Citation[0].Page != "p. 12" || n.Citation[0].Source != got.Source[0] {
+		t.Errorf("note citation not preserved: %+v", n.Citation)
+	}
+}
+
+func TestEncodeTextContinuation(t *testing.T) {
+	long := strings.Repeat("0123456789", 24) + "0123456789"
+
+	testCases := []struct {
+		name    string
+		mode    ContinuationMode
+		text    string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "conc",
+			mode: ContinueWithConc,
+			text: long,
+			want: []string{
+				"1 NOTE " + long[:247],
+				"2 CONC 789",
+			},
+		},
+		{
+			name: "cont only multiline",
+			mode: ContinueOnlyError,
+			text: "line 1\nline 2",
+			want: []string{
+				"1 NOTE line 1",
+				"2 CONT line 2",
+			},
+		},
+		{
+			name:    "cont only error",
+			mode:    ContinueOnlyError,
+			text:    "line 1\n" + long,
+			wantErr: true,
+		},
+		{
+			name: "cont only truncate",
+			mode: ContinueOnlyTruncate,
+			text: "line 1\n" + long,
+			want: []string{
+				"1 NOTE line 1",
+				"2 CONT " + long[:247],
+			},
+		},
+		{
+			name: "long cont",
+			mode: ContinueWithConc,
+			text: "line 1\n" + long,
+			want: []string{
+				"1 NOTE line 1",
+				"2 CONT " + long[:247],
+				"2 CONC 789",
+			},
+		},
+		{
+			name: "truncate at rune boundary",
+			mode: ContinueOnlyTruncate,
+			text: strings.Repeat("a", 246) + "é",
+			want: []string{
+				"1 NOTE " + strings.Repeat("a", 246),
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			enc := NewEncoder(buf, WithContinuation(tc.mode))
+
+			enc.tagWithText(1, "NOTE", tc.text)
+			err := enc.flush()
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("got no error, wanted error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error during flush: %v", err)
+			}
+
+			lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+			if diff := cmp.Diff(tc.want, lines); diff != "" {
+				t.Errorf("text mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestEncodeEscapedLineLength(t *testing.T) {
+	text := strings.Repeat("ab@", 150)
+	escaped := strings.ReplaceAll(text, "@", "@@")
+
+	buf := new(bytes.Buffer)
+	enc := NewEncoder(buf)
+	enc.tagWithText(1, "NOTE", text)
+	if err := enc.flush(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var joined string
+	for i, line := range strings.SplitAfter(strings.TrimSuffix(buf.String(), "\n"), "\n") {
+		if len(line) > DefaultMaxLineLength {
+			t.Errorf("line %d is %d bytes long, wanted no more than %d", i+1, len(line), DefaultMaxLineLength)
+		}
+		line = strings.TrimSuffix(line, "\n")
+		prefix := "2 CONC "
+		if i == 0 {
+			prefix = "1 NOTE "
+		}
+		value, ok := strings.CutPrefix(line, prefix)
+		if !ok {
+			t.Fatalf("line %d is %q, wanted it to begin with %q", i+1, line, prefix)
+		}
+		if strings.Count(value, "@")%2 != 0 {
+			t.Errorf("line %d splits an escaped @: %q", i+1, value)
+		}
+		joined += value
+	}
+	if joined != escaped {
+		t.Errorf("got text %q, wanted %q", joined, escaped)
+	}
+}
+
+func TestEncodeLineLength(t *testing.T) {
+	text := "The quick brown fox jumps over the lazy dog"
+
+	testCases := []struct {
+		name string
+		opts []EncoderOption
+		want []string
+	}{
+		{
+			name: "mid word",
+			opts: []EncoderOption{WithMaxLineLength(25)},
+			want: []string{
+				"1 NOTE The quick brown f",
+				"2 CONC ox jumps over th",
+				"2 CONC e lazy dog",
+			},
+		},
+		{
+			name: "words",
+			opts: []EncoderOption{WithMaxLineLength(25), WithConcSplit(ConcSplitWords)},
+			want: []string{
+				"1 NOTE The quick brown ",
+				"2 CONC fox jumps over ",
+				"2 CONC the lazy dog",
+			},
+		},
+		{
+			name: "crlf",
+			opts: []EncoderOption{WithMaxLineLength(25), WithLineEnding(LineEndingCRLF)},
+			want: []string{
+				"1 NOTE The quick brow",
+				"2 CONC n fox jumps ove",
+				"2 CONC r the lazy dog",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			enc := NewEncoder(buf, tc.opts...)
+			enc.tagWithText(1, "NOTE", text)
+			if err := enc.flush(); err != nil {
+				t.Fatalf("unexpected error during flush: %v", err)
+			}
+
+			lines := strings.SplitAfter(buf.String(), enc.eol)
+			lines = lines[:len(lines)-1]
+			var got []string
+			for _, l := range lines {
+				if len(l) > 25 {
+					t.Errorf("line %q is %d bytes, wanted at most 25", l, len(l))
+				}
+				got = append(got, strings.TrimSuffix(l, enc.eol))
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("text mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestEncodeRecordHooks(t *testing.T) {
+	g := &Gedcom{
+		Individual: []*IndividualRecord{
+			{Xref: "I1", Sex: "M"},
+			{Xref: "I2", Sex: "F"},
+		},
+		Family: []*FamilyRecord{
+			{Xref: "F1"},
+		},
+	}
+
+	hooks := RecordHooks{
+		Before: func(r Record) bool {
+			indi, ok := r.(*IndividualRecord)
+			return !ok || indi.Xref != "I2"
+		},
+		After: func(r Record) []UserDefinedTag {
+			if _, ok := r.(*FamilyRecord); ok {
+				return []UserDefinedTag{{Tag: "_UID", Value: "abc", UserDefined: []UserDefinedTag{{Tag: "_SRC", Value: "app"}}}}
+			}
+			return nil
+		},
+	}
+
+	buf := new(bytes.Buffer)
+	if err := NewEncoder(buf, WithRecordHooks(hooks)).Encode(g); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{
+		"0 @I1@ INDI",
+		"1 SEX M",
+		"0 @F1@ FAM",
+		"1 _UID abc",
+		"2 _SRC app",
+		"0 TRLR",
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if diff := cmp.Diff(want, lines); diff != "" {
+		t.Errorf("output mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestEncodeCardinality(t *testing.T) {
+	g := &Gedcom{
+		Header: &Header{
+			Version:     "5.5.1",
+			Form:        "LINEAGE-LINKED",
+			UserDefined: []UserDefinedTag{{Tag: "GEDC", UserDefined: []UserDefinedTag{{Tag: "VERS", Value: "5.5"}}}},
+		},
+		Individual: []*IndividualRecord{
+			{Xref: "I1", Sex: "M", UserDefined: []UserDefinedTag{{Tag: "SEX", Value: "F"}}},
+			{Xref: "I2", Sex: "F"},
+		},
+	}
+
+	t.Run("ignore", func(t *testing.T) {
+		enc := NewEncoder(new(bytes.Buffer))
+		if err := enc.Encode(g); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(enc.Findings()) != 0 {
+			t.Errorf("got %d findings, wanted none", len(enc.Findings()))
+		}
+	})
+
+	t.Run("warn", func(t *testing.T) {
+		enc := NewEncoder(new(bytes.Buffer), WithCardinalityCheck(CardinalityWarn))
+		if err := enc.Encode(g); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := []Finding{
+			{Line: 6, Path: "HEAD.GEDC", Rule: RuleTooMany, Message: "GEDC occurs more than 1 times"},
+			{Line: 10, Path: "INDI.SEX", Xref: "I1", Rule: RuleTooMany, Message: "SEX occurs more than 1 times"},
+		}
+		if diff := cmp.Diff(want, enc.Findings()); diff != "" {
+			t.Errorf("findings mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		enc := NewEncoder(new(bytes.Buffer), WithCardinalityCheck(CardinalityError))
+		if err := enc.Encode(g); err == nil {
+			t.Errorf("got no error, wanted cardinality error")
+		}
+	})
+
+	t.Run("decoded", func(t *testing.T) {
+		g, err := NewDecoder(bytes.NewReader(data)).Decode()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		enc := NewEncoder(new(bytes.Buffer), WithCardinalityCheck(CardinalityError))
+		if err := enc.Encode(g); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+}
+
+func TestEncodeLineEnding(t *testing.T) {
+	input := "0 HEAD\r\n1 CHAR UTF-8\r\n1 SOUR\r\n0 @I1@ INDI\r\n1 FAMC @F1@\r\n0 @F1@ FAM\r\n0 TRLR\r\n"
+
+	g, err := NewDecoder(strings.NewReader(input)).Decode()
+	if err != nil {
+		t.Fatalf("unexpected decode error: %v", err)
+	}
+	if g.LineEnding != LineEndingCRLF {
+		t.Fatalf("got line ending %s, wanted %s", g.LineEnding, LineEndingCRLF)
+	}
+
+	buf := new(bytes.Buffer)
+	if err := NewEncoder(buf).Encode(g); err != nil {
+		t.Fatalf("unexpected encode error: %v", err)
+	}
+	if got := buf.String(); got != input {
+		t.Errorf("got %q, wanted %q", got, input)
+	}
+
+	buf.Reset()
+	if err := NewEncoder(buf, WithLineEnding(LineEndingLF)).Encode(g); err != nil {
+		t.Fatalf("unexpected encode error: %v", err)
+	}
+	if got, want := buf.String(), strings.ReplaceAll(input, "\r\n", "\n"); got != want {
+		t.Errorf("got %q, wanted %q", got, want)
+	}
+}
+
+func TestEncodeCanonicalDates(t *testing.T) {
+	g := &Gedcom{
+		Header: &Header{},
+		Individual: []*IndividualRecord{{
+			Xref: "I1",
+			Event: []*EventRecord{
+				{Tag: "BIRT", Date: "abt 3 mrz 1850"},
+				{Tag: "BAPM", Date: "@#DJULIAN@ 5 Mai 1850"},
+				{Tag: "DEAT", Date: "sometime in spring"},
+			},
+		}},
+	}
+
+	buf := new(bytes.Buffer)
+	if err := NewEncoder(buf, WithCanonicalDates(WithDateLocale("de"))).Encode(g); err != nil {
+		t.Fatalf("unexpected encode error: %v", err)
+	}
+	for _, want := range []string{"2 DATE ABT 3 MAR 1850\n", "2 DATE @#DJULIAN@ 5 MAY 1850\n", "2 DATE sometime in spring\n"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("output does not contain %q:\n%s", want, buf.String())
+		}
+	}
+
+	buf.Reset()
+	if err := NewEncoder(buf, WithTargetVersion("7.0"), WithCanonicalDates(WithDateLocale("de"))).Encode(g); err != nil {
+		t.Fatalf("unexpected encode error: %v", err)
+	}
+	if want := "2 DATE JULIAN 5 MAY 1850\n"; !strings.Contains(buf.String(), want) {
+		t.Errorf("output does not contain %q:\n%s", want, buf.String())
+	}
+}
+
+func TestEncodeNamePieces(t *testing.T) {
+	g := &Gedcom{
+		Header: &Header{},
+		Individual: []*IndividualRecord{{
+			Xref: "I1",
+			Name: []*NameRecord{{NamePieceGiven: "Anne", NamePieceSurnamePrefix: "de", NamePieceSurname: "Vere"}},
+		}},
+	}
+
+	buf := new(bytes.Buffer)
+	if err := NewEncoder(buf).Encode(g); err != nil {
+		t.Fatalf("unexpected encode error: %v", err)
+	}
+	if want := "1 NAME Anne /de Vere/\n2 GIVN Anne\n2 SPFX de\n2 SURN Vere\n"; !strings.Contains(buf.String(), want) {
+		t.Errorf("output does not contain %q:\n%s", want, buf.String())
+	}
+}
+
+func TestEncodeAtSigns(t *testing.T) {
+	testCases := []struct {
+		name      string
+		version   string
+		input     string
+		wantValue string
+		want      string
+	}{
+		{
+			name:      "doubled",
+			input:     "1 EMAIL jane@@example.com",
+			wantValue: "jane@example.com",
+			want:      "1 EMAIL jane@@example.com",
+		},
+		{
+			name:      "single",
+			input:     "1 EMAIL jane@example.com",
+			wantValue: "jane@example.com",
+			want:      "1 EMAIL jane@@example.com",
+		},
+		{
+			name:      "leading",
+			input:     "1 EMAIL @@jane",
+			wantValue: "@jane",
+			want:      "1 EMAIL @@jane",
+		},
+		{
+			name:      "version 7 leading",
+			version:   "7.0",
+			input:     "1 EMAIL @@jane@example.com",
+			wantValue: "@jane@example.com",
+			want:      "1 EMAIL @@jane@example.com",
+		},
+		{
+			name:      "version 7 inner",
+			version:   "7.0",
+			input:     "1 EMAIL jane@example.com",
+			wantValue: "jane@example.com",
+			want:      "1 EMAIL jane@example.com",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			header := "0 HEAD\n"
+			if tc.version != "" {
+				header += "1 GEDC\n2 VERS " + tc.version + "\n"
+			}
+			input := header + "0 @U1@ SUBM\n1 NAME Jane\n" + tc.input + "\n0 TRLR\n"
+			g, err := NewDecoder(strings.NewReader(input)).Decode()
+			if err != nil {
+				t.Fatalf("unexpected decode error: %v", err)
+			}
+			if got := g.Submitter[0].Address.Email[0]; got != tc.wantValue {
+				t.Errorf("got decoded value %q, wanted %q", got, tc.wantValue)
+			}
+
+			buf := new(bytes.Buffer)
+			if err := NewEncoder(buf).Encode(g); err != nil {
+				t.Fatalf("unexpected encode error: %v", err)
+			}
+			if !strings.Contains(buf.String(), tc.want+"\n") {
+				t.Errorf("output does not contain %q:\n%s", tc.want, buf.String())
+			}
+		})
+	}
+
+	// Escape sequences and pointers are not escaped
+	g := &Gedcom{
+		Header: &Header{},
+		Individual: []*IndividualRecord{{
+			Xref:  "I1",
+			Event: []*EventRecord{{Tag: "BIRT", Date: "@#DJULIAN@ 1 JAN 1700"}},
+			UserDefined: []UserDefinedTag{
+				{Tag: "_REF", Value: "@I2@"},
+			},
+		}},
+	}
+	buf := new(bytes.Buffer)
+	if err := NewEncoder(buf).Encode(g); err != nil {
+		t.Fatalf("unexpected encode error: %v", err)
+	}
+	for _, want := range []string{"2 DATE @#DJULIAN@ 1 JAN 1700\n", "1 _REF @I2@\n"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("output does not contain %q:\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestEncodeTrailer(t *testing.T) {
+	// The trailer is written even when the Gedcom has none
+	g := &Gedcom{
+		Header:     &Header{},
+		Individual: []*IndividualRecord{{Xref: "I1"}},
+	}
+	buf := new(bytes.Buffer)
+	if err := NewEncoder(buf).Encode(g); err != nil {
+		t.Fatalf("unexpected encode error: %v", err)
+	}
+	if !strings.HasSuffix(buf.String(), "\n0 TRLR\n") {
+		t.Errorf("output does not end with trailer:\n%s", buf.String())
+	}
+
+	got, err := NewDecoder(buf).Decode()
+	if err != nil {
+		t.Fatalf("unexpected decode error: %v", err)
+	}
+	if got.Trailer == nil {
+		t.Errorf("decoded trailer is nil")
+	}
+}
+
+func TestEncodeAssociation(t *testing.T) {
+	input := `0 HEAD
+0 @I1@ INDI
+1 NAME John /Smith/
+1 ASSO @I2@
+2 RELA Godfather
+2 SOUR @S1@
+3 PAGE p. 12
+2 NOTE Named in the baptism register
+0 @I2@ INDI
+1 NAME William /Jones/
+0 @S1@ SOUR
+1 TITL Parish register
+0 TRLR
+`
+	g, err := NewDecoder(strings.NewReader(input)).Decode()
+	if err != nil {
+		t.Fatalf("unexpected decode error: %v", err)
+	}
+	a := g.Individual[0].Association[0]
+	if a.Individual != g.Individual[1] {
+		t.Errorf("association individual not resolved, got %+v", a.Individual)
+	}
+
+	buf := new(bytes.Buffer)
+	if err := NewEncoder(buf).Encode(g); err != nil {
+		t.Fatalf("unexpected encode error: %v", err)
+	}
+	want := "1 ASSO @I2@\n2 RELA Godfather\n2 SOUR @S1@\n3 PAGE p. 12\n2 NOTE Named in the baptism register\n"
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("output does not contain %q:\n%s", want, buf.String())
+	}
+
+	// The resolved individual takes precedence over the xref
+	a.Xref = ""
+	a.Individual = &IndividualRecord{Xref: "I9"}
+	buf.Reset()
+	if err := NewEncoder(buf).Encode(g); err != nil {
+		t.Fatalf("unexpected encode error: %v", err)
+	}
+	if want := "1 ASSO @I9@\n"; !strings.Contains(buf.String(), want) {
+		t.Errorf("output does not contain %q:\n%s", want, buf.String())
+	}
+}
+
+func TestEncodeSubmitter(t *testing.T) {
+	input := `0 HEAD
+1 SUBM @U1@
+0 @U1@ SUBM
+1 NAME Jane Researcher
+1 ADDR 12 High Street
+2 CONT Oxford
+2 CITY Oxford
+2 POST OX1 1AA
+2 CTRY England
+1 PHON +44 1865 000000
+1 EMAIL jane@example.com
+1 LANG English
+1 LANG Welsh
+1 RFN 1234
+1 RIN 42
+1 NOTE Transcribed the parish registers
+1 CHAN
+2 DATE 1 JAN 2020
+0 @I1@ INDI
+1 NAME John /Smith/
+1 SUBM @U1@
+0 TRLR
+`
+	want, err := NewDecoder(strings.NewReader(input)).Decode()
+	if err != nil {
+		t.Fatalf("unexpected decode error: %v", err)
+	}
+
+	if s := want.Submitter[0]; s.Address == nil || len(s.Address.Email) != 1 || len(s.Language) != 2 {
+		t.Fatalf("submitter contact details not decoded: %+v", s)
+	}
+
+	buf := new(bytes.Buffer)
+	if err := NewEncoder(buf).Encode(want); err != nil {
+		t.Fatalf("unexpected encode error: %v", err)
+	}
+	got, err := NewDecoder(buf).Decode()
+	if err != nil {
+		t.Fatalf("unexpected decode error of encoded output: %v", err)
+	}
+
+	if diff := cmp.Diff(want.Submitter, got.Submitter); diff != "" {
+		t.Errorf("submitter mismatch (-want +got):\n%s", diff)
+	}
+	if len(got.Individual) != 1 || len(got.Individual[0].Submitter) != 1 || got.Individual[0].Submitter[0] != got.Submitter[0] {
+		t.Errorf("individual submitter not linked to submitter record")
+	}
+	if got.Header.Submitter != got.Submitter[0] {
+		t.Errorf("header submitter not linked to submitter record")
+	}
+}
+
+func TestEncodeSubmission(t *testing.T) {
+	input := `0 HEAD
+1 SUBM @U1@
+1 SUBN @SUBMISSION@
+0 @U1@ SUBM
+1 NAME Jane Researcher
+0 @SUBMISSION@ SUBN
+1 SUBM @U1@
+1 FAMF NameOfFamilyFile
+1 TEMP LONDO
+1 ANCE 1
+1 DESC 2
+1 ORDI yes
+1 RIN 1
+1 NOTE Submitted for processing
+1 CHAN
+2 DATE 1 JAN 2020
+0 TRLR
+`
+	want, err := NewDecoder(strings.NewReader(input)).Decode()
+	if err != nil {
+		t.Fatalf("unexpected decode error: %v", err)
+	}
+	if len(want.Submission) != 1 || want.Header.Submission != want.Submission[0] {
+		t.Fatalf("submission record not decoded and linked to the header")
+	}
+
+	buf := new(bytes.Buffer)
+	if err := NewEncoder(buf).Encode(want); err != nil {
+		t.Fatalf("unexpected encode error: %v", err)
+	}
+	got, err := NewDecoder(buf).Decode()
+	if err != nil {
+		t.Fatalf("unexpected decode error of encoded output: %v", err)
+	}
+
+	if diff := cmp.Diff(want.Submission, got.Submission); diff != "" {
+		t.Errorf("submission mismatch (-want +got):\n%s", diff)
+	}
+	if len(got.Submission) != 1 || got.Header.Submission != got.Submission[0] {
+		t.Errorf("header submission not linked to submission record")
+	}
+	if len(got.Submitter) != 1 || got.Submission[0].Submitter != got.Submitter[0] {
+		t.Errorf("submission submitter not linked to submitter record")
+	}
+}
+
+func TestEncodeHeaderDefaults(t *testing.T) {
+	testCases := []struct {
+		name   string
+		header *Header
+		want   string
+	}{
+		{
+			name:   "no header",
+			header: nil,
+			want:   "0 HEAD\n1 CHAR UTF-8\n1 SOUR gedcom\n1 GEDC\n2 VERS 5.5.1\n2 FORM LINEAGE-LINKED\n",
+		},
+		{
+			name:   "empty header",
+			header: &Header{Language: "English"},
+			want:   "0 HEAD\n1 CHAR UTF-8\n1 SOUR gedcom\n1 GEDC\n2 VERS 5.5.1\n2 FORM LINEAGE-LINKED\n1 LANG English\n",
+		},
+		{
+			name: "complete header",
+			header: &Header{
+				SourceSystem: SystemRecord{Xref: "MYAPP"},
+				CharacterSet: "ANSEL",
+				Version:      "5.5",
+				Form:         "LINEAGE-LINKED",
+			},
+			want: "0 HEAD\n1 CHAR ANSEL\n1 SOUR MYAPP\n1 GEDC\n2 VERS 5.5\n2 FORM LINEAGE-LINKED\n",
+		},
+		{
+			name:   "version 7",
+			header: &Header{Version: "7.0"},
+			want:   "0 HEAD\n1 GEDC\n2 VERS 7.0\n1 SOUR gedcom\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := &Gedcom{
+				Header:     tc.header,
+				Individual: []*IndividualRecord{{Xref: "I1"}},
+			}
+			buf := new(bytes.Buffer)
+			if err := NewEncoder(buf, WithHeaderDefaults()).Encode(g); err != nil {
+				t.Fatalf("unexpected encode error: %v", err)
+			}
+			want := tc.want + "0 @I1@ INDI\n0 TRLR\n"
+			if diff := cmp.Diff(want, buf.String()); diff != "" {
+				t.Errorf("output mismatch (-want +got):\n%s", diff)
+			}
+			if g.Header != tc.header || (tc.header != nil && tc.header.SourceSystem.Xref == "gedcom") {
+				t.Errorf("encoding modified the header")
+			}
+		})
+	}
+}
+
+func TestEncodeOmitEmpty(t *testing.T) {
+	input := `0 HEAD
+1 SOUR test
+1 GEDC
+2 VERS 5.5.1
+0 @I1@ INDI
+1 NAME John /Smith/
+1 BIRT
+1 RESI
+2 ADDR
+1 SOUR @S1@
+2 PAGE 12
+1 OBJE
+0 @S1@ SOUR
+1 TITL Parish register
+1 DATA
+0 TRLR
+`
+	want := `0 HEAD
+1 SOUR test
+1 GEDC
+2 VERS 5.5.1
+0 @I1@ INDI
+1 NAME John /Smith/
+1 BIRT
+1 RESI
+1 SOUR @S1@
+2 PAGE 12
+0 @S1@ SOUR
+1 TITL Parish register
+0 TRLR
+`
+	g, err := NewDecoder(strings.NewReader(input)).Decode()
+	if err != nil {
+		t.Fatalf("unexpected decode error: %v", err)
+	}
+
+	buf := new(bytes.Buffer)
+	if err := NewEncoder(buf).Encode(g); err != nil {
+		t.Fatalf("unexpected encode error: %v", err)
+	}
+	if diff := cmp.Diff(input, buf.String()); diff != "" {
+		t.Errorf("empty structures not written by default (-want +got):\n%s", diff)
+	}
+
+	buf.Reset()
+	if err := NewEncoder(buf, WithOmitEmpty()).Encode(g); err != nil {
+		t.Fatalf("unexpected encode error: %v", err)
+	}
+	got := buf.String()
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("output mismatch (-want +got):\n%s", diff)
+	}
+
+	// The decoded output has no empty structures left to omit
+	g2, err := NewDecoder(strings.NewReader(got)).Decode()
+	if err != nil {
+		t.Fatalf("unexpected decode error of encoded output: %v", err)
+	}
+	buf.Reset()
+	if err := NewEncoder(buf).Encode(g2); err != nil {
+		t.Fatalf("unexpected encode error: %v", err)
+	}
+	if diff := cmp.Diff(got, buf.String()); diff != "" {
+		t.Errorf("round trip mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestEncodeGeneratedXrefs(t *testing.T) {
+	source := &SourceRecord{Title: "Parish register"}
+	john := &IndividualRecord{
+		Name:     []*NameRecord{{Name: "John /Smith/"}},
+		Citation: []*CitationRecord{{Source: source}},
+	}
+	mary := &IndividualRecord{Xref: "I1", Name: []*NameRecord{{Name: "Mary /Jones/"}}}
+	anne := &IndividualRecord{Name: []*NameRecord{{Name: "Anne /Smith/"}}}
+	fam := &FamilyRecord{Husband: john, Wife: mary, Child: []*IndividualRecord{anne}}
+	john.Family = []*FamilyLinkRecord{{Family: fam}}
+	mary.Family = []*FamilyLinkRecord{{Family: fam}}
+	anne.Parents = []*FamilyLinkRecord{{Family: fam}}
+	g := &Gedcom{
+		Header:     &Header{},
+		Individual: []*IndividualRecord{john, mary, anne},
+		Family:     []*FamilyRecord{fam},
+		Source:     []*SourceRecord{source},
+	}
+
+	if err := NewEncoder(new(bytes.Buffer)).Encode(g); err == nil {
+		t.Fatalf("expected an error encoding records without xrefs")
+	}
+
+	buf := new(bytes.Buffer)
+	if err := NewEncoder(buf, WithGeneratedXrefs()).Encode(g); err != nil {
+		t.Fatalf("unexpected encode error: %v", err)
+	}
+	got := buf.String()
+	for _, line := range []string{
+		"0 @I2@ INDI\n",
+		"1 SOUR @S1@\n",
+		"1 FAMS @F1@\n",
+		"0 @I1@ INDI\n",
+		"0 @I3@ INDI\n",
+		"1 FAMC @F1@\n",
+		"0 @F1@ FAM\n1 HUSB @I2@\n1 WIFE @I1@\n1 CHIL @I3@\n",
+		"0 @S1@ SOUR\n",
+	} {
+		if !strings.Contains(got, line) {
+			t.Errorf("output does not contain %q:\n%s", line, got)
+		}
+	}
+
+	if john.Xref != "" || fam.Xref != "" || source.Xref != "" {
+		t.Errorf("encoding modified the xrefs of the records")
+	}
+
+	dec, err := NewDecoder(strings.NewReader(got)).Decode()
+	if err != nil {
+		t.Fatalf("unexpected decode error of encoded output: %v", err)
+	}
+	if len(dec.Family) != 1 || dec.Family[0].Husband != dec.Individual[0] || dec.Family[0].Child[0] != dec.Individual[2] {
+		t.Errorf("family members not linked to individual records")
+	}
+}
+
+func TestEncodeErrors(t *testing.T) {
+	g := &Gedcom{
+		Header: &Header{},
+		Individual: []*IndividualRecord{
+			{
+				Xref:   "I1",
+				Family: []*FamilyLinkRecord{{Family: &FamilyRecord{}}},
+			},
+			{
+				Xref: "I2",
+				Event: []*EventRecord{
+					{Tag: "BIRT", Date: "1 JAN 1900", Citation: []*CitationRecord{{Page: "12"}}},
+				},
+			},
+			{
+				Xref: "I3",
+				Name: []*NameRecord{{Name: "John /Smith/"}},
+			},
+		},
+	}
+
+	buf := new(bytes.Buffer)
+	err := NewEncoder(buf).Encode(g)
+	if err == nil {
+		t.Fatalf("expected an encode error")
+	}
+
+	var errs []*EncodeError
+	for _, err := range err.(interface{ Unwrap() []error }).Unwrap() {
+		var ee *EncodeError
+		if !errors.As(err, &ee) {
+			t.Fatalf("error is not an EncodeError: %v", err)
+		}
+		errs = append(errs, ee)
+	}
+	want := []string{
+		"encode @I1@ INDI.FAMS: family missing xref",
+		"encode @I2@ INDI.BIRT.SOUR: source missing",
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("got %d errors, wanted %d: %v", len(errs), len(want), err)
+	}
+	for i := range want {
+		if got := errs[i].Error(); got != want[i] {
+			t.Errorf("error %d: got %q, wanted %q", i, got, want[i])
+		}
+	}
+
+	// Records after a record with a problem are still written
+	if out := buf.String(); !strings.Contains(out, "0 @I3@ INDI\n1 NAME John /Smith/\n") || !strings.HasSuffix(out, "0 TRLR\n") {
+		t.Errorf("records following errors were not written:\n%s", out)
+	}
+}
+
 func TestDecodeEncode(t *testing.T) {
 	data, err := os.ReadFile("testdata/alexclark.ged")
 	if err != nil {
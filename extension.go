@@ -0,0 +1,95 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+
+package gedcom
+
+import (
+	"fmt"
+)
+
+// An Extension converts a vendor extension tag between its GEDCOM form and a typed
+// value held in the Data field of a UserDefinedTag.
+type Extension struct {
+	// Decode converts a tag, including its subordinate tags, into a typed value.
+	Decode func(t UserDefinedTag) (any, error)
+
+	// Encode converts a typed value produced by Decode back into a tag and its
+	// subordinate tags. The tag's name is used if the returned tag has none.
+	Encode func(v any) (UserDefinedTag, error)
+}
+
+// An ExtensionRegistry holds the extensions used to decode and encode vendor extension
+// tags. Registering a tag's decoder and encoder together ensures that a value decoded into
+// a typed structure is written back in the same form. A registry must not be modified
+// while it is in use by a Decoder or Encoder.
+type ExtensionRegistry struct {
+	exts map[string]Extension
+}
+
+// NewExtensionRegistry returns an empty ExtensionRegistry.
+func NewExtensionRegistry() *ExtensionRegistry {
+	return &ExtensionRegistry{
+		exts: make(map[string]Extension),
+	}
+}
+
+// Register registers the extension used for tag, replacing any previously registered.
+func (r *ExtensionRegistry) Register(tag string, ext Extension) {
+	r.exts[tag] = ext
+}
+
+// Lookup returns the extension registered for tag.
+func (r *ExtensionRegistry) Lookup(tag string) (Extension, bool) {
+	if r == nil {
+		return Extension{}, false
+	}
+	ext, ok := r.exts[tag]
+	return ext, ok
+}
+
+// WithExtensions configures a Decoder to decode user defined tags registered in reg,
+// storing the result in the Data field of the UserDefinedTag, and an Encoder to write
+// the Data field of user defined tags using reg.
+func WithExtensions(reg *ExtensionRegistry) Option {
+	return option{
+		decoder: func(d *Decoder) { d.extensions = reg },
+		encoder: func(e *Encoder) { e.extensions = reg },
+	}
+}
+
+// decode sets the Data field of u using the extension registered for its tag
+func (r *ExtensionRegistry) decode(u *UserDefinedTag) error {
+	ext, ok := r.Lookup(u.Tag)
+	if !ok || ext.Decode == nil {
+		return nil
+	}
+	v, err := ext.Decode(*u)
+	if err != nil {
+		return fmt.Errorf("decode extension %s: %w", u.Tag, err)
+	}
+	u.Data = v
+	return nil
+}
+
+// encode replaces u with the tag produced from its Data field by the registered extension
+func (r *ExtensionRegistry) encode(u UserDefinedTag) (UserDefinedTag, error) {
+	if u.Data == nil {
+		return u, nil
+	}
+	ext, ok := r.Lookup(u.Tag)
+	if !ok || ext.Encode == nil {
+		return u, nil
+	}
+	t, err := ext.Encode(u.Data)
+	if err != nil {
+		return u, fmt.Errorf("encode extension %s: %w", u.Tag, err)
+	}
+	if t.Tag == "" {
+		t.Tag = u.Tag
+	}
+	t.Level = u.Level
+	t.Data = nil
+	return t, nil
+}
@@ -0,0 +1,109 @@
+package gedcom
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type testGeo struct {
+	Lat, Long string
+}
+
+func testGeoRegistry() *ExtensionRegistry {
+	reg := NewExtensionRegistry()
+	reg.Register("_GEO", Extension{
+		Decode: func(t UserDefinedTag) (any, error) {
+			var g testGeo
+			for _, sub := range t.UserDefined {
+				switch sub.Tag {
+				case "_LAT":
+					g.Lat = sub.Value
+				case "_LONG":
+					g.Long = sub.Value
+				default:
+					return nil, fmt.Errorf("unexpected tag %s", sub.Tag)
+				}
+			}
+			return g, nil
+		},
+		Encode: func(v any) (UserDefinedTag, error) {
+			g, ok := v.(testGeo)
+			if !ok {
+				return UserDefinedTag{}, fmt.Errorf("unexpected type %T", v)
+			}
+			return UserDefinedTag{
+				UserDefined: []UserDefinedTag{
+					{Tag: "_LAT", Value: g.Lat},
+					{Tag: "_LONG", Value: g.Long},
+				},
+			}, nil
+		},
+	})
+	return reg
+}
+
+func TestExtensionRegistry(t *testing.T) {
+	input := `0 @I1@ INDI
+1 NAME John /Smith/
+1 _GEO
+2 _LAT N51.5
+2 _LONG W0.12
+1 _OTHER x
+0 TRLR
+`
+	reg := testGeoRegistry()
+
+	g, err := NewDecoder(strings.NewReader(input), WithExtensions(reg)).Decode()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	uds := g.Individual[0].UserDefined
+	if len(uds) != 2 {
+		t.Fatalf("got %d user defined tags, wanted 2", len(uds))
+	}
+	if diff := cmp.Diff(testGeo{Lat: "N51.5", Long: "W0.12"}, uds[0].Data); diff != "" {
+		t.Errorf("decoded extension mismatch (-want +got):\n%s", diff)
+	}
+	if uds[1].Data != nil {
+		t.Errorf("got data for unregistered tag, wanted nil")
+	}
+
+	// Modify the typed value and check it is written back
+	uds[0].Data = testGeo{Lat: "N52.0", Long: "E1.5"}
+	uds[0].UserDefined = nil
+
+	buf := new(bytes.Buffer)
+	if err := NewEncoder(buf, WithExtensions(reg)).Encode(g); err != nil {
+		t.Fatalf("unexpected encode error: %v", err)
+	}
+	want := `0 @I1@ INDI
+1 NAME John /Smith/
+1 _GEO
+2 _LAT N52.0
+2 _LONG E1.5
+1 _OTHER x
+0 TRLR
+`
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("encoded output mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestExtensionAtEndOfInput(t *testing.T) {
+	input := `0 @I1@ INDI
+1 _GEO
+2 _LAT N51.5
+`
+	g, err := NewDecoder(strings.NewReader(input), WithExtensions(testGeoRegistry())).Decode()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(testGeo{Lat: "N51.5"}, g.Individual[0].UserDefined[0].Data); diff != "" {
+		t.Errorf("decoded extension mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,176 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+
+package gedcom
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+)
+
+// A FidelityReport describes the differences found when a file is decoded, encoded and
+// decoded again.
+type FidelityReport struct {
+	Differences []FidelityDifference
+}
+
+// OK reports whether the round trip preserved all of the decoded data.
+func (r *FidelityReport) OK() bool {
+	return len(r.Differences) == 0
+}
+
+// A FidelityDifference describes a single value that changed during a round trip.
+type FidelityDifference struct {
+	Path      string // location of the value, e.g. "Individual[2].Event[0].Date"
+	Original  string // the value decoded from the original file
+	RoundTrip string // the value decoded after encoding
+}
+
+func (d FidelityDifference) String() string {
+	return fmt.Sprintf("%s: %s != %s", d.Path, d.Original, d.RoundTrip)
+}
+
+// CheckRoundTrip decodes the GEDCOM data read from r, encodes it and decodes the result,
+// then reports any differences between the two decoded forms. It can be used to verify
+// that the package preserves the contents of a particular file. An error is returned if
+// any of the decoding or encoding steps fail.
+func CheckRoundTrip(r io.Reader) (*FidelityReport, error) {
+	orig, err := NewDecoder(r).Decode()
+	if err != nil {
+		return nil, fmt.Errorf("decode original: %w", err)
+	}
+
+	buf := new(bytes.Buffer)
+	if err := NewEncoder(buf).Encode(orig); err != nil {
+		return nil, fmt.Errorf("encode: %w", err)
+	}
+
+	rt, err := NewDecoder(buf).Decode()
+	if err != nil {
+		return nil, fmt.Errorf("decode encoded: %w", err)
+	}
+
+	return CompareGedcom(orig, rt), nil
+}
+
+// CompareGedcom reports the differences between two Gedcoms. Records are compared field by
+// field in order. References from one record to another are compared by xref only.
+func CompareGedcom(a, b *Gedcom) *FidelityReport {
+	report := &FidelityReport{}
+	av, bv := reflect.ValueOf(a).Elem(), reflect.ValueOf(b).Elem()
+	for i := 0; i < av.NumField(); i++ {
+		name := av.Type().Field(i).Name
+		if name == "Order" {
+			// Order holds the same records as the lists of each type
+			continue
+		}
+		fa, fb := av.Field(i), bv.Field(i)
+		if fa.Kind() == reflect.Slice {
+			// Elements of the top-level lists are the records themselves, not references
+			compareSlice(report, name, fa, fb, true)
+			continue
+		}
+		compareValues(report, name, fa, fb)
+	}
+	return report
+}
+
+func compareValues(report *FidelityReport, path string, a, b reflect.Value) {
+	switch a.Kind() {
+	case reflect.Pointer:
+		if a.IsNil() || b.IsNil() {
+			if a.IsNil() != b.IsNil() {
+				report.add(path, a, b)
+			}
+			return
+		}
+		if xa, ok := recordXref(a); ok {
+			xb, _ := recordXref(b)
+			if xa != xb {
+				report.add(path, a, b)
+			}
+			return
+		}
+		compareValues(report, path, a.Elem(), b.Elem())
+	case reflect.Struct:
+		for i := 0; i < a.NumField(); i++ {
+			if !a.Type().Field(i).IsExported() {
+				continue
+			}
+			compareValues(report, path+"."+a.Type().Field(i).Name, a.Field(i), b.Field(i))
+		}
+	case reflect.Slice:
+		compareSlice(report, path, a, b, false)
+	default:
+		if !reflect.DeepEqual(a.Interface(), b.Interface()) {
+			report.add(path, a, b)
+		}
+	}
+}
+
+// compareSlice compares the elements of two slices, treating nil and empty slices as equal.
+// If records is true the elements are compared in full even if they have an xref.
+func compareSlice(report *FidelityReport, path string, a, b reflect.Value, records bool) {
+	n := max(a.Len(), b.Len())
+	for i := 0; i < n; i++ {
+		ep := path + "[" + strconv.Itoa(i) + "]"
+		switch {
+		case i >= a.Len():
+			report.add(ep, reflect.Value{}, b.Index(i))
+		case i >= b.Len():
+			report.add(ep, a.Index(i), reflect.Value{})
+		case records && a.Index(i).Kind() == reflect.Pointer && !a.Index(i).IsNil() && !b.Index(i).IsNil():
+			compareValues(report, ep, a.Index(i).Elem(), b.Index(i).Elem())
+		default:
+			compareValues(report, ep, a.Index(i), b.Index(i))
+		}
+	}
+}
+
+// recordXref returns the xref of the record v points to, if it has one
+func recordXref(v reflect.Value) (string, bool) {
+	e := v.Elem()
+	if e.Kind() != reflect.Struct {
+		return "", false
+	}
+	f := e.FieldByName("Xref")
+	if !f.IsValid() || f.Kind() != reflect.String || f.String() == "" {
+		return "", false
+	}
+	return f.String(), true
+}
+
+func (r *FidelityReport) add(path string, a, b reflect.Value) {
+	r.Differences = append(r.Differences, FidelityDifference{
+		Path:      path,
+		Original:  formatFidelityValue(a),
+		RoundTrip: formatFidelityValue(b),
+	})
+}
+
+// formatFidelityValue returns a short description of v
+func formatFidelityValue(v reflect.Value) string {
+	if !v.IsValid() {
+		return "<missing>"
+	}
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return "<nil>"
+		}
+		if xref, ok := recordXref(v); ok {
+			return "@" + xref + "@"
+		}
+		return formatFidelityValue(v.Elem())
+	case reflect.Struct:
+		return "{" + v.Type().Name() + "}"
+	case reflect.String:
+		return strconv.Quote(v.String())
+	}
+	return fmt.Sprint(v.Interface())
+}
@@ -0,0 +1,78 @@
+package gedcom
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCheckRoundTrip(t *testing.T) {
+	input := `0 HEAD
+1 CHAR UTF-8
+0 @I1@ INDI
+1 NAME John /Smith/
+1 SEX M
+1 BIRT
+2 DATE 1 JAN 1900
+2 PLAC Boston, Suffolk, Massachusetts
+2 SOUR @S1@
+3 PAGE 12
+1 FAMS @F1@
+1 NOTE First line
+2 CONT second line
+0 @F1@ FAM
+1 HUSB @I1@
+1 MARR
+2 DATE 1925
+0 @S1@ SOUR
+1 TITL Parish register
+0 TRLR
+`
+	report, err := CheckRoundTrip(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !report.OK() {
+		for _, d := range report.Differences {
+			t.Errorf("unexpected difference: %s", d)
+		}
+	}
+}
+
+func TestCompareGedcom(t *testing.T) {
+	input := `
+0 @I1@ INDI
+1 NAME John /Smith/
+1 BIRT
+2 DATE 1 JAN 1900
+1 FAMS @F1@
+0 @F1@ FAM
+1 HUSB @I1@
+`
+	decode := func() *Gedcom {
+		g, err := NewDecoder(strings.NewReader(input)).Decode()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return g
+	}
+
+	a, b := decode(), decode()
+	if r := CompareGedcom(a, b); !r.OK() {
+		t.Fatalf("got differences for identical input: %v", r.Differences)
+	}
+
+	b.Individual[0].Event[0].Date = "2 JAN 1900"
+	b.Family[0].Husband = &IndividualRecord{Xref: "I2"}
+	b.Individual[0].Name = append(b.Individual[0].Name, &NameRecord{Name: "Jack /Smith/"})
+
+	want := []FidelityDifference{
+		{Path: "Family[0].Husband", Original: "@I1@", RoundTrip: "@I2@"},
+		{Path: "Individual[0].Name[1]", Original: "<missing>", RoundTrip: "{NameRecord}"},
+		{Path: "Individual[0].Event[0].Date", Original: `"1 JAN 1900"`, RoundTrip: `"2 JAN 1900"`},
+	}
+	if diff := cmp.Diff(want, CompareGedcom(a, b).Differences); diff != "" {
+		t.Errorf("differences mismatch (-want +got):\n%s", diff)
+	}
+}
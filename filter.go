@@ -0,0 +1,67 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+
+package gedcom
+
+// recordTags are the tags of the standard level 0 records other than the header and trailer
+var recordTags = map[string]bool{
+	"INDI": true, "FAM": true, "OBJE": true, "NOTE": true, "REPO": true, "SOUR": true, "SUBM": true, "SUBN": true,
+}
+
+// WithSkipRecords configures the decoder to skip records with the given tags, such as
+// OBJE or NOTE, without decoding them. Substructures outside the header that have the
+// same tags, such as links to media and notes or source citations, are also skipped, so
+// skipped records cost no memory. The header and trailer are never skipped.
+func WithSkipRecords(tags ...string) DecoderOption {
+	return decoderOptionFunc(func(d *Decoder) {
+		if d.skipRecords == nil {
+			d.skipRecords = make(map[string]bool)
+		}
+		for _, tag := range tags {
+			d.skipRecords[tag] = true
+		}
+	})
+}
+
+// WithSkipMedia configures the decoder to skip multimedia records and links to media.
+func WithSkipMedia() DecoderOption {
+	return WithSkipRecords("OBJE")
+}
+
+// WithSkipSources configures the decoder to skip source records and source citations.
+func WithSkipSources() DecoderOption {
+	return WithSkipRecords("SOUR")
+}
+
+// WithOnlyRecords configures the decoder to decode only records with the given tags, such
+// as INDI and FAM, skipping all others as WithSkipRecords does. Level 0 user defined tags
+// are skipped unless listed.
+func WithOnlyRecords(tags ...string) DecoderOption {
+	return decoderOptionFunc(func(d *Decoder) {
+		d.onlyRecords = make(map[string]bool, len(tags))
+		for _, tag := range tags {
+			d.onlyRecords[tag] = true
+		}
+	})
+}
+
+// skipped reports whether a line with the given level and tag should be skipped
+// together with its subordinate lines
+func (d *Decoder) skipped(level int, tag string) bool {
+	if d.skipRecords == nil && d.onlyRecords == nil {
+		return false
+	}
+	if level == 0 {
+		if tag == "HEAD" || tag == "TRLR" {
+			return false
+		}
+	} else if d.record == "HEAD" || !recordTags[tag] {
+		return false
+	}
+	if d.onlyRecords != nil && !d.onlyRecords[tag] {
+		return true
+	}
+	return d.skipRecords[tag]
+}
@@ -0,0 +1,102 @@
+package gedcom
+
+import (
+	"strings"
+	"testing"
+)
+
+const filterInput = `0 HEAD
+1 SOUR MyApp
+1 SUBM @U1@
+0 @I1@ INDI
+1 NAME Jane /Doe/
+1 BIRT
+2 DATE 1901
+2 SOUR @S1@
+3 PAGE 12
+2 NOTE Born at home
+1 OBJE @M1@
+1 FAMS @F1@
+0 @F1@ FAM
+1 WIFE @I1@
+1 SOUR @S1@
+0 @S1@ SOUR
+1 TITL Parish register
+1 OBJE @M1@
+0 @M1@ OBJE
+1 FILE photo.jpg
+0 @N1@ NOTE A shared note
+0 @U1@ SUBM
+1 NAME Submitter
+0 _PLAC Custom
+0 TRLR
+`
+
+func TestSkipRecords(t *testing.T) {
+	g, err := NewDecoder(strings.NewReader(filterInput), WithSkipSources(), WithSkipMedia()).Decode()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(g.Source) != 0 || len(g.Media) != 0 {
+		t.Errorf("got %d sources and %d media, wanted none", len(g.Source), len(g.Media))
+	}
+	if g.Header == nil || g.Header.SourceSystem.Xref != "MyApp" {
+		t.Errorf("header source system was skipped")
+	}
+	indi := g.Individual[0]
+	if len(indi.Event) != 1 || indi.Event[0].Date != "1901" || len(indi.Event[0].Citation) != 0 || len(indi.Event[0].Note) != 1 {
+		t.Errorf("got birth %+v, wanted date and note without citation", indi.Event[0])
+	}
+	if len(indi.Media) != 0 || len(indi.Family) != 1 {
+		t.Errorf("got %d media and %d families, wanted 0 and 1", len(indi.Media), len(indi.Family))
+	}
+	if len(g.Family) != 1 || len(g.Family[0].Citation) != 0 || g.Family[0].Wife != indi {
+		t.Errorf("family was not decoded without its citation")
+	}
+	if len(g.Note) != 1 || len(g.Submitter) != 1 || len(g.UserDefined) != 1 {
+		t.Errorf("got %d notes, %d submitters and %d user defined tags, wanted 1 of each", len(g.Note), len(g.Submitter), len(g.UserDefined))
+	}
+	if len(g.Unhandled) != 0 {
+		t.Errorf("got unhandled tags %+v", g.Unhandled)
+	}
+}
+
+func TestOnlyRecords(t *testing.T) {
+	g, err := NewDecoder(strings.NewReader(filterInput), WithOnlyRecords("INDI", "FAM")).Decode()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(g.Individual) != 1 || len(g.Family) != 1 {
+		t.Fatalf("got %d individuals and %d families, wanted 1 of each", len(g.Individual), len(g.Family))
+	}
+	if n := len(g.Source) + len(g.Media) + len(g.Note) + len(g.Submitter) + len(g.UserDefined); n != 0 {
+		t.Errorf("got %d other records, wanted none", n)
+	}
+	if g.Header == nil || g.Trailer == nil {
+		t.Errorf("header or trailer was skipped")
+	}
+	ev := g.Individual[0].Event[0]
+	if ev.Date != "1901" || len(ev.Citation) != 0 || len(ev.Note) != 0 {
+		t.Errorf("got birth %+v, wanted only the date", ev)
+	}
+}
+
+func TestOnlyRecordsNext(t *testing.T) {
+	d := NewDecoder(strings.NewReader(filterInput), WithOnlyRecords("FAM"))
+	var got []Record
+	for {
+		r, err := d.Next()
+		if err != nil {
+			break
+		}
+		got = append(got, r)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d records, wanted header and family", len(got))
+	}
+	if f, ok := got[1].(*FamilyRecord); !ok || f.Xref != "F1" {
+		t.Errorf("got record %+v, wanted family F1", got[1])
+	}
+}
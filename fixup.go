@@ -0,0 +1,157 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+
+package gedcom
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// A FixupFunc repairs a line of malformed input before it is decoded. The lines that
+// contain l are given in parents, outermost first. It reports whether it changed l.
+type FixupFunc func(l *Line, parents []Line) bool
+
+// A Profile names the fixups a Decoder applies to files written by a particular product.
+// Each fixup is either one of the Fixup constants, which are built into the decoder, or
+// the name of a fixup registered with RegisterFixup.
+type Profile struct {
+	Name   string
+	Fixups []string
+}
+
+// Profiles for files written by common products. Each is registered under its name.
+var (
+	// ProfileDefault is used by a Decoder that has not been configured with a profile.
+	ProfileDefault = Profile{Name: "default", Fixups: []string{FixupNoteNewline, FixupPublicationFacts}}
+
+	// ProfileAncestry repairs notes containing unescaped newlines and merges the DATE
+	// and PLAC tags Ancestry writes under PUBL into the publication facts.
+	ProfileAncestry = Profile{Name: "ancestry", Fixups: []string{FixupNoteNewline, FixupPublicationFacts}}
+
+	// ProfileFamilyTreeMaker applies the same fixups as ProfileAncestry since Family Tree
+	// Maker shares Ancestry's export format.
+	ProfileFamilyTreeMaker = Profile{Name: "familytreemaker", Fixups: []string{FixupNoteNewline, FixupPublicationFacts}}
+
+	// ProfileMyHeritage applies no fixups. MyHeritage writes well formed files; the
+	// profile allows fixups for it to be registered and selected by name.
+	ProfileMyHeritage = Profile{Name: "myheritage"}
+
+	// ProfileGramps applies no fixups since Gramps writes well formed files.
+	ProfileGramps = Profile{Name: "gramps"}
+)
+
+var fixupRegistry = struct {
+	sync.RWMutex
+	fixups   map[string]FixupFunc
+	profiles map[string]Profile
+}{
+	fixups: make(map[string]FixupFunc),
+	profiles: map[string]Profile{
+		ProfileDefault.Name:         ProfileDefault,
+		ProfileAncestry.Name:        ProfileAncestry,
+		ProfileFamilyTreeMaker.Name: ProfileFamilyTreeMaker,
+		ProfileMyHeritage.Name:      ProfileMyHeritage,
+		ProfileGramps.Name:          ProfileGramps,
+	},
+}
+
+// builtinFixups are the fixups implemented by the decoder itself
+var builtinFixups = map[string]bool{
+	FixupNoteNewline:      true,
+	FixupPublicationFacts: true,
+}
+
+// RegisterFixup registers f under name so it may be listed in a Profile, replacing any
+// fixup previously registered with that name. It panics if name is one of the Fixup
+// constants built into the decoder.
+func RegisterFixup(name string, f FixupFunc) {
+	if builtinFixups[name] {
+		panic("gedcom: cannot replace built in fixup " + name)
+	}
+	fixupRegistry.Lock()
+	defer fixupRegistry.Unlock()
+	fixupRegistry.fixups[name] = f
+}
+
+// RegisterProfile registers p under its name, replacing any profile previously registered
+// with that name.
+func RegisterProfile(p Profile) {
+	fixupRegistry.Lock()
+	defer fixupRegistry.Unlock()
+	fixupRegistry.profiles[p.Name] = p
+}
+
+// LookupProfile returns the profile registered under name.
+func LookupProfile(name string) (Profile, bool) {
+	fixupRegistry.RLock()
+	defer fixupRegistry.RUnlock()
+	p, ok := fixupRegistry.profiles[name]
+	return p, ok
+}
+
+// WithProfile configures the decoder to apply only the fixups listed in p, replacing
+// those of ProfileDefault. Options that follow it, such as WithNoteFixup, may enable or
+// disable individual fixups.
+func WithProfile(p Profile) DecoderOption {
+	return decoderOptionFunc(func(d *Decoder) {
+		d.fixups = make(map[string]bool, len(p.Fixups))
+		for _, name := range p.Fixups {
+			d.fixups[name] = true
+		}
+	})
+}
+
+// namedFixup is a registered fixup enabled for a Decoder
+type namedFixup struct {
+	name string
+	f    FixupFunc
+}
+
+// lineFixups returns the registered fixups enabled for the decoder in name order
+func (d *Decoder) lineFixups() ([]namedFixup, error) {
+	fixupRegistry.RLock()
+	defer fixupRegistry.RUnlock()
+
+	var fs []namedFixup
+	for name, enabled := range d.fixups {
+		if !enabled || builtinFixups[name] {
+			continue
+		}
+		f, ok := fixupRegistry.fixups[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown fixup %q", name)
+		}
+		fs = append(fs, namedFixup{name: name, f: f})
+	}
+	slices.SortFunc(fs, func(a, b namedFixup) int { return strings.Compare(a.name, b.name) })
+	return fs, nil
+}
+
+// fixupLines applies fixups to lines read by a Decoder, tracking the lines that contain
+// each one
+type fixupLines struct {
+	fixups  []namedFixup
+	parents []Line
+}
+
+// apply applies the fixups to the current line of s, reporting each that changes it to m
+func (x *fixupLines) apply(s *Scanner, m Metrics) {
+	l := s.Line()
+	if l.Level < len(x.parents) {
+		x.parents = x.parents[:l.Level]
+	}
+	for _, nf := range x.fixups {
+		if nf.f(&l, x.parents) && m != nil {
+			m.AddFixup(nf.name)
+		}
+	}
+	s.level, s.tag, s.value, s.xref = l.Level, l.Tag, l.Value, l.Xref
+	if l.Level == len(x.parents) {
+		x.parents = append(x.parents, l)
+	}
+}
@@ -0,0 +1,99 @@
+package gedcom
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestProfilePublicationFacts(t *testing.T) {
+	input := "0 @S1@ SOUR\n1 PUBL Name: London\n2 DATE 1901\n2 PLAC Kew\n0 TRLR\n"
+
+	testCases := []struct {
+		name string
+		opts []DecoderOption
+		want string
+	}{
+		{name: "default", want: "Name: London, 1901, Kew"},
+		{name: "ancestry", opts: []DecoderOption{WithProfile(ProfileAncestry)}, want: "Name: London, 1901, Kew"},
+		{name: "gramps", opts: []DecoderOption{WithProfile(ProfileGramps)}, want: "Name: London"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m := &MetricsCounts{}
+			g, err := NewDecoder(strings.NewReader(input), append(tc.opts, WithMetrics(m))...).Decode()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := g.Source[0].PublicationFacts; got != tc.want {
+				t.Errorf("got publication facts %q, wanted %q", got, tc.want)
+			}
+			wantFixups := 0
+			if tc.want != "Name: London" {
+				wantFixups = 2
+			}
+			if got := m.Fixups()[FixupPublicationFacts]; got != int64(wantFixups) {
+				t.Errorf("got %d fixups, wanted %d", got, wantFixups)
+			}
+		})
+	}
+}
+
+func TestRegisterFixup(t *testing.T) {
+	RegisterFixup("test-sex", func(l *Line, parents []Line) bool {
+		if l.Tag != "SEX" || len(parents) == 0 || parents[0].Tag != "INDI" {
+			return false
+		}
+		v := normalizeSex(l.Value)
+		if v == l.Value {
+			return false
+		}
+		l.Value = v
+		return true
+	})
+	RegisterProfile(Profile{Name: "test-profile", Fixups: []string{FixupNoteNewline, "test-sex"}})
+
+	p, ok := LookupProfile("test-profile")
+	if !ok {
+		t.Fatalf("profile was not registered")
+	}
+
+	input := "0 @I1@ INDI\n1 SEX female\n0 @I2@ INDI\n1 SEX M\n0 TRLR\n"
+	m := &MetricsCounts{}
+	g, err := NewDecoder(strings.NewReader(input), WithProfile(p), WithMetrics(m)).Decode()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got []string
+	for _, i := range g.Individual {
+		got = append(got, i.Sex)
+	}
+	if diff := cmp.Diff([]string{"F", "M"}, got); diff != "" {
+		t.Errorf("sex mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(map[string]int64{"test-sex": 1}, m.Fixups()); diff != "" {
+		t.Errorf("fixups mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestUnknownFixup(t *testing.T) {
+	p := Profile{Name: "missing", Fixups: []string{"no-such-fixup"}}
+	if _, err := NewDecoder(strings.NewReader("0 TRLR\n"), WithProfile(p)).Decode(); err == nil {
+		t.Errorf("got no error, wanted error for unknown fixup")
+	}
+}
+
+func TestLookupProfile(t *testing.T) {
+	for _, p := range []Profile{ProfileDefault, ProfileAncestry, ProfileFamilyTreeMaker, ProfileMyHeritage, ProfileGramps} {
+		got, ok := LookupProfile(p.Name)
+		if !ok {
+			t.Errorf("profile %q is not registered", p.Name)
+			continue
+		}
+		if diff := cmp.Diff(p, got); diff != "" {
+			t.Errorf("profile %q mismatch (-want +got):\n%s", p.Name, diff)
+		}
+	}
+}
@@ -0,0 +1,20 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+
+package gedcom
+
+// GEDCOM 5.5.1 added structures to GEDCOM 5.5 that programs reading 5.5 data may reject,
+// such as the EMAIL, FAX and WWW tags of an address, the MAP structure of a place with
+// its LATI and LONG tags and the FONE and ROMN variants of names and places. When writing
+// GEDCOM 5.5 the encoder writes these as user defined tags, so that their values are kept
+// without making the data invalid.
+
+// encode55 returns the tag written in place of tag when writing GEDCOM 5.5
+func encode55(tag string) string {
+	if tagsAdded551[tag] {
+		return "_" + tag
+	}
+	return tag
+}
@@ -0,0 +1,75 @@
+package gedcom
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestEncodeGedcom55(t *testing.T) {
+	input := `0 HEAD
+1 GEDC
+2 VERS 5.5.1
+2 FORM LINEAGE-LINKED
+1 CHAR UTF-8
+0 @U1@ SUBM
+1 NAME Jane Researcher
+1 ADDR 12 High Street
+2 ADR1 12 High Street
+2 ADR3 Summertown
+2 CITY Oxford
+1 EMAIL jane@example.com
+1 FAX 01865 000001
+1 WWW http://example.com
+0 @I1@ INDI
+1 NAME John /Smith/
+1 BIRT
+2 PLAC Oxford
+3 MAP
+4 LATI N51.75
+4 LONG W1.25
+0 TRLR
+`
+	g, err := NewDecoder(strings.NewReader(input)).Decode()
+	if err != nil {
+		t.Fatalf("unexpected decode error: %v", err)
+	}
+
+	buf := new(bytes.Buffer)
+	if err := NewEncoder(buf, WithTargetVersion("5.5")).Encode(g); err != nil {
+		t.Fatalf("unexpected encode error: %v", err)
+	}
+	got := buf.String()
+
+	for _, line := range []string{
+		"2 VERS 5.5\n",
+		"2 _ADR3 Summertown\n",
+		"1 _EMAIL jane@@example.com\n",
+		"1 _FAX 01865 000001\n",
+		"1 _WWW http://example.com\n",
+		"3 _MAP\n4 _LATI N51.75\n4 _LONG W1.25\n",
+	} {
+		if !strings.Contains(got, line) {
+			t.Errorf("output does not contain %q:\n%s", line, got)
+		}
+	}
+
+	findings, err := Validate(strings.NewReader(got))
+	if err != nil {
+		t.Fatalf("unexpected validate error: %v", err)
+	}
+	for _, f := range findings {
+		if f.Rule == RuleVersion {
+			t.Errorf("unexpected finding: %s", f)
+		}
+	}
+
+	// The same data written as 5.5.1 keeps the standard tags
+	buf.Reset()
+	if err := NewEncoder(buf).Encode(g); err != nil {
+		t.Fatalf("unexpected encode error: %v", err)
+	}
+	if got := buf.String(); !strings.Contains(got, "1 EMAIL jane@@example.com\n") || !strings.Contains(got, "3 MAP\n") {
+		t.Errorf("5.5.1 output does not contain standard tags:\n%s", got)
+	}
+}
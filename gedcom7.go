@@ -0,0 +1,226 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+
+package gedcom
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// GEDCOM 7 differs from 5.5.1 in ways that affect decoding: it is always UTF-8 and has no
+// CHAR tag, CONC is not allowed, shared notes are SNOTE records, pointers may be the null
+// pointer @VOID@ and enumerated values are written in upper case. The decoder detects the
+// version from the VERS tag of the header's GEDC structure and translates lines of
+// GEDCOM 7 data to their 5.5.1 equivalents so that both versions decode into the same
+// structures. The encoder reverses the translation when writing GEDCOM 7.
+
+// voidXref is the GEDCOM 7 null pointer, used where a pointer is required but there is no
+// record to point to
+const voidXref = "@VOID@"
+
+// isVersion7 reports whether v, the value of the VERS tag of a header's GEDC structure,
+// names GEDCOM 7 or a later minor version such as 7.0.14
+func isVersion7(v string) bool {
+	v = strings.TrimSpace(v)
+	return v == "7" || strings.HasPrefix(v, "7.")
+}
+
+// enumTags7 are the tags whose values are enumerations written in lower case by GEDCOM
+// 5.5.1 and in upper case by GEDCOM 7
+var enumTags7 = map[string]bool{
+	"MEDI": true,
+	"PEDI": true,
+	"RESN": true,
+}
+
+// translate7 returns the GEDCOM 5.5.1 form of a line of GEDCOM 7 data. SNOTE becomes
+// NOTE, void pointers become empty values and enumerated values are converted to lower
+// case.
+func translate7(tag string, value string) (string, string) {
+	switch {
+	case tag == "SNOTE":
+		tag = "NOTE"
+	case enumTags7[tag]:
+		value = strings.ToLower(value)
+	}
+	if value == voidXref {
+		value = ""
+	}
+	return tag, value
+}
+
+// voidPointer reports whether value, the value of a tag that points to a record, is empty,
+// as is the value of a GEDCOM 7 void pointer. If it is, the subordinate tags of the
+// pointer are recorded as unhandled since there is no record to hold them.
+func (d *Decoder) voidPointer(value string, level int) bool {
+	if value != "" {
+		return false
+	}
+	d.pushParser(makeUnhandledParser(d, level))
+	return true
+}
+
+// isStandardTag reports whether tag is defined by the GEDCOM 5.5.1 grammar, or by the
+// GEDCOM 7 grammar if version7 is true
+func isStandardTag(tag string, version7 bool) bool {
+	if version7 {
+		return standardTags7()[tag]
+	}
+	return standardTags()[tag]
+}
+
+// standardTags7 returns the set of tags defined by GEDCOM 7
+var standardTags7 = sync.OnceValue(func() map[string]bool {
+	tags := make(map[string]bool)
+	for _, tag := range strings.Fields(`
+		ABBR ADDR ADOP ADR1 ADR2 ADR3 AGE AGNC ALIA ANCI ANUL ASSO AUTH BAPL BAPM BARM BASM
+		BIRT BLES BURI CALN CAST CAUS CENS CHAN CHIL CHR CHRA CITY CONF CONL CONT COPR CORP
+		CREA CREM CROP CTRY DATA DATE DEAT DESI DEST DIV DIVF DSCR EDUC EMAIL EMIG ENDL ENGA
+		EVEN EXID FACT FAM FAMC FAMS FAX FCOM FILE FORM GEDC GIVN GRAD HEAD HEIGHT HUSB IDNO
+		IMMI INDI INIL LANG LATI LEFT LONG MAP MARB MARC MARL MARR MARS MEDI MIME NAME NATI
+		NATU NCHI NICK NMR NO NOTE NPFX NSFX OBJE OCCU ORDN PAGE PEDI PHON PHRASE PLAC POST
+		PROB PROP PUBL QUAY REFN RELI REPO RESI RESN RETI ROLE SCHMA SDATE SEX SLGC SLGS
+		SNOTE SOUR SPFX SSN STAE STAT SUBM SURN TAG TEMP TEXT TIME TITL TOP TRAN TRLR TYPE
+		UID VERS WIDTH WIFE WILL WWW`) {
+		tags[tag] = true
+	}
+	return tags
+})
+
+// tags7 maps tags that were removed in GEDCOM 7 to the tags the encoder writes in their
+// place
+var tags7 = map[string]string{
+	"AFN":  "_AFN",
+	"FONE": "_FONE",
+	"RELA": "ROLE",
+	"RFN":  "_RFN",
+	"RIN":  "_RIN",
+	"ROMN": "_ROMN",
+}
+
+// encode7 returns the GEDCOM 7 form of a line written by the encoder
+func encode7(tag string, value string) (string, string) {
+	if t, ok := tags7[tag]; ok {
+		tag = t
+	}
+	if enumTags7[tag] {
+		value = strings.ToUpper(value)
+	}
+	return tag, value
+}
+
+// mediaTypes7 maps the multimedia formats of GEDCOM 5.5.1 to the media types used by
+// GEDCOM 7
+var mediaTypes7 = map[string]string{
+	"BMP":  "image/bmp",
+	"GIF":  "image/gif",
+	"JPG":  "image/jpeg",
+	"JPEG": "image/jpeg",
+	"PNG":  "image/png",
+	"TIF":  "image/tiff",
+	"TIFF": "image/tiff",
+	"PDF":  "application/pdf",
+	"WAV":  "audio/wav",
+	"MP3":  "audio/mpeg",
+	"MP4":  "video/mp4",
+	"TXT":  "text/plain",
+	"HTM":  "text/html",
+	"HTML": "text/html",
+}
+
+// mediaType7 returns the media type for a multimedia format. Formats that are already
+// media types and formats with no known media type are returned unchanged.
+func mediaType7(format string) string {
+	if t, ok := mediaTypes7[strings.ToUpper(strings.TrimPrefix(format, "."))]; ok {
+		return t
+	}
+	return format
+}
+
+// header7 writes the substructures of a GEDCOM 7 header, which begins with the GEDC
+// structure and has no CHAR, FILE or SUBN tags
+func (e *Encoder) header7(h *Header) {
+	e.tag(1, "GEDC", "")
+	e.tag(2, "VERS", e.targetVersion(h))
+	e.schema()
+	e.sourceSystem(0, h.SourceSystem)
+	e.maybeTag(1, "DEST", h.Destination)
+	e.maybeTag(1, "DATE", h.Date)
+	e.maybeTag(2, "TIME", h.Time)
+	if h.Submitter != nil {
+		e.tagWithPointer(1, "SUBM", e.xref(h.Submitter, h.Submitter.Xref))
+	}
+	e.maybeTag(1, "COPR", h.Copyright)
+	e.maybeTag(1, "LANG", h.Language)
+	e.maybeTagWithText(1, "NOTE", h.Note)
+	e.userDefinedList(1, h.UserDefined)
+}
+
+// schema writes a SCHMA structure declaring the URIs of the extension tags in e.declared
+func (e *Encoder) schema() {
+	if len(e.declared) == 0 {
+		return
+	}
+	e.tag(1, "SCHMA", "")
+	for _, st := range e.declared {
+		e.tag(2, "TAG", st.Tag+" "+st.URI)
+	}
+}
+
+// declaredTags returns the extension tags declared by the header of g followed by the
+// other user defined tags in g that have a URI, each listed once
+func declaredTags(g *Gedcom) []SchemaTag {
+	var decls []SchemaTag
+	seen := make(map[string]bool)
+	add := func(tag string, uri string) {
+		if seen[tag] {
+			return
+		}
+		seen[tag] = true
+		decls = append(decls, SchemaTag{Tag: tag, URI: uri})
+	}
+	if g.Header != nil {
+		for _, st := range g.Header.Schema {
+			add(st.Tag, st.URI)
+		}
+	}
+
+	visited := make(map[uintptr]bool)
+	var walk func(v reflect.Value)
+	walk = func(v reflect.Value) {
+		switch v.Kind() {
+		case reflect.Pointer:
+			if v.IsNil() || visited[v.Pointer()] {
+				return
+			}
+			visited[v.Pointer()] = true
+			walk(v.Elem())
+		case reflect.Slice:
+			for i := 0; i < v.Len(); i++ {
+				walk(v.Index(i))
+			}
+		case reflect.Struct:
+			if u, ok := v.Interface().(UserDefinedTag); ok && u.URI != "" {
+				add(u.Tag, u.URI)
+			}
+			for i := 0; i < v.NumField(); i++ {
+				if v.Type().Field(i).IsExported() {
+					walk(v.Field(i))
+				}
+			}
+		}
+	}
+
+	gv := reflect.ValueOf(g).Elem()
+	for i := 0; i < gv.NumField(); i++ {
+		// Unhandled tags are not written
+		if gv.Type().Field(i).Name != "Unhandled" {
+			walk(gv.Field(i))
+		}
+	}
+	return decls
+}
@@ -0,0 +1,219 @@
+package gedcom
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const gedcom7Input = `0 HEAD
+1 GEDC
+2 VERS 7.0
+0 @I1@ INDI
+1 RESN CONFIDENTIAL
+1 NAME Jane /Doe/
+1 FAMC @F1@
+2 PEDI ADOPTED
+1 SNOTE @N1@
+1 EXID 123-456
+2 TYPE http://example.com/ids
+1 CREA
+2 DATE 1 JAN 2020
+3 TIME 12:30
+0 @F1@ FAM
+1 HUSB @VOID@
+2 PHRASE Unknown father
+1 WIFE @I2@
+1 CHIL @I1@
+0 @N1@ SNOTE Shared note
+1 CONT second line
+0 TRLR
+`
+
+func TestDecodeGedcom7(t *testing.T) {
+	d := NewDecoder(strings.NewReader(gedcom7Input))
+	g, err := d.Decode()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(g.Individual) != 1 {
+		t.Fatalf("got %d individuals, wanted 1", len(g.Individual))
+	}
+	indi := g.Individual[0]
+	if indi.RestrictionNotice != "confidential" {
+		t.Errorf("got restriction notice %q, wanted %q", indi.RestrictionNotice, "confidential")
+	}
+	if len(indi.Parents) != 1 || indi.Parents[0].Type != "adopted" {
+		t.Errorf("got parents %+v, wanted one family link with pedigree adopted", indi.Parents)
+	}
+	if len(indi.ExternalID) != 1 || indi.ExternalID[0].ID != "123-456" || indi.ExternalID[0].Type != "http://example.com/ids" {
+		t.Errorf("got external ids %+v", indi.ExternalID)
+	}
+	if indi.Creation.Date != "1 JAN 2020" || indi.Creation.Time != "12:30" {
+		t.Errorf("got creation %+v", indi.Creation)
+	}
+
+	if len(g.Note) != 1 || g.Note[0].Xref != "N1" || g.Note[0].Note != "Shared note\nsecond line" {
+		t.Fatalf("got shared notes %+v", g.Note)
+	}
+	if len(indi.Note) != 1 || indi.Note[0] != g.Note[0] {
+		t.Errorf("individual note does not point to the shared note")
+	}
+
+	if len(g.Family) != 1 {
+		t.Fatalf("got %d families, wanted 1", len(g.Family))
+	}
+	fam := g.Family[0]
+	if fam.Husband != nil {
+		t.Errorf("got husband %+v, wanted none for a void pointer", fam.Husband)
+	}
+	if fam.Wife == nil || fam.Wife.Xref != "I2" {
+		t.Errorf("got wife %+v, wanted I2", fam.Wife)
+	}
+	if len(g.Unhandled) != 1 || g.Unhandled[0].Tag != "PHRASE" {
+		t.Errorf("got unhandled tags %+v, wanted PHRASE", g.Unhandled)
+	}
+
+	for _, w := range d.Warnings() {
+		if w.Kind == WarningUnknownTag {
+			t.Errorf("unexpected warning: %s", w)
+		}
+	}
+}
+
+func TestDecodeGedcom7Conc(t *testing.T) {
+	input := "0 HEAD\n1 GEDC\n2 VERS 7.0\n0 @I1@ INDI\n1 NOTE Some\n2 CONC thing\n0 TRLR\n"
+
+	d := NewDecoder(strings.NewReader(input))
+	if _, err := d.Decode(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if w := d.Warnings(); len(w) != 1 || w[0].Kind != WarningUnknownTag || w[0].Tag != "CONC" {
+		t.Errorf("got warnings %v, wanted unknown tag CONC", w)
+	}
+
+	_, err := NewDecoder(strings.NewReader(input), WithStrict()).Decode()
+	if !errors.Is(err, ErrUnknownTag) {
+		t.Errorf("got error %v, wanted %v", err, ErrUnknownTag)
+	}
+}
+
+func TestDecodeGedcom7Charset(t *testing.T) {
+	// GEDCOM 7 is always UTF-8 so a CHAR tag, which is not part of the grammar, is ignored
+	input := "0 HEAD\n1 GEDC\n2 VERS 7.0\n1 CHAR ANSEL\n0 @I1@ INDI\n1 NAME José /Doe/\n0 TRLR\n"
+
+	g, err := NewDecoder(strings.NewReader(input)).Decode()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, want := g.Individual[0].Name[0].Name, "José /Doe/"; got != want {
+		t.Errorf("got name %q, wanted %q", got, want)
+	}
+}
+
+func TestEncodeGedcom7(t *testing.T) {
+	input := `0 HEAD
+1 CHAR UTF-8
+1 SUBN @SUBN1@
+1 GEDC
+2 VERS 5.5.1
+2 FORM LINEAGE-LINKED
+0 @I1@ INDI
+1 RESN locked
+1 NAME Jane /Doe/
+1 NOTE @N1@
+1 NOTE A long note that is split usi
+2 CONC ng CONC in GEDCOM 5.5.1
+1 RIN 42
+0 @M1@ OBJE
+1 FILE photo.jpg
+2 FORM jpg
+3 TYPE photo
+0 @N1@ NOTE Shared
+0 @SUBN1@ SUBN
+1 TEMP SLAKE
+0 TRLR
+`
+	g, err := NewDecoder(strings.NewReader(input)).Decode()
+	if err != nil {
+		t.Fatalf("unexpected decode error: %v", err)
+	}
+	g.Individual[0].Citation = append(g.Individual[0].Citation, &CitationRecord{Source: &SourceRecord{}, Page: "p. 1"})
+
+	buf := new(strings.Builder)
+	if err := NewEncoder(buf, WithTargetVersion("7.0")).Encode(g); err != nil {
+		t.Fatalf("unexpected encode error: %v", err)
+	}
+	got := buf.String()
+
+	if !strings.HasPrefix(got, "0 HEAD\n1 GEDC\n2 VERS 7.0\n") {
+		t.Errorf("output does not begin with a version 7 header:\n%s", got)
+	}
+	for _, want := range []string{
+		"1 RESN LOCKED\n",
+		"1 SNOTE @N1@\n",
+		"1 NOTE A long note that is split using CONC in GEDCOM 5.5.1\n",
+		"1 _RIN 42\n",
+		"1 SOUR @VOID@\n2 PAGE p. 1\n",
+		"1 FILE photo.jpg\n2 FORM image/jpeg\n3 MEDI PHOTO\n",
+		"0 @N1@ SNOTE Shared\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output does not contain %q:\n%s", want, got)
+		}
+	}
+	for _, unwanted := range []string{"CHAR", "2 CONC", "FORM LINEAGE-LINKED", "SUBN"} {
+		if strings.Contains(got, unwanted) {
+			t.Errorf("output contains %q:\n%s", unwanted, got)
+		}
+	}
+
+	// The output decodes to the same records
+	g7, err := NewDecoder(strings.NewReader(got)).Decode()
+	if err != nil {
+		t.Fatalf("unexpected decode error: %v", err)
+	}
+	if g7.Individual[0].RestrictionNotice != "locked" {
+		t.Errorf("got restriction notice %q, wanted %q", g7.Individual[0].RestrictionNotice, "locked")
+	}
+	if len(g7.Note) != 1 || g7.Individual[0].Note[0] != g7.Note[0] {
+		t.Errorf("individual note does not point to the shared note")
+	}
+}
+
+func TestGedcom7Schema(t *testing.T) {
+	input := `0 HEAD
+1 GEDC
+2 VERS 7.0
+1 SCHMA
+2 TAG _SKYPEID http://xmlns.com/foaf/0.1/skypeID
+0 @I1@ INDI
+1 _SKYPEID example.person
+0 TRLR
+`
+	g, err := NewDecoder(strings.NewReader(input)).Decode()
+	if err != nil {
+		t.Fatalf("unexpected decode error: %v", err)
+	}
+	want := SchemaTag{Tag: "_SKYPEID", URI: "http://xmlns.com/foaf/0.1/skypeID"}
+	if len(g.Header.Schema) != 1 || g.Header.Schema[0] != want {
+		t.Errorf("got schema %+v, wanted %+v", g.Header.Schema, want)
+	}
+	ud := g.Individual[0].UserDefined
+	if len(ud) != 1 || ud[0].URI != want.URI {
+		t.Errorf("got user defined tags %+v, wanted URI %q", ud, want.URI)
+	}
+
+	// Declarations are written for extension tags that have a URI but were not declared
+	g.Header.Schema = nil
+	g.Individual[0].UserDefined = append(g.Individual[0].UserDefined, UserDefinedTag{Tag: "_UID", Value: "abc", URI: "http://example.com/uid"})
+
+	buf := new(strings.Builder)
+	if err := NewEncoder(buf).Encode(g); err != nil {
+		t.Fatalf("unexpected encode error: %v", err)
+	}
+	if wanted := "2 VERS 7.0\n1 SCHMA\n2 TAG _SKYPEID http://xmlns.com/foaf/0.1/skypeID\n2 TAG _UID http://example.com/uid\n"; !strings.Contains(buf.String(), wanted) {
+		t.Errorf("output does not contain %q:\n%s", wanted, buf.String())
+	}
+}
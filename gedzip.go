@@ -0,0 +1,81 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+
+package gedcom
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// gedzipDataset is the name of the file holding the GEDCOM data in a GEDZIP archive
+const gedzipDataset = "gedcom.ged"
+
+// ReadGedzip decodes the GEDCOM data held in a GEDZIP archive, the zip file defined by
+// GEDCOM 7 that packages a dataset together with the media files it references. The
+// archive is read from r, which holds size bytes. Files of multimedia records that are
+// held in the archive have their Archive field set so they may be read using the Open
+// method of the FileRecord; r must remain readable while they are in use.
+func ReadGedzip(r io.ReaderAt, size int64, opts ...DecoderOption) (*Gedcom, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("read gedzip: %w", err)
+	}
+	f, err := zr.Open(gedzipDataset)
+	if err != nil {
+		return nil, fmt.Errorf("read gedzip: %w", err)
+	}
+	defer f.Close()
+
+	g, err := NewDecoder(f, opts...).Decode()
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range g.Media {
+		if m == nil {
+			continue
+		}
+		for _, file := range m.File {
+			name, ok := gedzipPath(file.Name)
+			if !ok {
+				continue
+			}
+			if _, err := fs.Stat(zr, name); err == nil {
+				file.Archive = zr
+			}
+		}
+	}
+	return g, nil
+}
+
+// Open opens the file for reading from the GEDZIP archive that holds it. It returns an
+// error wrapping fs.ErrNotExist if the file is not held in an archive.
+func (f *FileRecord) Open() (fs.File, error) {
+	name, ok := gedzipPath(f.Name)
+	if f.Archive == nil || !ok {
+		return nil, &fs.PathError{Op: "open", Path: f.Name, Err: fs.ErrNotExist}
+	}
+	return f.Archive.Open(name)
+}
+
+// gedzipPath returns the path within a GEDZIP archive of a file named by a FILE tag,
+// which is a URL. It reports false if the URL is not a relative path, such as a web
+// address or an absolute file path.
+func gedzipPath(name string) (string, bool) {
+	u, err := url.Parse(strings.ReplaceAll(name, `\`, "/"))
+	if err != nil || u.Scheme != "" || u.Host != "" || strings.HasPrefix(u.Path, "/") {
+		return "", false
+	}
+	p := path.Clean(u.Path)
+	if !fs.ValidPath(p) || p == "." {
+		return "", false
+	}
+	return p, true
+}
@@ -0,0 +1,84 @@
+package gedcom
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"testing"
+)
+
+func TestReadGedzip(t *testing.T) {
+	files := map[string]string{
+		"gedcom.ged": `0 HEAD
+1 GEDC
+2 VERS 7.0
+0 @O1@ OBJE
+1 FILE media/jane%20doe.jpg
+2 FORM image/jpeg
+0 @O2@ OBJE
+1 FILE https://example.com/photo.jpg
+2 FORM image/jpeg
+0 @O3@ OBJE
+1 FILE media/missing.jpg
+2 FORM image/jpeg
+0 TRLR
+`,
+		"media/jane doe.jpg": "jpeg data",
+	}
+
+	buf := new(bytes.Buffer)
+	zw := zip.NewWriter(buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("create %s: %v", name, err)
+		}
+		io.WriteString(w, content)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("close zip: %v", err)
+	}
+
+	g, err := ReadGedzip(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(g.Media) != 3 {
+		t.Fatalf("got %d media records, wanted 3", len(g.Media))
+	}
+
+	f, err := g.Media[0].File[0].Open()
+	if err != nil {
+		t.Fatalf("unexpected error opening bundled file: %v", err)
+	}
+	data, err := io.ReadAll(f)
+	f.Close()
+	if err != nil {
+		t.Fatalf("unexpected error reading bundled file: %v", err)
+	}
+	if string(data) != "jpeg data" {
+		t.Errorf("got file content %q, wanted %q", data, "jpeg data")
+	}
+
+	for _, m := range g.Media[1:] {
+		if m.File[0].Archive != nil {
+			t.Errorf("file %s is marked as held in the archive", m.File[0].Name)
+		}
+		if _, err := m.File[0].Open(); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("got error %v opening %s, wanted %v", err, m.File[0].Name, fs.ErrNotExist)
+		}
+	}
+}
+
+func TestReadGedzipMissingDataset(t *testing.T) {
+	buf := new(bytes.Buffer)
+	zw := zip.NewWriter(buf)
+	zw.Create("media/photo.jpg")
+	zw.Close()
+
+	if _, err := ReadGedzip(bytes.NewReader(buf.Bytes()), int64(buf.Len())); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("got error %v, wanted %v", err, fs.ErrNotExist)
+	}
+}
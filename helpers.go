@@ -1,19 +1,265 @@
 package gedcom
 
 import (
+	"strconv"
 	"strings"
+	"time"
 )
 
 type ParsedName struct {
-	Full     string
-	Given    string
-	Surname  string
-	Suffix   string
-	Nickname string
+	Full          string
+	Prefix        string // title before the given name such as "Dr." or "Rev.", as in NPFX
+	Given         string
+	SurnamePrefix string // particle before the surname such as "van der" or "de la", as in SPFX
+	Surname       string
+	Suffix        string
+	Nickname      string
+
+	// PaternalSurname and MaternalSurname are the surnames inherited from the father and
+	// mother when the name has two surnames and SplitPersonalName was configured with
+	// WithDoubleSurnames
+	PaternalSurname string
+	MaternalSurname string
+}
+
+// A NameOption configures how SplitPersonalName interprets a name.
+type NameOption func(*nameConfig)
+
+type nameConfig struct {
+	prefixes     map[string]bool
+	particles    map[string]bool
+	splitPrefix  bool // whether SplitPersonalName moves particles into SurnamePrefix
+	double       bool
+	surnameOrder SurnameOrder
+}
+
+// A SurnameOrder is the order in which the two surnames of a name are written.
+type SurnameOrder int
+
+const (
+	PaternalFirst SurnameOrder = iota // the father's surname then the mother's, as in Spanish names
+	MaternalFirst                     // the mother's surname then the father's, as in Portuguese names
+)
+
+// WithDoubleSurnames configures SplitPersonalName to split a surname made of two
+// surnames, such as "Garcia Lopez", into PaternalSurname and MaternalSurname, written in
+// the given order. Each surname keeps its particles, as in "de la Fuente", and a
+// conjunction between them, as in "Garcia y Lopez", is dropped. Surnames written in two
+// slash-delimited parts, as in "Maria /Garcia/ /Lopez/", are split at the slashes. The
+// surname is not split unless exactly two surnames are found.
+func WithDoubleSurnames(order SurnameOrder) NameOption {
+	return func(c *nameConfig) {
+		c.double = true
+		c.surnameOrder = order
+	}
+}
+
+// WithNamePrefixes configures SplitPersonalName to recognize the given titles, in place of
+// those returned by DefaultNamePrefixes. Titles are not case sensitive and match with or
+// without a trailing full stop.
+func WithNamePrefixes(prefixes ...string) NameOption {
+	return func(c *nameConfig) {
+		c.prefixes = nameWordSet(prefixes)
+	}
+}
+
+// WithSurnamePrefixes configures SplitPersonalName to move the given particles from the
+// start of the surname into SurnamePrefix, which it does not do otherwise. Pass the
+// result of DefaultSurnamePrefixes to use the default particles. The particles also
+// replace the defaults used by NormalizeSurname, SurnameKey and the other functions that
+// recognize particles. Particles are not case sensitive.
+func WithSurnamePrefixes(particles ...string) NameOption {
+	return func(c *nameConfig) {
+		c.particles = nameWordSet(particles)
+		c.splitPrefix = true
+	}
+}
+
+// DefaultNamePrefixes returns the titles recognized by SplitPersonalName by default.
+func DefaultNamePrefixes() []string {
+	return []string{
+		"Mr", "Mrs", "Ms", "Miss", "Mstr", "Dr", "Prof", "Rev", "Revd", "Fr", "Sir", "Dame", "Lady", "Lord",
+		"Hon", "Capt", "Col", "Gen", "Lt", "Maj", "Sgt", "Cpl", "Pvt", "Adm", "Cmdr", "Gov", "Judge", "Rabbi",
+	}
+}
+
+// splitSurnamePrefixes returns an option that moves the default surname particles into
+// SurnamePrefix, for callers that compare surnames without their particles.
+func splitSurnamePrefixes() NameOption {
+	return WithSurnamePrefixes(DefaultSurnamePrefixes()...)
+}
+
+// DefaultSurnamePrefixes returns the surname particles recognized by default. Words
+// such as St, Le and Mac that are as often the start of a surname as a particle before
+// it are not included.
+func DefaultSurnamePrefixes() []string {
+	return []string{
+		"van", "von", "der", "den", "de", "del", "della", "des", "di", "da", "dos", "das", "du", "la",
+		"ten", "ter", "te", "zu", "und", "af", "av", "al", "el", "bin", "ibn",
+	}
+}
+
+// newNameConfig returns the default configuration modified by opts
+func newNameConfig(opts []NameOption) nameConfig {
+	cfg := nameConfig{
+		prefixes:  nameWordSet(DefaultNamePrefixes()),
+		particles: nameWordSet(DefaultSurnamePrefixes()),
+	}
+	for _, o := range opts {
+		o(&cfg)
+	}
+	return cfg
+}
+
+func nameWordSet(words []string) map[string]bool {
+	m := make(map[string]bool, len(words))
+	for _, w := range words {
+		m[strings.ToUpper(strings.TrimSuffix(w, "."))] = true
+	}
+	return m
+}
+
+// SplitPersonalName parses a name in the format "First Name /Surname/ suffix" into its
+// components. Titles at the start of the given name, such as "Dr.", are returned in
+// Prefix. The surname is returned whole in Surname unless the name is split using
+// WithSurnamePrefixes, which moves particles at its start, such as "van der", into
+// SurnamePrefix, always keeping at least one word in Surname. A compound surname written in several
+// slash-delimited parts separated by spaces, as in "Maria /Garcia/ /Lopez/", is joined
+// into a single surname.
+func SplitPersonalName(name string, opts ...NameOption) ParsedName {
+	cfg := newNameConfig(opts)
+	pn, groups := splitPersonalName(name)
+	if cfg.double {
+		if len(groups) != 2 {
+			groups = surnameComponents(pn.Surname, cfg.particles)
+		}
+		if len(groups) == 2 {
+			if cfg.surnameOrder == MaternalFirst {
+				groups[0], groups[1] = groups[1], groups[0]
+			}
+			pn.PaternalSurname, pn.MaternalSurname = groups[0], groups[1]
+		}
+	}
+	pn.Prefix, pn.Given = splitLeadingWords(pn.Given, cfg.prefixes, 0)
+	if cfg.splitPrefix {
+		pn.SurnamePrefix, pn.Surname = splitLeadingWords(pn.Surname, cfg.particles, 1)
+	}
+	return pn
+}
+
+// surnameConjunctions are the words that may join two surnames
+var surnameConjunctions = map[string]bool{
+	"Y": true,
+	"E": true,
+	"I": true,
+}
+
+// surnameComponents splits a compound surname into surnames, each being a single word
+// preceded by any particles in set, dropping conjunctions between them
+func surnameComponents(surname string, set map[string]bool) []string {
+	var comps, cur []string
+	for _, w := range strings.Fields(surname) {
+		key := strings.ToUpper(strings.TrimSuffix(w, "."))
+		if len(cur) == 0 && len(comps) > 0 && surnameConjunctions[key] {
+			continue
+		}
+		cur = append(cur, w)
+		if !set[key] {
+			comps = append(comps, strings.Join(cur, " "))
+			cur = nil
+		}
+	}
+	if len(cur) > 0 {
+		if len(comps) == 0 {
+			return []string{strings.Join(cur, " ")}
+		}
+		comps[len(comps)-1] += " " + strings.Join(cur, " ")
+	}
+	return comps
+}
+
+// FormatPersonalName returns the NAME value for the components of a name in the format
+// "Prefix Given /Surname Prefix Surname/ Suffix", the inverse of SplitPersonalName. The
+// surname is delimited by slashes when there is a surname or a suffix. The nickname and
+// full name are not included.
+func FormatPersonalName(pn ParsedName) string {
+	surname := joinWords(pn.SurnamePrefix, pn.Surname)
+	if surname != "" || pn.Suffix != "" {
+		surname = "/" + surname + "/"
+	}
+	return joinWords(pn.Prefix, pn.Given, surname, pn.Suffix)
+}
+
+// Parsed returns the components of the name. Name pieces given by NPFX, GIVN, NICK, SPFX,
+// SURN and NSFX tags are used where present, with the remaining components taken from
+// splitting the name value with SplitPersonalName using opts. Full is the full name
+// without the nickname, built from the components if the name has no value.
+func (n *NameRecord) Parsed(opts ...NameOption) ParsedName {
+	return mergeNamePieces(SplitPersonalName(n.Name, opts...), n.Name, n.NamePiecePrefix, n.NamePieceGiven, n.NamePieceNick, n.NamePieceSurnamePrefix, n.NamePieceSurname, n.NamePieceSuffix)
+}
+
+// Parsed returns the components of the phonetic or romanized name, combining its name
+// pieces with its value in the same way as NameRecord.Parsed.
+func (n *VariantNameRecord) Parsed(opts ...NameOption) ParsedName {
+	return mergeNamePieces(SplitPersonalName(n.Name, opts...), n.Name, n.NamePiecePrefix, n.NamePieceGiven, n.NamePieceNick, n.NamePieceSurnamePrefix, n.NamePieceSurname, n.NamePieceSuffix)
+}
+
+// mergeNamePieces replaces the components of pn with the non-empty name pieces
+func mergeNamePieces(pn ParsedName, name, prefix, given, nick, spfx, surname, suffix string) ParsedName {
+	for _, f := range []struct {
+		dst   *string
+		piece string
+	}{
+		{&pn.Prefix, prefix}, {&pn.Given, given}, {&pn.Nickname, nick},
+		{&pn.SurnamePrefix, spfx}, {&pn.Surname, surname}, {&pn.Suffix, suffix},
+	} {
+		if p := strings.TrimSpace(f.piece); p != "" {
+			*f.dst = p
+		}
+	}
+	if strings.TrimSpace(name) == "" {
+		pn.Full = joinWords(pn.Prefix, pn.Given, pn.SurnamePrefix, pn.Surname, pn.Suffix)
+	}
+	return pn
+}
+
+// joinWords joins the non-empty strings with spaces
+func joinWords(ss ...string) string {
+	var words []string
+	for _, s := range ss {
+		if s != "" {
+			words = append(words, s)
+		}
+	}
+	return strings.Join(words, " ")
 }
 
-// SplitPersonalName parses a name in the format "First Name /Surname/ suffix" into its components.
-func SplitPersonalName(name string) ParsedName {
+// fullSurname returns the surname including any surname prefix
+func (pn ParsedName) fullSurname() string {
+	if pn.SurnamePrefix == "" {
+		return pn.Surname
+	}
+	return pn.SurnamePrefix + " " + pn.Surname
+}
+
+// splitLeadingWords splits s after the leading words that are in set, ignoring case and
+// a trailing full stop, keeping at least keep words in the remainder
+func splitLeadingWords(s string, set map[string]bool, keep int) (string, string) {
+	words := strings.Fields(s)
+	n := 0
+	for n < len(words)-keep && set[strings.ToUpper(strings.TrimSuffix(words[n], "."))] {
+		n++
+	}
+	if n == 0 {
+		return "", s
+	}
+	return strings.Join(words[:n], " "), strings.Join(words[n:], " ")
+}
+
+// splitPersonalName parses a name into its full name, given name, surname, suffix and
+// nickname. If the surname was written in several slash-delimited parts it also returns
+// the parts.
+func splitPersonalName(name string) (ParsedName, []string) {
 	name = strings.TrimSpace(name)
 
 	parts := strings.Split(name, "/")
@@ -21,7 +267,7 @@ func SplitPersonalName(name string) ParsedName {
 		return ParsedName{
 			Full:  name,
 			Given: name,
-		}
+		}, nil
 	}
 
 	// Find a part that was delimited by slashes with no whitespace after the leading slash or before the following slash
@@ -50,6 +296,7 @@ func SplitPersonalName(name string) ParsedName {
 
 		// See if there is a following part that could be part of the surname.
 		// Some surnames may have alternatives: smith/smyth
+		end := i
 		for j := i + 1; j < len(parts); j++ {
 			p := parts[j]
 			if len(p) == 0 || p[0] == ' ' || p[len(p)-1] == ' ' {
@@ -59,6 +306,24 @@ func SplitPersonalName(name string) ParsedName {
 			// Append this part to the surname and recalculate the suffix
 			pn.Surname += "/" + p
 			pn.Suffix = strings.TrimSpace(strings.Join(parts[j+1:], "/"))
+			end = j
+		}
+
+		// Join further slash-delimited parts separated from the surname only by spaces,
+		// which some files use for compound surnames: Garcia/ /Lopez
+		var groups []string
+		for end+3 < len(parts) && strings.TrimSpace(parts[end+1]) == "" {
+			p := parts[end+2]
+			if len(p) == 0 || p[0] == ' ' || p[len(p)-1] == ' ' {
+				break
+			}
+			if groups == nil {
+				groups = []string{pn.Surname}
+			}
+			groups = append(groups, p)
+			pn.Surname += " " + p
+			end += 2
+			pn.Suffix = strings.TrimSpace(strings.Join(parts[end+1:], "/"))
 		}
 
 		pn.Full = pn.Given
@@ -75,7 +340,7 @@ func SplitPersonalName(name string) ParsedName {
 			pn.Full += pn.Suffix
 		}
 
-		return pn
+		return pn, groups
 
 	}
 
@@ -83,5 +348,169 @@ func SplitPersonalName(name string) ParsedName {
 	return ParsedName{
 		Full:  strings.TrimRight(name, "/ "),
 		Given: strings.TrimRight(name, "/ "),
+	}, nil
+}
+
+// MtDNAHaplogroup returns the mitochondrial DNA haplogroup recorded for the individual
+// using the _MTDNA extension tag, or an empty string if there is none.
+func (r *IndividualRecord) MtDNAHaplogroup() string {
+	return r.dnaValue("_MTDNA")
+}
+
+// YDNAHaplogroup returns the Y chromosome DNA haplogroup recorded for the individual
+// using the _YDNA extension tag, or an empty string if there is none.
+func (r *IndividualRecord) YDNAHaplogroup() string {
+	return r.dnaValue("_YDNA")
+}
+
+func (r *IndividualRecord) dnaValue(tag string) string {
+	for _, d := range r.DNA {
+		if d != nil && d.Tag == tag {
+			return d.Value
+		}
+	}
+	return ""
+}
+
+// ParsedAge holds the components of an age phrase such as "> 42y 6m".
+type ParsedAge struct {
+	Qualifier string // one of "<" or ">" if the age is a bound, otherwise empty
+	Keyword   string // one of CHILD, INFANT or STILLBORN if the age was given as a keyword
+	Years     int
+	Months    int
+	Weeks     int // 7.0
+	Days      int
+}
+
+// ParseAge parses an age phrase as found in the AGE tag of an event. Weeks, which are
+// allowed by GEDCOM 7, are accepted with the unit w. It reports false if the phrase is not
+// a valid age.
+func ParseAge(s string) (ParsedAge, bool) {
+	var a ParsedAge
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return a, false
+	}
+	switch strings.ToUpper(s) {
+	case "CHILD", "INFANT", "STILLBORN":
+		a.Keyword = strings.ToUpper(s)
+		return a, true
 	}
+	if s[0] == '<' || s[0] == '>' {
+		a.Qualifier = s[:1]
+		s = s[1:]
+	}
+
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return ParsedAge{}, false
+	}
+	seen := ""
+	for _, f := range fields {
+		if len(f) < 2 {
+			return ParsedAge{}, false
+		}
+		unit := strings.ToLower(f[len(f)-1:])
+		n, err := strconv.Atoi(f[:len(f)-1])
+		if err != nil || n < 0 || strings.Contains(seen, unit) {
+			return ParsedAge{}, false
+		}
+		seen += unit
+		switch unit {
+		case "y":
+			a.Years = n
+		case "m":
+			a.Months = n
+		case "w":
+			a.Weeks = n
+		case "d":
+			a.Days = n
+		default:
+			return ParsedAge{}, false
+		}
+	}
+	return a, true
+}
+
+// String returns the age in GEDCOM syntax, such as "> 42y 6m".
+func (a ParsedAge) String() string {
+	if a.Keyword != "" {
+		return a.Keyword
+	}
+	var parts []string
+	for _, p := range []struct {
+		n    int
+		unit string
+	}{{a.Years, "y"}, {a.Months, "m"}, {a.Weeks, "w"}, {a.Days, "d"}} {
+		if p.n != 0 {
+			parts = append(parts, strconv.Itoa(p.n)+p.unit)
+		}
+	}
+	if len(parts) == 0 {
+		parts = append(parts, "0y")
+	}
+	s := strings.Join(parts, " ")
+	if a.Qualifier != "" {
+		s = a.Qualifier + " " + s
+	}
+	return s
+}
+
+// BirthDate estimates the date of birth of someone who was of age a on the date at,
+// returning the span of possible birth dates as a date value in the Gregorian calendar.
+// An age counts completed units so "42y" means at least 42 years and less than 43 years,
+// "42y 6m" at least 42 years and 6 months and less than 42 years and 7 months. A CHILD is
+// taken to be under 8 years old, an INFANT under 1 year and a STILLBORN child to be born
+// on the date itself. For example an age of 42y on 1 JAN 1900 gives BET 2 JAN 1857 AND
+// 1 JAN 1858. It reports false if at cannot be placed in time or has an open bound.
+func (a ParsedAge) BirthDate(at DateValue) (DateValue, bool) {
+	lo, hi, ok := at.bounds()
+	if !ok || lo == openLo || hi == openHi {
+		return DateValue{}, false
+	}
+
+	var earliest, latest int
+	switch a.Keyword {
+	case "STILLBORN":
+		earliest, latest = lo, hi
+	case "INFANT":
+		earliest, latest = subtractAge(lo, ParsedAge{Years: 1})+1, hi
+	case "CHILD":
+		earliest, latest = subtractAge(lo, ParsedAge{Years: 8})+1, hi
+	default:
+		// The age is less than one more of its smallest unit
+		next := a
+		switch {
+		case a.Days != 0:
+			next.Days++
+		case a.Weeks != 0:
+			next.Weeks++
+		case a.Months != 0:
+			next.Months++
+		default:
+			next.Years++
+		}
+		earliest, latest = subtractAge(lo, next)+1, subtractAge(hi, a)
+		switch a.Qualifier {
+		case "<":
+			earliest, latest = subtractAge(lo, a)+1, hi
+		case ">":
+			earliest = openLo
+		}
+	}
+
+	switch {
+	case earliest == openLo:
+		return DateValue{Qualifier: "BEF", Date1: dayDate(latest + 1)}, true
+	case earliest == latest:
+		return DateValue{Date1: dayDate(earliest)}, true
+	}
+	return DateValue{Qualifier: "BET", Date1: dayDate(earliest), Date2: dayDate(latest)}, true
+}
+
+// subtractAge returns the Julian day number of the day that is the age a before the day jd
+func subtractAge(jd int, a ParsedAge) int {
+	y, m, d := gregorianFromDay(jd)
+	t := time.Date(y, time.Month(m), d, 0, 0, 0, 0, time.UTC).AddDate(-a.Years, -a.Months, -7*a.Weeks-a.Days)
+	return gregorianDay(t.Year(), int(t.Month()), t.Day())
 }
@@ -122,6 +122,32 @@ func TestSplitPersonalName(t *testing.T) {
 				Nickname: "",
 			},
 		},
+		{
+			name: `Maria /Garcia/ /Lopez/`,
+			want: ParsedName{
+				Full:    "Maria Garcia Lopez",
+				Given:   "Maria",
+				Surname: "Garcia Lopez",
+			},
+		},
+		{
+			name: `Maria /Garcia/ /Lopez/ Jr`,
+			want: ParsedName{
+				Full:    "Maria Garcia Lopez Jr",
+				Given:   "Maria",
+				Surname: "Garcia Lopez",
+				Suffix:  "Jr",
+			},
+		},
+		{
+			name: `Maria /Garcia/ /Lopez`,
+			want: ParsedName{
+				Full:    "Maria Garcia /Lopez",
+				Given:   "Maria",
+				Surname: "Garcia",
+				Suffix:  "/Lopez",
+			},
+		},
 		{
 			name: `John "Jack" /Bryan/`,
 			want: ParsedName{
@@ -158,3 +184,265 @@ func TestSplitPersonalName(t *testing.T) {
 		})
 	}
 }
+
+func TestSplitPersonalNamePrefixes(t *testing.T) {
+	testCases := []struct {
+		name string
+		opts []NameOption
+		want ParsedName
+	}{
+		{
+			name: "Dr. John /Smith/",
+			want: ParsedName{Full: "Dr. John Smith", Prefix: "Dr.", Given: "John", Surname: "Smith"},
+		},
+		{
+			name: "Rev. Dr. Martin Luther /King/ Jr.",
+			want: ParsedName{Full: "Rev. Dr. Martin Luther King Jr.", Prefix: "Rev. Dr.", Given: "Martin Luther", Surname: "King", Suffix: "Jr."},
+		},
+		{
+			name: "Johannes /van der Berg/",
+			opts: []NameOption{WithSurnamePrefixes(DefaultSurnamePrefixes()...)},
+			want: ParsedName{Full: "Johannes van der Berg", Given: "Johannes", SurnamePrefix: "van der", Surname: "Berg"},
+		},
+		{
+			name: "Maria /de la Fuente/",
+			opts: []NameOption{WithSurnamePrefixes(DefaultSurnamePrefixes()...)},
+			want: ParsedName{Full: "Maria de la Fuente", Given: "Maria", SurnamePrefix: "de la", Surname: "Fuente"},
+		},
+		{
+			name: "Anne /Van/",
+			opts: []NameOption{WithSurnamePrefixes(DefaultSurnamePrefixes()...)},
+			want: ParsedName{Full: "Anne Van", Given: "Anne", Surname: "Van"},
+		},
+		{
+			name: "Ludwig /van Beethoven/",
+			want: ParsedName{Full: "Ludwig van Beethoven", Given: "Ludwig", Surname: "van Beethoven"},
+		},
+		{
+			name: "Mary /St John/",
+			opts: []NameOption{WithSurnamePrefixes(DefaultSurnamePrefixes()...)},
+			want: ParsedName{Full: "Mary St John", Given: "Mary", Surname: "St John"},
+		},
+		{
+			name: "Sir Walter",
+			want: ParsedName{Full: "Sir Walter", Prefix: "Sir", Given: "Walter"},
+		},
+		{
+			name: "Herr Karl /ter Horst/",
+			opts: []NameOption{WithNamePrefixes("Herr"), WithSurnamePrefixes("van")},
+			want: ParsedName{Full: "Herr Karl ter Horst", Prefix: "Herr", Given: "Karl", Surname: "ter Horst"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := SplitPersonalName(tc.name, tc.opts...); got != tc.want {
+				t.Errorf("got %+v, wanted %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSplitPersonalNameDoubleSurnames(t *testing.T) {
+	testCases := []struct {
+		name string
+		opts []NameOption
+		want ParsedName
+	}{
+		{
+			name: "Maria /Garcia Lopez/",
+			want: ParsedName{Full: "Maria Garcia Lopez", Given: "Maria", Surname: "Garcia Lopez"},
+		},
+		{
+			name: "Maria /Garcia Lopez/",
+			opts: []NameOption{WithDoubleSurnames(PaternalFirst)},
+			want: ParsedName{Full: "Maria Garcia Lopez", Given: "Maria", Surname: "Garcia Lopez", PaternalSurname: "Garcia", MaternalSurname: "Lopez"},
+		},
+		{
+			name: "Maria /Garcia/ /Lopez/",
+			opts: []NameOption{WithDoubleSurnames(PaternalFirst)},
+			want: ParsedName{Full: "Maria Garcia Lopez", Given: "Maria", Surname: "Garcia Lopez", PaternalSurname: "Garcia", MaternalSurname: "Lopez"},
+		},
+		{
+			name: "José /Ortega y Gasset/",
+			opts: []NameOption{WithDoubleSurnames(PaternalFirst)},
+			want: ParsedName{Full: "José Ortega y Gasset", Given: "José", Surname: "Ortega y Gasset", PaternalSurname: "Ortega", MaternalSurname: "Gasset"},
+		},
+		{
+			name: "Juan /Garcia de la Fuente/",
+			opts: []NameOption{WithDoubleSurnames(PaternalFirst)},
+			want: ParsedName{Full: "Juan Garcia de la Fuente", Given: "Juan", Surname: "Garcia de la Fuente", PaternalSurname: "Garcia", MaternalSurname: "de la Fuente"},
+		},
+		{
+			name: "Juan /Garcia de la Fuente/ /Lopez/",
+			opts: []NameOption{WithDoubleSurnames(PaternalFirst)},
+			want: ParsedName{Full: "Juan Garcia de la Fuente Lopez", Given: "Juan", Surname: "Garcia de la Fuente Lopez", PaternalSurname: "Garcia de la Fuente", MaternalSurname: "Lopez"},
+		},
+		{
+			name: "João /da Silva Santos/",
+			opts: []NameOption{WithDoubleSurnames(MaternalFirst), WithSurnamePrefixes(DefaultSurnamePrefixes()...)},
+			want: ParsedName{Full: "João da Silva Santos", Given: "João", SurnamePrefix: "da", Surname: "Silva Santos", PaternalSurname: "Santos", MaternalSurname: "da Silva"},
+		},
+		{
+			name: "Maria /Lopez/",
+			opts: []NameOption{WithDoubleSurnames(PaternalFirst)},
+			want: ParsedName{Full: "Maria Lopez", Given: "Maria", Surname: "Lopez"},
+		},
+		{
+			name: "Maria /Garcia Lopez Perez/",
+			opts: []NameOption{WithDoubleSurnames(PaternalFirst)},
+			want: ParsedName{Full: "Maria Garcia Lopez Perez", Given: "Maria", Surname: "Garcia Lopez Perez"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := SplitPersonalName(tc.name, tc.opts...); got != tc.want {
+				t.Errorf("got %+v, wanted %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseAge(t *testing.T) {
+	testCases := []struct {
+		age    string
+		want   ParsedAge
+		wantOK bool
+	}{
+		{age: "42y", want: ParsedAge{Years: 42}, wantOK: true},
+		{age: "42y 6m", want: ParsedAge{Years: 42, Months: 6}, wantOK: true},
+		{age: "42y 6m 9d", want: ParsedAge{Years: 42, Months: 6, Days: 9}, wantOK: true},
+		{age: "6m 9d", want: ParsedAge{Months: 6, Days: 9}, wantOK: true},
+		{age: "42d", want: ParsedAge{Days: 42}, wantOK: true},
+		{age: "> 30y", want: ParsedAge{Qualifier: ">", Years: 30}, wantOK: true},
+		{age: "<1y", want: ParsedAge{Qualifier: "<", Years: 1}, wantOK: true},
+		{age: "CHILD", want: ParsedAge{Keyword: "CHILD"}, wantOK: true},
+		{age: "stillborn", want: ParsedAge{Keyword: "STILLBORN"}, wantOK: true},
+		{age: "3w 2d", want: ParsedAge{Weeks: 3, Days: 2}, wantOK: true},
+		{age: "", wantOK: false},
+		{age: "42", wantOK: false},
+		{age: "42y 3y", wantOK: false},
+		{age: "about 42y", wantOK: false},
+		{age: ">", wantOK: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.age, func(t *testing.T) {
+			got, ok := ParseAge(tc.age)
+			if ok != tc.wantOK {
+				t.Fatalf("got ok %v, wanted %v", ok, tc.wantOK)
+			}
+			if got != tc.want {
+				t.Errorf("got %+v, wanted %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParsedAgeString(t *testing.T) {
+	for in, want := range map[string]string{"42y": "42y", ">30y 6m": "> 30y 6m", "< 3w 2d": "< 3w 2d", "infant": "INFANT"} {
+		a, ok := ParseAge(in)
+		if !ok {
+			t.Fatalf("could not parse %q", in)
+		}
+		if got := a.String(); got != want {
+			t.Errorf("got %q, wanted %q", got, want)
+		}
+	}
+}
+
+func TestParsedAgeBirthDate(t *testing.T) {
+	testCases := []struct {
+		age  string
+		at   string
+		want string
+	}{
+		{age: "42y", at: "1 JAN 1900", want: "BET 2 JAN 1857 AND 1 JAN 1858"},
+		{age: "42y 6m", at: "1 JAN 1900", want: "BET 2 JUN 1857 AND 1 JUL 1857"},
+		{age: "3d", at: "10 JAN 1900", want: "7 JAN 1900"},
+		{age: "< 1y", at: "1 JAN 1900", want: "BET 2 JAN 1899 AND 1 JAN 1900"},
+		{age: "> 30y", at: "1 JAN 1900", want: "BEF 2 JAN 1870"},
+		{age: "INFANT", at: "1 JAN 1900", want: "BET 2 JAN 1899 AND 1 JAN 1900"},
+		{age: "STILLBORN", at: "1 JAN 1900", want: "1 JAN 1900"},
+		{age: "42y", at: "1900", want: "BET 2 JAN 1857 AND 31 DEC 1858"},
+	}
+	for _, tc := range testCases {
+		a, _ := ParseAge(tc.age)
+		at, _ := ParseDate(tc.at)
+		got, ok := a.BirthDate(at)
+		if !ok {
+			t.Errorf("%s at %s: not ok", tc.age, tc.at)
+			continue
+		}
+		if got.String() != tc.want {
+			t.Errorf("%s at %s: got %q, wanted %q", tc.age, tc.at, got, tc.want)
+		}
+	}
+
+	a, _ := ParseAge("42y")
+	if _, ok := a.BirthDate(DateValue{Phrase: "unknown"}); ok {
+		t.Errorf("got ok for a date phrase")
+	}
+}
+
+func TestNameRecordParsed(t *testing.T) {
+	testCases := []struct {
+		desc string
+		name *NameRecord
+		want ParsedName
+	}{
+		{
+			desc: "value only",
+			name: &NameRecord{Name: "Dr. John /van Dyke/"},
+			want: ParsedName{Full: "Dr. John van Dyke", Prefix: "Dr.", Given: "John", Surname: "van Dyke"},
+		},
+		{
+			desc: "pieces override value",
+			name: &NameRecord{Name: "John Henry /Smith/", NamePieceGiven: "John", NamePieceNick: "Jack", NamePieceSuffix: "Jr"},
+			want: ParsedName{Full: "John Henry Smith", Given: "John", Surname: "Smith", Suffix: "Jr", Nickname: "Jack"},
+		},
+		{
+			desc: "pieces only",
+			name: &NameRecord{NamePiecePrefix: "Lt", NamePieceGiven: "Anne", NamePieceSurnamePrefix: "de", NamePieceSurname: "Vere"},
+			want: ParsedName{Full: "Lt Anne de Vere", Prefix: "Lt", Given: "Anne", SurnamePrefix: "de", Surname: "Vere"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			if got := tc.name.Parsed(); got != tc.want {
+				t.Errorf("got %+v, wanted %+v", got, tc.want)
+			}
+		})
+	}
+
+	v := &VariantNameRecord{Name: "Ivan /Petrov/", NamePieceSurname: "Petroff"}
+	if got := v.Parsed(); got.Given != "Ivan" || got.Surname != "Petroff" {
+		t.Errorf("got %+v for variant name", got)
+	}
+}
+
+func TestFormatPersonalName(t *testing.T) {
+	testCases := []struct {
+		pn   ParsedName
+		want string
+	}{
+		{pn: ParsedName{Given: "John", Surname: "Smith"}, want: "John /Smith/"},
+		{pn: ParsedName{Prefix: "Dr.", Given: "John", SurnamePrefix: "van", Surname: "Dyke", Suffix: "Jr", Nickname: "Jack"}, want: "Dr. John /van Dyke/ Jr"},
+		{pn: ParsedName{Given: "Walter"}, want: "Walter"},
+		{pn: ParsedName{Given: "Walter", Suffix: "III"}, want: "Walter // III"},
+		{pn: ParsedName{Surname: "Smith"}, want: "/Smith/"},
+	}
+	for _, tc := range testCases {
+		got := FormatPersonalName(tc.pn)
+		if got != tc.want {
+			t.Errorf("got %q, wanted %q", got, tc.want)
+		}
+		if tc.pn.Suffix == "" || tc.pn.Surname != "" {
+			back := SplitPersonalName(got, WithSurnamePrefixes(DefaultSurnamePrefixes()...))
+			if back.Given != tc.pn.Given || back.Surname != tc.pn.Surname || back.Suffix != tc.pn.Suffix {
+				t.Errorf("%q splits to %+v", got, back)
+			}
+		}
+	}
+}
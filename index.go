@@ -0,0 +1,150 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+
+package gedcom
+
+import (
+	"slices"
+)
+
+// An Index is a read-only view of a Gedcom with lookups of records by xref, individuals
+// by name and events by place. An Index is built once and never modified, so it may be
+// used by multiple goroutines concurrently without further synchronization, provided the
+// Gedcom it was built from is not modified while it is in use.
+type Index struct {
+	individuals  map[string]*IndividualRecord
+	families     map[string]*FamilyRecord
+	sources      map[string]*SourceRecord
+	repositories map[string]*RepositoryRecord
+	submitters   map[string]*SubmitterRecord
+	media        map[string]*MediaRecord
+	names        map[string][]*IndividualRecord
+	surnames     map[string][]*IndividualRecord
+	soundex      map[string][]*IndividualRecord
+	places       *PlaceIndex
+}
+
+// NewIndex builds an Index of the records in g.
+func NewIndex(g *Gedcom) *Index {
+	x := &Index{
+		individuals:  make(map[string]*IndividualRecord, len(g.Individual)),
+		families:     make(map[string]*FamilyRecord, len(g.Family)),
+		sources:      make(map[string]*SourceRecord, len(g.Source)),
+		repositories: make(map[string]*RepositoryRecord, len(g.Repository)),
+		submitters:   make(map[string]*SubmitterRecord, len(g.Submitter)),
+		media:        make(map[string]*MediaRecord, len(g.Media)),
+		names:        make(map[string][]*IndividualRecord),
+		surnames:     make(map[string][]*IndividualRecord),
+		soundex:      make(map[string][]*IndividualRecord),
+		places:       NewPlaceIndex(g),
+	}
+
+	for _, r := range g.Individual {
+		if r == nil {
+			continue
+		}
+		if r.Xref != "" {
+			x.individuals[r.Xref] = r
+		}
+
+		// Index each distinct name and surname once per individual
+		seen := make(map[string]bool)
+		add := func(m map[string][]*IndividualRecord, key string) {
+			key = normalizeLinkText(key)
+			if key == "" || seen[key] {
+				return
+			}
+			seen[key] = true
+			m[key] = append(m[key], r)
+		}
+		for _, n := range r.Name {
+			if n == nil {
+				continue
+			}
+			pn := SplitPersonalName(n.Name, splitSurnamePrefixes())
+			add(x.names, pn.Full)
+			add(x.surnames, pn.Surname)
+			add(x.surnames, pn.fullSurname())
+			add(x.surnames, n.NamePieceSurname)
+			add(x.soundex, n.SurnameSoundex())
+		}
+	}
+	for _, r := range g.Family {
+		if r != nil && r.Xref != "" {
+			x.families[r.Xref] = r
+		}
+	}
+	for _, r := range g.Source {
+		if r != nil && r.Xref != "" {
+			x.sources[r.Xref] = r
+		}
+	}
+	for _, r := range g.Repository {
+		if r != nil && r.Xref != "" {
+			x.repositories[r.Xref] = r
+		}
+	}
+	for _, r := range g.Submitter {
+		if r != nil && r.Xref != "" {
+			x.submitters[r.Xref] = r
+		}
+	}
+	for _, r := range g.Media {
+		if r != nil && r.Xref != "" {
+			x.media[r.Xref] = r
+		}
+	}
+	return x
+}
+
+// Individual returns the individual with the given xref or nil if there is none.
+func (x *Index) Individual(xref string) *IndividualRecord { return x.individuals[xref] }
+
+// Family returns the family with the given xref or nil if there is none.
+func (x *Index) Family(xref string) *FamilyRecord { return x.families[xref] }
+
+// Source returns the source with the given xref or nil if there is none.
+func (x *Index) Source(xref string) *SourceRecord { return x.sources[xref] }
+
+// Repository returns the repository with the given xref or nil if there is none.
+func (x *Index) Repository(xref string) *RepositoryRecord { return x.repositories[xref] }
+
+// Submitter returns the submitter with the given xref or nil if there is none.
+func (x *Index) Submitter(xref string) *SubmitterRecord { return x.submitters[xref] }
+
+// Media returns the multimedia object with the given xref or nil if there is none.
+func (x *Index) Media(xref string) *MediaRecord { return x.media[xref] }
+
+// IndividualsByName returns the individuals with a name matching name, which may be
+// written with or without slashes around the surname. Names are compared ignoring case
+// and differences in spacing.
+func (x *Index) IndividualsByName(name string) []*IndividualRecord {
+	return slices.Clone(x.names[normalizeLinkText(SplitPersonalName(name).Full)])
+}
+
+// IndividualsBySurname returns the individuals with a name having the given surname,
+// compared ignoring case.
+func (x *Index) IndividualsBySurname(surname string) []*IndividualRecord {
+	return slices.Clone(x.surnames[normalizeLinkText(surname)])
+}
+
+// IndividualsBySoundex returns the individuals with a name whose surname has the same
+// Soundex code as surname, such as Smith and Smyth. As with NameRecord.SurnameSoundex,
+// surname prefixes such as van or de are ignored. See Soundex.
+func (x *Index) IndividualsBySoundex(surname string) []*IndividualRecord {
+	_, surname = splitLeadingWords(surname, newNameConfig(nil).particles, 1)
+	return slices.Clone(x.soundex[normalizeLinkText(Soundex(surname))])
+}
+
+// EventsAtPlace returns the events that took place at place. See PlaceIndex.Lookup.
+func (x *Index) EventsAtPlace(place string) []EventEntry {
+	return slices.Clone(x.places.Lookup(place))
+}
+
+// IndividualsAtPlace returns the individuals with an event, or a family event, that took
+// place at place.
+func (x *Index) IndividualsAtPlace(place string) []*IndividualRecord {
+	return x.places.Individuals(place)
+}
@@ -0,0 +1,93 @@
+package gedcom
+
+import (
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestIndex(t *testing.T) {
+	input := `
+0 @I1@ INDI
+1 NAME John /Smith/
+1 BIRT
+2 PLAC Boston, Suffolk, Massachusetts
+0 @I2@ INDI
+1 NAME Mary /Smith/
+1 NAME Mary /Jones/
+0 @F1@ FAM
+1 HUSB @I1@
+1 WIFE @I2@
+1 MARR
+2 PLAC Salem, Essex, Massachusetts
+0 @S1@ SOUR
+1 TITL Parish register
+`
+	g, err := NewDecoder(strings.NewReader(input)).Decode()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	x := NewIndex(g)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if r := x.Individual("I2"); r == nil || r != g.Individual[1] {
+				t.Errorf("individual lookup failed")
+			}
+			if r := x.Family("F1"); r == nil || r.Husband != g.Individual[0] {
+				t.Errorf("family lookup failed")
+			}
+			if r := x.Source("S1"); r == nil || r.Title != "Parish register" {
+				t.Errorf("source lookup failed")
+			}
+			if r := x.Individual("I9"); r != nil {
+				t.Errorf("got individual for unknown xref")
+			}
+			if rs := x.IndividualsBySurname("SMITH"); len(rs) != 2 {
+				t.Errorf("got %d individuals with surname Smith, wanted 2", len(rs))
+			}
+			if rs := x.IndividualsBySurname("jones"); len(rs) != 1 || rs[0].Xref != "I2" {
+				t.Errorf("surname lookup for Jones failed")
+			}
+			if rs := x.IndividualsBySoundex("Smyth"); len(rs) != 2 {
+				t.Errorf("got %d individuals with surname sounding like Smyth, wanted 2", len(rs))
+			}
+			if rs := x.IndividualsByName("mary  jones"); len(rs) != 1 || rs[0].Xref != "I2" {
+				t.Errorf("name lookup failed")
+			}
+			if es := x.EventsAtPlace("boston, suffolk, massachusetts"); len(es) != 1 || es[0].Event.Tag != "BIRT" {
+				t.Errorf("place lookup failed")
+			}
+			if rs := x.IndividualsAtPlace("Salem, Essex, Massachusetts"); len(rs) != 2 {
+				t.Errorf("got %d individuals at Salem, wanted 2", len(rs))
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestIndexSoundexSurnamePrefix(t *testing.T) {
+	input := `
+0 @I1@ INDI
+1 NAME Ludwig /van Beethoven/
+0 @I2@ INDI
+1 NAME Johann /Bethoven/
+`
+	g, err := NewDecoder(strings.NewReader(input)).Decode()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	x := NewIndex(g)
+
+	for _, surname := range []string{"van Beethoven", "Beethoven", "VAN BEETHOVEN"} {
+		if rs := x.IndividualsBySoundex(surname); len(rs) != 2 {
+			t.Errorf("got %d individuals with surname sounding like %q, wanted 2", len(rs), surname)
+		}
+	}
+	if rs := x.IndividualsBySoundex("Vanbeethoven"); len(rs) != 0 {
+		t.Errorf("got %d individuals with surname sounding like Vanbeethoven, wanted 0", len(rs))
+	}
+}
@@ -0,0 +1,342 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+
+package gedcom
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// The JSON form of a Gedcom is reference safe: the top-level record lists hold the full
+// records while every other pointer to an individual, family, media object, source,
+// repository, submitter, submission or shared note that has an xref is written as the xref
+// string. Records without an xref, such as inline sources, are written in full wherever
+// they appear. Order is written as the list and index of each record it holds, so records
+// in Order that are not in one of the record lists are dropped. The record types
+// themselves have no JSON methods, so a record marshaled on its own is written in full.
+
+// recordTypes are the types of pointers to records that may be written as xrefs
+var recordTypes = map[reflect.Type]bool{
+	reflect.TypeOf((*IndividualRecord)(nil)): true,
+	reflect.TypeOf((*FamilyRecord)(nil)):     true,
+	reflect.TypeOf((*MediaRecord)(nil)):      true,
+	reflect.TypeOf((*SourceRecord)(nil)):     true,
+	reflect.TypeOf((*RepositoryRecord)(nil)): true,
+	reflect.TypeOf((*SubmitterRecord)(nil)):  true,
+	reflect.TypeOf((*SubmissionRecord)(nil)): true,
+	reflect.TypeOf((*NoteRecord)(nil)):       true,
+}
+
+var gedcomType = reflect.TypeOf(Gedcom{})
+
+// isRecordList reports whether f is one of the record lists of a Gedcom, whose records
+// are written in full
+func isRecordList(t reflect.Type, f reflect.StructField) bool {
+	return t == gedcomType && f.Type.Kind() == reflect.Slice && recordTypes[f.Type.Elem()]
+}
+
+// jsonOrder is the JSON form of an entry in the Order of a Gedcom: the name of the record
+// list holding the record and its index in the list
+type jsonOrder struct {
+	List  string
+	Index int
+}
+
+// MarshalJSON writes the Gedcom in its reference safe JSON form.
+func (g *Gedcom) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := writeJSON(&buf, reflect.ValueOf(g).Elem(), false, make(map[any]bool)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeJSON writes the JSON form of v to buf. If full is false, a pointer to a record with
+// an xref is written as the xref. The pointers being written are held in visiting so that
+// records without xrefs that refer to each other are reported as a cycle.
+func writeJSON(buf *bytes.Buffer, v reflect.Value, full bool, visiting map[any]bool) error {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			buf.WriteString("null")
+			return nil
+		}
+		if !full && recordTypes[v.Type()] {
+			if xref := v.Elem().FieldByName("Xref").String(); xref != "" {
+				return writeJSONValue(buf, xref)
+			}
+		}
+		p := v.Interface()
+		if visiting[p] {
+			return &json.UnsupportedValueError{Value: v, Str: fmt.Sprintf("encountered a cycle via %s", v.Type())}
+		}
+		visiting[p] = true
+		defer delete(visiting, p)
+		return writeJSON(buf, v.Elem(), false, visiting)
+	case reflect.Slice:
+		if v.IsNil() {
+			buf.WriteString("null")
+			return nil
+		}
+		buf.WriteByte('[')
+		for i := 0; i < v.Len(); i++ {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeJSON(buf, v.Index(i), full, visiting); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+		return nil
+	case reflect.Struct:
+		t := v.Type()
+		buf.WriteByte('{')
+		n := 0
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() || f.Tag.Get("json") == "-" {
+				continue
+			}
+			if n > 0 {
+				buf.WriteByte(',')
+			}
+			n++
+			writeJSONValue(buf, f.Name)
+			buf.WriteByte(':')
+			var err error
+			if t == gedcomType && f.Name == "Order" {
+				err = writeJSONValue(buf, jsonOrders(v.Addr().Interface().(*Gedcom)))
+			} else {
+				err = writeJSON(buf, v.Field(i), isRecordList(t, f), visiting)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+		return nil
+	}
+	return writeJSONValue(buf, v.Interface())
+}
+
+// writeJSONValue writes v to buf using encoding/json
+func writeJSONValue(buf *bytes.Buffer, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	buf.Write(data)
+	return nil
+}
+
+// jsonOrders returns the JSON form of the Order of g
+func jsonOrders(g *Gedcom) []jsonOrder {
+	if g.Order == nil {
+		return nil
+	}
+	index := make(map[Record]jsonOrder)
+	gv := reflect.ValueOf(g).Elem()
+	for i := 0; i < gedcomType.NumField(); i++ {
+		f := gedcomType.Field(i)
+		if !isRecordList(gedcomType, f) {
+			continue
+		}
+		lv := gv.Field(i)
+		for j := 0; j < lv.Len(); j++ {
+			if r, ok := lv.Index(j).Interface().(Record); ok {
+				index[r] = jsonOrder{List: f.Name, Index: j}
+			}
+		}
+	}
+	orders := make([]jsonOrder, 0, len(g.Order))
+	for _, r := range g.Order {
+		if o, ok := index[r]; ok {
+			orders = append(orders, o)
+		}
+	}
+	return orders
+}
+
+// UnmarshalJSON reads a Gedcom from its reference safe JSON form, re-linking every xref
+// reference to the corresponding top-level record.
+func (g *Gedcom) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return err
+	}
+	m, ok := v.(map[string]any)
+	if !ok {
+		type plain Gedcom
+		return json.Unmarshal(data, (*plain)(g))
+	}
+
+	var orders []jsonOrder
+	for k, ov := range m {
+		if !strings.EqualFold(k, "Order") {
+			continue
+		}
+		od, err := json.Marshal(ov)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(od, &orders); err != nil {
+			return err
+		}
+		delete(m, k)
+	}
+
+	expanded, err := json.Marshal(expandXrefs(gedcomType, m, false))
+	if err != nil {
+		return err
+	}
+	type plain Gedcom
+	if err := json.Unmarshal(expanded, (*plain)(g)); err != nil {
+		return err
+	}
+	g.Order = nil
+	if orders != nil {
+		g.Order = make([]Record, 0, len(orders))
+		gv := reflect.ValueOf(g).Elem()
+		for _, o := range orders {
+			f, ok := gedcomType.FieldByName(o.List)
+			if !ok || !isRecordList(gedcomType, f) {
+				continue
+			}
+			lv := gv.FieldByIndex(f.Index)
+			if o.Index < 0 || o.Index >= lv.Len() || lv.Index(o.Index).IsNil() {
+				continue
+			}
+			g.Order = append(g.Order, lv.Index(o.Index).Interface().(Record))
+		}
+	}
+	relinkRecords(g)
+	return nil
+}
+
+// expandXrefs replaces each xref written in place of a record in v, the decoded JSON form
+// of a value of type t, with an object holding only the xref, so that encoding/json can
+// decode it into a record for relinkRecords to replace. If full is false, v may be an
+// xref if t is a pointer to a record.
+func expandXrefs(t reflect.Type, v any, full bool) any {
+	switch t.Kind() {
+	case reflect.Pointer:
+		if xref, ok := v.(string); ok && !full && recordTypes[t] {
+			return map[string]any{"Xref": xref}
+		}
+		return expandXrefs(t.Elem(), v, false)
+	case reflect.Slice:
+		a, ok := v.([]any)
+		if !ok {
+			return v
+		}
+		for i := range a {
+			a[i] = expandXrefs(t.Elem(), a[i], full)
+		}
+		return a
+	case reflect.Struct:
+		m, ok := v.(map[string]any)
+		if !ok {
+			return v
+		}
+		for k, fv := range m {
+			f, ok := t.FieldByNameFunc(func(name string) bool { return strings.EqualFold(name, k) })
+			if !ok || !f.IsExported() {
+				continue
+			}
+			m[k] = expandXrefs(f.Type, fv, isRecordList(t, f))
+		}
+		return m
+	}
+	return v
+}
+
+// relinkRecords replaces every reference to a top-level record held in g with a pointer
+// to the record itself, so that the tree has the same shape as one produced by Decode.
+func relinkRecords(g *Gedcom) {
+	canon := make(map[reflect.Type]map[string]reflect.Value)
+	add := func(list any) {
+		lv := reflect.ValueOf(list)
+		for i := 0; i < lv.Len(); i++ {
+			rv := lv.Index(i)
+			if rv.IsNil() {
+				continue
+			}
+			xref := rv.Elem().FieldByName("Xref").String()
+			if xref == "" {
+				continue
+			}
+			if canon[rv.Type()] == nil {
+				canon[rv.Type()] = make(map[string]reflect.Value)
+			}
+			canon[rv.Type()][xref] = rv
+		}
+	}
+	add(g.Individual)
+	add(g.Family)
+	add(g.Media)
+	add(g.Source)
+	add(g.Repository)
+	add(g.Submitter)
+	add(g.Submission)
+	add(g.Note)
+
+	var walk func(v reflect.Value)
+	walk = func(v reflect.Value) {
+		switch v.Kind() {
+		case reflect.Pointer:
+			if v.IsNil() {
+				return
+			}
+			walk(v.Elem())
+		case reflect.Slice:
+			for i := 0; i < v.Len(); i++ {
+				relinkValue(v.Index(i), canon, walk)
+			}
+		case reflect.Struct:
+			for i := 0; i < v.NumField(); i++ {
+				if !v.Type().Field(i).IsExported() {
+					continue
+				}
+				relinkValue(v.Field(i), canon, walk)
+			}
+		}
+	}
+
+	// Walk the contents of each top-level record, leaving the records themselves in place
+	for _, list := range []any{g.Header, g.Individual, g.Family, g.Media, g.Source, g.Repository, g.Submitter, g.Submission, g.Note, g.UserDefined} {
+		lv := reflect.ValueOf(list)
+		if lv.Kind() == reflect.Slice {
+			for i := 0; i < lv.Len(); i++ {
+				walk(lv.Index(i))
+			}
+			continue
+		}
+		walk(lv)
+	}
+}
+
+// relinkValue replaces v with the canonical record it references, or walks into it if it is
+// not a reference
+func relinkValue(v reflect.Value, canon map[reflect.Type]map[string]reflect.Value, walk func(reflect.Value)) {
+	if v.Kind() == reflect.Pointer && !v.IsNil() {
+		if byXref, ok := canon[v.Type()]; ok {
+			xref := v.Elem().FieldByName("Xref").String()
+			if rec, ok := byXref[xref]; ok {
+				if v.CanSet() {
+					v.Set(rec)
+				}
+				return
+			}
+		}
+	}
+	walk(v)
+}
@@ -0,0 +1,188 @@
+package gedcom
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestJSONRoundTrip(t *testing.T) {
+	want, err := NewDecoder(bytes.NewReader(data)).Decode()
+	if err != nil {
+		t.Fatalf("decode gedcom: %v", err)
+	}
+
+	js, err := json.Marshal(want)
+	if err != nil {
+		t.Fatalf("marshal json: %v", err)
+	}
+
+	got := new(Gedcom)
+	if err := json.Unmarshal(js, got); err != nil {
+		t.Fatalf("unmarshal json: %v", err)
+	}
+
+	// References must point at the shared top-level records
+	indis := make(map[string]*IndividualRecord)
+	for _, r := range got.Individual {
+		indis[r.Xref] = r
+	}
+	for _, f := range got.Family {
+		if f.Husband != nil && indis[f.Husband.Xref] != f.Husband {
+			t.Errorf("family %s husband was not relinked to the individual record", f.Xref)
+		}
+		for _, c := range f.Child {
+			if indis[c.Xref] != c {
+				t.Errorf("family %s child %s was not relinked to the individual record", f.Xref, c.Xref)
+			}
+		}
+	}
+
+	// Encoding both trees must produce identical GEDCOM
+	wantBuf := new(bytes.Buffer)
+	if err := NewEncoder(wantBuf).Encode(want); err != nil {
+		t.Fatalf("encode original: %v", err)
+	}
+	gotBuf := new(bytes.Buffer)
+	if err := NewEncoder(gotBuf).Encode(got); err != nil {
+		t.Fatalf("encode unmarshaled: %v", err)
+	}
+	if diff := cmp.Diff(wantBuf.String(), gotBuf.String()); diff != "" {
+		t.Errorf("encoded gedcom mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestJSONReferences(t *testing.T) {
+	husb := &IndividualRecord{Xref: "I1"}
+	g := &Gedcom{
+		Individual: []*IndividualRecord{husb},
+		Family:     []*FamilyRecord{{Xref: "F1", Husband: husb}},
+	}
+	husb.Family = []*FamilyLinkRecord{{Family: g.Family[0]}}
+
+	js, err := json.Marshal(g)
+	if err != nil {
+		t.Fatalf("marshal json: %v", err)
+	}
+
+	var v struct {
+		Family []struct{ Husband string }
+	}
+	if err := json.Unmarshal(js, &v); err != nil {
+		t.Fatalf("unmarshal json: %v", err)
+	}
+	if len(v.Family) != 1 || v.Family[0].Husband != "I1" {
+		t.Errorf("got family %+v, wanted husband written as xref I1", v.Family)
+	}
+}
+
+func TestJSONSharedNotes(t *testing.T) {
+	note := &NoteRecord{Xref: "N1", Note: "shared"}
+	g := &Gedcom{
+		Individual: []*IndividualRecord{{Xref: "I1", Note: []*NoteRecord{note, {Note: "inline"}}}},
+		Note:       []*NoteRecord{note},
+	}
+
+	js, err := json.Marshal(g)
+	if err != nil {
+		t.Fatalf("marshal json: %v", err)
+	}
+
+	got := new(Gedcom)
+	if err := json.Unmarshal(js, got); err != nil {
+		t.Fatalf("unmarshal json: %v", err)
+	}
+	if len(got.Note) != 1 || got.Note[0].Note != "shared" {
+		t.Fatalf("got notes %+v, wanted shared note", got.Note)
+	}
+	notes := got.Individual[0].Note
+	if notes[0] != got.Note[0] {
+		t.Errorf("individual note was not relinked to the shared note")
+	}
+	if notes[1].Note != "inline" {
+		t.Errorf("got inline note %q, wanted %q", notes[1].Note, "inline")
+	}
+}
+
+func TestJSONSharedMedia(t *testing.T) {
+	media := &MediaRecord{Xref: "M1", File: []*FileRecord{{Name: "photo.jpg"}}}
+	g := &Gedcom{
+		Individual: []*IndividualRecord{{Xref: "I1", Media: []*MediaRecord{media}}},
+		Media:      []*MediaRecord{media},
+	}
+
+	js, err := json.Marshal(g)
+	if err != nil {
+		t.Fatalf("marshal json: %v", err)
+	}
+
+	got := new(Gedcom)
+	if err := json.Unmarshal(js, got); err != nil {
+		t.Fatalf("unmarshal json: %v", err)
+	}
+	if len(got.Media) != 1 || len(got.Media[0].File) != 1 {
+		t.Fatalf("got media %+v, wanted full media record", got.Media)
+	}
+	if got.Individual[0].Media[0] != got.Media[0] {
+		t.Errorf("individual media was not relinked to the media record")
+	}
+}
+
+func TestJSONRecordInFull(t *testing.T) {
+	r := &IndividualRecord{Xref: "I1", Sex: "M"}
+	js, err := json.Marshal(r)
+	if err != nil {
+		t.Fatalf("marshal json: %v", err)
+	}
+	var got IndividualRecord
+	if err := json.Unmarshal(js, &got); err != nil {
+		t.Fatalf("unmarshal json: %v", err)
+	}
+	if got.Xref != "I1" || got.Sex != "M" {
+		t.Errorf("got individual %+v from %s, wanted all fields", got, js)
+	}
+}
+
+func TestJSONCycle(t *testing.T) {
+	indi := &IndividualRecord{}
+	fam := &FamilyRecord{Husband: indi}
+	indi.Family = []*FamilyLinkRecord{{Family: fam}}
+	g := &Gedcom{Individual: []*IndividualRecord{indi}, Family: []*FamilyRecord{fam}}
+
+	_, err := json.Marshal(g)
+	var uerr *json.UnsupportedValueError
+	if !errors.As(err, &uerr) {
+		t.Fatalf("got error %v, wanted an unsupported value error", err)
+	}
+}
+
+func TestJSONOrder(t *testing.T) {
+	want, err := NewDecoder(bytes.NewReader(data)).Decode()
+	if err != nil {
+		t.Fatalf("decode gedcom: %v", err)
+	}
+	js, err := json.Marshal(want)
+	if err != nil {
+		t.Fatalf("marshal json: %v", err)
+	}
+	got := new(Gedcom)
+	if err := json.Unmarshal(js, got); err != nil {
+		t.Fatalf("unmarshal json: %v", err)
+	}
+
+	if len(got.Order) != len(want.Order) {
+		t.Fatalf("got %d records in order, wanted %d", len(got.Order), len(want.Order))
+	}
+	for i := range want.Order {
+		if xrefOf(got.Order[i]) != xrefOf(want.Order[i]) {
+			t.Errorf("order %d is %q, wanted %q", i, xrefOf(got.Order[i]), xrefOf(want.Order[i]))
+		}
+	}
+	if len(got.Individual) > 0 && !slices.Contains(got.Order, Record(got.Individual[0])) {
+		t.Errorf("order does not hold the individual records of the unmarshaled gedcom")
+	}
+}
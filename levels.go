@@ -0,0 +1,81 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+
+package gedcom
+
+// LevelJumpPolicy controls how the decoder handles a line whose level is more than one
+// greater than the level of the previous line, which is common in files edited by hand.
+// Such a line has no parent at the level below it, so its place in the structure is
+// ambiguous.
+type LevelJumpPolicy int
+
+const (
+	// LevelJumpWarn decodes the line at its level and reports a warning of kind
+	// WarningInvalidLevel. This is the default.
+	LevelJumpWarn LevelJumpPolicy = iota
+
+	// LevelJumpError stops decoding at the line and returns a StrictError wrapping
+	// ErrInvalidLevel. Decoders configured with WithStrict always use this policy.
+	LevelJumpError
+
+	// LevelJumpClamp decodes the line as though its level were one greater than the
+	// previous line, making it subordinate to that line, and reports a warning of kind
+	// WarningInvalidLevel. The lines subordinate to it are clamped by the same amount.
+	LevelJumpClamp
+)
+
+// WithLevelJumps configures how the decoder handles a line whose level is more than one
+// greater than the previous line.
+func WithLevelJumps(p LevelJumpPolicy) DecoderOption {
+	return decoderOptionFunc(func(d *Decoder) {
+		d.levelJumps = p
+	})
+}
+
+// levelMapping records the level of a line as read and as decoded
+type levelMapping struct {
+	read    int
+	decoded int
+}
+
+// checkLevel applies the decoder's LevelJumpPolicy to the current line, which may change
+// its level. The level of the line as read is recorded in st.readLevel and the level of
+// the previous line, if the current line jumps from it, in st.jumpFrom.
+func (d *Decoder) checkLevel(st *scanState) error {
+	s := st.s
+	st.readLevel = s.level
+	st.jumpFrom = -1
+	if n := len(st.levels); n > 0 && s.level > st.levels[n-1].read+1 {
+		st.jumpFrom = st.levels[n-1].read
+		if d.levelJumps == LevelJumpError {
+			return &StrictError{Err: ErrInvalidLevel, LineNumber: s.line, Tag: s.tag}
+		}
+	}
+
+	// Find the structure the line is subordinate to, using the levels as read
+	for len(st.levels) > 0 && st.levels[len(st.levels)-1].read >= s.level {
+		st.levels = st.levels[:len(st.levels)-1]
+	}
+	decoded := s.level
+	if n := len(st.levels); n > 0 && d.levelJumps == LevelJumpClamp {
+		decoded = min(s.level, st.levels[n-1].decoded+1)
+	}
+	st.levels = append(st.levels, levelMapping{read: s.level, decoded: decoded})
+	s.level = decoded
+	return nil
+}
+
+// warnLevel records a warning if the current line jumped more than one level
+func (d *Decoder) warnLevel(st *scanState) {
+	if st.jumpFrom < 0 {
+		return
+	}
+	s := st.s
+	if s.level != st.readLevel {
+		d.warn(WarningInvalidLevel, s.tag, s.value, "level %d is not subordinate to level %d, decoded as level %d", st.readLevel, st.jumpFrom, s.level)
+		return
+	}
+	d.warn(WarningInvalidLevel, s.tag, s.value, "level %d is not subordinate to level %d", st.readLevel, st.jumpFrom)
+}
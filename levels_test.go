@@ -0,0 +1,80 @@
+package gedcom
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestDecodeLevelJumps(t *testing.T) {
+	input := `0 HEAD
+1 CHAR UTF-8
+0 @I1@ INDI
+1 BIRT
+3 PLAC London
+4 MAP
+5 LATI N51.5
+5 LONG W0.1
+2 DATE 1 JAN 1900
+1 DEAT
+2 DATE 1950
+0 TRLR
+`
+
+	t.Run("warn", func(t *testing.T) {
+		d := NewDecoder(strings.NewReader(input))
+		if _, err := d.Decode(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := []Warning{
+			{Line: 5, Kind: WarningInvalidLevel, Tag: "PLAC", Value: "London", Record: "INDI", RecordXref: "I1", Message: "level 3 is not subordinate to level 1"},
+		}
+		if diff := cmp.Diff(want, d.Warnings()); diff != "" {
+			t.Errorf("warnings mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		_, err := NewDecoder(strings.NewReader(input), WithLevelJumps(LevelJumpError)).Decode()
+		if !errors.Is(err, ErrInvalidLevel) {
+			t.Fatalf("got error %v, wanted %v", err, ErrInvalidLevel)
+		}
+		var serr *StrictError
+		if !errors.As(err, &serr) || serr.LineNumber != 5 || serr.Tag != "PLAC" {
+			t.Errorf("got error %#v, wanted StrictError for PLAC on line 5", err)
+		}
+	})
+
+	t.Run("clamp", func(t *testing.T) {
+		d := NewDecoder(strings.NewReader(input), WithLevelJumps(LevelJumpClamp))
+		g, err := d.Decode()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := []Warning{
+			{Line: 5, Kind: WarningInvalidLevel, Tag: "PLAC", Value: "London", Record: "INDI", RecordXref: "I1", Message: "level 3 is not subordinate to level 1, decoded as level 2"},
+		}
+		if diff := cmp.Diff(want, d.Warnings()); diff != "" {
+			t.Errorf("warnings mismatch (-want +got):\n%s", diff)
+		}
+
+		if len(g.Individual) != 1 || len(g.Individual[0].Event) != 2 {
+			t.Fatalf("got %d individuals, wanted one with two events", len(g.Individual))
+		}
+		birt := g.Individual[0].Event[0]
+		wantBirt := &EventRecord{
+			Tag:   "BIRT",
+			Date:  "1 JAN 1900",
+			Place: PlaceRecord{Name: "London", Latitude: "N51.5", Longitude: "W0.1"},
+		}
+		if diff := cmp.Diff(wantBirt, birt, cmpopts.EquateEmpty()); diff != "" {
+			t.Errorf("birth mismatch (-want +got):\n%s", diff)
+		}
+		if deat := g.Individual[0].Event[1]; deat.Tag != "DEAT" || deat.Date != "1950" {
+			t.Errorf("got event %s %q, wanted DEAT 1950", deat.Tag, deat.Date)
+		}
+	})
+}
@@ -0,0 +1,48 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+
+package gedcom
+
+// LineLengthMode controls whether the decoder checks that lines are no longer than the
+// GEDCOM 5.5.1 limit of 255 characters, counting the level, xref, tag, value, delimiters
+// and line ending. Some programs refuse to import files with longer lines. GEDCOM 7 has
+// no limit, so lines of GEDCOM 7 data are never checked.
+type LineLengthMode int
+
+const (
+	// LineLengthIgnore decodes lines of any length without checking them. This is the
+	// default.
+	LineLengthIgnore LineLengthMode = iota
+
+	// LineLengthWarn decodes all lines and reports a warning of kind WarningLineTooLong
+	// for each line that is too long, giving its line number.
+	LineLengthWarn
+
+	// LineLengthError stops decoding at the first line that is too long and returns a
+	// StrictError wrapping ErrLineTooLong.
+	LineLengthError
+)
+
+// WithLineLengthCheck configures whether the decoder checks the length of each line of
+// the input, allowing producers of GEDCOM to verify that strict programs will accept
+// their files. Lines in records skipped by WithSkipRecords or WithOnlyRecords are not
+// checked.
+func WithLineLengthCheck(m LineLengthMode) DecoderOption {
+	return decoderOptionFunc(func(d *Decoder) {
+		d.lineLength = m
+	})
+}
+
+// checkLineLength applies the decoder's LineLengthMode to the current line of s
+func (d *Decoder) checkLineLength(s *Scanner) error {
+	if d.lineLength == LineLengthIgnore || d.version7 || s.chars <= DefaultMaxLineLength {
+		return nil
+	}
+	if d.lineLength == LineLengthError {
+		return &StrictError{Err: ErrLineTooLong, LineNumber: s.line, Tag: s.tag}
+	}
+	d.warn(WarningLineTooLong, s.tag, s.value, "line is %d characters long, more than the limit of %d", s.chars, DefaultMaxLineLength)
+	return nil
+}
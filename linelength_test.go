@@ -0,0 +1,61 @@
+package gedcom
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestDecodeLineLength(t *testing.T) {
+	// Lines of 255 characters are allowed, however many bytes they contain
+	input := "0 HEAD\n1 CHAR UTF-8\n0 @I1@ INDI\n" +
+		"1 NOTE " + strings.Repeat("a", 247) + "\n" +
+		"1 NOTE " + strings.Repeat("é", 247) + "\n" +
+		"1 NOTE " + strings.Repeat("b", 248) + "\n" +
+		"0 TRLR\n"
+
+	t.Run("ignore", func(t *testing.T) {
+		d := NewDecoder(strings.NewReader(input))
+		if _, err := d.Decode(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(d.Warnings()) != 0 {
+			t.Errorf("got warnings %v, wanted none", d.Warnings())
+		}
+	})
+
+	t.Run("warn", func(t *testing.T) {
+		d := NewDecoder(strings.NewReader(input), WithLineLengthCheck(LineLengthWarn))
+		if _, err := d.Decode(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := []Warning{
+			{Line: 6, Kind: WarningLineTooLong, Tag: "NOTE", Value: strings.Repeat("b", 248), Record: "INDI", RecordXref: "I1", Message: "line is 256 characters long, more than the limit of 255"},
+		}
+		if diff := cmp.Diff(want, d.Warnings()); diff != "" {
+			t.Errorf("warnings mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		_, err := NewDecoder(strings.NewReader(input), WithLineLengthCheck(LineLengthError)).Decode()
+		var serr *StrictError
+		if !errors.Is(err, ErrLineTooLong) || !errors.As(err, &serr) || serr.LineNumber != 6 {
+			t.Errorf("got error %v, wanted %v on line 6", err, ErrLineTooLong)
+		}
+	})
+
+	t.Run("gedcom 7", func(t *testing.T) {
+		input := "0 HEAD\n1 GEDC\n2 VERS 7.0\n0 @I1@ INDI\n1 NOTE " + strings.Repeat("c", 300) + "\n0 TRLR\n"
+		d := NewDecoder(strings.NewReader(input), WithLineLengthCheck(LineLengthError))
+		if _, err := d.Decode(); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if diff := cmp.Diff([]Warning(nil), d.Warnings(), cmpopts.EquateEmpty()); diff != "" {
+			t.Errorf("warnings mismatch (-want +got):\n%s", diff)
+		}
+	})
+}
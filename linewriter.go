@@ -0,0 +1,107 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+
+package gedcom
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// A LineWriter writes GEDCOM lines to an output stream. It is the counterpart of Scanner,
+// allowing programs that filter or transform data line by line to write the lines they
+// read without decoding them into records.
+type LineWriter struct {
+	e *Encoder
+}
+
+// NewLineWriter returns a new LineWriter that writes to w. The options that control how
+// lines are written apply: WithLineEnding, WithMaxLineLength, WithContinuation,
+// WithConcSplit, WithOutputCharset and WithTargetVersion, which removes the limit on the
+// length of lines when it names GEDCOM 7. Other options are ignored. Lines end with a line
+// feed unless configured otherwise.
+func NewLineWriter(w io.Writer, opts ...EncoderOption) *LineWriter {
+	e := NewEncoder(w, opts...)
+	e.begin(nil, LineEndingLF)
+	return &LineWriter{e: e}
+}
+
+// WriteLine writes l, which is formatted from its level, xref, tag and value. The value
+// is written as given, so pointers and escaped @ characters such as those read by a
+// Scanner are preserved. Newlines in the value are written as CONT lines and values too
+// long for a single line are split using CONC, as configured by the options given to
+// NewLineWriter. The line number, offset, length and raw text of l are ignored. Output
+// is buffered; call Flush once all lines have been written. Once an error has occurred it
+// is returned by every later call.
+func (lw *LineWriter) WriteLine(l Line) error {
+	e := lw.e
+	if e.err != nil {
+		return e.err
+	}
+	value := strings.ReplaceAll(l.Value, "\r\n", "\n")
+	conts := strings.Split(value, "\n")
+	lw.text(l.Level, l.Xref, l.Tag, conts[0])
+	for _, v := range conts[1:] {
+		lw.text(l.Level+1, "", "CONT", v)
+	}
+	return e.err
+}
+
+// Flush writes any buffered data to the underlying writer.
+func (lw *LineWriter) Flush() error {
+	return lw.e.flush()
+}
+
+// text writes a line, continuing a value that is too long using CONC
+func (lw *LineWriter) text(level int, xref string, tag string, value string) {
+	e := lw.e
+	limit := e.valueLimit(level, xref, tag)
+	if len(value) <= limit || e.v7 {
+		lw.line(level, xref, tag, value)
+		return
+	}
+	switch e.continuation {
+	case ContinueOnlyError:
+		e.err = fmt.Errorf("write line %s: line of %d bytes is too long to write without CONC", tag, len(value))
+		return
+	case ContinueOnlyTruncate:
+		lw.line(level, xref, tag, value[:keepEscape(value, runeBoundary(value, limit))])
+		return
+	}
+	n := keepEscape(value, e.splitConc(value, limit))
+	lw.line(level, xref, tag, value[:n])
+
+	limit = e.valueLimit(level+1, "", "CONC")
+	for value = value[n:]; value != ""; value = value[n:] {
+		n = len(value)
+		if n > limit {
+			n = keepEscape(value, e.splitConc(value, limit))
+		}
+		lw.line(level+1, "", "CONC", value[:n])
+	}
+}
+
+// line writes a single line
+func (lw *LineWriter) line(level int, xref string, tag string, value string) {
+	e := lw.e
+	if e.err != nil {
+		return
+	}
+	var b strings.Builder
+	b.WriteString(strconv.Itoa(level))
+	if xref != "" {
+		b.WriteString(" @" + xref + "@")
+	}
+	b.WriteString(" " + tag)
+	if value != "" {
+		b.WriteString(" " + value)
+	}
+	b.WriteString(e.eol)
+	if _, err := e.w.WriteString(b.String()); err != nil {
+		e.err = fmt.Errorf("write line %s: %w", tag, err)
+	}
+}
@@ -0,0 +1,89 @@
+package gedcom
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestLineWriter(t *testing.T) {
+	input := "0 HEAD\n1 CHAR UTF-8\n0 @I1@ INDI\n1 NAME John /Smith/\n1 FAMS @F1@\n1 NOTE Email john@@example.com\n0 @F1@ FAM\n1 HUSB @I1@\n0 TRLR\n"
+
+	var buf bytes.Buffer
+	lw := NewLineWriter(&buf)
+	s := NewScanner(strings.NewReader(input))
+	for s.Next() {
+		if err := lw.WriteLine(s.Line()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if err := s.Err(); err != nil {
+		t.Fatalf("unexpected scan error: %v", err)
+	}
+	if err := lw.Flush(); err != nil {
+		t.Fatalf("unexpected flush error: %v", err)
+	}
+	if got := buf.String(); got != input {
+		t.Errorf("got:\n%s\nwanted:\n%s", got, input)
+	}
+}
+
+func TestLineWriterContinuation(t *testing.T) {
+	testCases := []struct {
+		name string
+		opts []EncoderOption
+		line Line
+		want string
+	}{
+		{
+			name: "cont",
+			line: Line{Level: 1, Tag: "NOTE", Value: "first\nsecond\r\nthird"},
+			want: "1 NOTE first\n2 CONT second\n2 CONT third\n",
+		},
+		{
+			name: "conc",
+			opts: []EncoderOption{WithMaxLineLength(16)},
+			line: Line{Level: 0, Xref: "N1", Tag: "NOTE", Value: "abcdefghij"},
+			want: "0 @N1@ NOTE abc\n1 CONC defghij\n",
+		},
+		{
+			name: "escape kept together",
+			opts: []EncoderOption{WithMaxLineLength(11)},
+			line: Line{Level: 1, Tag: "NOTE", Value: "ab@@cdef"},
+			want: "1 NOTE ab\n2 CONC @@c\n2 CONC def\n",
+		},
+		{
+			name: "crlf",
+			opts: []EncoderOption{WithLineEnding(LineEndingCRLF)},
+			line: Line{Level: 2, Tag: "DATE", Value: "1 JAN 1900"},
+			want: "2 DATE 1 JAN 1900\r\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			lw := NewLineWriter(&buf, tc.opts...)
+			if err := lw.WriteLine(tc.line); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := lw.Flush(); err != nil {
+				t.Fatalf("unexpected flush error: %v", err)
+			}
+			if got := buf.String(); got != tc.want {
+				t.Errorf("got %q, wanted %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestLineWriterTooLong(t *testing.T) {
+	var buf bytes.Buffer
+	lw := NewLineWriter(&buf, WithMaxLineLength(16), WithContinuation(ContinueOnlyError))
+	if err := lw.WriteLine(Line{Level: 1, Tag: "NOTE", Value: strings.Repeat("x", 20)}); err == nil {
+		t.Fatalf("got no error, wanted one for a line that is too long")
+	}
+	if err := lw.WriteLine(Line{Level: 1, Tag: "NOTE", Value: "short"}); err == nil {
+		t.Errorf("got no error from a later line, wanted the first error")
+	}
+}
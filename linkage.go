@@ -0,0 +1,400 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+
+package gedcom
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// A LinkClass is the classification given to a pair of compared individuals.
+type LinkClass int
+
+const (
+	NonMatch      LinkClass = iota // the pair is very unlikely to be the same person
+	PossibleMatch                  // the pair may be the same person and should be reviewed
+	Match                          // the pair is very likely to be the same person
+)
+
+func (c LinkClass) String() string {
+	switch c {
+	case Match:
+		return "match"
+	case PossibleMatch:
+		return "possible"
+	default:
+		return "non-match"
+	}
+}
+
+// A BlockingKey selects how candidate pairs are grouped before comparison. Only
+// individuals that share a block are compared, which keeps linkage of large files tractable.
+type BlockingKey int
+
+const (
+	BlockSurname     BlockingKey = 1 << iota // only compare individuals with the same normalized surname
+	BlockBirthDecade                         // only compare individuals born in the same decade
+)
+
+// LinkageWeights assigns a relative weight to each field used when comparing two
+// individuals. A field with a zero weight is ignored.
+type LinkageWeights struct {
+	Given      float64
+	Surname    float64
+	Sex        float64
+	BirthDate  float64
+	BirthPlace float64
+	DeathDate  float64
+	DeathPlace float64
+}
+
+// LinkageConfig configures a record linkage run.
+type LinkageConfig struct {
+	Weights           LinkageWeights
+	Blocking          BlockingKey
+	MatchThreshold    float64 // minimum score for a pair to be classified as a Match
+	PossibleThreshold float64 // minimum score for a pair to be classified as a PossibleMatch
+	IncludeNonMatches bool    // include pairs classified as NonMatch in the results
+}
+
+// DefaultLinkageConfig returns a LinkageConfig with weights and thresholds that work
+// reasonably well for typical genealogical data.
+func DefaultLinkageConfig() LinkageConfig {
+	return LinkageConfig{
+		Weights: LinkageWeights{
+			Given:      3,
+			Surname:    3,
+			Sex:        1,
+			BirthDate:  2,
+			BirthPlace: 1,
+			DeathDate:  2,
+			DeathPlace: 1,
+		},
+		Blocking:          BlockSurname | BlockBirthDecade,
+		MatchThreshold:    0.85,
+		PossibleThreshold: 0.65,
+	}
+}
+
+// A LinkCandidate is a pair of individuals that were compared during record linkage.
+type LinkCandidate struct {
+	A     *IndividualRecord
+	B     *IndividualRecord
+	Score float64 // weighted similarity between 0 and 1
+	Class LinkClass
+}
+
+// LinkIndividuals compares the individuals in a with the individuals in b and returns
+// the classified candidate pairs, ordered by descending score. Each pair of individuals
+// that share a block is compared once.
+func LinkIndividuals(a, b *Gedcom, cfg LinkageConfig) []LinkCandidate {
+	left := linkProfiles(a.Individual)
+	right := linkProfiles(b.Individual)
+	idx := newBlockIndex(right, cfg.Blocking)
+
+	var cs []LinkCandidate
+	var js []int
+	for _, lp := range left {
+		js = idx.candidates(js[:0], lp)
+		for _, j := range js {
+			cs = appendCandidate(cs, lp, right[j], cfg)
+		}
+	}
+	sortCandidates(cs)
+	return cs
+}
+
+// FindDuplicates compares the individuals within g against each other and returns the
+// classified candidate pairs, ordered by descending score. Each pair of individuals that
+// share a block is compared once.
+func FindDuplicates(g *Gedcom, cfg LinkageConfig) []LinkCandidate {
+	ps := linkProfiles(g.Individual)
+	idx := newBlockIndex(ps, cfg.Blocking)
+
+	var cs []LinkCandidate
+	var js []int
+	for i, p := range ps {
+		js = idx.candidates(js[:0], p)
+		for _, j := range js {
+			if j <= i {
+				continue
+			}
+			cs = appendCandidate(cs, p, ps[j], cfg)
+		}
+	}
+	sortCandidates(cs)
+	return cs
+}
+
+// CompareIndividuals returns the weighted similarity of two individuals, between 0 and 1.
+func CompareIndividuals(a, b *IndividualRecord, w LinkageWeights) float64 {
+	return compareProfiles(newLinkProfile(a), newLinkProfile(b), w)
+}
+
+// linkProfile holds the normalized fields of an individual used for comparison
+type linkProfile struct {
+	rec        *IndividualRecord
+	given      string
+	surname    string
+	sex        string
+	birthDate  string
+	birthYear  int
+	birthPlace string
+	deathDate  string
+	deathYear  int
+	deathPlace string
+}
+
+func newLinkProfile(r *IndividualRecord) *linkProfile {
+	p := &linkProfile{
+		rec: r,
+		sex: strings.ToUpper(strings.TrimSpace(r.Sex)),
+	}
+	if len(r.Name) > 0 {
+		pn := SplitPersonalName(r.Name[0].Name)
+		p.given = normalizeLinkText(pn.Given)
+		p.surname = normalizeLinkText(pn.fullSurname())
+	}
+
+	if ev := firstEvent(r.Event, "BIRT", "CHR", "BAPM"); ev != nil {
+		p.birthDate = normalizeLinkText(ev.Date)
+		p.birthYear, _ = dateYear(ev.Date)
+		p.birthPlace = normalizeLinkText(ev.Place.Name)
+	}
+	if ev := firstEvent(r.Event, "DEAT", "BURI", "CREM"); ev != nil {
+		p.deathDate = normalizeLinkText(ev.Date)
+		p.deathYear, _ = dateYear(ev.Date)
+		p.deathPlace = normalizeLinkText(ev.Place.Name)
+	}
+	return p
+}
+
+func linkProfiles(rs []*IndividualRecord) []*linkProfile {
+	ps := make([]*linkProfile, 0, len(rs))
+	for _, r := range rs {
+		if r == nil {
+			continue
+		}
+		ps = append(ps, newLinkProfile(r))
+	}
+	return ps
+}
+
+// A linkBlock identifies the block an individual belongs to. The surname is empty unless
+// blocking by surname and the decade is zero unless blocking by birth decade and the year
+// of birth is known.
+type linkBlock struct {
+	surname string
+	decade  int
+}
+
+// blockIndex groups profiles by block so that each profile is compared only with the
+// profiles that share a block with it
+type blockIndex struct {
+	key       BlockingKey
+	blocks    map[linkBlock][]int // indexes of the profiles in each block
+	bySurname map[string][]int    // indexes of the profiles in each surname block, of any decade
+}
+
+func newBlockIndex(ps []*linkProfile, k BlockingKey) *blockIndex {
+	idx := &blockIndex{
+		key:       k,
+		blocks:    make(map[linkBlock][]int),
+		bySurname: make(map[string][]int),
+	}
+	for i, p := range ps {
+		b := idx.block(p)
+		idx.blocks[b] = append(idx.blocks[b], i)
+		idx.bySurname[b.surname] = append(idx.bySurname[b.surname], i)
+	}
+	return idx
+}
+
+// block returns the block p belongs to
+func (idx *blockIndex) block(p *linkProfile) linkBlock {
+	var b linkBlock
+	if idx.key&BlockSurname != 0 {
+		b.surname = p.surname
+	}
+	if idx.key&BlockBirthDecade != 0 && p.birthYear != 0 {
+		b.decade = p.birthYear / 10
+	}
+	return b
+}
+
+// candidates appends to js the indexes, in ascending order, of the profiles that share a
+// block with p. A profile with no year of birth shares a block with every profile born in
+// any decade, so it is listed once however many blocks it shares.
+func (idx *blockIndex) candidates(js []int, p *linkProfile) []int {
+	b := idx.block(p)
+	if b.decade == 0 {
+		return append(js, idx.bySurname[b.surname]...)
+	}
+	js = append(js, idx.blocks[b]...)
+	js = append(js, idx.blocks[linkBlock{surname: b.surname}]...)
+	sort.Ints(js)
+	return js
+}
+
+func appendCandidate(cs []LinkCandidate, a, b *linkProfile, cfg LinkageConfig) []LinkCandidate {
+	score := compareProfiles(a, b, cfg.Weights)
+	class := NonMatch
+	switch {
+	case score >= cfg.MatchThreshold:
+		class = Match
+	case score >= cfg.PossibleThreshold:
+		class = PossibleMatch
+	}
+	if class == NonMatch && !cfg.IncludeNonMatches {
+		return cs
+	}
+	return append(cs, LinkCandidate{A: a.rec, B: b.rec, Score: score, Class: class})
+}
+
+func sortCandidates(cs []LinkCandidate) {
+	sort.SliceStable(cs, func(i, j int) bool {
+		return cs[i].Score > cs[j].Score
+	})
+}
+
+func compareProfiles(a, b *linkProfile, w LinkageWeights) float64 {
+	var total, sum float64
+
+	add := func(weight float64, sim float64, ok bool) {
+		if weight == 0 || !ok {
+			return
+		}
+		total += weight
+		sum += weight * sim
+	}
+
+	add(w.Given, nameSimilarity(a.given, b.given), a.given != "" && b.given != "")
+	add(w.Surname, nameSimilarity(a.surname, b.surname), a.surname != "" && b.surname != "")
+	add(w.Sex, boolSimilarity(a.sex == b.sex), a.sex != "" && b.sex != "" && a.sex != "U" && b.sex != "U")
+	add(w.BirthDate, dateSimilarity(a.birthDate, b.birthDate, a.birthYear, b.birthYear), a.birthDate != "" && b.birthDate != "")
+	add(w.BirthPlace, placeSimilarity(a.birthPlace, b.birthPlace), a.birthPlace != "" && b.birthPlace != "")
+	add(w.DeathDate, dateSimilarity(a.deathDate, b.deathDate, a.deathYear, b.deathYear), a.deathDate != "" && b.deathDate != "")
+	add(w.DeathPlace, placeSimilarity(a.deathPlace, b.deathPlace), a.deathPlace != "" && b.deathPlace != "")
+
+	if total == 0 {
+		return 0
+	}
+	return sum / total
+}
+
+func boolSimilarity(v bool) float64 {
+	if v {
+		return 1
+	}
+	return 0
+}
+
+// nameSimilarity returns the normalized edit distance similarity of two names
+func nameSimilarity(a, b string) float64 {
+	if a == b {
+		return 1
+	}
+	ra, rb := []rune(a), []rune(b)
+	n := len(ra)
+	if len(rb) > n {
+		n = len(rb)
+	}
+	if n == 0 {
+		return 1
+	}
+	return 1 - float64(editDistance(ra, rb))/float64(n)
+}
+
+// dateSimilarity compares two dates, giving partial credit when only the years are close
+func dateSimilarity(a, b string, ya, yb int) float64 {
+	if a == b {
+		return 1
+	}
+	if ya == 0 || yb == 0 {
+		return 0
+	}
+	diff := ya - yb
+	if diff < 0 {
+		diff = -diff
+	}
+	switch diff {
+	case 0:
+		return 0.9
+	case 1:
+		return 0.7
+	case 2:
+		return 0.4
+	default:
+		return 0
+	}
+}
+
+// placeSimilarity compares two places, giving partial credit when the most specific parts agree
+func placeSimilarity(a, b string) float64 {
+	if a == b {
+		return 1
+	}
+	fa, _, _ := strings.Cut(a, ",")
+	fb, _, _ := strings.Cut(b, ",")
+	if strings.TrimSpace(fa) == strings.TrimSpace(fb) {
+		return 0.6
+	}
+	return 0
+}
+
+// editDistance returns the Levenshtein distance between a and b
+func editDistance(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+// normalizeLinkText lowercases s and collapses runs of whitespace
+func normalizeLinkText(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(s)), " ")
+}
+
+// firstEvent returns the first event matching one of the tags, in order of preference
+func firstEvent(evs []*EventRecord, tags ...string) *EventRecord {
+	for _, tag := range tags {
+		for _, ev := range evs {
+			if ev != nil && ev.Tag == tag {
+				return ev
+			}
+		}
+	}
+	return nil
+}
+
+// dateYear extracts the year from a GEDCOM date value, using the first year found.
+func dateYear(s string) (int, bool) {
+	for _, f := range strings.FieldsFunc(s, func(r rune) bool { return !unicode.IsDigit(r) }) {
+		if len(f) < 3 || len(f) > 4 {
+			continue
+		}
+		y, err := strconv.Atoi(f)
+		if err == nil {
+			return y, true
+		}
+	}
+	return 0, false
+}
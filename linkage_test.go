@@ -0,0 +1,108 @@
+package gedcom
+
+import (
+	"slices"
+	"testing"
+)
+
+func linkIndividual(xref, name, sex, birth, place string) *IndividualRecord {
+	return &IndividualRecord{
+		Xref: xref,
+		Name: []*NameRecord{{Name: name}},
+		Sex:  sex,
+		Event: []*EventRecord{
+			{Tag: "BIRT", Date: birth, Place: PlaceRecord{Name: place}},
+		},
+	}
+}
+
+func TestFindDuplicates(t *testing.T) {
+	g := &Gedcom{
+		Individual: []*IndividualRecord{
+			linkIndividual("I1", "John /Smith/", "M", "1 JAN 1850", "London, England"),
+			linkIndividual("I2", "Jon /Smith/", "M", "1850", "London, Middlesex, England"),
+			linkIndividual("I3", "Mary /Smith/", "F", "3 MAR 1851", "Leeds, England"),
+			linkIndividual("I4", "John /Smith/", "M", "1 JAN 1910", "London, England"),
+			linkIndividual("I5", "John /Jones/", "M", "1 JAN 1850", "London, England"),
+		},
+	}
+
+	cfg := DefaultLinkageConfig()
+	cs := FindDuplicates(g, cfg)
+	if len(cs) != 1 {
+		t.Fatalf("got %d candidates, wanted 1: %+v", len(cs), cs)
+	}
+	if cs[0].A.Xref != "I1" || cs[0].B.Xref != "I2" {
+		t.Errorf("got pair %s,%s, wanted I1,I2", cs[0].A.Xref, cs[0].B.Xref)
+	}
+	if cs[0].Class == NonMatch {
+		t.Errorf("got class %s, wanted match or possible", cs[0].Class)
+	}
+
+	cfg.IncludeNonMatches = true
+	cs = FindDuplicates(g, cfg)
+	for _, c := range cs {
+		if c.A.Xref == "I4" || c.B.Xref == "I4" {
+			t.Errorf("individual born in a different decade should have been blocked: %s,%s", c.A.Xref, c.B.Xref)
+		}
+		if c.A.Xref == "I5" || c.B.Xref == "I5" {
+			t.Errorf("individual with a different surname should have been blocked: %s,%s", c.A.Xref, c.B.Xref)
+		}
+	}
+}
+
+func TestFindDuplicatesBlocks(t *testing.T) {
+	g := &Gedcom{
+		Individual: []*IndividualRecord{
+			linkIndividual("I1", "John /Smith/", "M", "1850", ""),
+			linkIndividual("I2", "Mary /Smith/", "F", "1855", ""),
+			linkIndividual("I3", "Anne /Smith/", "F", "", ""),
+			linkIndividual("I4", "Jane /Smith/", "F", "1910", ""),
+			linkIndividual("I5", "John /Jones/", "M", "1850", ""),
+			linkIndividual("I6", "Paul /Jones/", "M", "", ""),
+		},
+	}
+
+	cfg := DefaultLinkageConfig()
+	cfg.IncludeNonMatches = true
+
+	// Every pair that is scored is returned, so the pairs show which were compared. An
+	// individual with no year of birth shares a block with those born in any decade.
+	var got []string
+	for _, c := range FindDuplicates(g, cfg) {
+		got = append(got, c.A.Xref+","+c.B.Xref)
+	}
+	slices.Sort(got)
+
+	want := []string{"I1,I2", "I1,I3", "I2,I3", "I3,I4", "I5,I6"}
+	if !slices.Equal(got, want) {
+		t.Errorf("got pairs %v, wanted %v", got, want)
+	}
+}
+
+func TestLinkIndividuals(t *testing.T) {
+	a := &Gedcom{
+		Individual: []*IndividualRecord{
+			linkIndividual("A1", "William /Brown/", "M", "12 MAY 1801", "Bath, Somerset"),
+		},
+	}
+	b := &Gedcom{
+		Individual: []*IndividualRecord{
+			linkIndividual("B1", "William /Brown/", "M", "12 MAY 1801", "Bath, Somerset"),
+			linkIndividual("B2", "Walter /Brown/", "M", "1806", "York"),
+		},
+	}
+
+	cfg := DefaultLinkageConfig()
+	cfg.IncludeNonMatches = true
+	cs := LinkIndividuals(a, b, cfg)
+	if len(cs) != 2 {
+		t.Fatalf("got %d candidates, wanted 2", len(cs))
+	}
+	if cs[0].B.Xref != "B1" || cs[0].Class != Match || cs[0].Score != 1 {
+		t.Errorf("got best candidate %s with class %s and score %v, wanted B1 match with score 1", cs[0].B.Xref, cs[0].Class, cs[0].Score)
+	}
+	if cs[1].Class != NonMatch {
+		t.Errorf("got class %s for B2, wanted non-match", cs[1].Class)
+	}
+}
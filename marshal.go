@@ -0,0 +1,59 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+
+package gedcom
+
+import (
+	"bytes"
+	"io"
+)
+
+// Marshal returns the GEDCOM encoding of g, written by an Encoder configured with opts.
+func Marshal(g *Gedcom, opts ...EncoderOption) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := NewEncoder(&buf, opts...).Encode(g); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Unmarshal decodes the GEDCOM data in data into g, read by a Decoder configured with
+// opts. Any existing contents of g are replaced.
+func Unmarshal(data []byte, g *Gedcom, opts ...DecoderOption) error {
+	dg, err := NewDecoder(bytes.NewReader(data), opts...).Decode()
+	if err != nil {
+		return err
+	}
+	*g = *dg
+	return nil
+}
+
+// WriteTo writes the GEDCOM encoding of g to w using an Encoder with the default options.
+// It returns the number of bytes written. It implements io.WriterTo.
+func (g *Gedcom) WriteTo(w io.Writer) (int64, error) {
+	cw := &countWriter{w: w}
+	err := NewEncoder(cw).Encode(g)
+	return cw.n, err
+}
+
+// String returns the GEDCOM encoding of g using an Encoder with the default options. Any
+// records that cannot be encoded are written only as far as the problem, see Encode.
+func (g *Gedcom) String() string {
+	var buf bytes.Buffer
+	NewEncoder(&buf).Encode(g)
+	return buf.String()
+}
+
+// countWriter is an io.Writer that counts the bytes written to w
+type countWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
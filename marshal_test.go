@@ -0,0 +1,78 @@
+package gedcom
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const marshalInput = `0 HEAD
+1 SOUR test
+1 GEDC
+2 VERS 5.5.1
+0 @I1@ INDI
+1 NAME John /Smith/
+1 FAMS @F1@
+0 @F1@ FAM
+1 HUSB @I1@
+0 TRLR
+`
+
+func TestMarshalUnmarshal(t *testing.T) {
+	var g Gedcom
+	if err := Unmarshal([]byte(marshalInput), &g); err != nil {
+		t.Fatalf("unexpected unmarshal error: %v", err)
+	}
+	if len(g.Individual) != 1 || len(g.Family) != 1 || g.Family[0].Husband != g.Individual[0] {
+		t.Fatalf("records not decoded and linked")
+	}
+
+	data, err := Marshal(&g)
+	if err != nil {
+		t.Fatalf("unexpected marshal error: %v", err)
+	}
+	if diff := cmp.Diff(marshalInput, string(data)); diff != "" {
+		t.Errorf("marshal output mismatch (-want +got):\n%s", diff)
+	}
+
+	data, err = Marshal(&g, WithLineEnding(LineEndingCRLF))
+	if err != nil {
+		t.Fatalf("unexpected marshal error: %v", err)
+	}
+	if !bytes.HasPrefix(data, []byte("0 HEAD\r\n")) {
+		t.Errorf("marshal options not applied: %q", data)
+	}
+}
+
+func TestUnmarshalError(t *testing.T) {
+	g := Gedcom{Individual: []*IndividualRecord{{Xref: "I9"}}}
+	if err := Unmarshal([]byte("0 HEAD\n1 NOTE x\n"), &g, WithStrict()); err == nil {
+		t.Fatalf("expected an unmarshal error")
+	}
+	if len(g.Individual) != 1 || g.Individual[0].Xref != "I9" {
+		t.Errorf("failed unmarshal modified the Gedcom")
+	}
+}
+
+func TestGedcomWriteTo(t *testing.T) {
+	var g Gedcom
+	if err := Unmarshal([]byte(marshalInput), &g); err != nil {
+		t.Fatalf("unexpected unmarshal error: %v", err)
+	}
+
+	buf := new(bytes.Buffer)
+	n, err := g.WriteTo(buf)
+	if err != nil {
+		t.Fatalf("unexpected write error: %v", err)
+	}
+	if n != int64(buf.Len()) {
+		t.Errorf("got count %d, wanted %d", n, buf.Len())
+	}
+	if got := buf.String(); got != marshalInput {
+		t.Errorf("got %q, wanted %q", got, marshalInput)
+	}
+	if got := g.String(); got != marshalInput {
+		t.Errorf("String() = %q, wanted %q", got, marshalInput)
+	}
+}
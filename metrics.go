@@ -0,0 +1,138 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+
+package gedcom
+
+import (
+	"sync"
+)
+
+// Metrics receives counts of the work done by a Decoder. Implementations may forward the
+// counts to a metrics system such as Prometheus. Lines and bytes are reported at the start
+// of each record and at the end of decoding rather than for every line. A Metrics may be
+// shared by many decoders so implementations should be safe for concurrent use.
+type Metrics interface {
+	AddLines(n int)         // lines scanned
+	AddBytes(n int64)       // bytes of input scanned
+	AddRecord(tag string)   // a level 0 record, such as INDI or FAM, was read
+	AddWarning(kind string) // a problem that did not stop decoding, one of the Warning constants
+	AddFixup(name string)   // a repair of malformed input, one of the Fixup constants
+}
+
+// Kinds of warning reported to Metrics.
+const (
+	WarningUnhandledTag = "unhandled-tag" // a tag was ignored by the decoder
+	WarningParseError   = "parse-error"   // a line could not be parsed and was skipped
+	WarningUnknownTag   = "unknown-tag"   // a tag is neither standard nor begins with an underscore
+	WarningInvalidLevel = "invalid-level" // a line's level is more than one greater than the previous line
+	WarningEmptyXref    = "empty-xref"    // a record or pointer has an empty xref
+	WarningInvalidDate  = "invalid-date"  // a date value could not be interpreted
+	WarningLineTooLong  = "line-too-long" // a line is longer than the GEDCOM 5.5.1 limit
+)
+
+// Names of fixups reported to Metrics.
+const (
+	FixupNoteNewline      = "note-newline"      // a NOTE value containing an unescaped newline was joined
+	FixupPublicationFacts = "publication-facts" // a DATE or PLAC under PUBL was merged into the publication facts
+)
+
+// WithMetrics configures the decoder to report counts of its work to m.
+func WithMetrics(m Metrics) DecoderOption {
+	return decoderOptionFunc(func(d *Decoder) {
+		d.metrics = m
+	})
+}
+
+// MetricsCounts is a Metrics that accumulates counts in memory. It is safe for concurrent
+// use. The zero value is ready to use.
+type MetricsCounts struct {
+	mu       sync.Mutex
+	lines    int64
+	bytes    int64
+	records  map[string]int64
+	warnings map[string]int64
+	fixups   map[string]int64
+}
+
+func (m *MetricsCounts) AddLines(n int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lines += int64(n)
+}
+
+func (m *MetricsCounts) AddBytes(n int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.bytes += n
+}
+
+func (m *MetricsCounts) AddRecord(tag string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.records = incrementCount(m.records, tag)
+}
+
+func (m *MetricsCounts) AddWarning(kind string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.warnings = incrementCount(m.warnings, kind)
+}
+
+func (m *MetricsCounts) AddFixup(name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.fixups = incrementCount(m.fixups, name)
+}
+
+// Lines returns the number of lines scanned.
+func (m *MetricsCounts) Lines() int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.lines
+}
+
+// Bytes returns the number of bytes scanned.
+func (m *MetricsCounts) Bytes() int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.bytes
+}
+
+// Records returns the number of records read of each type, keyed by tag.
+func (m *MetricsCounts) Records() map[string]int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return copyCounts(m.records)
+}
+
+// Warnings returns the number of warnings of each kind.
+func (m *MetricsCounts) Warnings() map[string]int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return copyCounts(m.warnings)
+}
+
+// Fixups returns the number of fixups of each kind that were applied.
+func (m *MetricsCounts) Fixups() map[string]int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return copyCounts(m.fixups)
+}
+
+func incrementCount(m map[string]int64, key string) map[string]int64 {
+	if m == nil {
+		m = make(map[string]int64)
+	}
+	m[key]++
+	return m
+}
+
+func copyCounts(m map[string]int64) map[string]int64 {
+	c := make(map[string]int64, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
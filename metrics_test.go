@@ -0,0 +1,34 @@
+package gedcom
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMetrics(t *testing.T) {
+	input := "0 HEAD\n1 CHAR UTF-8\n0 @I1@ INDI\n1 NAME John /Smith/\n1 NOTE first\nsecond\n0 @I2@ INDI\n0 @F1@ FAM\n0 TRLR\n"
+
+	m := &MetricsCounts{}
+	if _, err := NewDecoder(strings.NewReader(input), WithMetrics(m)).Decode(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Decode again to check the counts accumulate
+	if _, err := NewDecoder(strings.NewReader(input), WithMetrics(m)).Decode(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got, want := m.Lines(), int64(16); got != want {
+		t.Errorf("got %d lines, wanted %d", got, want)
+	}
+	if got, want := m.Bytes(), int64(2*len(input)); got != want {
+		t.Errorf("got %d bytes, wanted %d", got, want)
+	}
+	if diff := cmp.Diff(map[string]int64{"HEAD": 2, "INDI": 4, "FAM": 2, "TRLR": 2}, m.Records()); diff != "" {
+		t.Errorf("records mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(map[string]int64{FixupNoteNewline: 2}, m.Fixups()); diff != "" {
+		t.Errorf("fixups mismatch (-want +got):\n%s", diff)
+	}
+}
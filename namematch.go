@@ -0,0 +1,186 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+
+package gedcom
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Similarity scores given to words that do not match exactly
+const (
+	nicknameWordScore = 0.9  // given names that are nicknames of each other, see EquivalentGivenNames
+	phoneticWordScore = 0.85 // words with the same Soundex code
+	initialWordScore  = 0.75 // an initial and a word beginning with it
+)
+
+// NameSimilarity returns a score between 0 and 1 of how likely two names are to refer to
+// the same person, with 1 meaning the names are the same. Given names and surnames are
+// compared separately, ignoring case, accents, punctuation and the order of words, and
+// giving partial credit for words that are spelled similarly, sound alike or are initials,
+// and for given names that are nicknames of each other. Names whose parts were recorded
+// differently, such as a surname without slashes, are also compared as a whole. Name
+// pieces are used where present, see NameRecord.Parsed, and surname particles listed by
+// DefaultSurnamePrefixes are ignored. A component missing from either name is not
+// counted against the match.
+func NameSimilarity(a, b *NameRecord) float64 {
+	if a == nil || b == nil {
+		return 0
+	}
+	return parsedNameSimilarity(a.Parsed(splitSurnamePrefixes()), b.Parsed(splitSurnamePrefixes()))
+}
+
+func parsedNameSimilarity(a, b ParsedName) float64 {
+	ga, gb := matchWords(a.Given), matchWords(b.Given)
+	sa, sb := matchWords(a.Surname), matchWords(b.Surname)
+
+	var total, sum float64
+	if len(ga) > 0 && len(gb) > 0 {
+		total++
+		sum += wordsSimilarity(ga, gb, givenWordSimilarity)
+	}
+	if len(sa) > 0 && len(sb) > 0 {
+		total++
+		sum += wordsSimilarity(sa, sb, wordSimilarity)
+	}
+	var score float64
+	if total > 0 {
+		score = sum / total
+	}
+	if whole := wordsSimilarity(append(ga, sa...), append(gb, sb...), wordSimilarity); whole > score {
+		score = whole
+	}
+	return score
+}
+
+// wordsSimilarity pairs each word in the shorter list with its most similar unpaired
+// word in the longer list, in any order, and returns the total similarity divided by the
+// length of the longer list
+func wordsSimilarity(a, b []string, similarity func(a, b string) float64) float64 {
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	if len(b) == 0 {
+		return 0
+	}
+
+	used := make([]bool, len(b))
+	var sum float64
+	for _, wa := range a {
+		best, bestIdx := 0.0, -1
+		for j, wb := range b {
+			if used[j] {
+				continue
+			}
+			if sim := similarity(wa, wb); sim > best {
+				best, bestIdx = sim, j
+			}
+		}
+		if bestIdx >= 0 {
+			used[bestIdx] = true
+			sum += best
+		}
+	}
+	return sum / float64(len(b))
+}
+
+// wordSimilarity returns the similarity of two normalized words, the greatest of their
+// edit distance similarity and the scores for phonetic equivalence and initials
+func wordSimilarity(a, b string) float64 {
+	if a == b {
+		return 1
+	}
+	sim := nameSimilarity(a, b)
+	if sim < phoneticWordScore && Soundex(a) == Soundex(b) {
+		sim = phoneticWordScore
+	}
+	if sim < initialWordScore && (len(a) == 1 && strings.HasPrefix(b, a) || len(b) == 1 && strings.HasPrefix(a, b)) {
+		sim = initialWordScore
+	}
+	return sim
+}
+
+// givenWordSimilarity returns the similarity of two normalized words of given names,
+// giving credit for words that are nicknames of each other
+func givenWordSimilarity(a, b string) float64 {
+	sim := wordSimilarity(a, b)
+	if sim < nicknameWordScore && EquivalentGivenNames(a, b) {
+		sim = nicknameWordScore
+	}
+	return sim
+}
+
+// matchWords returns the words of s in lower case without accents, dropping apostrophes
+// and treating other punctuation as spaces
+func matchWords(s string) []string {
+	s = strings.Map(func(r rune) rune {
+		switch {
+		case r == '\'' || r == '’':
+			return -1
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			return unicode.ToLower(r)
+		default:
+			return ' '
+		}
+	}, StripDiacritics(s))
+	return strings.Fields(s)
+}
+
+// EqualNames reports whether two names have exactly the same value, type and name pieces.
+// Phonetic and romanized variants, citations, notes and user defined tags are not
+// compared. Two nil names are equal. EqualNames may be used as a comparer with go-cmp's
+// cmp.Comparer, as may the functions returned by EquivalentNames and SimilarNames.
+func EqualNames(a, b *NameRecord) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Name == b.Name &&
+		a.Type == b.Type &&
+		a.NamePiecePrefix == b.NamePiecePrefix &&
+		a.NamePieceGiven == b.NamePieceGiven &&
+		a.NamePieceNick == b.NamePieceNick &&
+		a.NamePieceSurnamePrefix == b.NamePieceSurnamePrefix &&
+		a.NamePieceSurname == b.NamePieceSurname &&
+		a.NamePieceSuffix == b.NamePieceSuffix
+}
+
+// EquivalentNames returns a function that reports whether two names have the same
+// components, as given by NameRecord.Parsed with opts, ignoring case and differences in
+// spacing. Names written differently but with the same pieces, such as "John /Smith/" and
+// a name with only the GIVN John and SURN SMITH, are equivalent. Two nil names are
+// equivalent.
+func EquivalentNames(opts ...NameOption) func(a, b *NameRecord) bool {
+	return func(a, b *NameRecord) bool {
+		if a == nil || b == nil {
+			return a == b
+		}
+		pa, pb := a.Parsed(opts...), b.Parsed(opts...)
+		for _, f := range [][2]string{
+			{pa.Prefix, pb.Prefix},
+			{pa.Given, pb.Given},
+			{pa.Nickname, pb.Nickname},
+			{pa.SurnamePrefix, pb.SurnamePrefix},
+			{pa.Surname, pb.Surname},
+			{pa.Suffix, pb.Suffix},
+		} {
+			if normalizeLinkText(f[0]) != normalizeLinkText(f[1]) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// SimilarNames returns a function that reports whether the NameSimilarity of two names is
+// at least threshold. Two nil names are similar.
+func SimilarNames(threshold float64) func(a, b *NameRecord) bool {
+	return func(a, b *NameRecord) bool {
+		if a == nil || b == nil {
+			return a == b
+		}
+		return NameSimilarity(a, b) >= threshold
+	}
+}
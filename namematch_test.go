@@ -0,0 +1,142 @@
+package gedcom
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNameSimilarity(t *testing.T) {
+	testCases := []struct {
+		a, b    *NameRecord
+		min     float64
+		max     float64
+		comment string
+	}{
+		{
+			a:   &NameRecord{Name: "John /Smith/"},
+			b:   &NameRecord{Name: "john  /SMITH/"},
+			min: 1, max: 1,
+			comment: "case and spacing",
+		},
+		{
+			a:   &NameRecord{Name: "José /Müller/"},
+			b:   &NameRecord{Name: "Jose /Muller/"},
+			min: 1, max: 1,
+			comment: "accents",
+		},
+		{
+			a:   &NameRecord{Name: "Mary Ann /Jones/"},
+			b:   &NameRecord{Name: "Ann Mary /Jones/"},
+			min: 1, max: 1,
+			comment: "reordered given names",
+		},
+		{
+			a:   &NameRecord{Name: "John Smith"},
+			b:   &NameRecord{Name: "John /Smith/"},
+			min: 1, max: 1,
+			comment: "surname without slashes",
+		},
+		{
+			a:   &NameRecord{Name: "John /Smith/"},
+			b:   &NameRecord{Name: "Jon /Smyth/"},
+			min: 0.8, max: 0.9,
+			comment: "spelling variants",
+		},
+		{
+			a:   &NameRecord{Name: "Catherine /Reid/"},
+			b:   &NameRecord{Name: "Kathryn /Reed/"},
+			min: 0.7, max: 0.9,
+			comment: "phonetic equivalents",
+		},
+		{
+			a:   &NameRecord{Name: "Peggy /Smith/"},
+			b:   &NameRecord{Name: "Margaret /Smith/"},
+			min: 0.95, max: 0.95,
+			comment: "nickname",
+		},
+		{
+			a:   &NameRecord{Name: "J. /Smith/"},
+			b:   &NameRecord{Name: "John /Smith/"},
+			min: 0.85, max: 0.9,
+			comment: "initial",
+		},
+		{
+			a:   &NameRecord{Name: "Anna /van der Berg/"},
+			b:   &NameRecord{NamePieceGiven: "Anna", NamePieceSurname: "Berg"},
+			min: 1, max: 1,
+			comment: "surname prefix and name pieces",
+		},
+		{
+			a:   &NameRecord{Name: "John /Smith/"},
+			b:   &NameRecord{Name: "Mary /Jones/"},
+			min: 0, max: 0.3,
+			comment: "different names",
+		},
+		{
+			a:   &NameRecord{Name: "John /Smith/"},
+			b:   nil,
+			min: 0, max: 0,
+			comment: "nil name",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.comment, func(t *testing.T) {
+			got := NameSimilarity(tc.a, tc.b)
+			if got < tc.min || got > tc.max {
+				t.Errorf("got %v, wanted between %v and %v", got, tc.min, tc.max)
+			}
+			if rev := NameSimilarity(tc.b, tc.a); rev != got {
+				t.Errorf("got %v with names reversed, wanted %v", rev, got)
+			}
+		})
+	}
+}
+
+func TestNameComparers(t *testing.T) {
+	john := &NameRecord{Name: "John /Smith/"}
+	testCases := []struct {
+		b          *NameRecord
+		equal      bool
+		equivalent bool
+		similar    bool
+	}{
+		{b: &NameRecord{Name: "John /Smith/"}, equal: true, equivalent: true, similar: true},
+		{b: &NameRecord{Name: "john  /SMITH/"}, equal: false, equivalent: true, similar: true},
+		{b: &NameRecord{NamePieceGiven: "John", NamePieceSurname: "Smith"}, equal: false, equivalent: true, similar: true},
+		{b: &NameRecord{Name: "John /Smith/", Type: "aka"}, equal: false, equivalent: true, similar: true},
+		{b: &NameRecord{Name: "Jon /Smyth/"}, equal: false, equivalent: false, similar: true},
+		{b: &NameRecord{Name: "Mary /Jones/"}, equal: false, equivalent: false, similar: false},
+		{b: nil, equal: false, equivalent: false, similar: false},
+	}
+
+	equivalent := EquivalentNames()
+	similar := SimilarNames(0.8)
+	for _, tc := range testCases {
+		if got := EqualNames(john, tc.b); got != tc.equal {
+			t.Errorf("EqualNames(%+v) = %v, wanted %v", tc.b, got, tc.equal)
+		}
+		if got := equivalent(john, tc.b); got != tc.equivalent {
+			t.Errorf("EquivalentNames(%+v) = %v, wanted %v", tc.b, got, tc.equivalent)
+		}
+		if got := similar(john, tc.b); got != tc.similar {
+			t.Errorf("SimilarNames(%+v) = %v, wanted %v", tc.b, got, tc.similar)
+		}
+	}
+}
+
+func TestNameComparersWithCmp(t *testing.T) {
+	a := &IndividualRecord{Name: []*NameRecord{{Name: "Peggy /Smith/"}}}
+	b := &IndividualRecord{Name: []*NameRecord{{Name: "Margaret /SMITH/", Type: "birth"}}}
+
+	if cmp.Equal(a, b, cmp.Comparer(EqualNames)) {
+		t.Errorf("individuals are equal comparing names exactly")
+	}
+	if cmp.Equal(a, b, cmp.Comparer(EquivalentNames())) {
+		t.Errorf("individuals are equal comparing name components")
+	}
+	if !cmp.Equal(a, b, cmp.Comparer(SimilarNames(0.9))) {
+		t.Errorf("individuals are not equal comparing similar names")
+	}
+}
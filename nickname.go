@@ -0,0 +1,194 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+
+package gedcom
+
+import (
+	"slices"
+	"strings"
+	"sync"
+)
+
+// builtinNicknames maps formal given names to their common nicknames and diminutives
+var builtinNicknames = map[string][]string{
+	"Abigail":     {"Abby", "Abbie", "Nabby"},
+	"Abraham":     {"Abe", "Bram"},
+	"Albert":      {"Al", "Bert", "Bertie"},
+	"Alexander":   {"Alec", "Alex", "Sandy", "Sandie", "Xander"},
+	"Alfred":      {"Alf", "Alfie", "Fred"},
+	"Alice":       {"Allie", "Elsie"},
+	"Andrew":      {"Andy", "Drew"},
+	"Ann":         {"Annie", "Nan", "Nancy", "Nanny"},
+	"Anne":        {"Annie", "Nan", "Nancy", "Nanny"},
+	"Anthony":     {"Tony"},
+	"Archibald":   {"Archie", "Baldie"},
+	"Arthur":      {"Art", "Artie"},
+	"Barbara":     {"Babs", "Barb", "Bab"},
+	"Benjamin":    {"Ben", "Benjy", "Benny"},
+	"Bridget":     {"Biddy", "Bridie", "Delia"},
+	"Caroline":    {"Carrie", "Caddie", "Lina"},
+	"Catherine":   {"Cathy", "Kate", "Kitty", "Katie", "Kit", "Cassie"},
+	"Charles":     {"Charlie", "Chas", "Chuck", "Chaz"},
+	"Christina":   {"Chris", "Chrissie", "Tina", "Kirsty"},
+	"Christopher": {"Chris", "Kit", "Kester"},
+	"Cornelius":   {"Neil", "Con", "Corney"},
+	"Daniel":      {"Dan", "Danny"},
+	"David":       {"Dave", "Davy", "Dai"},
+	"Deborah":     {"Debbie", "Deb", "Debby"},
+	"Dorothy":     {"Dolly", "Dot", "Dottie", "Dora"},
+	"Edward":      {"Ed", "Eddie", "Ned", "Ted", "Teddy"},
+	"Eleanor":     {"Ellie", "Nell", "Nellie", "Nora", "Elly"},
+	"Elizabeth":   {"Bess", "Bessie", "Beth", "Betsy", "Betty", "Eliza", "Libby", "Lisa", "Liz", "Lizzie", "Elsie", "Tetty"},
+	"Ellen":       {"Nell", "Nellie"},
+	"Ezekiel":     {"Zeke"},
+	"Frances":     {"Fanny", "Fran", "Frankie"},
+	"Francis":     {"Frank", "Frankie"},
+	"Frederick":   {"Fred", "Freddie", "Fritz"},
+	"Gabriel":     {"Gabe"},
+	"Geoffrey":    {"Geoff", "Jeff"},
+	"George":      {"Georgie", "Geordie"},
+	"Gertrude":    {"Gertie", "Trudy"},
+	"Gilbert":     {"Gil", "Bert"},
+	"Harold":      {"Hal", "Harry"},
+	"Helen":       {"Nell", "Nellie", "Lena"},
+	"Henrietta":   {"Etta", "Hetty", "Hettie", "Nettie"},
+	"Henry":       {"Hal", "Hank", "Harry", "Hen"},
+	"Herbert":     {"Bert", "Herb"},
+	"Hezekiah":    {"Hez", "Kiah"},
+	"Isaac":       {"Ike", "Zack"},
+	"Isabella":    {"Bella", "Isa", "Izzy", "Tibbie"},
+	"Jacob":       {"Jake", "Jack"},
+	"James":       {"Jim", "Jimmy", "Jamie", "Jem", "Jas"},
+	"Jane":        {"Jenny", "Jennie", "Jinny", "Janey"},
+	"Janet":       {"Jenny", "Jennie", "Jessie", "Netta"},
+	"Jeremiah":    {"Jerry", "Jem"},
+	"Johanna":     {"Hannah", "Jo", "Josie"},
+	"John":        {"Jack", "Johnny", "Jock", "Jon", "Hank"},
+	"Jonathan":    {"Jon", "Jonny", "Nathan"},
+	"Joseph":      {"Joe", "Joey", "Jos", "Jody"},
+	"Josephine":   {"Jo", "Josie", "Phenie"},
+	"Katherine":   {"Kathy", "Kate", "Kitty", "Katie", "Kit"},
+	"Lawrence":    {"Larry", "Laurie", "Lawrie"},
+	"Leonard":     {"Len", "Lenny", "Leo"},
+	"Lewis":       {"Lew", "Lou"},
+	"Louis":       {"Lou", "Louie"},
+	"Louisa":      {"Lou", "Lulu", "Louie"},
+	"Magdalena":   {"Lena", "Maggie", "Madge"},
+	"Margaret":    {"Maggie", "Madge", "Meg", "Megan", "Peg", "Peggy", "Daisy", "Greta", "Gretchen", "Marge", "Margie", "May", "Molly"},
+	"Martha":      {"Marty", "Mattie", "Patsy", "Patty"},
+	"Mary":        {"Mamie", "May", "Molly", "Polly", "Mollie", "Minnie", "Mae"},
+	"Matilda":     {"Mattie", "Tilda", "Tilly", "Maud"},
+	"Matthew":     {"Matt", "Mat", "Matty"},
+	"Michael":     {"Mick", "Mickey", "Mike", "Mikey"},
+	"Nathaniel":   {"Nat", "Nathan", "Natty"},
+	"Nicholas":    {"Nick", "Nicky", "Claus"},
+	"Patricia":    {"Pat", "Patsy", "Patty", "Tricia", "Trish"},
+	"Patrick":     {"Pat", "Paddy", "Patsy"},
+	"Peter":       {"Pete", "Pate"},
+	"Philip":      {"Phil", "Pip"},
+	"Rebecca":     {"Becky", "Becca", "Reba"},
+	"Richard":     {"Dick", "Dickie", "Rich", "Richie", "Rick", "Ricky"},
+	"Robert":      {"Bob", "Bobby", "Rob", "Robbie", "Robin", "Bert", "Rab", "Dob"},
+	"Roger":       {"Hodge", "Rodge"},
+	"Ronald":      {"Ron", "Ronnie"},
+	"Samuel":      {"Sam", "Sammy"},
+	"Sarah":       {"Sal", "Sally", "Sadie", "Sara"},
+	"Solomon":     {"Sol", "Solly"},
+	"Stephen":     {"Steve", "Steven", "Stevie"},
+	"Susannah":    {"Sue", "Susie", "Sukey", "Susan", "Hannah"},
+	"Theodore":    {"Ted", "Teddy", "Theo"},
+	"Thomas":      {"Tom", "Tommy", "Thom"},
+	"Timothy":     {"Tim", "Timmy"},
+	"Victoria":    {"Vicky", "Tori"},
+	"Walter":      {"Walt", "Wat", "Wally"},
+	"William":     {"Bill", "Billy", "Will", "Willie", "Willy", "Liam", "Wim"},
+	"Winifred":    {"Win", "Winnie", "Freda"},
+	"Zachariah":   {"Zach", "Zack", "Zeke"},
+}
+
+// nicknameRegistry maps each nickname, in lower case, to the formal names it is short for
+var nicknameRegistry = struct {
+	sync.RWMutex
+	formal map[string][]string
+}{
+	formal: make(map[string][]string),
+}
+
+func init() {
+	for formal, nicknames := range builtinNicknames {
+		registerNicknames(formal, nicknames)
+	}
+}
+
+// RegisterNicknames adds nicknames to the table used by EquivalentGivenNames as nicknames
+// of the formal given name, in addition to any already known. Names are not case
+// sensitive.
+func RegisterNicknames(formal string, nicknames ...string) {
+	nicknameRegistry.Lock()
+	defer nicknameRegistry.Unlock()
+	registerNicknames(formal, nicknames)
+}
+
+func registerNicknames(formal string, nicknames []string) {
+	formal = nicknameKey(formal)
+	for _, n := range nicknames {
+		n = nicknameKey(n)
+		if !slices.Contains(nicknameRegistry.formal[n], formal) {
+			nicknameRegistry.formal[n] = append(nicknameRegistry.formal[n], formal)
+		}
+	}
+}
+
+// FormalGivenNames returns the formal given names that nickname is known to be short for,
+// in lower case, or nil if it is not a known nickname.
+func FormalGivenNames(nickname string) []string {
+	nicknameRegistry.RLock()
+	defer nicknameRegistry.RUnlock()
+	return slices.Clone(nicknameRegistry.formal[nicknameKey(nickname)])
+}
+
+// EquivalentGivenNames reports whether two given names may be the same name, either
+// because they are equal ignoring case and accents, because one is a known nickname of the
+// other, such as Peggy and Margaret, or because both are nicknames of the same name, such
+// as Peggy and Meg. Given names with several words are equivalent when each word is
+// equivalent to the word in the same position in the other name. See RegisterNicknames.
+func EquivalentGivenNames(a, b string) bool {
+	wa, wb := matchWords(a), matchWords(b)
+	if len(wa) != len(wb) || len(wa) == 0 {
+		return false
+	}
+	nicknameRegistry.RLock()
+	defer nicknameRegistry.RUnlock()
+	for i := range wa {
+		if !equivalentGivenWords(wa[i], wb[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// equivalentGivenWords reports whether two normalized words are equal, one is a nickname
+// of the other or both are nicknames of the same name. The caller must hold a read lock on
+// the registry.
+func equivalentGivenWords(a, b string) bool {
+	if a == b {
+		return true
+	}
+	fa, fb := nicknameRegistry.formal[a], nicknameRegistry.formal[b]
+	if slices.Contains(fa, b) || slices.Contains(fb, a) {
+		return true
+	}
+	for _, f := range fa {
+		if slices.Contains(fb, f) {
+			return true
+		}
+	}
+	return false
+}
+
+// nicknameKey returns the normalized form of a given name used as a key of the registry
+func nicknameKey(s string) string {
+	return strings.Join(matchWords(s), " ")
+}
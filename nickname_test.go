@@ -0,0 +1,44 @@
+package gedcom
+
+import (
+	"testing"
+)
+
+func TestEquivalentGivenNames(t *testing.T) {
+	testCases := []struct {
+		a, b string
+		want bool
+	}{
+		{a: "Margaret", b: "Peggy", want: true},
+		{a: "jack", b: "JOHN", want: true},
+		{a: "Peggy", b: "Meg", want: true},
+		{a: "José", b: "jose", want: true},
+		{a: "Mary Ann", b: "Polly Nancy", want: true},
+		{a: "Mary Ann", b: "Mary", want: false},
+		{a: "Jack", b: "Meg", want: false},
+		{a: "John", b: "Jacob", want: false},
+		{a: "", b: "", want: false},
+	}
+
+	for _, tc := range testCases {
+		if got := EquivalentGivenNames(tc.a, tc.b); got != tc.want {
+			t.Errorf("EquivalentGivenNames(%q, %q) = %v, wanted %v", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
+
+func TestRegisterNicknames(t *testing.T) {
+	if EquivalentGivenNames("Hamish", "James") {
+		t.Fatalf("Hamish is equivalent to James before registration")
+	}
+	RegisterNicknames("James", "Hamish")
+	if !EquivalentGivenNames("hamish", "JAMES") {
+		t.Errorf("Hamish is not equivalent to James after registration")
+	}
+	if !EquivalentGivenNames("Hamish", "Jim") {
+		t.Errorf("Hamish is not equivalent to Jim after registration")
+	}
+	if got := FormalGivenNames("Hamish"); len(got) != 1 || got[0] != "james" {
+		t.Errorf("FormalGivenNames(Hamish) = %q, wanted [james]", got)
+	}
+}
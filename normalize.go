@@ -0,0 +1,260 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+
+package gedcom
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Fixups reported in a NormalizeChange.
+const (
+	NormalizeXref       = "xref"        // surrounding spaces and @ signs removed from an xref
+	NormalizeWhitespace = "whitespace"  // leading and trailing whitespace removed from a value
+	NormalizeDateCase   = "date-case"   // date keywords and months converted to upper case
+	NormalizeSex        = "sex"         // sex value converted to one of M, F or U
+	NormalizeEmpty      = "empty"       // a substructure holding no data was removed
+	NormalizeVendorDate = "vendor-date" // a date rewritten in GEDCOM date syntax
+	NormalizeBadDate    = "bad-date"    // a date that could not be interpreted, left unchanged
+)
+
+// NormalizeConfig selects the fixups applied by Normalize.
+type NormalizeConfig struct {
+	TrimXrefs      bool // remove spaces and @ signs surrounding xrefs
+	TrimWhitespace bool // remove whitespace at the start and end of values and lines of text
+	UpperDates     bool // convert date keywords and month names to upper case
+	FixSex         bool // convert sex values such as "male" or "f" to M, F or U
+	RemoveEmpty    bool // remove substructures that hold no data
+
+	// VendorDates rewrites dates written in the styles of genealogy programs, such as
+	// "Abt. 1900" or "March 3, 1900", in GEDCOM date syntax using NormalizeDate. Dates that
+	// cannot be interpreted are reported with the NormalizeBadDate fixup.
+	VendorDates bool
+}
+
+// DefaultNormalizeConfig returns a NormalizeConfig that applies every fixup except
+// VendorDates, which interprets dates that do not follow the GEDCOM grammar and may not
+// read them as their author intended.
+func DefaultNormalizeConfig() NormalizeConfig {
+	return NormalizeConfig{
+		TrimXrefs:      true,
+		TrimWhitespace: true,
+		UpperDates:     true,
+		FixSex:         true,
+		RemoveEmpty:    true,
+	}
+}
+
+// A NormalizeChange describes a single change made by Normalize.
+type NormalizeChange struct {
+	Path   string // location of the value, e.g. "Individual[2].Event[0].Date"
+	Fixup  string // the fixup that was applied, one of the Normalize constants
+	Before string
+	After  string // empty when a structure was removed, the same as Before for a bad date
+}
+
+// Normalize standardises the values held by the Gedcom by applying the fixups selected by
+// cfg, returning a description of each change made. It is intended to be called once on
+// newly decoded data before it is stored or compared. The top-level record lists, Header
+// and Trailer are always retained.
+func (g *Gedcom) Normalize(cfg NormalizeConfig) []NormalizeChange {
+	n := &normalizer{
+		cfg:     cfg,
+		visited: make(map[uintptr]bool),
+		v7:      g.Header != nil && isVersion7(g.Header.Version),
+	}
+
+	gv := reflect.ValueOf(g).Elem()
+	for i := 0; i < gv.NumField(); i++ {
+		name := gv.Type().Field(i).Name
+		f := gv.Field(i)
+		switch f.Kind() {
+		case reflect.Pointer:
+			if !f.IsNil() {
+				n.visited[f.Pointer()] = true
+				n.walk(name, f.Elem())
+			}
+		case reflect.Slice:
+			// Elements of the top-level lists are the records themselves, not references
+			for j := 0; j < f.Len(); j++ {
+				e := f.Index(j)
+				if e.Kind() == reflect.Pointer && !e.IsNil() {
+					n.visited[e.Pointer()] = true
+					e = e.Elem()
+				}
+				n.walk(name+"["+strconv.Itoa(j)+"]", e)
+			}
+		}
+	}
+	return n.changes
+}
+
+type normalizer struct {
+	cfg     NormalizeConfig
+	visited map[uintptr]bool
+	changes []NormalizeChange
+	v7      bool // whether dates are written in GEDCOM 7 syntax
+}
+
+func (n *normalizer) add(path, fixup, before, after string) {
+	n.changes = append(n.changes, NormalizeChange{Path: path, Fixup: fixup, Before: before, After: after})
+}
+
+// walk normalizes the value held by v, which must be addressable
+func (n *normalizer) walk(path string, v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() || n.visited[v.Pointer()] {
+			return
+		}
+		// References to records are normalized with the record itself
+		if _, ok := recordXref(v); ok {
+			return
+		}
+		n.visited[v.Pointer()] = true
+		n.walk(path, v.Elem())
+	case reflect.Slice:
+		n.slice(path, v)
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			sf := v.Type().Field(i)
+			if !sf.IsExported() {
+				continue
+			}
+			f := v.Field(i)
+			fp := path + "." + sf.Name
+			if f.Kind() == reflect.String {
+				n.value(fp, v.Type(), sf.Name, f)
+				continue
+			}
+			n.walk(fp, f)
+			if n.cfg.RemoveEmpty && f.Kind() == reflect.Pointer && !f.IsNil() && f.Elem().Kind() == reflect.Struct && f.Elem().IsZero() {
+				n.add(fp, NormalizeEmpty, "{"+f.Elem().Type().Name()+"}", "")
+				f.Set(reflect.Zero(f.Type()))
+			}
+		}
+	}
+}
+
+// slice normalizes each element of the slice held by v and removes elements that point
+// to empty structures
+func (n *normalizer) slice(path string, v reflect.Value) {
+	if v.Kind() != reflect.Slice || v.IsNil() {
+		return
+	}
+	out := 0
+	for i := 0; i < v.Len(); i++ {
+		e := v.Index(i)
+		ep := path + "[" + strconv.Itoa(i) + "]"
+		if e.Kind() == reflect.String {
+			n.value(ep, nil, "", e)
+		} else {
+			n.walk(ep, e)
+		}
+		if n.cfg.RemoveEmpty && e.Kind() == reflect.Pointer && !e.IsNil() && e.Elem().Kind() == reflect.Struct && e.Elem().IsZero() {
+			n.add(ep, NormalizeEmpty, "{"+e.Elem().Type().Name()+"}", "")
+			continue
+		}
+		if out != i {
+			v.Index(out).Set(e)
+		}
+		out++
+	}
+	if out < v.Len() {
+		if out == 0 {
+			v.Set(reflect.Zero(v.Type()))
+			return
+		}
+		v.Set(v.Slice(0, out))
+	}
+}
+
+// value applies the fixups to the string held by v, which is the field of a structure of
+// type parent with the given name, or an element of a slice if parent is nil
+func (n *normalizer) value(path string, parent reflect.Type, name string, v reflect.Value) {
+	s := v.String()
+	if s == "" {
+		return
+	}
+	set := func(fixup, after string) {
+		if after != s {
+			n.add(path, fixup, s, after)
+			s = after
+			v.SetString(s)
+		}
+	}
+
+	if n.cfg.TrimXrefs && name == "Xref" {
+		set(NormalizeXref, strings.Trim(s, " \t@"))
+	}
+	if n.cfg.TrimWhitespace {
+		set(NormalizeWhitespace, trimLines(s))
+	}
+	if n.cfg.UpperDates && (name == "Date" || name == "SourceDate") {
+		set(NormalizeDateCase, upperDate(s))
+	}
+	if n.cfg.VendorDates && (name == "Date" || name == "SourceDate") {
+		if dv, ok := parseVendorDate(s); !ok {
+			n.add(path, NormalizeBadDate, s, s)
+		} else if !(n.v7 && dv.Phrase != "") {
+			set(NormalizeVendorDate, dv.format(n.v7))
+		}
+	}
+	if n.cfg.FixSex && name == "Sex" && parent == reflect.TypeOf(IndividualRecord{}) {
+		set(NormalizeSex, normalizeSex(s))
+	}
+}
+
+// trimLines removes whitespace from the start and end of s and from the end of each line
+func trimLines(s string) string {
+	s = strings.TrimSpace(s)
+	if !strings.Contains(s, "\n") {
+		return s
+	}
+	lines := strings.Split(s, "\n")
+	for i := range lines {
+		lines[i] = strings.TrimRight(lines[i], " \t\r")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// upperDate converts a date value to upper case, leaving any date phrase in parentheses
+// unchanged
+func upperDate(s string) string {
+	var b strings.Builder
+	depth := 0
+	for _, c := range s {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			if depth > 0 {
+				depth--
+			}
+		}
+		if depth == 0 {
+			b.WriteString(strings.ToUpper(string(c)))
+		} else {
+			b.WriteRune(c)
+		}
+	}
+	return b.String()
+}
+
+// normalizeSex converts common spellings of a sex to the GEDCOM values M, F and U.
+// Unrecognised values are returned unchanged.
+func normalizeSex(s string) string {
+	switch strings.ToUpper(strings.TrimSpace(s)) {
+	case "M", "MALE":
+		return "M"
+	case "F", "FEMALE":
+		return "F"
+	case "U", "UNKNOWN", "?":
+		return "U"
+	}
+	return s
+}
@@ -0,0 +1,89 @@
+package gedcom
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNormalize(t *testing.T) {
+	fam := &FamilyRecord{Xref: "F1"}
+	g := &Gedcom{
+		Individual: []*IndividualRecord{
+			{
+				Xref: " @I1@",
+				Name: []*NameRecord{{Name: " John /Smith/  "}},
+				Sex:  "male",
+				Event: []*EventRecord{
+					{Tag: "BIRT", Date: "abt 1 jan 1900 (about new year)"},
+				},
+				Parents: []*FamilyLinkRecord{{Family: fam}},
+				Note:    []*NoteRecord{{}, {Note: "first  \nsecond"}},
+			},
+		},
+		Family: []*FamilyRecord{fam},
+	}
+
+	changes := g.Normalize(DefaultNormalizeConfig())
+
+	want := []NormalizeChange{
+		{Path: "Individual[0].Xref", Fixup: NormalizeXref, Before: " @I1@", After: "I1"},
+		{Path: "Individual[0].Name[0].Name", Fixup: NormalizeWhitespace, Before: " John /Smith/  ", After: "John /Smith/"},
+		{Path: "Individual[0].Sex", Fixup: NormalizeSex, Before: "male", After: "M"},
+		{Path: "Individual[0].Event[0].Date", Fixup: NormalizeDateCase, Before: "abt 1 jan 1900 (about new year)", After: "ABT 1 JAN 1900 (about new year)"},
+		{Path: "Individual[0].Note[0]", Fixup: NormalizeEmpty, Before: "{NoteRecord}"},
+		{Path: "Individual[0].Note[1].Note", Fixup: NormalizeWhitespace, Before: "first  \nsecond", After: "first\nsecond"},
+	}
+	if diff := cmp.Diff(want, changes); diff != "" {
+		t.Errorf("changes mismatch (-want +got):\n%s", diff)
+	}
+
+	indi := g.Individual[0]
+	if len(indi.Note) != 1 || indi.Note[0].Note != "first\nsecond" {
+		t.Errorf("got notes %v, wanted single non-empty note", indi.Note)
+	}
+	if indi.Parents[0].Family != fam {
+		t.Errorf("family link was not preserved")
+	}
+
+	if changes := g.Normalize(DefaultNormalizeConfig()); len(changes) != 0 {
+		t.Errorf("got %d changes on second pass, wanted none", len(changes))
+	}
+
+	g.Individual[0].Sex = "female"
+	if changes := g.Normalize(NormalizeConfig{TrimWhitespace: true}); len(changes) != 0 {
+		t.Errorf("got %d changes with sex fixup disabled, wanted none", len(changes))
+	}
+}
+
+func TestNormalizeVendorDates(t *testing.T) {
+	g := &Gedcom{
+		Individual: []*IndividualRecord{
+			{
+				Xref: "I1",
+				Event: []*EventRecord{
+					{Tag: "BIRT", Date: "Abt. 1900"},
+					{Tag: "CHR", Date: "1 JAN 1901"},
+					{Tag: "DEAT", Date: "when the war ended"},
+				},
+			},
+		},
+	}
+
+	changes := g.Normalize(NormalizeConfig{VendorDates: true})
+	want := []NormalizeChange{
+		{Path: "Individual[0].Event[0].Date", Fixup: NormalizeVendorDate, Before: "Abt. 1900", After: "ABT 1900"},
+		{Path: "Individual[0].Event[2].Date", Fixup: NormalizeBadDate, Before: "when the war ended", After: "when the war ended"},
+	}
+	if diff := cmp.Diff(want, changes); diff != "" {
+		t.Errorf("changes mismatch (-want +got):\n%s", diff)
+	}
+
+	// GEDCOM 7 dates are written in GEDCOM 7 syntax
+	g.Header = &Header{Version: "7.0"}
+	g.Individual[0].Event[1].Date = "@#djulian@ 1 jan 1700"
+	changes = g.Normalize(NormalizeConfig{VendorDates: true})
+	if len(changes) != 2 || changes[0].After != "JULIAN 1 JAN 1700" {
+		t.Errorf("got changes %+v, wanted the Julian date in GEDCOM 7 syntax", changes)
+	}
+}
@@ -0,0 +1,63 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+
+package gedcom
+
+// WithSourceOrder configures the encoder to write records in the order they were decoded,
+// as recorded by the Order field of the Gedcom, rather than grouped by type, so that
+// decoding and encoding a file changes it as little as possible. Records in Order that
+// are no longer held by the Gedcom are not written. Records that are not in Order, such
+// as those added after decoding, follow in the usual order. The order of records set by
+// WithSourceOrder takes precedence over WithCanonicalOrder, which still orders their
+// substructures.
+func WithSourceOrder() EncoderOption {
+	return encoderOptionFunc(func(e *Encoder) {
+		e.sourceOrder = true
+	})
+}
+
+// records returns the records of g other than the header in the order they are written
+func (e *Encoder) records(g *Gedcom) []Record {
+	var rs []Record
+	rs = appendRecords(rs, canonicalRecords(e, g.Individual))
+	rs = appendRecords(rs, canonicalRecords(e, g.Family))
+	rs = appendRecords(rs, canonicalRecords(e, g.Media))
+	rs = appendRecords(rs, canonicalRecords(e, g.Repository))
+	rs = appendRecords(rs, canonicalRecords(e, g.Source))
+	rs = appendRecords(rs, canonicalRecords(e, g.Submitter))
+	if !e.v7 {
+		// Submission records were removed in GEDCOM 7
+		rs = appendRecords(rs, canonicalRecords(e, g.Submission))
+	}
+	rs = appendRecords(rs, canonicalRecords(e, g.Note))
+	if !e.sourceOrder || len(g.Order) == 0 {
+		return rs
+	}
+
+	held := make(map[Record]bool, len(rs))
+	for _, r := range rs {
+		held[r] = true
+	}
+	ordered := make([]Record, 0, len(rs))
+	for _, r := range g.Order {
+		if held[r] {
+			ordered = append(ordered, r)
+			delete(held, r)
+		}
+	}
+	for _, r := range rs {
+		if held[r] {
+			ordered = append(ordered, r)
+		}
+	}
+	return ordered
+}
+
+func appendRecords[T Record](rs []Record, list []T) []Record {
+	for _, r := range list {
+		rs = append(rs, r)
+	}
+	return rs
+}
@@ -0,0 +1,75 @@
+package gedcom
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestEncodeSourceOrder(t *testing.T) {
+	input := `0 HEAD
+1 SOUR test
+1 GEDC
+2 VERS 5.5.1
+0 @N1@ NOTE A shared note
+0 @F1@ FAM
+1 HUSB @I2@
+1 CHIL @I1@
+0 @I2@ INDI
+1 NAME Henry /Smith/
+1 FAMS @F1@
+0 @S1@ SOUR
+1 TITL Parish register
+0 @I1@ INDI
+1 NAME John /Smith/
+1 FAMC @F1@
+0 TRLR
+`
+	g, err := NewDecoder(strings.NewReader(input)).Decode()
+	if err != nil {
+		t.Fatalf("unexpected decode error: %v", err)
+	}
+	if len(g.Order) != 5 || g.Order[0] != g.Note[0] || g.Order[4] != g.Individual[1] {
+		t.Fatalf("order of records not recorded: %v", g.Order)
+	}
+
+	buf := new(bytes.Buffer)
+	if err := NewEncoder(buf, WithSourceOrder()).Encode(g); err != nil {
+		t.Fatalf("unexpected encode error: %v", err)
+	}
+	if diff := cmp.Diff(input, buf.String()); diff != "" {
+		t.Errorf("output mismatch (-want +got):\n%s", diff)
+	}
+
+	// Records no longer held are dropped and new records follow in the usual order
+	g.Source = nil
+	g.Individual = append(g.Individual, &IndividualRecord{Xref: "I3"})
+	g.Note = append(g.Note, &NoteRecord{Xref: "N2", Note: "Another note"})
+	want := `0 HEAD
+1 SOUR test
+1 GEDC
+2 VERS 5.5.1
+0 @N1@ NOTE A shared note
+0 @F1@ FAM
+1 HUSB @I2@
+1 CHIL @I1@
+0 @I2@ INDI
+1 NAME Henry /Smith/
+1 FAMS @F1@
+0 @I1@ INDI
+1 NAME John /Smith/
+1 FAMC @F1@
+0 @I3@ INDI
+0 @N2@ NOTE Another note
+0 TRLR
+`
+	buf.Reset()
+	if err := NewEncoder(buf, WithSourceOrder()).Encode(g); err != nil {
+		t.Fatalf("unexpected encode error: %v", err)
+	}
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("output mismatch after changes (-want +got):\n%s", diff)
+	}
+}
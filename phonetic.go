@@ -0,0 +1,203 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+
+package gedcom
+
+import (
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Soundex returns the American Soundex code of a surname, such as R163 for Robert or
+// Rupert: its first letter followed by three digits coding the sounds of the following
+// consonants. Letters other than A to Z, after removing accents, are ignored. It returns an
+// empty string if the name has no letters.
+func Soundex(name string) string {
+	s := phoneticLetters(name)
+	if s == "" {
+		return ""
+	}
+
+	code := []byte{s[0]}
+	last := soundexDigit(s[0])
+	for i := 1; i < len(s) && len(code) < 4; i++ {
+		c := s[i]
+		if c == 'H' || c == 'W' {
+			// H and W do not separate consonants with the same code
+			continue
+		}
+		d := soundexDigit(c)
+		if d != 0 && d != last {
+			code = append(code, d)
+		}
+		last = d
+	}
+	for len(code) < 4 {
+		code = append(code, '0')
+	}
+	return string(code)
+}
+
+// soundexDigit returns the Soundex digit of an upper case letter, or 0 for a vowel
+func soundexDigit(c byte) byte {
+	switch c {
+	case 'B', 'F', 'P', 'V':
+		return '1'
+	case 'C', 'G', 'J', 'K', 'Q', 'S', 'X', 'Z':
+		return '2'
+	case 'D', 'T':
+		return '3'
+	case 'L':
+		return '4'
+	case 'M', 'N':
+		return '5'
+	case 'R':
+		return '6'
+	}
+	return 0
+}
+
+// DaitchMokotoff returns the Daitch-Mokotoff Soundex codes of a surname, such as 645740
+// for Moskowitz. Each code has six digits. Some letters can be pronounced in more than
+// one way, so a name may have several codes, which are returned in a consistent order
+// without duplicates. Letters other than A to Z, after removing accents, are ignored. It
+// returns nil if the name has no letters.
+func DaitchMokotoff(name string) []string {
+	s := phoneticLetters(name)
+	if s == "" {
+		return nil
+	}
+
+	type branch struct {
+		code string
+		last string // code of the previous letter group, empty after a group with no code
+	}
+	branches := []branch{{}}
+	for i := 0; i < len(s); {
+		r := dmRule(s[i:])
+		next := i + len(r.pattern)
+		col := 2
+		switch {
+		case i == 0:
+			col = 0
+		case next < len(s) && strings.IndexByte("AEIOU", s[next]) >= 0:
+			col = 1
+		}
+
+		var out []branch
+		for _, b := range branches {
+			for _, codes := range r.codes {
+				c := codes[col]
+				nb := branch{code: b.code, last: c}
+				if c != "" && c != b.last {
+					nb.code += c
+				}
+				out = append(out, nb)
+			}
+		}
+		branches = out
+		i = next
+	}
+
+	var codes []string
+	for _, b := range branches {
+		c := (b.code + "000000")[:6]
+		if !slices.Contains(codes, c) {
+			codes = append(codes, c)
+		}
+	}
+	return codes
+}
+
+// SurnameSoundex returns the Soundex code of the surname of the name, without any
+// surname prefix such as van or de. See NameRecord.Parsed.
+func (n *NameRecord) SurnameSoundex() string {
+	return Soundex(n.Parsed(splitSurnamePrefixes()).Surname)
+}
+
+// SurnameDaitchMokotoff returns the Daitch-Mokotoff codes of the surname of the name,
+// without any surname prefix such as van or de. See NameRecord.Parsed.
+func (n *NameRecord) SurnameDaitchMokotoff() []string {
+	return DaitchMokotoff(n.Parsed(splitSurnamePrefixes()).Surname)
+}
+
+// A dmCoding gives the codes of a letter group at the start of a name, before a vowel and
+// in any other position. An empty code means the group is not coded.
+type dmCoding [3]string
+
+// A dmLetterRule is a letter group of the Daitch-Mokotoff coding chart with its codings,
+// of which there are two for groups that can be pronounced in two ways
+type dmLetterRule struct {
+	pattern string
+	codes   []dmCoding
+}
+
+// dmChart is the Daitch-Mokotoff coding chart
+var dmChart = []struct {
+	patterns string
+	codes    []dmCoding
+}{
+	{"AI AJ AY EI EJ EY OI OJ OY UI UJ UY", []dmCoding{{"0", "1", ""}}},
+	{"AU", []dmCoding{{"0", "7", ""}}},
+	{"A E I O U UE", []dmCoding{{"0", "", ""}}},
+	{"EU", []dmCoding{{"1", "1", ""}}},
+	{"IA IE IO IU Y", []dmCoding{{"1", "", ""}}},
+	{"B F FB P PF PH V W", []dmCoding{{"7", "7", "7"}}},
+	{"CHS", []dmCoding{{"5", "54", "54"}}},
+	{"KS X", []dmCoding{{"5", "54", "54"}}},
+	{"CH C", []dmCoding{{"5", "5", "5"}, {"4", "4", "4"}}},
+	{"CK", []dmCoding{{"5", "5", "5"}, {"45", "45", "45"}}},
+	{"CSZ CZS CS CZ DRZ DRS DS DSH DSZ DZ DZH DZS SCH SH SZ S TCH TTCH TTSCH TRZ TRS TSCH TSH TS TTS TTSZ TC TZ TTZ TZS TSZ ZH ZS ZSCH ZSH Z", []dmCoding{{"4", "4", "4"}}},
+	{"D DT T TH", []dmCoding{{"3", "3", "3"}}},
+	{"G K KH Q", []dmCoding{{"5", "5", "5"}}},
+	{"H", []dmCoding{{"5", "5", ""}}},
+	{"J", []dmCoding{{"1", "1", "1"}, {"4", "4", "4"}}},
+	{"L", []dmCoding{{"8", "8", "8"}}},
+	{"M N", []dmCoding{{"6", "6", "6"}}},
+	{"MN NM", []dmCoding{{"66", "66", "66"}}},
+	{"R", []dmCoding{{"9", "9", "9"}}},
+	{"RZ RS", []dmCoding{{"94", "94", "94"}, {"4", "4", "4"}}},
+	{"SCHTSCH SCHTSH SCHTCH SHTCH SHCH SHTSH STCH STSCH SC STRZ STRS STSH SZCZ SZCS ZDZ ZDZH ZHDZH", []dmCoding{{"2", "4", "4"}}},
+	{"SHT SCHT SCHD ST SZT SHD SZD SD ZD ZHD", []dmCoding{{"2", "43", "43"}}},
+}
+
+// dmRules returns the letter groups of the coding chart indexed by their first letter,
+// longest first
+var dmRules = sync.OnceValue(func() map[byte][]dmLetterRule {
+	rules := make(map[byte][]dmLetterRule)
+	for _, row := range dmChart {
+		for _, p := range strings.Fields(row.patterns) {
+			rules[p[0]] = append(rules[p[0]], dmLetterRule{pattern: p, codes: row.codes})
+		}
+	}
+	for _, rs := range rules {
+		slices.SortStableFunc(rs, func(a, b dmLetterRule) int { return len(b.pattern) - len(a.pattern) })
+	}
+	return rules
+})
+
+// dmRule returns the rule for the longest letter group at the start of s
+func dmRule(s string) dmLetterRule {
+	for _, r := range dmRules()[s[0]] {
+		if strings.HasPrefix(s, r.pattern) {
+			return r
+		}
+	}
+	// Every letter has a rule
+	panic("no Daitch-Mokotoff rule for " + s[:1])
+}
+
+// phoneticLetters returns the letters A to Z of name in upper case, after removing
+// accents, ignoring all other characters
+func phoneticLetters(name string) string {
+	var b strings.Builder
+	for _, r := range StripDiacritics(strings.ToUpper(name)) {
+		if r >= 'A' && r <= 'Z' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
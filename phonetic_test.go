@@ -0,0 +1,66 @@
+package gedcom
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSoundex(t *testing.T) {
+	testCases := map[string]string{
+		"Robert":   "R163",
+		"Rupert":   "R163",
+		"Rubin":    "R150",
+		"Ashcraft": "A261",
+		"Ashcroft": "A261",
+		"Tymczak":  "T522",
+		"Pfister":  "P236",
+		"Honeyman": "H555",
+		"Lee":      "L000",
+		"O'Brien":  "O165",
+		"Müller":   "M460",
+		"":         "",
+		"123":      "",
+	}
+	for in, want := range testCases {
+		if got := Soundex(in); got != want {
+			t.Errorf("Soundex(%q) = %q, wanted %q", in, got, want)
+		}
+	}
+}
+
+func TestDaitchMokotoff(t *testing.T) {
+	testCases := map[string][]string{
+		"Moskowitz":  {"645740"},
+		"Lewinsky":   {"876450"},
+		"Levinski":   {"876450"},
+		"Peters":     {"739400", "734000"},
+		"Auerbach":   {"097500", "097400"},
+		"Ohrbach":    {"097500", "097400"},
+		"Lipshitz":   {"874400"},
+		"Lippszyc":   {"874500", "874400"},
+		"Szlamawicz": {"486740"},
+		"Shlamovitz": {"486740"},
+		"":           nil,
+	}
+	for in, want := range testCases {
+		if diff := cmp.Diff(want, DaitchMokotoff(in)); diff != "" {
+			t.Errorf("DaitchMokotoff(%q) mismatch (-want +got):\n%s", in, diff)
+		}
+	}
+}
+
+func TestNameRecordPhonetic(t *testing.T) {
+	n := &NameRecord{Name: "Anna /van der Berg/"}
+	if got, want := n.SurnameSoundex(), "B620"; got != want {
+		t.Errorf("SurnameSoundex() = %q, wanted %q", got, want)
+	}
+	if diff := cmp.Diff([]string{"795000"}, n.SurnameDaitchMokotoff()); diff != "" {
+		t.Errorf("SurnameDaitchMokotoff() mismatch (-want +got):\n%s", diff)
+	}
+
+	n = &NameRecord{Name: "Jan /Kowalski/", NamePieceSurname: "Kowalsky"}
+	if got, want := n.SurnameSoundex(), "K420"; got != want {
+		t.Errorf("SurnameSoundex() = %q, wanted %q", got, want)
+	}
+}
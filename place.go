@@ -0,0 +1,121 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+
+package gedcom
+
+import (
+	"sort"
+	"strings"
+)
+
+// A PlaceIndex maps normalized place names to the events that occurred there. It is built
+// in a single pass over the individuals and families of a Gedcom.
+type PlaceIndex struct {
+	places     map[string][]EventEntry
+	localities map[string][]EventEntry
+}
+
+// NewPlaceIndex builds a PlaceIndex covering the events and attributes of all individuals
+// and the events of all families in g.
+func NewPlaceIndex(g *Gedcom) *PlaceIndex {
+	x := &PlaceIndex{
+		places:     make(map[string][]EventEntry),
+		localities: make(map[string][]EventEntry),
+	}
+
+	for _, r := range g.Individual {
+		if r == nil {
+			continue
+		}
+		for _, evs := range [][]*EventRecord{r.Event, r.Attribute} {
+			for _, ev := range evs {
+				x.add(EventEntry{Individual: r, Event: ev})
+			}
+		}
+	}
+	for _, r := range g.Family {
+		if r == nil {
+			continue
+		}
+		for _, ev := range r.Event {
+			x.add(EventEntry{Family: r, Event: ev})
+		}
+	}
+	return x
+}
+
+func (x *PlaceIndex) add(e EventEntry) {
+	if e.Event == nil {
+		return
+	}
+	name := NormalizePlace(e.Event.Place.Name)
+	if name == "" {
+		return
+	}
+	x.places[name] = append(x.places[name], e)
+
+	locality, _, _ := strings.Cut(name, ",")
+	x.localities[locality] = append(x.localities[locality], e)
+}
+
+// Lookup returns the entries for events whose place matches the given place name after
+// normalization.
+func (x *PlaceIndex) Lookup(place string) []EventEntry {
+	return x.places[NormalizePlace(place)]
+}
+
+// LookupLocality returns the entries for events whose most specific jurisdiction, such as
+// the village or town, matches the given name after normalization.
+func (x *PlaceIndex) LookupLocality(name string) []EventEntry {
+	return x.localities[NormalizePlace(name)]
+}
+
+// Individuals returns the distinct individuals associated with events at the given place,
+// including the husband and wife of families whose events took place there.
+func (x *PlaceIndex) Individuals(place string) []*IndividualRecord {
+	return placeIndividuals(x.Lookup(place))
+}
+
+// Places returns the normalized names of all indexed places in sorted order.
+func (x *PlaceIndex) Places() []string {
+	names := make([]string, 0, len(x.places))
+	for name := range x.places {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func placeIndividuals(es []EventEntry) []*IndividualRecord {
+	var rs []*IndividualRecord
+	seen := make(map[*IndividualRecord]bool)
+	add := func(r *IndividualRecord) {
+		if r != nil && !seen[r] {
+			seen[r] = true
+			rs = append(rs, r)
+		}
+	}
+	for _, e := range es {
+		add(e.Individual)
+		if e.Family != nil {
+			add(e.Family.Husband)
+			add(e.Family.Wife)
+		}
+	}
+	return rs
+}
+
+// NormalizePlace returns a normalized form of a place name suitable for comparison. It
+// lower cases the name, collapses whitespace and removes empty jurisdictions, so that
+// "Ashby,  ,Leicestershire" and "ashby, leicestershire" are equivalent.
+func NormalizePlace(s string) string {
+	var parts []string
+	for _, p := range strings.Split(s, ",") {
+		if p = strings.Join(strings.Fields(strings.ToLower(p)), " "); p != "" {
+			parts = append(parts, p)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,57 @@
+package gedcom
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPlaceIndex(t *testing.T) {
+	input := `
+0 @I1@ INDI
+1 NAME John /Smith/
+1 BIRT
+2 PLAC Ashby,  Leicestershire, England
+1 RESI
+2 PLAC Loughborough, Leicestershire, England
+0 @I2@ INDI
+1 NAME Mary /Jones/
+1 BIRT
+2 PLAC ashby, leicestershire,, england
+0 @I3@ INDI
+1 NAME Ann /Smith/
+1 BIRT
+2 PLAC Ashby, Norfolk, England
+0 @F1@ FAM
+1 HUSB @I1@
+1 WIFE @I2@
+1 MARR
+2 PLAC Loughborough, Leicestershire, England
+`
+	g, err := NewDecoder(strings.NewReader(input)).Decode()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	x := NewPlaceIndex(g)
+
+	if got := len(x.Lookup("Ashby, Leicestershire, England")); got != 2 {
+		t.Errorf("got %d entries for Ashby, Leicestershire, wanted 2", got)
+	}
+	if got := len(x.LookupLocality("ASHBY")); got != 3 {
+		t.Errorf("got %d entries for locality Ashby, wanted 3", got)
+	}
+
+	indis := x.Individuals("loughborough, leicestershire, england")
+	var xrefs []string
+	for _, r := range indis {
+		xrefs = append(xrefs, r.Xref)
+	}
+	if strings.Join(xrefs, ",") != "I1,I2" {
+		t.Errorf("got individuals %v in Loughborough, wanted I1,I2", xrefs)
+	}
+
+	want := []string{"ashby, leicestershire, england", "ashby, norfolk, england", "loughborough, leicestershire, england"}
+	if got := x.Places(); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("got places %q, wanted %q", got, want)
+	}
+}
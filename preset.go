@@ -0,0 +1,55 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+
+package gedcom
+
+// Presets bundle the decoder fixups and encoder settings suited to files exchanged with a
+// particular product. A preset may be passed to both NewDecoder and NewEncoder and may be
+// followed by further options that override its settings.
+var (
+	// PresetAncestry suits files exported from and imported into Ancestry. The decoder
+	// applies the fixups of ProfileAncestry and the encoder splits long text using CONC.
+	PresetAncestry Option = presetOptions{
+		WithProfile(ProfileAncestry),
+		WithContinuation(ContinueWithConc),
+	}
+
+	// PresetFamilyTreeMaker suits files exchanged with Family Tree Maker, which shares
+	// Ancestry's export format. Repeated singleton tags are reported by the encoder's
+	// Findings method since Family Tree Maker discards them on import.
+	PresetFamilyTreeMaker Option = presetOptions{
+		WithProfile(ProfileFamilyTreeMaker),
+		WithContinuation(ContinueWithConc),
+		WithCardinalityCheck(CardinalityWarn),
+	}
+
+	// PresetGramps suits files exchanged with Gramps, which writes well formed files and
+	// checks the structure of files it imports. The decoder applies no fixups and the
+	// encoder fails rather than write repeated singleton tags.
+	PresetGramps Option = presetOptions{
+		WithProfile(ProfileGramps),
+		WithContinuation(ContinueWithConc),
+		WithCardinalityCheck(CardinalityError),
+	}
+)
+
+// presetOptions applies each of its options that applies to the decoder or encoder
+type presetOptions []any
+
+func (p presetOptions) applyDecoder(d *Decoder) {
+	for _, o := range p {
+		if do, ok := o.(DecoderOption); ok {
+			do.applyDecoder(d)
+		}
+	}
+}
+
+func (p presetOptions) applyEncoder(e *Encoder) {
+	for _, o := range p {
+		if eo, ok := o.(EncoderOption); ok {
+			eo.applyEncoder(e)
+		}
+	}
+}
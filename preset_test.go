@@ -0,0 +1,51 @@
+package gedcom
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestPresets(t *testing.T) {
+	input := "0 @I1@ INDI\n1 NOTE first\nsecond\n0 TRLR\n"
+
+	testCases := []struct {
+		name        string
+		preset      Option
+		note        string // empty if the malformed note should be rejected
+		cardinality CardinalityMode
+	}{
+		{name: "ancestry", preset: PresetAncestry, note: "first\nsecond", cardinality: CardinalityIgnore},
+		{name: "ftm", preset: PresetFamilyTreeMaker, note: "first\nsecond", cardinality: CardinalityWarn},
+		{name: "gramps", preset: PresetGramps, cardinality: CardinalityError},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g, err := NewDecoder(strings.NewReader(input), tc.preset).Decode()
+			if tc.note == "" {
+				if err == nil {
+					t.Errorf("got no error, wanted error for malformed note")
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if len(g.Individual) != 1 || len(g.Individual[0].Note) != 1 || g.Individual[0].Note[0].Note != tc.note {
+					t.Errorf("note was not repaired")
+				}
+			}
+
+			enc := NewEncoder(new(bytes.Buffer), tc.preset)
+			if enc.cardinality != tc.cardinality {
+				t.Errorf("got cardinality mode %d, wanted %d", enc.cardinality, tc.cardinality)
+			}
+
+			// Later options override the preset
+			enc = NewEncoder(new(bytes.Buffer), tc.preset, WithCardinalityCheck(CardinalityIgnore))
+			if enc.cardinality != CardinalityIgnore {
+				t.Errorf("preset was not overridden")
+			}
+		})
+	}
+}
@@ -0,0 +1,221 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+
+package gedcom
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// An EventEntry records an event together with the record that holds it. Exactly one of
+// Individual or Family is set, depending on which record holds the event.
+type EventEntry struct {
+	Individual *IndividualRecord
+	Family     *FamilyRecord
+	Event      *EventRecord
+}
+
+// ApproximateDates controls how the date range queries treat dates that are approximate,
+// such as "ABT 1850" or "BEF 1900", or that span a period, such as "BET 1850 AND 1855".
+type ApproximateDates int
+
+const (
+	// IncludeApproximate matches a date if any part of the period it could refer to falls
+	// within the query range.
+	IncludeApproximate ApproximateDates = iota
+
+	// ExcludeApproximate matches a date only if it is not approximate and the whole period
+	// it refers to falls within the query range.
+	ExcludeApproximate
+)
+
+// IndividualsBornBetween returns the individuals whose birth date falls between from and to
+// inclusive. Christening or baptism dates are used when an individual has no birth event.
+// Individuals with no date or with dates that cannot be interpreted are not included.
+func IndividualsBornBetween(g *Gedcom, from, to time.Time, mode ApproximateDates) []*IndividualRecord {
+	var rs []*IndividualRecord
+	for _, r := range g.Individual {
+		if r == nil {
+			continue
+		}
+		ev := firstEvent(r.Event, "BIRT", "CHR", "BAPM")
+		if ev != nil && dateInRange(ev.Date, from, to, mode) {
+			rs = append(rs, r)
+		}
+	}
+	return rs
+}
+
+// EventsInRange returns the individual and family events with the given tag whose date falls
+// between from and to inclusive. Individual attributes such as RESI are included. An empty
+// tag matches events of any type.
+func EventsInRange(g *Gedcom, tag string, from, to time.Time, mode ApproximateDates) []EventEntry {
+	var es []EventEntry
+	match := func(ev *EventRecord) bool {
+		return ev != nil && (tag == "" || ev.Tag == tag) && dateInRange(ev.Date, from, to, mode)
+	}
+
+	for _, r := range g.Individual {
+		if r == nil {
+			continue
+		}
+		for _, evs := range [][]*EventRecord{r.Event, r.Attribute} {
+			for _, ev := range evs {
+				if match(ev) {
+					es = append(es, EventEntry{Individual: r, Event: ev})
+				}
+			}
+		}
+	}
+	for _, r := range g.Family {
+		if r == nil {
+			continue
+		}
+		for _, ev := range r.Event {
+			if match(ev) {
+				es = append(es, EventEntry{Family: r, Event: ev})
+			}
+		}
+	}
+	return es
+}
+
+func dateInRange(s string, from, to time.Time, mode ApproximateDates) bool {
+	dv, ok := ParseDate(s)
+	if !ok {
+		return false
+	}
+	lo, hi, ok := dv.bounds()
+	if !ok {
+		return false
+	}
+	first, last := timeDay(from), timeDay(to)
+	if mode == ExcludeApproximate {
+		if isApproximate(dv) || lo == openLo || hi == openHi {
+			return false
+		}
+		return lo >= first && hi <= last
+	}
+	return lo <= last && hi >= first
+}
+
+// isApproximate reports whether dv is an approximate date or a date range or period
+func isApproximate(dv DateValue) bool {
+	switch dv.Qualifier {
+	case "", "INT":
+		return false
+	}
+	return true
+}
+
+// timeDay returns the Julian day number of the day of t in its location
+func timeDay(t time.Time) int {
+	y, m, d := t.Date()
+	return gregorianDay(y, int(m), d)
+}
+
+// ChangedSince returns a Gedcom holding only the records of g whose change date, recorded
+// in the CHAN structure, is after t. Records without a valid change date are not included.
+// The returned records are shared with g.
+func ChangedSince(g *Gedcom, t time.Time) *Gedcom {
+	changed := func(c *ChangeRecord) bool {
+		if c == nil {
+			return false
+		}
+		ts, ok := c.Timestamp()
+		return ok && ts.After(t)
+	}
+
+	cg := &Gedcom{
+		Family:     make([]*FamilyRecord, 0),
+		Individual: make([]*IndividualRecord, 0),
+		Media:      make([]*MediaRecord, 0),
+		Repository: make([]*RepositoryRecord, 0),
+		Source:     make([]*SourceRecord, 0),
+		Submitter:  make([]*SubmitterRecord, 0),
+	}
+	for _, r := range g.Family {
+		if r != nil && changed(&r.Change) {
+			cg.Family = append(cg.Family, r)
+		}
+	}
+	for _, r := range g.Individual {
+		if r != nil && changed(&r.Change) {
+			cg.Individual = append(cg.Individual, r)
+		}
+	}
+	for _, r := range g.Media {
+		if r != nil && changed(&r.Change) {
+			cg.Media = append(cg.Media, r)
+		}
+	}
+	for _, r := range g.Repository {
+		if r != nil && changed(&r.Change) {
+			cg.Repository = append(cg.Repository, r)
+		}
+	}
+	for _, r := range g.Source {
+		if r != nil && changed(&r.Change) {
+			cg.Source = append(cg.Source, r)
+		}
+	}
+	for _, r := range g.Submitter {
+		if r != nil && changed(r.Change) {
+			cg.Submitter = append(cg.Submitter, r)
+		}
+	}
+	for _, r := range g.Submission {
+		if r != nil && changed(&r.Change) {
+			cg.Submission = append(cg.Submission, r)
+		}
+	}
+	return cg
+}
+
+// Timestamp returns the time of the change, combining the DATE and optional TIME values.
+// Times are interpreted as UTC. It returns false if the date is not an exact date.
+func (c *ChangeRecord) Timestamp() (time.Time, bool) {
+	dv, ok := ParseDate(c.Date)
+	if !ok || isApproximate(dv) {
+		return time.Time{}, false
+	}
+	lo, hi, ok := dv.bounds()
+	if !ok || lo != hi {
+		return time.Time{}, false
+	}
+	day, ok := dv.Civil()
+	if !ok {
+		return time.Time{}, false
+	}
+	if c.Time == "" {
+		return day.Time(time.UTC), true
+	}
+
+	var hms [3]int
+	clock, frac, _ := strings.Cut(strings.TrimSpace(c.Time), ".")
+	parts := strings.Split(clock, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return time.Time{}, false
+	}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return time.Time{}, false
+		}
+		hms[i] = n
+	}
+	var nsec int
+	if frac != "" {
+		f, err := strconv.ParseFloat("0."+frac, 64)
+		if err != nil {
+			return time.Time{}, false
+		}
+		nsec = int(f * float64(time.Second))
+	}
+
+	return time.Date(day.Year, day.Month, day.Day, hms[0], hms[1], hms[2], nsec, time.UTC), true
+}
@@ -0,0 +1,145 @@
+package gedcom
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIndividualsBornBetween(t *testing.T) {
+	input := `
+0 @I1@ INDI
+1 BIRT
+2 DATE 12 MAR 1850
+0 @I2@ INDI
+1 BIRT
+2 DATE ABT 1851
+0 @I3@ INDI
+1 CHR
+2 DATE MAY 1849
+0 @I4@ INDI
+1 BIRT
+2 DATE BET 1845 AND 1852
+0 @I5@ INDI
+1 BIRT
+2 DATE BEF 1800
+0 @I6@ INDI
+1 BIRT
+2 DATE unknown
+0 @I7@ INDI
+1 BIRT
+2 DATE BEF 1850
+0 @I8@ INDI
+1 BIRT
+2 DATE @#DJULIAN@ 25 DEC 1849
+`
+	g, err := NewDecoder(strings.NewReader(input)).Decode()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	from := time.Date(1850, time.January, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(1855, time.December, 31, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name string
+		mode ApproximateDates
+		want string
+	}{
+		// BEF 1850 ends on 31 DEC 1849 and the Julian date is 6 JAN 1850 in the Gregorian
+		// calendar
+		{name: "include", mode: IncludeApproximate, want: "I1,I2,I4,I8"},
+		{name: "exclude", mode: ExcludeApproximate, want: "I1,I8"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var xrefs []string
+			for _, r := range IndividualsBornBetween(g, from, to, tc.mode) {
+				xrefs = append(xrefs, r.Xref)
+			}
+			if got := strings.Join(xrefs, ","); got != tc.want {
+				t.Errorf("got %s, wanted %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestEventsInRange(t *testing.T) {
+	input := `
+0 @I1@ INDI
+1 RESI
+2 DATE FROM 1881 TO 1891
+1 DEAT
+2 DATE 4 JUN 1895
+0 @F1@ FAM
+1 MARR
+2 DATE 2 FEB 1875
+`
+	g, err := NewDecoder(strings.NewReader(input)).Decode()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	from := time.Date(1870, time.January, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(1890, time.December, 31, 0, 0, 0, 0, time.UTC)
+
+	es := EventsInRange(g, "", from, to, IncludeApproximate)
+	if len(es) != 2 {
+		t.Fatalf("got %d events, wanted 2", len(es))
+	}
+	if es[0].Event.Tag != "RESI" || es[0].Individual == nil {
+		t.Errorf("got first event %s, wanted individual RESI", es[0].Event.Tag)
+	}
+	if es[1].Event.Tag != "MARR" || es[1].Family == nil {
+		t.Errorf("got second event %s, wanted family MARR", es[1].Event.Tag)
+	}
+
+	es = EventsInRange(g, "MARR", from, to, ExcludeApproximate)
+	if len(es) != 1 {
+		t.Errorf("got %d MARR events, wanted 1", len(es))
+	}
+}
+
+func TestChangedSince(t *testing.T) {
+	input := `
+0 @I1@ INDI
+1 CHAN
+2 DATE 1 APR 1998
+3 TIME 12:34:56.789
+0 @I2@ INDI
+1 CHAN
+2 DATE 3 JAN 2021
+0 @I3@ INDI
+0 @F1@ FAM
+1 CHAN
+2 DATE 5 MAY 2022
+3 TIME 08:00
+0 @S1@ SOUR
+1 CHAN
+2 DATE ABT 2022
+`
+	g, err := NewDecoder(strings.NewReader(input)).Decode()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ts, ok := g.Individual[0].Change.Timestamp()
+	if !ok {
+		t.Fatalf("got no timestamp, wanted one")
+	}
+	if want := time.Date(1998, time.April, 1, 12, 34, 56, 789000000, time.UTC); !ts.Equal(want) {
+		t.Errorf("got timestamp %v, wanted %v", ts, want)
+	}
+
+	cg := ChangedSince(g, time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC))
+	if len(cg.Individual) != 1 || cg.Individual[0].Xref != "I2" {
+		t.Errorf("got %d changed individuals, wanted I2 only", len(cg.Individual))
+	}
+	if len(cg.Family) != 1 {
+		t.Errorf("got %d changed families, wanted 1", len(cg.Family))
+	}
+	if len(cg.Source) != 0 {
+		t.Errorf("got %d changed sources, wanted none", len(cg.Source))
+	}
+}
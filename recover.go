@@ -0,0 +1,71 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+
+package gedcom
+
+import "fmt"
+
+// SkippedLines describes a range of lines skipped by a Decoder configured with
+// WithRecovery because they could not be scanned.
+type SkippedLines struct {
+	Start int   // line number of the first line skipped, which contains the error
+	End   int   // line number of the last line skipped
+	Err   error // the error found on the first line
+}
+
+func (s SkippedLines) String() string {
+	return fmt.Sprintf("lines %d to %d: %v", s.Start, s.End, s.Err)
+}
+
+// WithRecovery configures the decoder to continue after finding a line that cannot be
+// scanned, rather than stopping with a ScanErr. The line and those following it are
+// skipped until the start of the next level 0 record, leaving the record containing the
+// error partially decoded. The skipped lines are reported by Skipped and as warnings.
+// Errors reading the input still stop decoding.
+func WithRecovery() DecoderOption {
+	return decoderOptionFunc(func(d *Decoder) {
+		d.recover = true
+	})
+}
+
+// Skipped returns the ranges of lines skipped by the most recent call to Decode, or by
+// calls to Next, when the decoder is configured with WithRecovery.
+func (d *Decoder) Skipped() []SkippedLines {
+	return d.skippedLines
+}
+
+// skipToRecord recovers from the scan error found by s by skipping lines until the start
+// of the next level 0 record, which becomes the current line of s. It reports whether a
+// record was found before the end of the input.
+func (d *Decoder) skipToRecord(s *Scanner) (bool, error) {
+	skip := SkippedLines{Start: s.line, Err: s.Err()}
+	record := func(end int) {
+		skip.End = end
+		d.skippedLines = append(d.skippedLines, skip)
+		d.line = skip.Start
+		d.warn(WarningParseError, "", "", "skipped %s", skip)
+	}
+
+	for {
+		if !s.recover() {
+			if s.readErr {
+				return false, s.Err()
+			}
+			record(s.line)
+			return false, nil
+		}
+		for s.Next() {
+			if s.level == 0 {
+				record(s.line - 1)
+				return true, nil
+			}
+		}
+		if s.Err() == nil {
+			// Next counts the line it failed to find at the end of the input
+			record(s.line - 1)
+			return false, nil
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package gedcom
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestRecovery(t *testing.T) {
+	input := `0 HEAD
+0 @I1@ INDI
+1 NAME Jane /Doe/
+1 SE*X F
+1 BIRT
+2 DATE 1901
+0 @I2@ INDI
+1 NAME John /Doe/
+0 @I3@ INDI
+x this line has no level
+1 NAME Lost
+0 @I4@ INDI
+1 NAME Ann /Doe/
+0 TRLR
+`
+	if _, err := NewDecoder(strings.NewReader(input)).Decode(); err == nil {
+		t.Fatalf("got no error without recovery, wanted scan error")
+	}
+
+	d := NewDecoder(strings.NewReader(input), WithRecovery())
+	g, err := d.Decode()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var names []string
+	for _, i := range g.Individual {
+		var name string
+		if len(i.Name) > 0 {
+			name = i.Name[0].Name
+		}
+		names = append(names, i.Xref+" "+name)
+	}
+	want := []string{"I1 Jane /Doe/", "I2 John /Doe/", "I3 ", "I4 Ann /Doe/"}
+	if diff := cmp.Diff(want, names); diff != "" {
+		t.Errorf("individuals mismatch (-want +got):\n%s", diff)
+	}
+	if g.Trailer == nil {
+		t.Errorf("trailer was not decoded")
+	}
+
+	wantSkipped := []SkippedLines{{Start: 4, End: 6}, {Start: 10, End: 11}}
+	if diff := cmp.Diff(wantSkipped, d.Skipped(), cmpopts.IgnoreFields(SkippedLines{}, "Err")); diff != "" {
+		t.Errorf("skipped lines mismatch (-want +got):\n%s", diff)
+	}
+	for _, s := range d.Skipped() {
+		var se *ScanErr
+		if !errors.As(s.Err, &se) || se.LineNumber != s.Start {
+			t.Errorf("got error %v for lines starting at %d, wanted a ScanErr for that line", s.Err, s.Start)
+		}
+	}
+
+	var parseErrors int
+	for _, w := range d.Warnings() {
+		if w.Kind == WarningParseError {
+			parseErrors++
+		}
+	}
+	if parseErrors != 2 {
+		t.Errorf("got %d parse error warnings, wanted 2", parseErrors)
+	}
+}
+
+func TestRecoveryAtEnd(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input string
+		want  []SkippedLines
+	}{
+		{
+			name:  "error on last line",
+			input: "0 @I1@ INDI\r\n1 NAME Jane /Doe/\r\n1 SE*X F\r\n",
+			want:  []SkippedLines{{Start: 3, End: 3}},
+		},
+		{
+			name:  "error before end",
+			input: "0 @I1@ INDI\n1 SE*X F\n1 NAME Jane /Doe/\n",
+			want:  []SkippedLines{{Start: 2, End: 3}},
+		},
+		{
+			name:  "no final newline",
+			input: "0 @I1@ INDI\n1 NAME Jane /Doe/\n1 SEX",
+			want:  []SkippedLines{{Start: 3, End: 3}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			d := NewDecoder(strings.NewReader(tc.input), WithRecovery())
+			g, err := d.Decode()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(g.Individual) != 1 || g.Individual[0].Xref != "I1" {
+				t.Errorf("individual was not partially decoded")
+			}
+			if diff := cmp.Diff(tc.want, d.Skipped(), cmpopts.IgnoreFields(SkippedLines{}, "Err")); diff != "" {
+				t.Errorf("skipped lines mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
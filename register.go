@@ -0,0 +1,146 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+
+package gedcom
+
+// A RegisterStyle selects the numbering convention used by a descendant report.
+type RegisterStyle int
+
+const (
+	// RegisterStyleNEHGS follows the NEHGS Register convention where only children
+	// who are carried forward with descendants of their own receive a number.
+	RegisterStyleNEHGS RegisterStyle = iota
+
+	// RegisterStyleNGSQ follows the National Genealogical Society Quarterly convention
+	// where every child receives a number.
+	RegisterStyleNGSQ
+)
+
+// A RegisterEntry describes one numbered individual who is given their own section
+// in a Register-style descendant report.
+type RegisterEntry struct {
+	Number     int // the individual's number in the report
+	Generation int // the generation relative to the progenitor, who is generation 1
+	Individual *IndividualRecord
+	Lineage    []RegisterLineage // the line of descent, starting with the individual and ending with the progenitor
+	Families   []*RegisterFamily
+}
+
+// A RegisterLineage is one step in the line of descent that follows an individual's name,
+// as in "John³ (William², Thomas¹)".
+type RegisterLineage struct {
+	Individual *IndividualRecord
+	GivenName  string
+	Generation int
+}
+
+// A RegisterFamily lists the children of an entry's individual with one spouse.
+type RegisterFamily struct {
+	Family   *FamilyRecord
+	Spouse   *IndividualRecord
+	Children []*RegisterChild
+}
+
+// A RegisterChild is a child listed under a parent's family.
+type RegisterChild struct {
+	Individual *IndividualRecord
+	Ordinal    int  // position of the child within the family, usually shown as a lower case roman numeral
+	Number     int  // the child's number in the report, zero if the child is not numbered
+	Carried    bool // true if the child has their own entry later in the report
+}
+
+// RegisterReport builds a Register-style descendant report for the descendants of root,
+// covering at most the given number of generations (root being the first). The entries are
+// returned in report order.
+func RegisterReport(root *IndividualRecord, generations int, style RegisterStyle) []*RegisterEntry {
+	if root == nil || generations < 1 {
+		return nil
+	}
+
+	first := &RegisterEntry{
+		Number:     1,
+		Generation: 1,
+		Individual: root,
+		Lineage:    []RegisterLineage{{Individual: root, GivenName: givenName(root), Generation: 1}},
+	}
+
+	seen := map[*IndividualRecord]bool{root: true}
+	next := 2
+	entries := []*RegisterEntry{first}
+
+	for i := 0; i < len(entries); i++ {
+		e := entries[i]
+		for _, fl := range e.Individual.Family {
+			if fl == nil || fl.Family == nil {
+				continue
+			}
+			f := fl.Family
+			rf := &RegisterFamily{Family: f, Spouse: spouseIn(f, e.Individual)}
+			e.Families = append(e.Families, rf)
+
+			for ord, child := range f.Child {
+				if child == nil {
+					continue
+				}
+				rc := &RegisterChild{Individual: child, Ordinal: ord + 1}
+				rf.Children = append(rf.Children, rc)
+
+				rc.Carried = !seen[child] && e.Generation < generations && hasChildren(child)
+				if rc.Carried || style == RegisterStyleNGSQ {
+					rc.Number = next
+					next++
+				}
+				if !rc.Carried {
+					continue
+				}
+				seen[child] = true
+
+				lineage := make([]RegisterLineage, 0, len(e.Lineage)+1)
+				lineage = append(lineage, RegisterLineage{Individual: child, GivenName: givenName(child), Generation: e.Generation + 1})
+				lineage = append(lineage, e.Lineage...)
+
+				entries = append(entries, &RegisterEntry{
+					Number:     rc.Number,
+					Generation: e.Generation + 1,
+					Individual: child,
+					Lineage:    lineage,
+				})
+			}
+		}
+	}
+
+	return entries
+}
+
+// givenName returns the given name from the individual's primary name
+func givenName(r *IndividualRecord) string {
+	if len(r.Name) == 0 || r.Name[0] == nil {
+		return ""
+	}
+	if r.Name[0].NamePieceGiven != "" {
+		return r.Name[0].NamePieceGiven
+	}
+	return SplitPersonalName(r.Name[0].Name).Given
+}
+
+// spouseIn returns the partner of r in the family f, if any
+func spouseIn(f *FamilyRecord, r *IndividualRecord) *IndividualRecord {
+	switch {
+	case f.Husband != nil && f.Husband != r:
+		return f.Husband
+	case f.Wife != nil && f.Wife != r:
+		return f.Wife
+	}
+	return nil
+}
+
+func hasChildren(r *IndividualRecord) bool {
+	for _, fl := range r.Family {
+		if fl != nil && fl.Family != nil && len(fl.Family.Child) > 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,124 @@
+package gedcom
+
+import (
+	"strings"
+	"testing"
+)
+
+const registerInput = `
+0 @I1@ INDI
+1 NAME Thomas /Fuller/
+1 FAMS @F1@
+0 @I2@ INDI
+1 NAME Ann /Hale/
+1 FAMS @F1@
+0 @I3@ INDI
+1 NAME William /Fuller/
+1 FAMC @F1@
+1 FAMS @F2@
+0 @I4@ INDI
+1 NAME Sarah /Fuller/
+1 FAMC @F1@
+0 @I5@ INDI
+1 NAME John /Fuller/
+1 FAMC @F2@
+1 FAMS @F3@
+0 @I6@ INDI
+1 NAME Peter /Fuller/
+1 FAMC @F3@
+0 @F1@ FAM
+1 HUSB @I1@
+1 WIFE @I2@
+1 CHIL @I4@
+1 CHIL @I3@
+0 @F2@ FAM
+1 HUSB @I3@
+1 CHIL @I5@
+0 @F3@ FAM
+1 HUSB @I5@
+1 CHIL @I6@
+`
+
+func TestRegisterReport(t *testing.T) {
+	g, err := NewDecoder(strings.NewReader(registerInput)).Decode()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	root := g.Individual[0]
+
+	testCases := []struct {
+		name        string
+		style       RegisterStyle
+		generations int
+		numbers     []int    // entry numbers
+		lineage     []string // given names in the lineage of the last entry
+		childNums   []int    // numbers of the progenitor's children
+	}{
+		{
+			name:        "nehgs",
+			style:       RegisterStyleNEHGS,
+			generations: 4,
+			numbers:     []int{1, 2, 3},
+			lineage:     []string{"John", "William", "Thomas"},
+			childNums:   []int{0, 2},
+		},
+		{
+			name:        "ngsq",
+			style:       RegisterStyleNGSQ,
+			generations: 4,
+			numbers:     []int{1, 3, 4},
+			lineage:     []string{"John", "William", "Thomas"},
+			childNums:   []int{2, 3},
+		},
+		{
+			name:        "limited",
+			style:       RegisterStyleNEHGS,
+			generations: 2,
+			numbers:     []int{1, 2},
+			lineage:     []string{"William", "Thomas"},
+			childNums:   []int{0, 2},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			entries := RegisterReport(root, tc.generations, tc.style)
+			if len(entries) != len(tc.numbers) {
+				t.Fatalf("got %d entries, wanted %d", len(entries), len(tc.numbers))
+			}
+			for i, e := range entries {
+				if e.Number != tc.numbers[i] {
+					t.Errorf("entry %d got number %d, wanted %d", i, e.Number, tc.numbers[i])
+				}
+				if e.Generation != i+1 {
+					t.Errorf("entry %d got generation %d, wanted %d", i, e.Generation, i+1)
+				}
+			}
+
+			last := entries[len(entries)-1]
+			var names []string
+			for _, l := range last.Lineage {
+				names = append(names, l.GivenName)
+			}
+			if strings.Join(names, ",") != strings.Join(tc.lineage, ",") {
+				t.Errorf("got lineage %v, wanted %v", names, tc.lineage)
+			}
+
+			children := entries[0].Families[0].Children
+			if len(children) != len(tc.childNums) {
+				t.Fatalf("got %d children, wanted %d", len(children), len(tc.childNums))
+			}
+			for i, c := range children {
+				if c.Number != tc.childNums[i] {
+					t.Errorf("child %d got number %d, wanted %d", i, c.Number, tc.childNums[i])
+				}
+				if c.Ordinal != i+1 {
+					t.Errorf("child %d got ordinal %d, wanted %d", i, c.Ordinal, i+1)
+				}
+			}
+			if entries[0].Families[0].Spouse.Xref != "I2" {
+				t.Errorf("got spouse %s, wanted I2", entries[0].Families[0].Spouse.Xref)
+			}
+		})
+	}
+}
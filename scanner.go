@@ -6,9 +6,12 @@ information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
 package gedcom
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"strconv"
+	"strings"
+	"unicode/utf8"
 )
 
 type Line struct {
@@ -16,8 +19,10 @@ type Line struct {
 	Tag        string
 	Value      string
 	Xref       string
-	LineNumber int // the line number of the input file
-	Offset     int // the character offset in the input file
+	LineNumber int    // the line number of the input file
+	Offset     int    // the byte offset of the start of the line in the input file
+	Length     int    // the number of bytes of the line in the input file, including its line ending
+	Raw        string // the text of the line as read, without its line ending; set only by a Scanner configured with KeepRaw
 }
 
 func (l *Line) String() string {
@@ -34,11 +39,65 @@ type Scanner struct {
 	state  int
 	line   int
 	offset int
+	pos    int64  // total bytes consumed from r
+	start  int64  // byte offset of the start of the current line, including any leading whitespace
+	begin  int64  // byte offset of the level of the current line
+	chars  int    // number of characters in the current line from its level, including its line ending
+	raw    []byte // text of the current line from its level, including its line ending
+	text   string // raw as a string, if it has been converted
 	level  int
-	buf    []rune
+	buf    []byte // the field of the current line being scanned
 	tag    string
 	value  string
 	xref   string
+	field  int // offset in raw of the first character of the field in buf
+	xrefAt span
+	valAt  span
+
+	// tags holds a string for each distinct tag scanned so that tags are converted to
+	// strings only once
+	tags map[string]string
+
+	noNoteFixup  bool // disables the fixup for notes containing unescaped newlines
+	extendedTags bool // accepts punctuation in tags
+	keepRaw      bool // sets the Raw field of each Line
+	noteFixups   int  // number of times the note fixup has been applied
+
+	eol     LineEnding // line ending of the first line
+	eolSeen bool
+
+	last    rune // the most recent character read
+	readErr bool // err was caused by a failure to read from r
+}
+
+// LineEnding is the character sequence used to terminate lines in GEDCOM data.
+type LineEnding int
+
+const (
+	LineEndingLF   LineEnding = iota // a line feed, as used by Unix systems
+	LineEndingCRLF                   // a carriage return followed by a line feed, as used by Windows
+	LineEndingCR                     // a carriage return, as used by classic Mac OS
+)
+
+func (l LineEnding) String() string {
+	switch l {
+	case LineEndingCRLF:
+		return "CRLF"
+	case LineEndingCR:
+		return "CR"
+	}
+	return "LF"
+}
+
+// chars returns the characters that terminate a line
+func (l LineEnding) chars() string {
+	switch l {
+	case LineEndingCRLF:
+		return "\r\n"
+	case LineEndingCR:
+		return "\r"
+	}
+	return "\n"
 }
 
 // NewScanner creates a new Scanner ready for use.
@@ -46,10 +105,22 @@ func NewScanner(r io.RuneScanner) *Scanner {
 	return &Scanner{
 		r:     r,
 		state: stateBegin,
-		buf:   make([]rune, 0, 4),
+		buf:   make([]byte, 0, 64),
+		raw:   make([]byte, 0, 128),
+		tags:  make(map[string]string),
 	}
 }
 
+// A span is the range of raw holding a field of the current line, which is empty if the
+// field is not held in raw as read.
+type span struct {
+	start int
+	end   int
+}
+
+// maxLevelDigits is the number of digits in a level that may be parsed without overflow
+const maxLevelDigits = 9
+
 const (
 	stateBegin = iota
 	stateLevel
@@ -73,13 +144,21 @@ func (s *Scanner) Next() bool {
 	s.xref = ""
 	s.tag = ""
 	s.value = ""
+	s.text = ""
+	s.xrefAt = span{}
+	s.valAt = span{}
 	s.offset = 0
+	s.start = s.pos
+	s.begin = s.pos
+	s.chars = 0
+	s.raw = s.raw[:0]
 	s.line++
 
 	for {
 		c, n, err := s.r.ReadRune()
 		if err != nil {
 			if err != io.EOF {
+				s.readErr = true
 				s.state = stateError
 				s.err = &ScanErr{
 					LineNumber: s.line,
@@ -100,14 +179,25 @@ func (s *Scanner) Next() bool {
 			return false
 		}
 		s.offset += n
+		s.pos += int64(n)
+		s.chars++
+		s.raw = utf8.AppendRune(s.raw, c)
+		s.last = c
 
 		switch s.state {
 		case stateBegin:
 			switch {
 			case c >= '0' && c <= '9':
-				s.buf = append(s.buf, c)
+				s.buf = append(s.buf, byte(c))
 				s.state = stateLevel
+				s.begin = s.pos - int64(n)
+				s.chars = 1
 			case isSpace(c):
+				s.raw = s.raw[:0]
+				continue
+			case c == '\uFEFF' && s.line == 1:
+				// Skip a byte order mark at the start of the input
+				s.raw = s.raw[:0]
 				continue
 			default:
 				s.state = stateError
@@ -121,19 +211,26 @@ func (s *Scanner) Next() bool {
 		case stateLevel:
 			switch {
 			case c >= '0' && c <= '9':
-				s.buf = append(s.buf, c)
+				s.buf = append(s.buf, byte(c))
 				continue
 			case c == ' ':
-				parsedLevel, perr := strconv.ParseInt(string(s.buf), 10, 64)
-				if perr != nil {
-					s.err = &ScanErr{
-						LineNumber: s.line,
-						Offset:     s.offset,
-						Err:        fmt.Errorf("parse level: %w", perr),
+				if len(s.buf) <= maxLevelDigits {
+					s.level = 0
+					for _, d := range s.buf {
+						s.level = s.level*10 + int(d-'0')
 					}
-					return false
+				} else {
+					parsedLevel, perr := strconv.ParseInt(string(s.buf), 10, 64)
+					if perr != nil {
+						s.err = &ScanErr{
+							LineNumber: s.line,
+							Offset:     s.offset,
+							Err:        fmt.Errorf("parse level: %w", perr),
+						}
+						return false
+					}
+					s.level = int(parsedLevel)
 				}
-				s.level = int(parsedLevel)
 				s.buf = s.buf[:0]
 				s.state = stateSeekTagOrXref
 			default:
@@ -148,8 +245,8 @@ func (s *Scanner) Next() bool {
 
 		case stateSeekTag:
 			switch {
-			case isAlphaNumeric(c):
-				s.buf = append(s.buf, c)
+			case s.isTagChar(c):
+				s.buf = append(s.buf, byte(c))
 				s.state = stateTag
 			case c == ' ':
 				continue
@@ -164,11 +261,12 @@ func (s *Scanner) Next() bool {
 			}
 		case stateSeekTagOrXref:
 			switch {
-			case isAlphaNumeric(c):
-				s.buf = append(s.buf, c)
-				s.state = stateTag
 			case c == '@':
 				s.state = stateXref
+				s.field = len(s.raw)
+			case s.isTagChar(c):
+				s.buf = append(s.buf, byte(c))
+				s.state = stateTag
 			case c == ' ':
 				continue
 			default:
@@ -183,17 +281,18 @@ func (s *Scanner) Next() bool {
 
 		case stateTag:
 			switch {
-			case isAlphaNumeric(c):
-				s.buf = append(s.buf, c)
+			case s.isTagChar(c):
+				s.buf = append(s.buf, byte(c))
 				continue
 			case c == '\n' || c == '\r':
 				s.swallowCr(c)
-				s.tag = string(s.buf)
+				s.tag = s.internTag()
 				s.buf = s.buf[:0]
 				s.state = stateEnd
+				s.endLine()
 				return true
 			case c == ' ':
-				s.tag = string(s.buf)
+				s.tag = s.internTag()
 				s.buf = s.buf[:0]
 				s.state = stateSeekValue
 			default:
@@ -209,12 +308,12 @@ func (s *Scanner) Next() bool {
 		case stateXref:
 			switch {
 			case isAlphaNumeric(c):
-				s.buf = append(s.buf, c)
+				s.buf = append(s.buf, byte(c))
 				continue
 			case c == '@':
 				continue
 			case c == ' ':
-				s.xref = string(s.buf)
+				s.xref, s.xrefAt = s.endField()
 				s.buf = s.buf[:0]
 				s.state = stateSeekTag
 			default:
@@ -231,11 +330,13 @@ func (s *Scanner) Next() bool {
 			case c == '\n' || c == '\r':
 				s.swallowCr(c)
 				s.state = stateEnd
+				s.endLine()
 				return true
 			case c == ' ':
 				continue
 			default:
-				s.buf = append(s.buf, c)
+				s.field = len(s.raw) - utf8.RuneLen(c)
+				s.buf = utf8.AppendRune(s.buf, c)
 				s.state = stateValue
 			}
 
@@ -251,52 +352,153 @@ func (s *Scanner) Next() bool {
 				//   1 NOTE Board of Guardian Records and Church of England Parish Registers. London Metropolitan Archives, London.
 				//   <p>Images produced by permission of the City of London Corporation. The City of London gives n
 
-				if s.tag == "NOTE" {
+				if s.tag == "NOTE" && !s.noNoteFixup {
 					next, _, err := s.r.ReadRune()
 					s.r.UnreadRune()
 					if err == nil {
 						if !isNumeric(next) {
 							// Looks like it might be a malformed note, so continue parsing
 							s.buf = append(s.buf, '\n')
+							s.noteFixups++
 							continue
 						}
 					}
 				}
 
-				s.value = string(s.buf)
+				s.value, s.valAt = s.endField()
 				s.buf = s.buf[:0]
 				s.state = stateEnd
+				s.endLine()
 				return true
 			default:
-				s.buf = append(s.buf, c)
+				s.buf = utf8.AppendRune(s.buf, c)
 				continue
 			}
 		}
 	}
 }
 
-// swallowCr skips a carriage return if it is followed by a newline
+// internTag returns the tag in buf as a string, converting each distinct tag only once
+func (s *Scanner) internTag() string {
+	if t, ok := s.tags[string(s.buf)]; ok {
+		return t
+	}
+	if s.tags == nil {
+		s.tags = make(map[string]string)
+	}
+	t := string(s.buf)
+	s.tags[t] = t
+	return t
+}
+
+// endField returns the field in buf if it differs from the text of the line as read, as an
+// xref containing @ characters or a value joined by the note fixup does. Otherwise it
+// returns the span of raw holding the field, which endLine takes from the text of the
+// line without converting it separately.
+func (s *Scanner) endField() (string, span) {
+	end := s.field + len(s.buf)
+	if end <= len(s.raw) && bytes.Equal(s.raw[s.field:end], s.buf) {
+		return "", span{start: s.field, end: end}
+	}
+	return string(s.buf), span{}
+}
+
+// endLine completes the xref and value of the line just scanned. The text of the line is
+// converted to a string only if it holds one of them, which are substrings of it.
+func (s *Scanner) endLine() {
+	if s.xrefAt.start == s.xrefAt.end && s.valAt.start == s.valAt.end {
+		return
+	}
+	s.text = string(s.raw)
+	if s.xrefAt.start != s.xrefAt.end {
+		s.xref = s.text[s.xrefAt.start:s.xrefAt.end]
+	}
+	if s.valAt.start != s.valAt.end {
+		s.value = s.text[s.valAt.start:s.valAt.end]
+	}
+}
+
+// swallowCr skips a carriage return if it is followed by a newline and notes the line
+// ending if it is the first one seen
 func (s *Scanner) swallowCr(c rune) {
+	eol := LineEndingLF
 	if c == '\r' {
-		next, _, _ := s.r.ReadRune()
+		eol = LineEndingCR
+		next, n, _ := s.r.ReadRune()
 		if next == '\n' {
-			s.offset++
+			eol = LineEndingCRLF
+			s.offset += n
+			s.pos += int64(n)
+			s.chars++
+			s.raw = append(s.raw, '\n')
 		} else {
 			s.r.UnreadRune()
 		}
 	}
+	if !s.eolSeen {
+		s.eol = eol
+		s.eolSeen = true
+	}
+}
+
+// recover discards the remainder of the line on which a syntax error was found so that
+// scanning may continue with the following line. It reports whether scanning can
+// continue, which it cannot after a read error or if the input ends on that line.
+func (s *Scanner) recover() bool {
+	if s.err == nil || s.readErr {
+		return false
+	}
+	s.err = nil
+	c := s.last
+	for c != '\n' && c != '\r' {
+		var n int
+		var err error
+		c, n, err = s.r.ReadRune()
+		if err != nil {
+			return false
+		}
+		s.pos += int64(n)
+	}
+	s.swallowCr(c)
+	return true
+}
+
+// LineEnding returns the line ending used by the first line read by the scanner. It
+// returns LineEndingLF if no complete line has been read.
+func (s *Scanner) LineEnding() LineEnding {
+	return s.eol
+}
+
+// KeepRaw configures whether the scanner sets the Raw field of the lines returned by Line.
+// Keeping the raw text may allocate a string for each line, so it is off by default.
+func (s *Scanner) KeepRaw(keep bool) {
+	s.keepRaw = keep
 }
 
-// Line returns the most recent line tokenized by a call to Next.
+// Line returns the most recent line tokenized by a call to Next. Its Raw field is empty
+// unless the scanner has been configured with KeepRaw.
 func (s *Scanner) Line() Line {
-	return Line{
+	l := Line{
 		Level:      s.level,
 		Tag:        s.tag,
 		Value:      s.value,
 		Xref:       s.xref,
 		LineNumber: s.line,
-		Offset:     s.offset,
+		Offset:     int(s.begin),
+		Length:     int(s.pos - s.begin),
 	}
+	if s.keepRaw {
+		l.Raw = strings.TrimRight(s.rawText(), "\r\n")
+	}
+	return l
+}
+
+// rawText returns the text of the current line, including its line ending
+func (s *Scanner) rawText() string {
+	if s.text == "" {
+		s.text = string(s.raw)
+	}
+	return s.text
 }
 
 // Err returns the first non-EOF error that was encountered by the Scanner.
@@ -325,6 +527,16 @@ func isSpace(c rune) bool {
 	return c == ' ' || c == '\t' || c == '\r' || c == '\n'
 }
 
+// isTagChar reports whether c may appear in a tag. Tags are alphanumeric unless the
+// scanner accepts extended tags, which may also contain ASCII punctuation other than @,
+// such as the hyphen in _FOO-BAR.
+func (s *Scanner) isTagChar(c rune) bool {
+	if isAlphaNumeric(c) {
+		return true
+	}
+	return s.extendedTags && c > ' ' && c < 0x7f && c != '@'
+}
+
 func isAlphaNumeric(c rune) bool {
 	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c == '_'
 }
@@ -0,0 +1,26 @@
+//go:build go1.23
+
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+
+package gedcom
+
+import "iter"
+
+// Lines returns an iterator over the lines remaining to be scanned by s, for use with a
+// range loop. Each line is yielded with a nil error. If scanning stops because of an
+// error, the error is yielded with a zero Line as the final pair.
+func (s *Scanner) Lines() iter.Seq2[Line, error] {
+	return func(yield func(Line, error) bool) {
+		for s.Next() {
+			if !yield(s.Line(), nil) {
+				return
+			}
+		}
+		if err := s.Err(); err != nil {
+			yield(Line{}, err)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+//go:build go1.23
+
+package gedcom
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestScannerLines(t *testing.T) {
+	s := NewScanner(bytes.NewReader([]byte("0 HEAD\n1 CHAR UTF-8\n0 TRLR\n")))
+	var tags []string
+	for line, err := range s.Lines() {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		tags = append(tags, line.Tag)
+	}
+	if got, want := len(tags), 3; got != want || tags[0] != "HEAD" || tags[2] != "TRLR" {
+		t.Errorf("got tags %v, wanted HEAD CHAR TRLR", tags)
+	}
+
+	s = NewScanner(bytes.NewReader([]byte("0 HEAD\nX CHAR UTF-8\n0 TRLR\n")))
+	var errs []error
+	n := 0
+	for _, err := range s.Lines() {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		n++
+	}
+	if n != 1 || len(errs) != 1 {
+		t.Errorf("got %d lines and %d errors, wanted 1 line and 1 error", n, len(errs))
+	}
+
+	// Breaking out of the loop leaves the remaining lines to be scanned
+	s = NewScanner(bytes.NewReader([]byte("0 HEAD\n1 CHAR UTF-8\n0 TRLR\n")))
+	for range s.Lines() {
+		break
+	}
+	if !s.Next() || s.Line().Tag != "CHAR" {
+		t.Errorf("got tag %q after breaking from the loop, wanted CHAR", s.Line().Tag)
+	}
+}
@@ -7,6 +7,7 @@ package gedcom
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"io"
 	"testing"
 )
@@ -86,18 +87,22 @@ func TestLineEndings(t *testing.T) {
 	testCases := []struct {
 		platform string
 		input    []byte
+		eol      LineEnding
 	}{
 		{
 			platform: "unix",
 			input:    []byte("0 HEAD\n1 CHAR UTF-8\n1 GEDC\n1 NOTE first line\n2 CONT second line\n"),
+			eol:      LineEndingLF,
 		},
 		{
 			platform: "windows",
 			input:    []byte("0 HEAD\r\n1 CHAR UTF-8\r\n1 GEDC\r\n1 NOTE first line\r\n2 CONT second line\r\n"),
+			eol:      LineEndingCRLF,
 		},
 		{
 			platform: "macclassic",
 			input:    []byte("0 HEAD\r1 CHAR UTF-8\r\n1 GEDC\r1 NOTE first line\r2 CONT second line\r"),
+			eol:      LineEndingCR,
 		},
 	}
 
@@ -140,6 +145,155 @@ func TestLineEndings(t *testing.T) {
 			if s.Next() {
 				t.Errorf("got an unexpected tag")
 			}
+
+			if s.LineEnding() != tc.eol {
+				t.Errorf("got line ending %s, wanted %s", s.LineEnding(), tc.eol)
+			}
 		})
 	}
 }
+
+func TestLineOffsets(t *testing.T) {
+	input := []byte("0 HEAD\r\n1 NAME Zoë\r\n\r\n  2 CONT second\r\n0 TRLR\r\n")
+	want := []string{
+		"0 HEAD\r\n",
+		"1 NAME Zoë\r\n",
+		"2 CONT second\r\n",
+		"0 TRLR\r\n",
+	}
+
+	s := NewScanner(bytes.NewReader(input))
+	for i, w := range want {
+		if !s.Next() {
+			t.Fatalf("missing line %d, err=%v", i+1, s.Err())
+		}
+		l := s.Line()
+		if l.Offset < 0 || l.Offset+l.Length > len(input) {
+			t.Fatalf("line %d has offset %d and length %d outside the input", i+1, l.Offset, l.Length)
+		}
+		if got := string(input[l.Offset : l.Offset+l.Length]); got != w {
+			t.Errorf("line %d at offset %d, length %d is %q, wanted %q", i+1, l.Offset, l.Length, got, w)
+		}
+	}
+}
+
+func TestScanByteOrderMark(t *testing.T) {
+	input := []byte("\xef\xbb\xbf0 HEAD\n1 CHAR UTF-8\n")
+	s := NewScanner(bytes.NewReader(input))
+	if !s.Next() {
+		t.Fatalf("got no line, err=%v", s.Err())
+	}
+	if l := s.Line(); l.Tag != "HEAD" || l.Offset != 3 {
+		t.Errorf("got tag %q at offset %d, wanted HEAD at offset 3", l.Tag, l.Offset)
+	}
+	if !s.Next() || s.Line().Tag != "CHAR" {
+		t.Errorf("second line not read, err=%v", s.Err())
+	}
+
+	// A byte order mark is only allowed at the start of the input
+	s = NewScanner(bytes.NewReader([]byte("0 HEAD\n\xef\xbb\xbf1 CHAR UTF-8\n")))
+	s.Next()
+	if s.Next() {
+		t.Errorf("got line for byte order mark after the first line, wanted error")
+	}
+}
+
+func TestScanExtendedTags(t *testing.T) {
+	input := "0 @I1@ INDI\n1 _FOO-BAR.BAZ value\n"
+
+	s := NewScanner(bytes.NewReader([]byte(input)))
+	s.Next()
+	if s.Next() {
+		t.Errorf("got tag %q, wanted error for a tag containing punctuation", s.Line().Tag)
+	}
+
+	s = NewScanner(bytes.NewReader([]byte(input)))
+	s.extendedTags = true
+	var lines []Line
+	for s.Next() {
+		lines = append(lines, s.Line())
+	}
+	if err := s.Err(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(lines) != 2 || lines[1].Tag != "_FOO-BAR.BAZ" || lines[1].Value != "value" {
+		t.Errorf("got lines %v, wanted second line with tag _FOO-BAR.BAZ and value", lines)
+	}
+}
+
+func TestLineRaw(t *testing.T) {
+	input := []byte("0 HEAD\r\n  1  NAME   John  /Smith/\r\n1 NOTE first\r\nsecond\r\n0 @I1@ INDI\r\n0 TRLR\r\n")
+	want := []string{
+		"0 HEAD",
+		"1  NAME   John  /Smith/",
+		"1 NOTE first\r\nsecond",
+		"0 @I1@ INDI",
+		"0 TRLR",
+	}
+
+	s := NewScanner(bytes.NewReader(input))
+	s.KeepRaw(true)
+	for i, w := range want {
+		if !s.Next() {
+			t.Fatalf("missing line %d, err=%v", i+1, s.Err())
+		}
+		if got := s.Line().Raw; got != w {
+			t.Errorf("line %d raw text is %q, wanted %q", i+1, got, w)
+		}
+	}
+}
+
+func BenchmarkScan(b *testing.B) {
+	b.ReportAllocs()
+	b.SetBytes(int64(len(data)))
+	for i := 0; i < b.N; i++ {
+		s := NewScanner(bytes.NewReader(data))
+		for s.Next() {
+		}
+		if err := s.Err(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkScanLines(b *testing.B) {
+	for _, keepRaw := range []bool{false, true} {
+		b.Run(fmt.Sprintf("raw=%v", keepRaw), func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(data)))
+			for i := 0; i < b.N; i++ {
+				s := NewScanner(bytes.NewReader(data))
+				s.KeepRaw(keepRaw)
+				for s.Next() {
+					_ = s.Line()
+				}
+				if err := s.Err(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestScanAllocations(t *testing.T) {
+	input := bytes.Repeat([]byte("1 BIRT\n2 DATE 1 JAN 1900\n"), 100)
+	r := bytes.NewReader(input)
+	s := NewScanner(r)
+	for s.Next() {
+	}
+
+	// Lines without an xref or value are scanned without allocating and other lines
+	// allocate only the text of the line, also when the line is returned by Line
+	allocs := testing.AllocsPerRun(10, func() {
+		r.Reset(input)
+		for s.Next() {
+			_ = s.Line()
+		}
+	})
+	if allocs > 100 {
+		t.Errorf("got %v allocations to scan 200 lines, wanted no more than 100", allocs)
+	}
+	if err := s.Err(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,189 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+
+package gedcom
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// A TagSchema describes a tag and the subordinate tags it may contain. Schemas are
+// usually loaded from JSON using LoadTagSchemas, for example:
+//
+//	[
+//	  {"tag": "_GEO", "children": [
+//	    {"tag": "_LAT", "min": 1, "max": 1},
+//	    {"tag": "_LONG", "min": 1, "max": 1}
+//	  ]}
+//	]
+//
+// Min and Max limit the number of times a subordinate tag occurs within its parent. They
+// are not supported for the top-level schemas, which may be used in any structure.
+type TagSchema struct {
+	Tag      string       `json:"tag"`
+	Min      int          `json:"min,omitempty"` // minimum number of occurrences
+	Max      int          `json:"max,omitempty"` // maximum number of occurrences, zero for no limit
+	Children []*TagSchema `json:"children,omitempty"`
+}
+
+// LoadTagSchemas reads a JSON array of tag schemas from r.
+func LoadTagSchemas(r io.Reader) ([]*TagSchema, error) {
+	var schemas []*TagSchema
+	if err := json.NewDecoder(r).Decode(&schemas); err != nil {
+		return nil, fmt.Errorf("decode tag schema: %w", err)
+	}
+	for _, s := range schemas {
+		if err := s.check(); err != nil {
+			return nil, err
+		}
+		if s.Min != 0 || s.Max != 0 {
+			return nil, fmt.Errorf("tag schema %s: min and max are not supported for a top-level tag", s.Tag)
+		}
+	}
+	return schemas, nil
+}
+
+func (s *TagSchema) check() error {
+	if s == nil || s.Tag == "" {
+		return fmt.Errorf("tag schema: missing tag name")
+	}
+	if s.Min < 0 || s.Max < 0 || (s.Max > 0 && s.Min > s.Max) {
+		return fmt.Errorf("tag schema %s: invalid cardinality %d..%d", s.Tag, s.Min, s.Max)
+	}
+	for _, c := range s.Children {
+		if err := c.check(); err != nil {
+			return fmt.Errorf("%s: %w", s.Tag, err)
+		}
+	}
+	return nil
+}
+
+// A SchemaValue holds a tag parsed according to a TagSchema.
+type SchemaValue struct {
+	Tag      string
+	Value    string
+	Xref     string
+	Children []*SchemaValue // subordinate tags in the order they were read
+}
+
+// Get returns the first subordinate tag with the given name, or nil if there is none.
+func (v *SchemaValue) Get(tag string) *SchemaValue {
+	if v == nil {
+		return nil
+	}
+	for _, c := range v.Children {
+		if c.Tag == tag {
+			return c
+		}
+	}
+	return nil
+}
+
+// All returns the subordinate tags with the given name, in the order they were read.
+func (v *SchemaValue) All(tag string) []*SchemaValue {
+	if v == nil {
+		return nil
+	}
+	var cs []*SchemaValue
+	for _, c := range v.Children {
+		if c.Tag == tag {
+			cs = append(cs, c)
+		}
+	}
+	return cs
+}
+
+// ValueOf returns the value of the first subordinate tag with the given name.
+func (v *SchemaValue) ValueOf(tag string) string {
+	if c := v.Get(tag); c != nil {
+		return c.Value
+	}
+	return ""
+}
+
+// RegisterTagSchemas registers an extension for each schema so that matching user
+// defined tags are decoded into a *SchemaValue and encoded from one. Decoding a tag that
+// does not conform to its schema fails and the tag is left undecoded. The Min and Max of
+// the schemas given are not supported and are ignored.
+func RegisterTagSchemas(reg *ExtensionRegistry, schemas []*TagSchema) {
+	for _, s := range schemas {
+		s := s
+		reg.Register(s.Tag, Extension{
+			Decode: func(t UserDefinedTag) (any, error) {
+				return s.decode(t)
+			},
+			Encode: func(v any) (UserDefinedTag, error) {
+				sv, ok := v.(*SchemaValue)
+				if !ok {
+					return UserDefinedTag{}, fmt.Errorf("unexpected type %T for tag %s", v, s.Tag)
+				}
+				return s.encode(sv), nil
+			},
+		})
+	}
+}
+
+func (s *TagSchema) decode(t UserDefinedTag) (*SchemaValue, error) {
+	v := &SchemaValue{
+		Tag:   t.Tag,
+		Value: t.Value,
+		Xref:  t.Xref,
+	}
+
+	children := s.children()
+	counts := make(map[string]int, len(s.Children))
+	for _, sub := range t.UserDefined {
+		cs, ok := children[sub.Tag]
+		if !ok {
+			return nil, fmt.Errorf("%s: unexpected tag %s", s.Tag, sub.Tag)
+		}
+		cv, err := cs.decode(sub)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", s.Tag, err)
+		}
+		v.Children = append(v.Children, cv)
+		counts[sub.Tag]++
+	}
+
+	for _, c := range s.Children {
+		n := counts[c.Tag]
+		if n < c.Min {
+			return nil, fmt.Errorf("%s: got %d %s tags, wanted at least %d", s.Tag, n, c.Tag, c.Min)
+		}
+		if c.Max > 0 && n > c.Max {
+			return nil, fmt.Errorf("%s: got %d %s tags, wanted at most %d", s.Tag, n, c.Tag, c.Max)
+		}
+	}
+
+	return v, nil
+}
+
+// encode converts v into a user defined tag, writing subordinate tags in the order they
+// are held and omitting those that are not in the schema
+func (s *TagSchema) encode(v *SchemaValue) UserDefinedTag {
+	t := UserDefinedTag{
+		Tag:   s.Tag,
+		Value: v.Value,
+		Xref:  v.Xref,
+	}
+	children := s.children()
+	for _, cv := range v.Children {
+		if c, ok := children[cv.Tag]; ok {
+			t.UserDefined = append(t.UserDefined, c.encode(cv))
+		}
+	}
+	return t
+}
+
+// children returns the schemas of the subordinate tags of s, keyed by tag
+func (s *TagSchema) children() map[string]*TagSchema {
+	children := make(map[string]*TagSchema, len(s.Children))
+	for _, c := range s.Children {
+		children[c.Tag] = c
+	}
+	return children
+}
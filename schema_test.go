@@ -0,0 +1,88 @@
+package gedcom
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+const testSchemaJSON = `[
+  {"tag": "_GEO", "children": [
+    {"tag": "_LAT", "min": 1, "max": 1},
+    {"tag": "_LONG", "min": 1, "max": 1},
+    {"tag": "_SRC"}
+  ]}
+]`
+
+func TestTagSchema(t *testing.T) {
+	schemas, err := LoadTagSchemas(strings.NewReader(testSchemaJSON))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	reg := NewExtensionRegistry()
+	RegisterTagSchemas(reg, schemas)
+
+	input := `0 @I1@ INDI
+1 _GEO home
+2 _LONG W0.12
+2 _SRC survey
+2 _LAT N51.5
+2 _SRC map
+1 _GEO bad
+2 _LAT N51.5
+0 TRLR
+`
+	g, err := NewDecoder(strings.NewReader(input), WithExtensions(reg)).Decode()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	uds := g.Individual[0].UserDefined
+	v, ok := uds[0].Data.(*SchemaValue)
+	if !ok {
+		t.Fatalf("got data of type %T, wanted *SchemaValue", uds[0].Data)
+	}
+	if v.Value != "home" || v.ValueOf("_LAT") != "N51.5" || v.ValueOf("_LONG") != "W0.12" {
+		t.Errorf("got value %q lat %q long %q", v.Value, v.ValueOf("_LAT"), v.ValueOf("_LONG"))
+	}
+	if n := len(v.All("_SRC")); n != 2 {
+		t.Errorf("got %d _SRC tags, wanted 2", n)
+	}
+	if uds[1].Data != nil {
+		t.Errorf("got data for tag missing required _LONG, wanted nil")
+	}
+
+	buf := new(bytes.Buffer)
+	if err := NewEncoder(buf, WithExtensions(reg)).Encode(g); err != nil {
+		t.Fatalf("unexpected encode error: %v", err)
+	}
+	want := `0 @I1@ INDI
+1 _GEO home
+2 _LONG W0.12
+2 _SRC survey
+2 _LAT N51.5
+2 _SRC map
+1 _GEO bad
+2 _LAT N51.5
+0 TRLR
+`
+	if buf.String() != want {
+		t.Errorf("got output:\n%s\nwanted:\n%s", buf.String(), want)
+	}
+}
+
+func TestLoadTagSchemasInvalid(t *testing.T) {
+	testCases := []string{
+		`[{"tag": ""}]`,
+		`[{"tag": "_X", "min": 2, "max": 1}]`,
+		`[{"tag": "_X", "children": [{"tag": "_Y", "max": -1}]}]`,
+		`[{"tag": "_X", "max": 1}]`,
+		`[{"tag": "_X", "min": 1}]`,
+		`{"tag": "_X"}`,
+	}
+	for _, tc := range testCases {
+		if _, err := LoadTagSchemas(strings.NewReader(tc)); err == nil {
+			t.Errorf("got no error for schema %s", tc)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+
+package gedcom
+
+import (
+	"context"
+	"io"
+)
+
+// Next decodes the next top-level record from the input and returns it, allowing large
+// files to be processed one record at a time with bounded memory. It returns io.EOF once
+// the end of the input has been reached. Level 0 user defined tags and the trailer are
+// skipped.
+//
+// The decoder retains nothing from one record to the next, so references to other
+// records, such as a family's husband or an individual's citations, are to records that
+// hold only an xref. Use the xref to look up the full record once it has been read.
+// Warnings returns the problems found while decoding the record most recently returned.
+// Next should not be used on a Decoder that has been used to Decode.
+func (d *Decoder) Next() (Record, error) {
+	if d.stream == nil {
+		g := &Gedcom{}
+		d.gedcom = g
+		d.warnings = nil
+		d.skippedLines = nil
+		d.refs = make(map[string]interface{})
+		d.arena.reset()
+		d.parsers = []parser{makeRootParser(d, g)}
+		if err := d.seek(); err != nil {
+			return nil, err
+		}
+		st, err := d.newScan()
+		if err != nil {
+			return nil, err
+		}
+		d.stream = st
+	}
+
+	st := d.stream
+	if st.done {
+		return nil, io.EOF
+	}
+
+	var rec Record
+	for {
+		if !st.pending {
+			ok, err := d.nextLine(st)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				st.done = true
+				d.finish()
+				if err := d.endScan(st, d.gedcom); err != nil {
+					return nil, err
+				}
+				if rec == nil {
+					return nil, io.EOF
+				}
+				return rec, nil
+			}
+		}
+		st.pending = false
+
+		if st.s.level == 0 {
+			if rec != nil {
+				// The record is complete, keep the line for the next call
+				st.pending = true
+				return rec, nil
+			}
+			d.resetStream()
+		}
+		if err := d.decodeLine(context.Background(), st); err != nil {
+			return nil, err
+		}
+		if st.s.level == 0 {
+			rec = d.current
+		}
+	}
+}
+
+// resetStream discards the records decoded by Next before the start of the next record
+func (d *Decoder) resetStream() {
+	*d.gedcom = Gedcom{}
+	d.warnings = nil
+	clear(d.refs)
+}
+
+// RecordHandlers holds functions called by DecodeFunc as each top-level record of the
+// corresponding type is decoded. Any function may be nil, in which case records of that
+// type are skipped. Decoding stops if a function returns an error.
+type RecordHandlers struct {
+	Header     func(r *Header) error
+	Individual func(r *IndividualRecord) error
+	Family     func(r *FamilyRecord) error
+	Media      func(r *MediaRecord) error
+	Repository func(r *RepositoryRecord) error
+	Source     func(r *SourceRecord) error
+	Submitter  func(r *SubmitterRecord) error
+	Submission func(r *SubmissionRecord) error
+	Note       func(r *NoteRecord) error
+}
+
+// DecodeFunc decodes the input one record at a time, as Next does, calling the function
+// in h for the type of each record once it is complete. It returns nil at the end of the
+// input or the first error returned by a handler.
+func (d *Decoder) DecodeFunc(h RecordHandlers) error {
+	for {
+		r, err := d.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := h.handle(r); err != nil {
+			return err
+		}
+	}
+}
+
+// handle calls the handler for the type of r
+func (h *RecordHandlers) handle(r Record) error {
+	switch r := r.(type) {
+	case *Header:
+		if h.Header != nil {
+			return h.Header(r)
+		}
+	case *IndividualRecord:
+		if h.Individual != nil {
+			return h.Individual(r)
+		}
+	case *FamilyRecord:
+		if h.Family != nil {
+			return h.Family(r)
+		}
+	case *MediaRecord:
+		if h.Media != nil {
+			return h.Media(r)
+		}
+	case *RepositoryRecord:
+		if h.Repository != nil {
+			return h.Repository(r)
+		}
+	case *SourceRecord:
+		if h.Source != nil {
+			return h.Source(r)
+		}
+	case *SubmitterRecord:
+		if h.Submitter != nil {
+			return h.Submitter(r)
+		}
+	case *SubmissionRecord:
+		if h.Submission != nil {
+			return h.Submission(r)
+		}
+	case *NoteRecord:
+		if h.Note != nil {
+			return h.Note(r)
+		}
+	}
+	return nil
+}
+
+// EncodeHeader begins writing data one record at a time, allowing large trees to be
+// written from a database or another Decoder without holding them all in memory. It
+// writes the header h and should be followed by a call to EncodeRecord for each record
+// and finally EncodeTrailer. Data is written with the version declared by h unless
+// configured otherwise by WithTargetVersion. When writing GEDCOM 7, extension tags must
+// be declared by the Schema of h since the records that use them have not been seen.
+func (e *Encoder) EncodeHeader(h *Header) error {
+	e.begin(h, LineEndingLF)
+	e.recordXref = ""
+	e.header(h)
+	e.collect()
+	return e.result()
+}
+
+// EncodeRecord writes a single top-level record after the header written by EncodeHeader.
+// Pointers to other records are written using their xrefs, so every record, whether or
+// not it has been written yet, must have an xref: WithGeneratedXrefs has no effect. Order
+// the records as they should appear in the output, since WithCanonicalOrder sorts only
+// their substructures. Submission records are not written in GEDCOM 7. A problem with
+// the record is returned as an EncodeError and does not prevent further records from
+// being written.
+func (e *Encoder) EncodeRecord(r Record) error {
+	e.errs = nil
+	if _, ok := r.(*SubmissionRecord); ok && e.v7 {
+		// Submission records were removed in GEDCOM 7
+		return e.result()
+	}
+	e.record(r)
+	return e.result()
+}
+
+// EncodeTrailer finishes writing data begun by EncodeHeader, writing the trailer and
+// flushing any buffered output.
+func (e *Encoder) EncodeTrailer() error {
+	e.errs = nil
+	e.trailer(nil)
+	return e.flush()
+}
@@ -0,0 +1,289 @@
+package gedcom
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNext(t *testing.T) {
+	input := `0 HEAD
+1 CHAR UTF-8
+0 @I1@ INDI
+1 NAME Jane /Doe/
+1 FAMS @F1@
+0 @F1@ FAM
+1 WIFE @I1@
+1 MARR
+2 DATE 1901
+0 _CUSTOM data
+0 @N1@ NOTE A shared note
+0 TRLR
+`
+	d := NewDecoder(strings.NewReader(input))
+
+	var got []string
+	var recs []Record
+	for {
+		r, err := d.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		recs = append(recs, r)
+		switch r := r.(type) {
+		case *Header:
+			got = append(got, "HEAD "+r.CharacterSet)
+		case *IndividualRecord:
+			got = append(got, "INDI "+r.Xref+" "+r.Name[0].Name)
+		case *FamilyRecord:
+			got = append(got, "FAM "+r.Xref+" "+r.Event[0].Date)
+		case *NoteRecord:
+			got = append(got, "NOTE "+r.Xref+" "+r.Note)
+		default:
+			t.Errorf("unexpected record type %T", r)
+		}
+	}
+
+	want := []string{
+		"HEAD UTF-8",
+		"INDI I1 Jane /Doe/",
+		"FAM F1 1901",
+		"NOTE N1 A shared note",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("records mismatch (-want +got):\n%s", diff)
+	}
+
+	// References are to records holding only an xref
+	fam := recs[2].(*FamilyRecord)
+	if fam.Wife == nil || fam.Wife.Xref != "I1" || len(fam.Wife.Name) != 0 {
+		t.Errorf("got wife %+v, wanted a record holding only the xref I1", fam.Wife)
+	}
+	if fam.Wife == recs[1] {
+		t.Errorf("wife refers to a previously returned record")
+	}
+
+	if _, err := d.Next(); !errors.Is(err, io.EOF) {
+		t.Errorf("got error %v after end of input, wanted io.EOF", err)
+	}
+}
+
+func TestNextNoTrailer(t *testing.T) {
+	d := NewDecoder(strings.NewReader("0 @I1@ INDI\n1 NAME Jane /Doe/\n"))
+	r, err := d.Next()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if i, ok := r.(*IndividualRecord); !ok || i.Xref != "I1" {
+		t.Errorf("got record %+v, wanted individual I1", r)
+	}
+	if _, err := d.Next(); !errors.Is(err, io.EOF) {
+		t.Errorf("got error %v, wanted io.EOF", err)
+	}
+}
+
+func TestNextMatchesDecode(t *testing.T) {
+	data, err := os.ReadFile("testdata/allged.ged")
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	g, err := NewDecoder(bytes.NewReader(data)).Decode()
+	if err != nil {
+		t.Fatalf("unexpected decode error: %v", err)
+	}
+
+	counts := make(map[string]int)
+	d := NewDecoder(bytes.NewReader(data))
+	for {
+		r, err := d.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		switch r.(type) {
+		case *Header:
+			counts["HEAD"]++
+		case *IndividualRecord:
+			counts["INDI"]++
+		case *FamilyRecord:
+			counts["FAM"]++
+		case *MediaRecord:
+			counts["OBJE"]++
+		case *RepositoryRecord:
+			counts["REPO"]++
+		case *SourceRecord:
+			counts["SOUR"]++
+		case *SubmitterRecord:
+			counts["SUBM"]++
+		case *SubmissionRecord:
+			counts["SUBN"]++
+		case *NoteRecord:
+			counts["NOTE"]++
+		}
+	}
+
+	want := map[string]int{
+		"HEAD": 1,
+		"INDI": len(g.Individual),
+		"FAM":  len(g.Family),
+		"OBJE": len(g.Media),
+		"REPO": len(g.Repository),
+		"SOUR": len(g.Source),
+		"SUBM": len(g.Submitter),
+		"SUBN": len(g.Submission),
+		"NOTE": len(g.Note),
+	}
+	for k, v := range want {
+		if v == 0 {
+			delete(want, k)
+		}
+	}
+	if diff := cmp.Diff(want, counts); diff != "" {
+		t.Errorf("record counts mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestDecodeFunc(t *testing.T) {
+	input := `0 HEAD
+0 @I1@ INDI
+1 NAME Jane /Doe/
+0 @I2@ INDI
+1 NAME John /Doe/
+0 @F1@ FAM
+1 HUSB @I2@
+1 WIFE @I1@
+0 @S1@ SOUR
+1 TITL Parish register
+0 TRLR
+`
+	var got []string
+	err := NewDecoder(strings.NewReader(input)).DecodeFunc(RecordHandlers{
+		Individual: func(r *IndividualRecord) error {
+			got = append(got, r.Xref)
+			return nil
+		},
+		Family: func(r *FamilyRecord) error {
+			got = append(got, r.Xref+" "+r.Husband.Xref+" "+r.Wife.Xref)
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff([]string{"I1", "I2", "F1 I2 I1"}, got); diff != "" {
+		t.Errorf("handled records mismatch (-want +got):\n%s", diff)
+	}
+
+	// An error from a handler stops decoding
+	errStop := errors.New("stop")
+	got = nil
+	err = NewDecoder(strings.NewReader(input)).DecodeFunc(RecordHandlers{
+		Individual: func(r *IndividualRecord) error {
+			got = append(got, r.Xref)
+			return errStop
+		},
+	})
+	if !errors.Is(err, errStop) {
+		t.Errorf("got error %v, wanted %v", err, errStop)
+	}
+	if diff := cmp.Diff([]string{"I1"}, got); diff != "" {
+		t.Errorf("handled records mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestEncodeRecord(t *testing.T) {
+	data, err := os.ReadFile("testdata/kennedy.ged")
+	if err != nil {
+		t.Fatalf("failed to read testdata/kennedy.ged")
+	}
+	g, err := NewDecoder(bytes.NewReader(data)).Decode()
+	if err != nil {
+		t.Fatalf("unexpected decode error: %v", err)
+	}
+	g.UserDefined = nil
+
+	want := new(bytes.Buffer)
+	if err := NewEncoder(want, WithLineEnding(LineEndingLF)).Encode(g); err != nil {
+		t.Fatalf("unexpected encode error: %v", err)
+	}
+
+	got := new(bytes.Buffer)
+	e := NewEncoder(got)
+	if err := e.EncodeHeader(g.Header); err != nil {
+		t.Fatalf("unexpected error encoding header: %v", err)
+	}
+	var records []Record
+	for _, r := range g.Individual {
+		records = append(records, r)
+	}
+	for _, r := range g.Family {
+		records = append(records, r)
+	}
+	for _, r := range g.Media {
+		records = append(records, r)
+	}
+	for _, r := range g.Repository {
+		records = append(records, r)
+	}
+	for _, r := range g.Source {
+		records = append(records, r)
+	}
+	for _, r := range g.Submitter {
+		records = append(records, r)
+	}
+	for _, r := range g.Submission {
+		records = append(records, r)
+	}
+	for _, r := range g.Note {
+		records = append(records, r)
+	}
+	for _, r := range records {
+		if err := e.EncodeRecord(r); err != nil {
+			t.Fatalf("unexpected error encoding record: %v", err)
+		}
+	}
+	if err := e.EncodeTrailer(); err != nil {
+		t.Fatalf("unexpected error encoding trailer: %v", err)
+	}
+
+	if diff := cmp.Diff(want.String(), got.String()); diff != "" {
+		t.Errorf("streamed output differs from Encode (-want +got):\n%s", diff)
+	}
+}
+
+func TestEncodeRecordError(t *testing.T) {
+	buf := new(bytes.Buffer)
+	e := NewEncoder(buf)
+	if err := e.EncodeHeader(&Header{Version: "5.5.1"}); err != nil {
+		t.Fatalf("unexpected error encoding header: %v", err)
+	}
+
+	err := e.EncodeRecord(&FamilyRecord{Xref: "F1", Husband: &IndividualRecord{}})
+	var ee *EncodeError
+	if !errors.As(err, &ee) {
+		t.Fatalf("got error %v, wanted an EncodeError", err)
+	}
+	if ee.Xref != "F1" || ee.Path != "FAM.HUSB" {
+		t.Errorf("got error for @%s@ %s, wanted @F1@ FAM.HUSB", ee.Xref, ee.Path)
+	}
+
+	if err := e.EncodeRecord(&IndividualRecord{Xref: "I1"}); err != nil {
+		t.Errorf("unexpected error encoding record after a failed record: %v", err)
+	}
+	if err := e.EncodeTrailer(); err != nil {
+		t.Fatalf("unexpected error encoding trailer: %v", err)
+	}
+	if got := buf.String(); !strings.HasSuffix(got, "0 @I1@ INDI\n0 TRLR\n") {
+		t.Errorf("records following an error were not written:\n%s", got)
+	}
+}
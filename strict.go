@@ -0,0 +1,115 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+
+package gedcom
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Errors wrapped by a StrictError.
+var (
+	ErrMissingHeader  = errors.New("first record is not a header")
+	ErrMissingTrailer = errors.New("last record is not a trailer")
+	ErrInvalidLevel   = errors.New("level is more than one greater than the previous line")
+	ErrUnknownTag     = errors.New("tag is not defined by the GEDCOM standard and does not begin with an underscore")
+	ErrValueTooLong   = errors.New("value is longer than 255 characters")
+	ErrLineTooLong    = errors.New("line is longer than 255 characters")
+)
+
+// maxValueLength is the maximum number of characters allowed in a line value
+const maxValueLength = 255
+
+// A StrictError describes a violation of the GEDCOM specification found by a Decoder
+// configured with WithStrict.
+type StrictError struct {
+	Err        error
+	LineNumber int
+	Tag        string
+}
+
+func (e *StrictError) Error() string {
+	if e.Tag == "" {
+		return fmt.Sprintf("strict error (line:%d): %v", e.LineNumber, e.Err)
+	}
+	return fmt.Sprintf("strict error (line:%d, tag:%s): %v", e.LineNumber, e.Tag, e.Err)
+}
+
+func (e *StrictError) Unwrap() error {
+	return e.Err
+}
+
+// WithStrict configures the decoder to stop at the first violation of the GEDCOM
+// specification and return a StrictError describing it, rather than tolerating the
+// violation. The decoder rejects data that does not begin with a header or end with a
+// trailer, lines whose level is more than one greater than the previous line, tags that
+// are neither standard nor begin with an underscore and values longer than 255
+// characters. The NOTE fixup enabled by WithNoteFixup is not applied in strict mode.
+func WithStrict() DecoderOption {
+	return decoderOptionFunc(func(d *Decoder) {
+		d.strict = true
+	})
+}
+
+// strictChecker tracks the state needed to check each line read by a strict Decoder
+type strictChecker struct {
+	lines     int    // number of lines checked
+	line      int    // line number of the previous line
+	prevLevel int    // level of the previous line
+	lastTag   string // tag of the most recent level 0 line
+	version7  bool   // the input is GEDCOM 7
+}
+
+// check returns an error if the current line of s violates the specification. If
+// fromStart is false the input does not begin at the start of the data and the header
+// is not required.
+func (c *strictChecker) check(s *Scanner, fromStart bool) error {
+	c.lines++
+	fail := func(err error) error {
+		return &StrictError{Err: err, LineNumber: s.line, Tag: s.tag}
+	}
+
+	if c.lines == 1 && fromStart && (s.level != 0 || s.tag != "HEAD") {
+		return fail(ErrMissingHeader)
+	}
+	if c.lines > 1 && s.level > c.prevLevel+1 {
+		return fail(ErrInvalidLevel)
+	}
+	c.prevLevel = s.level
+	c.line = s.line
+	if s.level == 0 {
+		c.lastTag = s.tag
+	}
+	if !strings.HasPrefix(s.tag, "_") && !isStandardTag(s.tag, c.version7) {
+		return fail(ErrUnknownTag)
+	}
+	if utf8.RuneCountInString(s.value) > maxValueLength {
+		return fail(ErrValueTooLong)
+	}
+	return nil
+}
+
+// finish returns an error if the data read did not end with a trailer
+func (c *strictChecker) finish() error {
+	if c.lastTag != "TRLR" {
+		return &StrictError{Err: ErrMissingTrailer, LineNumber: c.line}
+	}
+	return nil
+}
+
+// standardTags returns the set of tags defined by the GEDCOM 5.5.1 grammar
+var standardTags = sync.OnceValue(func() map[string]bool {
+	tags := make(map[string]bool)
+	for _, rules := range grammar551 {
+		for tag := range rules {
+			tags[tag] = true
+		}
+	}
+	return tags
+})
@@ -0,0 +1,91 @@
+package gedcom
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestStrictDecode(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		wantErr  error
+		wantLine int
+	}{
+		{
+			name:  "valid",
+			input: "0 HEAD\n1 CHAR UTF-8\n0 @I1@ INDI\n1 NAME Jane /Doe/\n1 _MILT Navy\n0 TRLR\n",
+		},
+		{
+			name:     "missing header",
+			input:    "0 @I1@ INDI\n1 NAME Jane /Doe/\n0 TRLR\n",
+			wantErr:  ErrMissingHeader,
+			wantLine: 1,
+		},
+		{
+			name:     "missing trailer",
+			input:    "0 HEAD\n0 @I1@ INDI\n1 NAME Jane /Doe/\n",
+			wantErr:  ErrMissingTrailer,
+			wantLine: 3,
+		},
+		{
+			name:     "level jump",
+			input:    "0 HEAD\n0 @I1@ INDI\n2 DATE 1901\n0 TRLR\n",
+			wantErr:  ErrInvalidLevel,
+			wantLine: 3,
+		},
+		{
+			name:     "unknown tag",
+			input:    "0 HEAD\n0 @I1@ INDI\n1 MILT Navy\n0 TRLR\n",
+			wantErr:  ErrUnknownTag,
+			wantLine: 3,
+		},
+		{
+			name:     "long value",
+			input:    "0 HEAD\n0 @I1@ INDI\n1 NOTE " + strings.Repeat("x", 256) + "\n0 TRLR\n",
+			wantErr:  ErrValueTooLong,
+			wantLine: 3,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewDecoder(strings.NewReader(tc.input), WithStrict()).Decode()
+			if tc.wantErr == nil {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("got error %v, wanted %v", err, tc.wantErr)
+			}
+			var se *StrictError
+			if !errors.As(err, &se) {
+				t.Fatalf("got error of type %T, wanted *StrictError", err)
+			}
+			if se.LineNumber != tc.wantLine {
+				t.Errorf("got line %d, wanted %d", se.LineNumber, tc.wantLine)
+			}
+
+			// Without strict mode the same data is decoded without error
+			if _, err := NewDecoder(strings.NewReader(tc.input)).Decode(); err != nil {
+				t.Errorf("unexpected error when not strict: %v", err)
+			}
+		})
+	}
+}
+
+func TestStrictDecodeAllged(t *testing.T) {
+	f, err := os.Open("testdata/allged.ged")
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer f.Close()
+
+	if _, err := NewDecoder(f, WithStrict()).Decode(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
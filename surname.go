@@ -0,0 +1,134 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+
+package gedcom
+
+import (
+	"strings"
+	"unicode"
+)
+
+// joinedParticles are the surname particles that are capitalized and form part of the
+// surname itself, such as Mac in Mac Donald, rather than preceding it
+var joinedParticles = map[string]bool{
+	"MAC": true,
+	"MC":  true,
+	"O":   true,
+	"ST":  true,
+}
+
+// NormalizeSurname returns surname in a standard form: runs of whitespace are collapsed,
+// leading particles such as van and de are written in lower case, and words written
+// entirely in upper or lower case are capitalized, so that "VAN DER BERG" becomes "van
+// der Berg". Words in mixed case, such as McDonald, are unchanged, as are diacritics.
+// Particles are those recognized by SplitPersonalName, configured by opts.
+func NormalizeSurname(surname string, opts ...NameOption) string {
+	cfg := newNameConfig(opts)
+	prefix, rest := splitLeadingWords(surname, cfg.particles, 1)
+
+	words := strings.Fields(prefix)
+	for i, w := range words {
+		if joinedParticles[strings.ToUpper(strings.TrimSuffix(w, "."))] {
+			words[i] = capitalizeWord(w)
+		} else {
+			words[i] = strings.ToLower(w)
+		}
+	}
+	for _, w := range strings.Fields(rest) {
+		words = append(words, capitalizeWord(w))
+	}
+	return strings.Join(words, " ")
+}
+
+// SurnameKey returns a key for grouping surnames that differ only in case, diacritics,
+// punctuation, spacing or leading particles, so that "van der Berg", "BERG" and "Bérg"
+// share the key "berg". Particles that form part of the surname, such as Mac and O, are
+// kept, so "Mac Donald" and "MacDonald" share a key but differ from "Donald". Particles
+// are those recognized by SplitPersonalName, configured by opts.
+func SurnameKey(surname string, opts ...NameOption) string {
+	cfg := newNameConfig(opts)
+	prefix, rest := splitLeadingWords(surname, cfg.particles, 1)
+
+	var kept []string
+	for _, w := range strings.Fields(prefix) {
+		if joinedParticles[strings.ToUpper(strings.TrimSuffix(w, "."))] {
+			kept = append(kept, w)
+		}
+	}
+	return strings.Join(matchWords(joinWords(strings.Join(kept, " "), rest)), "")
+}
+
+// GroupBySurname returns the individuals in g grouped by the SurnameKey of the surnames in
+// their names, using name pieces where present. An individual with several names appears
+// once in the group for each distinct key. Individuals without a surname are not included.
+func GroupBySurname(g *Gedcom, opts ...NameOption) map[string][]*IndividualRecord {
+	groups := make(map[string][]*IndividualRecord)
+	for _, r := range g.Individual {
+		if r == nil {
+			continue
+		}
+		seen := make(map[string]bool)
+		for _, n := range r.Name {
+			if n == nil {
+				continue
+			}
+			key := SurnameKey(n.Parsed(opts...).fullSurname(), opts...)
+			if key == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			groups[key] = append(groups[key], r)
+		}
+	}
+	return groups
+}
+
+// capitalizeWord returns w with its first letter and each letter following a hyphen or
+// apostrophe in upper case and the rest in lower case, unless w is already in mixed case
+func capitalizeWord(w string) string {
+	if strings.ToUpper(w) != w && strings.ToLower(w) != w {
+		return w
+	}
+	rs := []rune(strings.ToLower(w))
+	upper := true
+	for i, r := range rs {
+		if upper && unicode.IsLetter(r) {
+			rs[i] = unicode.ToUpper(r)
+			upper = false
+		}
+		if r == '-' || r == '\'' || r == '’' {
+			upper = true
+		}
+	}
+	return string(rs)
+}
+
+// accentFolds maps accented and ligature letters to their unaccented equivalents
+var accentFolds = strings.NewReplacer(
+	"À", "A", "Á", "A", "Â", "A", "Ã", "A", "Ä", "A", "Å", "A", "Ą", "A", "Ă", "A", "Ā", "A", "Æ", "AE",
+	"Ç", "C", "Ć", "C", "Č", "C", "Ď", "D", "Đ", "D", "Ð", "D",
+	"È", "E", "É", "E", "Ê", "E", "Ë", "E", "Ę", "E", "Ě", "E", "Ē", "E", "Ė", "E",
+	"Ğ", "G", "Ì", "I", "Í", "I", "Î", "I", "Ï", "I", "İ", "I", "Ī", "I",
+	"Ł", "L", "Ľ", "L", "Ĺ", "L", "Ñ", "N", "Ń", "N", "Ň", "N",
+	"Ò", "O", "Ó", "O", "Ô", "O", "Õ", "O", "Ö", "O", "Ø", "O", "Ő", "O", "Ō", "O", "Œ", "OE",
+	"Ř", "R", "Ŕ", "R", "Ś", "S", "Š", "S", "Ş", "S", "Ș", "S", "ẞ", "SS", "Ť", "T", "Ţ", "T", "Ț", "T", "Þ", "TH",
+	"Ù", "U", "Ú", "U", "Û", "U", "Ü", "U", "Ů", "U", "Ű", "U", "Ū", "U",
+	"Ý", "Y", "Ÿ", "Y", "Ź", "Z", "Ż", "Z", "Ž", "Z",
+	"à", "a", "á", "a", "â", "a", "ã", "a", "ä", "a", "å", "a", "ą", "a", "ă", "a", "ā", "a", "æ", "ae",
+	"ç", "c", "ć", "c", "č", "c", "ď", "d", "đ", "d", "ð", "d",
+	"è", "e", "é", "e", "ê", "e", "ë", "e", "ę", "e", "ě", "e", "ē", "e", "ė", "e",
+	"ğ", "g", "ì", "i", "í", "i", "î", "i", "ï", "i", "ı", "i", "ī", "i",
+	"ł", "l", "ľ", "l", "ĺ", "l", "ñ", "n", "ń", "n", "ň", "n",
+	"ò", "o", "ó", "o", "ô", "o", "õ", "o", "ö", "o", "ø", "o", "ő", "o", "ō", "o", "œ", "oe",
+	"ř", "r", "ŕ", "r", "ś", "s", "š", "s", "ş", "s", "ș", "s", "ß", "ss", "ť", "t", "ţ", "t", "ț", "t", "þ", "th",
+	"ù", "u", "ú", "u", "û", "u", "ü", "u", "ů", "u", "ű", "u", "ū", "u",
+	"ý", "y", "ÿ", "y", "ź", "z", "ż", "z", "ž", "z",
+)
+
+// StripDiacritics replaces accented Latin letters and ligatures in s with their
+// unaccented equivalents, such as ö with o and æ with ae. Other characters are unchanged.
+func StripDiacritics(s string) string {
+	return accentFolds.Replace(s)
+}
@@ -0,0 +1,93 @@
+package gedcom
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalizeSurname(t *testing.T) {
+	testCases := map[string]string{
+		"VAN DER Berg":     "van der Berg",
+		"van der berg":     "van der Berg",
+		"  de   la  CRUZ ": "de la Cruz",
+		"SMITH":            "Smith",
+		"smith-jones":      "Smith-Jones",
+		"O'BRIEN":          "O'Brien",
+		"MAC DONALD":       "Mac Donald",
+		"McDonald":         "McDonald",
+		"MÜLLER":           "Müller",
+		"Van":              "Van",
+		"":                 "",
+	}
+	for in, want := range testCases {
+		if got := NormalizeSurname(in); got != want {
+			t.Errorf("NormalizeSurname(%q) = %q, wanted %q", in, got, want)
+		}
+	}
+
+	if got, want := NormalizeSurname("AP RHYS", WithSurnamePrefixes("ap")), "ap Rhys"; got != want {
+		t.Errorf("NormalizeSurname with prefixes = %q, wanted %q", got, want)
+	}
+}
+
+func TestSurnameKey(t *testing.T) {
+	testCases := map[string]string{
+		"van der Berg": "berg",
+		"BERG":         "berg",
+		"Bérg":         "berg",
+		"O'Brien":      "obrien",
+		"O Brien":      "obrien",
+		"Mac Donald":   "macdonald",
+		"MacDonald":    "macdonald",
+		"Donald":       "donald",
+		"Smith-Jones":  "smithjones",
+		"de":           "de",
+		"":             "",
+	}
+	for in, want := range testCases {
+		if got := SurnameKey(in); got != want {
+			t.Errorf("SurnameKey(%q) = %q, wanted %q", in, got, want)
+		}
+	}
+}
+
+func TestGroupBySurname(t *testing.T) {
+	input := `
+0 @I1@ INDI
+1 NAME Anna /van der Berg/
+0 @I2@ INDI
+1 NAME Jan /BERG/
+1 NAME Jan /Berg/
+0 @I3@ INDI
+1 NAME Piet
+2 SURN Bérg
+0 @I4@ INDI
+1 NAME Mary /Smith/
+1 NAME Mary /Jones/
+0 @I5@ INDI
+1 NAME Unknown
+`
+	g, err := NewDecoder(strings.NewReader(input)).Decode()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	groups := GroupBySurname(g)
+	want := map[string][]string{
+		"berg":  {"I1", "I2", "I3"},
+		"smith": {"I4"},
+		"jones": {"I4"},
+	}
+	if len(groups) != len(want) {
+		t.Errorf("got %d groups, wanted %d", len(groups), len(want))
+	}
+	for key, xrefs := range want {
+		var got []string
+		for _, r := range groups[key] {
+			got = append(got, r.Xref)
+		}
+		if strings.Join(got, " ") != strings.Join(xrefs, " ") {
+			t.Errorf("group %q: got %v, wanted %v", key, got, xrefs)
+		}
+	}
+}
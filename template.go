@@ -0,0 +1,224 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+
+package gedcom
+
+import (
+	"strings"
+	"text/template"
+)
+
+// TemplateData is a view of a Gedcom intended for use as the data of a text/template or
+// html/template.
+type TemplateData struct {
+	Header      *Header
+	Individuals []IndividualView
+	Families    []*FamilyRecord
+	Sources     []*SourceRecord
+	Repository  []*RepositoryRecord
+}
+
+// NewTemplateData creates a TemplateData for g.
+func NewTemplateData(g *Gedcom) *TemplateData {
+	td := &TemplateData{
+		Header:     g.Header,
+		Families:   g.Family,
+		Sources:    g.Source,
+		Repository: g.Repository,
+	}
+	for _, r := range g.Individual {
+		td.Individuals = append(td.Individuals, IndividualView{r})
+	}
+	return td
+}
+
+// IndividualView wraps an IndividualRecord with methods that are convenient to call
+// from templates. All fields of the record remain accessible.
+type IndividualView struct {
+	*IndividualRecord
+}
+
+// DisplayName returns the individual's primary name in a readable form.
+func (v IndividualView) DisplayName() string {
+	return formatName(v.IndividualRecord)
+}
+
+// Birth returns the first birth event of the individual, falling back to christening
+// or baptism. It returns nil if there is no such event.
+func (v IndividualView) Birth() *EventRecord {
+	return firstEvent(v.Event, "BIRT", "CHR", "BAPM")
+}
+
+// Death returns the first death event of the individual, falling back to burial or
+// cremation. It returns nil if there is no such event.
+func (v IndividualView) Death() *EventRecord {
+	return firstEvent(v.Event, "DEAT", "BURI", "CREM")
+}
+
+// Father returns the husband of the individual's first parent family, if any.
+func (v IndividualView) Father() *IndividualView {
+	for _, fl := range v.Parents {
+		if fl != nil && fl.Family != nil && fl.Family.Husband != nil {
+			return &IndividualView{fl.Family.Husband}
+		}
+	}
+	return nil
+}
+
+// Mother returns the wife of the individual's first parent family, if any.
+func (v IndividualView) Mother() *IndividualView {
+	for _, fl := range v.Parents {
+		if fl != nil && fl.Family != nil && fl.Family.Wife != nil {
+			return &IndividualView{fl.Family.Wife}
+		}
+	}
+	return nil
+}
+
+// Spouses returns the partners of the individual across all of their families.
+func (v IndividualView) Spouses() []IndividualView {
+	var vs []IndividualView
+	for _, fl := range v.Family {
+		if fl == nil || fl.Family == nil {
+			continue
+		}
+		if s := spouseIn(fl.Family, v.IndividualRecord); s != nil {
+			vs = append(vs, IndividualView{s})
+		}
+	}
+	return vs
+}
+
+// Children returns the children of the individual across all of their families.
+func (v IndividualView) Children() []IndividualView {
+	var vs []IndividualView
+	for _, fl := range v.Family {
+		if fl == nil || fl.Family == nil {
+			continue
+		}
+		for _, c := range fl.Family.Child {
+			if c != nil {
+				vs = append(vs, IndividualView{c})
+			}
+		}
+	}
+	return vs
+}
+
+// TemplateFuncs returns functions for formatting names, dates and places in templates.
+// The map may be converted to an html/template FuncMap.
+//
+//	name     formats the primary name of an individual or a GEDCOM name string
+//	given    returns the given names of an individual
+//	surname  returns the surname of an individual
+//	date     formats a GEDCOM date value for reading, e.g. "ABT 1 JAN 1900" as "about 1 Jan 1900"
+//	place    formats a PlaceRecord or place name, removing empty jurisdictions
+//	sex      formats a sex value as "male", "female" or "unknown"
+//
+// An individual may be given as an IndividualView, an *IndividualView or an
+// *IndividualRecord.
+func TemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"name": func(v any) string {
+			if s, ok := v.(string); ok {
+				return SplitPersonalName(s).Full
+			}
+			return formatName(templateIndividual(v))
+		},
+		"given": func(v any) string {
+			r := templateIndividual(v)
+			if r == nil {
+				return ""
+			}
+			return givenName(r)
+		},
+		"surname": func(v any) string {
+			r := templateIndividual(v)
+			if r == nil || len(r.Name) == 0 || r.Name[0] == nil {
+				return ""
+			}
+			if r.Name[0].NamePieceSurname != "" {
+				return r.Name[0].NamePieceSurname
+			}
+			return SplitPersonalName(r.Name[0].Name).fullSurname()
+		},
+		"date": formatDate,
+		"place": func(v any) string {
+			switch p := v.(type) {
+			case PlaceRecord:
+				return formatPlace(p.Name)
+			case *PlaceRecord:
+				if p == nil {
+					return ""
+				}
+				return formatPlace(p.Name)
+			case string:
+				return formatPlace(p)
+			}
+			return ""
+		},
+		"sex": func(s string) string {
+			switch strings.ToUpper(strings.TrimSpace(s)) {
+			case "M":
+				return "male"
+			case "F":
+				return "female"
+			}
+			return "unknown"
+		},
+	}
+}
+
+// templateIndividual returns the individual record held by v, which may be an
+// IndividualView, an *IndividualView or an *IndividualRecord, or nil if it holds none
+func templateIndividual(v any) *IndividualRecord {
+	switch r := v.(type) {
+	case *IndividualRecord:
+		return r
+	case IndividualView:
+		return r.IndividualRecord
+	case *IndividualView:
+		if r != nil {
+			return r.IndividualRecord
+		}
+	}
+	return nil
+}
+
+func formatName(r *IndividualRecord) string {
+	if r == nil || len(r.Name) == 0 || r.Name[0] == nil {
+		return ""
+	}
+	return SplitPersonalName(r.Name[0].Name).Full
+}
+
+var dateWords = map[string]string{
+	"ABT": "about", "CAL": "calculated", "EST": "estimated", "BEF": "before", "AFT": "after",
+	"BET": "between", "AND": "and", "FROM": "from", "TO": "to", "INT": "interpreted",
+	"JAN": "Jan", "FEB": "Feb", "MAR": "Mar", "APR": "Apr", "MAY": "May", "JUN": "Jun",
+	"JUL": "Jul", "AUG": "Aug", "SEP": "Sep", "OCT": "Oct", "NOV": "Nov", "DEC": "Dec",
+}
+
+// formatDate converts the keywords of a GEDCOM date into readable words
+func formatDate(s string) string {
+	fs := strings.Fields(s)
+	for i, f := range fs {
+		if w, ok := dateWords[strings.ToUpper(f)]; ok {
+			fs[i] = w
+		}
+	}
+	return strings.Join(fs, " ")
+}
+
+// formatPlace removes empty jurisdictions from a place name
+func formatPlace(s string) string {
+	var parts []string
+	for _, p := range strings.Split(s, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			parts = append(parts, p)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,54 @@
+package gedcom
+
+import (
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func TestTemplate(t *testing.T) {
+	input := `
+0 @I1@ INDI
+1 NAME John /Smith/
+1 SEX M
+1 BIRT
+2 DATE ABT 1 JAN 1850
+2 PLAC London, , England
+1 FAMS @F1@
+0 @I2@ INDI
+1 NAME Mary /Jones/
+1 SEX F
+1 FAMS @F1@
+0 @I3@ INDI
+1 NAME William /Smith/
+1 FAMC @F1@
+0 @F1@ FAM
+1 HUSB @I1@
+1 WIFE @I2@
+1 CHIL @I3@
+`
+	g, err := NewDecoder(strings.NewReader(input)).Decode()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tmpl := template.Must(template.New("t").Funcs(TemplateFuncs()).Parse(
+		`{{range .Individuals}}{{name .}} ({{sex .Sex}}){{with .Birth}} b. {{date .Date}} {{place .Place}}{{end}}` +
+			`{{with .Father}} son of {{given .}} {{surname .}}{{end}}{{if .Mother}} and {{name .Mother}}{{end}}` +
+			`{{range .Spouses}} m. {{surname .}}{{end}}` +
+			`{{range .Children}} father of {{given .}}{{end}}` + "\n{{end}}" +
+			`{{range .Families}}{{name .Husband}} & {{given .Wife}} {{surname .Wife}}{{end}}`))
+
+	buf := new(strings.Builder)
+	if err := tmpl.Execute(buf, NewTemplateData(g)); err != nil {
+		t.Fatalf("execute template: %v", err)
+	}
+
+	want := "John Smith (male) b. about 1 Jan 1850 London, England m. Jones father of William\n" +
+		"Mary Jones (female) m. Smith father of William\n" +
+		"William Smith (unknown) son of John Smith and Mary Jones\n" +
+		"John Smith & Mary Jones"
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwanted:\n%s", got, want)
+	}
+}
@@ -5,6 +5,8 @@ information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
 
 package gedcom
 
+import "io/fs"
+
 type Gedcom struct {
 	Header      *Header
 	Family      []*FamilyRecord
@@ -13,10 +15,32 @@ type Gedcom struct {
 	Repository  []*RepositoryRecord
 	Source      []*SourceRecord
 	Submitter   []*SubmitterRecord
+	Submission  []*SubmissionRecord
+	Note        []*NoteRecord // shared note records
+	Order       []Record      // records other than the header in the order they were decoded, see WithSourceOrder
 	Trailer     *Trailer
 	UserDefined []UserDefinedTag
+	Unhandled   []UnhandledTag // tags found where the decoder has no place to store them
+	LineEnding  LineEnding     // line ending used by the decoded data, reused by the Encoder
+}
+
+// A Record is one of the top-level records held by a Gedcom: a *Header, *IndividualRecord,
+// *FamilyRecord, *MediaRecord, *RepositoryRecord, *SourceRecord, *SubmitterRecord,
+// *SubmissionRecord or a shared *NoteRecord.
+type Record interface {
+	isRecord()
 }
 
+func (*Header) isRecord()           {}
+func (*IndividualRecord) isRecord() {}
+func (*FamilyRecord) isRecord()     {}
+func (*MediaRecord) isRecord()      {}
+func (*RepositoryRecord) isRecord() {}
+func (*SourceRecord) isRecord()     {}
+func (*SubmitterRecord) isRecord()  {}
+func (*SubmissionRecord) isRecord() {}
+func (*NoteRecord) isRecord()       {}
+
 // A Header contains information about the GEDCOM file.
 type Header struct {
 	SourceSystem        SystemRecord
@@ -34,9 +58,17 @@ type Header struct {
 	Language            string
 	Place               PlaceRecord
 	Note                string
+	Schema              []SchemaTag // 7.0, extension tags declared by SCHMA
 	UserDefined         []UserDefinedTag
 }
 
+// A SchemaTag declares the URI that defines the meaning of an extension tag, given by a
+// TAG line of the SCHMA structure of a GEDCOM 7 header.
+type SchemaTag struct {
+	Tag string
+	URI string
+}
+
 // A SystemRecord contains information about the system that produced the GEDCOM.
 type SystemRecord struct {
 	Xref            string
@@ -50,22 +82,37 @@ type SystemRecord struct {
 	UserDefined     []UserDefinedTag
 }
 
+// A SubmissionRecord holds the LDS submission metadata of a SUBN record.
 type SubmissionRecord struct {
-	Xref string
+	Xref              string
+	Submitter         *SubmitterRecord
+	FamilyFile        string // name of the family file, FAMF
+	Temple            string // temple code, TEMP
+	Ancestors         string // generations of ancestors, ANCE
+	Descendants       string // generations of descendants, DESC
+	Ordinance         string // ordinance process flag, ORDI
+	AutomatedRecordId string
+	Note              []*NoteRecord
+	Change            ChangeRecord
+	UserDefined       []UserDefinedTag
 }
 
 type Trailer struct{}
 
 type FamilyRecord struct {
 	Xref              string
+	RestrictionNotice string // 5.5.1, one of confidential, locked or privacy
 	Husband           *IndividualRecord
 	Wife              *IndividualRecord
 	Child             []*IndividualRecord
 	Event             []*EventRecord
 	NumberOfChildren  string
+	Ordinance         []*LdsOrdinanceRecord // LDS spouse sealings
 	UserReference     []*UserReferenceRecord
 	AutomatedRecordId string
 	Change            ChangeRecord
+	Creation          ChangeRecord        // 7.0, CREA
+	ExternalID        []*ExternalIDRecord // 7.0
 	Note              []*NoteRecord
 	Citation          []*CitationRecord
 	Media             []*MediaRecord
@@ -74,6 +121,7 @@ type FamilyRecord struct {
 
 type IndividualRecord struct {
 	Xref                      string
+	RestrictionNotice         string // 5.5.1, one of confidential, locked or privacy
 	Name                      []*NameRecord
 	Sex                       string
 	Event                     []*EventRecord
@@ -82,14 +130,18 @@ type IndividualRecord struct {
 	Family                    []*FamilyLinkRecord
 	Submitter                 []*SubmitterRecord
 	Association               []*AssociationRecord
+	Ordinance                 []*LdsOrdinanceRecord // LDS individual ordinances
 	PermanentRecordFileNumber string
 	AncestralFileNumber       string
 	UserReference             []*UserReferenceRecord
 	AutomatedRecordId         string
 	Change                    ChangeRecord
+	Creation                  ChangeRecord        // 7.0, CREA
+	ExternalID                []*ExternalIDRecord // 7.0
 	Note                      []*NoteRecord
 	Citation                  []*CitationRecord
 	Media                     []*MediaRecord
+	DNA                       []*DNARecord // vendor extension
 	UserDefined               []UserDefinedTag
 }
 
@@ -100,6 +152,8 @@ type MediaRecord struct {
 	UserReference     []*UserReferenceRecord
 	AutomatedRecordId string
 	Change            ChangeRecord
+	Creation          ChangeRecord        // 7.0, CREA
+	ExternalID        []*ExternalIDRecord // 7.0
 	Note              []*NoteRecord
 	Citation          []*CitationRecord
 	UserDefined       []UserDefinedTag
@@ -111,17 +165,20 @@ type FileRecord struct {
 	FormatType  string
 	Title       string
 	UserDefined []UserDefinedTag
+	Archive     fs.FS `json:"-"` // GEDZIP archive holding the file, set by ReadGedzip
 }
 
 type UserReferenceRecord struct {
-	Number string
-	Type   string
+	Number      string
+	Type        string
+	UserDefined []UserDefinedTag
 }
 
 type ChangeRecord struct {
-	Date string
-	Time string
-	Note []*NoteRecord
+	Date        string
+	Time        string
+	Note        []*NoteRecord
+	UserDefined []UserDefinedTag
 }
 
 type RepositoryRecord struct {
@@ -132,6 +189,8 @@ type RepositoryRecord struct {
 	UserReference     []*UserReferenceRecord
 	AutomatedRecordId string
 	Change            ChangeRecord
+	Creation          ChangeRecord        // 7.0, CREA
+	ExternalID        []*ExternalIDRecord // 7.0
 	UserDefined       []UserDefinedTag
 }
 
@@ -147,35 +206,45 @@ type SourceRecord struct {
 	UserReference     []*UserReferenceRecord
 	AutomatedRecordId string
 	Change            ChangeRecord
+	Creation          ChangeRecord        // 7.0, CREA
+	ExternalID        []*ExternalIDRecord // 7.0
 	Note              []*NoteRecord
 	Media             []*MediaRecord
 	UserDefined       []UserDefinedTag
 }
 
 type SourceDataRecord struct {
-	Event []*SourceEventRecord
+	Event       []*SourceEventRecord
+	Agency      string // agency responsible for the original data
+	Note        []*NoteRecord
+	UserDefined []UserDefinedTag
 }
 
 type SourceEventRecord struct {
-	Kind  string
-	Date  string
-	Place string
+	Kind        string
+	Date        string
+	Place       string
+	UserDefined []UserDefinedTag
 }
 
 type SourceRepositoryRecord struct {
-	Repository *RepositoryRecord
-	Note       []*NoteRecord
-	CallNumber []*SourceCallNumberRecord
+	Repository  *RepositoryRecord
+	Note        []*NoteRecord
+	CallNumber  []*SourceCallNumberRecord
+	UserDefined []UserDefinedTag
 }
 
 type SourceCallNumberRecord struct {
-	CallNumber string
-	MediaType  string
+	CallNumber  string
+	MediaType   string
+	UserDefined []UserDefinedTag
 }
 
 type CitationRecord struct {
 	Source      *SourceRecord
 	Page        string
+	Event       string // type of event the source was cited for, such as BIRT
+	Role        string // role of the individual in the cited event, such as CHIL
 	Data        DataRecord
 	Quay        string
 	Media       []*MediaRecord
@@ -193,6 +262,9 @@ type SubmitterRecord struct {
 	AutomatedRecordId     string
 	Note                  []*NoteRecord
 	Change                *ChangeRecord
+	Creation              *ChangeRecord       // 7.0, CREA
+	ExternalID            []*ExternalIDRecord // 7.0
+	UserDefined           []UserDefinedTag
 }
 
 type NameRecord struct {
@@ -222,6 +294,7 @@ type VariantNameRecord struct {
 	NamePieceSuffix        string
 	Citation               []*CitationRecord
 	Note                   []*NoteRecord
+	UserDefined            []UserDefinedTag
 }
 
 type DataRecord struct {
@@ -237,7 +310,9 @@ type EventRecord struct {
 	Date                 string
 	Place                PlaceRecord
 	Address              AddressRecord
-	Age                  string
+	Age                  string // age of the individual at the time of the event, such as 42y 6m
+	HusbandAge           string // age of the husband at the time of a family event
+	WifeAge              string // age of the wife at the time of a family event
 	ResponsibleAgency    string
 	ReligiousAffiliation string
 	Cause                string
@@ -251,29 +326,34 @@ type EventRecord struct {
 }
 
 type NoteRecord struct {
-	Note     string
-	Citation []*CitationRecord
+	Xref        string // set for shared note records only
+	Note        string
+	Citation    []*CitationRecord
+	UserDefined []UserDefinedTag
 }
 
 type PlaceRecord struct {
-	Name      string
-	Phonetic  []*VariantPlaceNameRecord
-	Romanized []*VariantPlaceNameRecord
-	Latitude  string
-	Longitude string
-	Citation  []*CitationRecord
-	Note      []*NoteRecord
+	Name        string
+	Phonetic    []*VariantPlaceNameRecord
+	Romanized   []*VariantPlaceNameRecord
+	Latitude    string
+	Longitude   string
+	Citation    []*CitationRecord
+	Note        []*NoteRecord
+	UserDefined []UserDefinedTag
 }
 
 type VariantPlaceNameRecord struct {
-	Name string
-	Type string
+	Name        string
+	Type        string
+	UserDefined []UserDefinedTag
 }
 
 type FamilyLinkRecord struct {
-	Family *FamilyRecord
-	Type   string
-	Note   []*NoteRecord
+	Family      *FamilyRecord
+	Type        string
+	Note        []*NoteRecord
+	UserDefined []UserDefinedTag
 }
 
 // See https://www.tamurajones.net/GEDCOMADDR.xhtml for very informative analysis of the ADDR structure
@@ -304,11 +384,62 @@ type UserDefinedTag struct {
 	Xref        string
 	Level       int
 	UserDefined []UserDefinedTag
+	Data        any    // value decoded by a registered Extension, if any
+	URI         string // URI declared for the tag by the header's SCHMA structure, if any
+}
+
+// An UnhandledTag is a tag, together with its subordinate tags, that the decoder found
+// within a structure that has no place to store it. Unhandled tags are not written by the
+// Encoder.
+type UnhandledTag struct {
+	UserDefinedTag
+	Line       int    // line number of the tag
+	Record     string // tag of the level 0 record containing the tag
+	RecordXref string // xref of the level 0 record containing the tag
 }
 
+// A DNARecord holds the result of a DNA test recorded using one of the vendor extension tags
+// _DNA, _MTDNA or _YDNA. The value is usually a haplogroup or a description of the test.
+type DNARecord struct {
+	Tag         string
+	Value       string
+	Type        string
+	Date        string
+	Note        []*NoteRecord
+	UserDefined []UserDefinedTag
+}
+
+// An AssociationRecord links an individual to an associated individual, such as a witness
+// or godparent, with the relationship given by Relation.
 type AssociationRecord struct {
-	Xref     string
-	Relation string
-	Citation []*CitationRecord
-	Note     []*NoteRecord
+	Xref        string            // xref of the associated record
+	Individual  *IndividualRecord // the associated individual, resolved from Xref when decoding
+	Relation    string
+	Citation    []*CitationRecord
+	Note        []*NoteRecord
+	UserDefined []UserDefinedTag
+}
+
+// An LdsOrdinanceRecord holds an LDS ordinance of an individual, which is a baptism (BAPL),
+// confirmation (CONL), endowment (ENDL) or sealing to parents (SLGC), or a sealing of
+// spouses (SLGS).
+type LdsOrdinanceRecord struct {
+	Tag         string
+	Status      string
+	StatusDate  string
+	Date        string
+	Temple      string
+	Place       string
+	Family      *FamilyRecord // family of the parents for a sealing to parents
+	Citation    []*CitationRecord
+	Note        []*NoteRecord
+	UserDefined []UserDefinedTag
+}
+
+// An ExternalIDRecord holds an identifier given to a record by an external system, such as
+// a FamilySearch person ID, recorded using the GEDCOM 7 EXID tag.
+type ExternalIDRecord struct {
+	ID          string
+	Type        string // URI of the system that issued the identifier
+	UserDefined []UserDefinedTag
 }
@@ -0,0 +1,260 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+
+package gedcom
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// UpdateRecords copies the GEDCOM data read from src to w, re-encoding only the top-level
+// records whose xrefs are listed in changed. All other lines are copied unmodified, which
+// avoids re-encoding a large file after a small number of edits.
+//
+// Each changed record found in src is replaced by the record in g with the same xref, or
+// removed if g no longer holds a record with that xref. Changed records in g that are not
+// found in src are written before the trailer, or at the end if src has no trailer. The
+// options are applied to the Encoder used to write the changed records, which uses the
+// line ending of src unless configured with WithLineEnding. The changed records are
+// written in the GEDCOM version declared by the header of src, overriding
+// WithTargetVersion unless src declares no version, and WithOutputCharset is ignored.
+// An error is returned if src uses a character set other than UTF-8 or ASCII, since the
+// copied lines could not be mixed with the re-encoded ones.
+func UpdateRecords(w io.Writer, src io.Reader, g *Gedcom, changed []string, opts ...EncoderOption) error {
+	records := make(map[string]Record)
+	add := func(xref string, r Record) {
+		if xref != "" {
+			records[xref] = r
+		}
+	}
+	for _, r := range g.Individual {
+		if r != nil {
+			add(r.Xref, r)
+		}
+	}
+	for _, r := range g.Family {
+		if r != nil {
+			add(r.Xref, r)
+		}
+	}
+	for _, r := range g.Media {
+		if r != nil {
+			add(r.Xref, r)
+		}
+	}
+	for _, r := range g.Repository {
+		if r != nil {
+			add(r.Xref, r)
+		}
+	}
+	for _, r := range g.Source {
+		if r != nil {
+			add(r.Xref, r)
+		}
+	}
+	for _, r := range g.Submitter {
+		if r != nil {
+			add(r.Xref, r)
+		}
+	}
+	for _, r := range g.Submission {
+		if r != nil {
+			add(r.Xref, r)
+		}
+	}
+	for _, r := range g.Note {
+		if r != nil {
+			add(r.Xref, r)
+		}
+	}
+
+	pending := make(map[string]bool, len(changed))
+	for _, xref := range changed {
+		pending[xref] = true
+	}
+
+	br := bufio.NewReader(src)
+	if bom, err := br.Peek(2); err == nil && (bytes.Equal(bom, []byte{0xFF, 0xFE}) || bytes.Equal(bom, []byte{0xFE, 0xFF})) {
+		return fmt.Errorf("update source: character set %s is not supported", CharsetUnicode)
+	}
+
+	// Read the header first so that changed records are written in the version and
+	// character set of the lines copied from src
+	var head [][]byte
+	var line []byte
+	var err error
+	for {
+		line, err = readLine(br)
+		if _, _, ok := parseRecordLine(line); ok && len(head) > 0 {
+			break
+		}
+		if len(line) > 0 {
+			head = append(head, line)
+			line = nil
+		}
+		if err != nil {
+			break
+		}
+	}
+	h, herr := sourceHeader(head)
+	if herr != nil {
+		return herr
+	}
+
+	e := NewEncoder(w, opts...)
+	if h.Version != "" {
+		e.version = h.Version
+	}
+	e.charset = ""
+	eol := LineEndingLF
+	if len(head) > 0 {
+		eol = lineEndingOf(head[0])
+	}
+	e.begin(h, eol)
+
+	// flushPending writes the changed records that were not found in src
+	flushPending := func() {
+		for _, x := range changed {
+			if pending[x] {
+				pending[x] = false
+				if r, ok := records[x]; ok {
+					e.record(r)
+				}
+			}
+		}
+	}
+
+	skip := false
+	copyLine := func(line []byte) {
+		if xref, tag, ok := parseRecordLine(line); ok {
+			skip = false
+			if tag == "TRLR" {
+				flushPending()
+			}
+			if pending[xref] {
+				pending[xref] = false
+				skip = true
+				if r, ok := records[xref]; ok {
+					e.record(r)
+				}
+			}
+		}
+		if !skip && e.err == nil {
+			if _, err := e.w.Write(line); err != nil {
+				e.err = fmt.Errorf("copy line: %w", err)
+			}
+		}
+	}
+
+	for _, l := range head {
+		copyLine(l)
+	}
+	for {
+		if len(line) > 0 {
+			copyLine(line)
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("read source: %w", err)
+		}
+		line, err = readLine(br)
+	}
+
+	// Records are still pending if src has no trailer
+	flushPending()
+
+	return e.flush()
+}
+
+// sourceHeader returns the version and character set declared by the header lines of the
+// source read by UpdateRecords, returning an error if the character set is one that the
+// lines copied from the source could not be mixed with
+func sourceHeader(lines [][]byte) (*Header, error) {
+	h := &Header{}
+	s := NewScanner(bytes.NewReader(bytes.Join(lines, nil)))
+	parent := ""
+	for s.Next() {
+		l := s.Line()
+		switch {
+		case l.Level == 0 && l.Tag != "HEAD":
+			return h, nil
+		case l.Level == 1:
+			parent = l.Tag
+			if l.Tag == "CHAR" {
+				h.CharacterSet = strings.TrimSpace(l.Value)
+			}
+		case l.Level == 2 && parent == "GEDC" && l.Tag == "VERS":
+			h.Version = strings.TrimSpace(l.Value)
+		}
+	}
+
+	switch strings.ToUpper(h.CharacterSet) {
+	case "", CharsetUTF8, CharsetASCII:
+	default:
+		return nil, fmt.Errorf("update source: character set %s is not supported", h.CharacterSet)
+	}
+	return h, nil
+}
+
+// readLine reads a line from br including its terminator, which may be LF, CRLF or CR
+func readLine(br *bufio.Reader) ([]byte, error) {
+	var line []byte
+	for {
+		c, err := br.ReadByte()
+		if err != nil {
+			return line, err
+		}
+		line = append(line, c)
+		switch c {
+		case '\n':
+			return line, nil
+		case '\r':
+			if next, err := br.Peek(1); err == nil && next[0] == '\n' {
+				br.ReadByte()
+				line = append(line, '\n')
+			}
+			return line, nil
+		}
+	}
+}
+
+// lineEndingOf returns the line ending that terminates line
+func lineEndingOf(line []byte) LineEnding {
+	switch {
+	case bytes.HasSuffix(line, []byte("\r\n")):
+		return LineEndingCRLF
+	case bytes.HasSuffix(line, []byte("\r")):
+		return LineEndingCR
+	}
+	return LineEndingLF
+}
+
+// parseRecordLine reports whether line is a level 0 line, returning the xref and tag
+// of the record it begins
+func parseRecordLine(line []byte) (xref string, tag string, ok bool) {
+	line = bytes.TrimPrefix(line, []byte("\xef\xbb\xbf"))
+	line = bytes.TrimLeft(line, " \t")
+	if len(line) < 2 || line[0] != '0' || (line[1] != ' ' && line[1] != '\t') {
+		return "", "", false
+	}
+	fields := bytes.Fields(line[1:])
+	if len(fields) == 0 {
+		return "", "", false
+	}
+	if len(fields[0]) > 2 && fields[0][0] == '@' && fields[0][len(fields[0])-1] == '@' {
+		xref = string(fields[0][1 : len(fields[0])-1])
+		fields = fields[1:]
+	}
+	if len(fields) > 0 {
+		tag = string(fields[0])
+	}
+	return xref, tag, true
+}
@@ -0,0 +1,132 @@
+package gedcom
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestUpdateRecords(t *testing.T) {
+	input := "0 HEAD\r\n" +
+		"1 CHAR UTF-8\r\n" +
+		"0 @I1@ INDI\r\n" +
+		"1 NAME John /Doe/\r\n" +
+		"1  SEX M\r\n" +
+		"0 @I2@ INDI\r\n" +
+		"1 NAME Jane /Doe/\r\n" +
+		"0 @I3@ INDI\r\n" +
+		"1 NAME Old /Record/\r\n" +
+		"0 TRLR\r\n"
+
+	g, err := NewDecoder(strings.NewReader(input)).Decode()
+	if err != nil {
+		t.Fatalf("unexpected decode error: %v", err)
+	}
+
+	g.Individual[1].Name[0].Name = "Janet /Doe/"
+	g.Individual = append(g.Individual[:2], &IndividualRecord{
+		Xref: "I4",
+		Name: []*NameRecord{{Name: "New /Record/"}},
+	})
+
+	buf := new(bytes.Buffer)
+	if err := UpdateRecords(buf, strings.NewReader(input), g, []string{"I2", "I3", "I4"}); err != nil {
+		t.Fatalf("unexpected update error: %v", err)
+	}
+
+	want := "0 HEAD\r\n" +
+		"1 CHAR UTF-8\r\n" +
+		"0 @I1@ INDI\r\n" +
+		"1 NAME John /Doe/\r\n" +
+		"1  SEX M\r\n" +
+		"0 @I2@ INDI\r\n" +
+		"1 NAME Janet /Doe/\r\n" +
+		"0 @I4@ INDI\r\n" +
+		"1 NAME New /Record/\r\n" +
+		"0 TRLR\r\n"
+
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%q\nwant:\n%q", got, want)
+	}
+}
+
+func TestUpdateRecordsNoTrailer(t *testing.T) {
+	input := "0 HEAD\n" +
+		"1 CHAR UTF-8\n" +
+		"0 @I1@ INDI\n" +
+		"1 NAME John /Doe/\n"
+
+	g := &Gedcom{
+		Individual: []*IndividualRecord{
+			{Xref: "I1", Name: []*NameRecord{{Name: "John /Doe/"}}},
+			{Xref: "I2", Name: []*NameRecord{{Name: "New /Record/"}}},
+		},
+	}
+
+	buf := new(bytes.Buffer)
+	if err := UpdateRecords(buf, strings.NewReader(input), g, []string{"I2"}); err != nil {
+		t.Fatalf("unexpected update error: %v", err)
+	}
+
+	want := input +
+		"0 @I2@ INDI\n" +
+		"1 NAME New /Record/\n"
+
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%q\nwant:\n%q", got, want)
+	}
+}
+
+func TestUpdateRecordsSourceVersion(t *testing.T) {
+	input := "0 HEAD\n" +
+		"1 GEDC\n" +
+		"2 VERS 7.0\n" +
+		"0 @I1@ INDI\n" +
+		"1 RESN confidential\n" +
+		"0 TRLR\n"
+
+	g := &Gedcom{
+		Individual: []*IndividualRecord{
+			{Xref: "I1", RestrictionNotice: "privacy"},
+		},
+	}
+
+	buf := new(bytes.Buffer)
+	if err := UpdateRecords(buf, strings.NewReader(input), g, []string{"I1"}, WithTargetVersion("5.5.1")); err != nil {
+		t.Fatalf("unexpected update error: %v", err)
+	}
+
+	want := "0 HEAD\n" +
+		"1 GEDC\n" +
+		"2 VERS 7.0\n" +
+		"0 @I1@ INDI\n" +
+		"1 RESN PRIVACY\n" +
+		"0 TRLR\n"
+
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%q\nwant:\n%q", got, want)
+	}
+}
+
+func TestUpdateRecordsUnsupportedCharset(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input string
+	}{
+		{name: "ansel", input: "0 HEAD\n1 CHAR ANSEL\n0 TRLR\n"},
+		{name: "unicode", input: "0 HEAD\n1 CHAR UNICODE\n0 TRLR\n"},
+		{name: "utf-16 bom", input: "\xff\xfe0\x00 \x00H\x00E\x00A\x00D\x00\n\x00"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			if err := UpdateRecords(buf, strings.NewReader(tc.input), &Gedcom{}, nil); err == nil {
+				t.Errorf("got no error, wanted one")
+			}
+			if buf.Len() != 0 {
+				t.Errorf("got output %q, wanted none", buf.String())
+			}
+		})
+	}
+}
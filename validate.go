@@ -0,0 +1,627 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+
+package gedcom
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// A Finding describes a way in which GEDCOM data does not conform to the grammar.
+type Finding struct {
+	Line    int    // line number of the tag, or of the containing structure for missing tags
+	Path    string // path of the structure from its record, e.g. "INDI.BIRT.DATE"
+	Xref    string // xref of the record containing the structure, if any
+	Rule    string // the rule that was broken, one of the Rule constants
+	Message string
+}
+
+func (f Finding) String() string {
+	if f.Xref != "" {
+		return fmt.Sprintf("line %d: @%s@ %s: %s", f.Line, f.Xref, f.Path, f.Message)
+	}
+	return fmt.Sprintf("line %d: %s: %s", f.Line, f.Path, f.Message)
+}
+
+// Rules reported in findings.
+const (
+	RuleTooMany       = "too-many"       // a tag occurs more often than allowed
+	RuleMissing       = "missing"        // a required tag is missing
+	RuleUnexpectedTag = "unexpected-tag" // a standard tag that is not allowed in the structure
+	RuleInvalidLevel  = "invalid-level"  // a line's level is more than one greater than its parent's
+	RuleConflicting   = "conflicting"    // an individual has births or deaths with different dates or places
+	RuleChildOrder    = "child-order"    // a family's children are not listed in order of birth
+	RuleVersion       = "version"        // a standard tag that is not defined in the version declared by the header
+)
+
+// Validate reads GEDCOM data from r and checks the tags of every structure against the
+// GEDCOM 5.5.1 grammar, reporting tags that occur more often than allowed, required
+// tags that are missing and standard tags that are not allowed where they appear.
+// Subordinate tags of user defined tags, which begin with an underscore, are not checked.
+// Individuals with more than one birth or death event whose dates or places differ, often
+// the result of merging records, are also reported, listing the conflicting events and
+// the sources cited for them, as are families whose CHIL tags are not in order of the
+// children's birth dates.
+//
+// Tags are also checked against the GEDCOM version declared by the VERS tag of the
+// header's GEDC structure, reporting tags such as EMAIL that were introduced after the
+// declared version or tags such as CONC that were removed before it. Data that declares
+// GEDCOM 5.5 is checked against the 5.5 grammar, in which multimedia objects hold their
+// data in a BLOB. The structures of data that declares
+// GEDCOM 7 are not checked against a grammar, only its tags are checked. Data with no
+// declared version is assumed to be GEDCOM 5.5.1. The returned error is non-nil only if
+// the data could not be read.
+func Validate(r io.Reader) ([]Finding, error) {
+	v := &validator{}
+	v.push(validateFrame{ctx: "root"})
+
+	s := NewScanner(bufio.NewReader(r))
+	last := 0
+	for s.Next() {
+		l := s.Line()
+		v.line(l)
+		last = l.LineNumber
+	}
+	if err := s.Err(); err != nil {
+		return v.findings, err
+	}
+	// Tags missing from the top level are reported at the end of the data
+	v.stack[0].line = last
+	for len(v.stack) > 0 {
+		v.pop()
+	}
+	v.checkChildOrder()
+	return v.findings, nil
+}
+
+type validateFrame struct {
+	tag    string
+	xref   string
+	ctx    string // grammar context of the structure
+	line   int
+	counts map[string]int
+}
+
+// count records an occurrence of tag within the structure
+func (f *validateFrame) count(tag string) {
+	if f.counts == nil {
+		f.counts = make(map[string]int)
+	}
+	f.counts[tag]++
+}
+
+type validator struct {
+	stack    []validateFrame
+	findings []Finding
+	vitals   []vitalEvent // birth and death events of the current individual
+	births   map[string]string
+	baptisms map[string]string
+	children []childLink
+	version  string // version declared by the header, empty until it is seen
+}
+
+// A childLink records a CHIL tag of a family
+type childLink struct {
+	family string
+	child  string
+	line   int
+}
+
+// A vitalEvent holds the details of a birth or death event used to detect conflicts
+type vitalEvent struct {
+	tag     string
+	line    int
+	date    string
+	place   string
+	sources []string
+}
+
+func (v *validator) push(f validateFrame) {
+	v.stack = append(v.stack, f)
+}
+
+// pop removes the innermost structure, checking that its required tags were present
+func (v *validator) pop() {
+	f := v.stack[len(v.stack)-1]
+	ctx := f.ctx
+	if v.version == "7.0" && ctx != "root" {
+		ctx = "*"
+	}
+	var missing []string
+	for tag, r := range v.grammar()[ctx] {
+		if r.min > 0 && f.counts[tag] < r.min {
+			missing = append(missing, tag)
+		}
+	}
+	sort.Strings(missing)
+	for _, tag := range missing {
+		v.add(f.line, "", RuleMissing, "missing required %s tag", tag)
+	}
+	if len(v.stack) == 2 && f.tag == "INDI" {
+		v.checkVitals()
+	}
+	v.stack = v.stack[:len(v.stack)-1]
+}
+
+// collectVital records details of birth and death events of individuals
+func (v *validator) collectVital(l Line) {
+	switch {
+	case l.Level == 1 && v.stack[1].tag == "INDI" && (l.Tag == "BIRT" || l.Tag == "DEAT"):
+		v.vitals = append(v.vitals, vitalEvent{tag: l.Tag, line: l.LineNumber})
+	case l.Level == 2 && len(v.vitals) > 0 && v.vitals[len(v.vitals)-1].line == v.stack[2].line:
+		ev := &v.vitals[len(v.vitals)-1]
+		switch l.Tag {
+		case "DATE":
+			ev.date = l.Value
+		case "PLAC":
+			ev.place = l.Value
+		case "SOUR":
+			ev.sources = append(ev.sources, l.Value)
+		}
+	}
+}
+
+// collectChildOrder records the children of families and the birth dates of individuals
+func (v *validator) collectChildOrder(l Line) {
+	switch {
+	case l.Level == 1 && v.stack[1].tag == "FAM" && l.Tag == "CHIL":
+		v.children = append(v.children, childLink{
+			family: v.stack[1].xref,
+			child:  strings.Trim(l.Value, "@"),
+			line:   l.LineNumber,
+		})
+	case l.Level == 2 && l.Tag == "DATE" && v.stack[1].tag == "INDI" && v.stack[1].xref != "":
+		m := &v.baptisms
+		switch v.stack[2].tag {
+		case "BIRT":
+			m = &v.births
+		case "CHR", "BAPM":
+		default:
+			return
+		}
+		if *m == nil {
+			*m = make(map[string]string)
+		}
+		if _, ok := (*m)[v.stack[1].xref]; !ok {
+			(*m)[v.stack[1].xref] = l.Value
+		}
+	}
+}
+
+// checkChildOrder reports children listed before a sibling who was certainly born
+// earlier. Christening or baptism dates are used for children with no birth date and
+// children whose birth date is unknown are ignored.
+func (v *validator) checkChildOrder() {
+	type dated struct {
+		childLink
+		date   string
+		lo, hi int // first and last days the date could refer to
+	}
+	var prev *dated
+	for _, c := range v.children {
+		if prev != nil && prev.family != c.family {
+			prev = nil
+		}
+		date, ok := v.births[c.child]
+		if !ok {
+			date, ok = v.baptisms[c.child]
+		}
+		if !ok {
+			continue
+		}
+		dv, ok := ParseDate(date)
+		if !ok {
+			continue
+		}
+		lo, hi, ok := dv.bounds()
+		if !ok {
+			continue
+		}
+		// Open bounds never compare as out of order
+		cur := &dated{childLink: c, date: date, lo: lo, hi: hi}
+		if prev != nil && prev.lo > cur.hi {
+			v.findings = append(v.findings, Finding{
+				Line:    c.line,
+				Path:    "FAM.CHIL",
+				Xref:    c.family,
+				Rule:    RuleChildOrder,
+				Message: fmt.Sprintf("child @%s@ born %s is listed after @%s@ born %s", c.child, date, prev.child, prev.date),
+			})
+		}
+		prev = cur
+	}
+}
+
+// checkVitals reports births or deaths of the current individual that conflict
+func (v *validator) checkVitals() {
+	for _, tag := range []string{"BIRT", "DEAT"} {
+		var evs []vitalEvent
+		for _, ev := range v.vitals {
+			if ev.tag == tag {
+				evs = append(evs, ev)
+			}
+		}
+		if !vitalsConflict(evs) {
+			continue
+		}
+		descs := make([]string, len(evs))
+		for i, ev := range evs {
+			descs[i] = ev.String()
+		}
+		v.add(evs[0].line, tag, RuleConflicting, "conflicting %s events: %s", tag, strings.Join(descs, "; "))
+	}
+	v.vitals = v.vitals[:0]
+}
+
+// vitalsConflict reports whether any two events have different dates or places
+func vitalsConflict(evs []vitalEvent) bool {
+	differ := func(a, b string) bool {
+		return a != "" && b != "" && normalizeVital(a) != normalizeVital(b)
+	}
+	for i := range evs {
+		for j := i + 1; j < len(evs); j++ {
+			if differ(evs[i].date, evs[j].date) || differ(evs[i].place, evs[j].place) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func normalizeVital(s string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(s), " "))
+}
+
+func (ev vitalEvent) String() string {
+	var parts []string
+	if ev.date != "" {
+		parts = append(parts, "date "+ev.date)
+	}
+	if ev.place != "" {
+		parts = append(parts, "place "+ev.place)
+	}
+	if len(ev.sources) > 0 {
+		parts = append(parts, "sources "+strings.Join(ev.sources, ", "))
+	}
+	if len(parts) == 0 {
+		return fmt.Sprintf("line %d", ev.line)
+	}
+	return fmt.Sprintf("line %d (%s)", ev.line, strings.Join(parts, ", "))
+}
+
+func (v *validator) line(l Line) {
+	// The frame at index i of the stack holds the structure at level i-1
+	for len(v.stack) > l.Level+1 {
+		v.pop()
+	}
+	if len(v.stack) < l.Level+1 {
+		v.add(l.LineNumber, l.Tag, RuleInvalidLevel, "level %d is not subordinate to level %d", l.Level, len(v.stack)-2)
+		for len(v.stack) < l.Level+1 {
+			v.push(validateFrame{ctx: "*", line: l.LineNumber})
+		}
+	}
+
+	parent := &v.stack[len(v.stack)-1]
+	f := validateFrame{tag: l.Tag, xref: l.Xref, ctx: "*", line: l.LineNumber}
+
+	if v.version == "" && l.Tag == "VERS" && len(v.stack) == 3 && v.stack[1].tag == "HEAD" && v.stack[2].tag == "GEDC" {
+		v.version = versionFamily(l.Value)
+	}
+
+	reported := false
+	if !strings.HasPrefix(l.Tag, "_") {
+		if version, ok := v.tagVersion(l.Tag); !ok {
+			v.add(l.LineNumber, l.Tag, RuleVersion, "%s is not defined in GEDCOM %s", l.Tag, version)
+			reported = true
+		}
+	}
+
+	if v.version == "7.0" {
+		// The structure of GEDCOM 7 data is not checked beyond the presence of HEAD and TRLR
+		if parent.ctx == "root" {
+			parent.count(l.Tag)
+		}
+	} else if parent.ctx != "*" {
+		r, ok := v.grammar()[parent.ctx][l.Tag]
+		switch {
+		case ok:
+			parent.count(l.Tag)
+			if r.max > 0 && parent.counts[l.Tag] == r.max+1 {
+				v.add(l.LineNumber, l.Tag, RuleTooMany, "%s occurs more than %d times", l.Tag, r.max)
+			}
+			f.ctx = r.ctx
+		case !strings.HasPrefix(l.Tag, "_") && !reported:
+			v.add(l.LineNumber, l.Tag, RuleUnexpectedTag, "%s is not allowed here", l.Tag)
+		}
+	}
+	v.push(f)
+	v.collectVital(l)
+	v.collectChildOrder(l)
+}
+
+// grammar returns the grammar of the GEDCOM version declared by the header
+func (v *validator) grammar() map[string]map[string]tagRule {
+	if v.version == "5.5" {
+		return grammar55
+	}
+	return grammar551
+}
+
+// tagVersion reports whether tag is defined by the GEDCOM version declared by the header,
+// also returning the version it was checked against. Tags that are not defined by any
+// version are reported as defined since they are not a version violation.
+func (v *validator) tagVersion(tag string) (string, bool) {
+	version := v.version
+	if version == "" {
+		version = "5.5.1"
+	}
+	in55 := tags55[tag] || (standardTags()[tag] && !tagsAdded551[tag])
+	in551 := standardTags()[tag]
+	in7 := standardTags7()[tag]
+	if !in55 && !in551 && !in7 {
+		return version, true
+	}
+	switch version {
+	case "5.5":
+		return version, in55
+	case "7.0":
+		return version, in7
+	default:
+		return version, in551
+	}
+}
+
+// versionFamily returns the GEDCOM version that v, the value of the VERS tag of a header's
+// GEDC structure, is checked against: "5.5", "5.5.1" or "7.0"
+func versionFamily(v string) string {
+	v = strings.TrimSpace(v)
+	switch {
+	case isVersion7(v):
+		return "7.0"
+	case v == "5.5" || v == "5.5.0":
+		return "5.5"
+	default:
+		return "5.5.1"
+	}
+}
+
+// add records a finding for the innermost structure, or for tag within it if tag is
+// not empty
+func (v *validator) add(line int, tag string, rule string, format string, args ...any) {
+	var tags []string
+	xref := ""
+	for i, f := range v.stack {
+		if i == 0 {
+			continue
+		}
+		if i == 1 {
+			xref = f.xref
+		}
+		tags = append(tags, f.tag)
+	}
+	if tag != "" {
+		tags = append(tags, tag)
+	}
+	path := strings.Join(tags, ".")
+	if path == "" {
+		path = "root"
+	}
+	v.findings = append(v.findings, Finding{
+		Line:    line,
+		Path:    path,
+		Xref:    xref,
+		Rule:    rule,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// A tagRule gives the number of times a tag may occur within a structure and the grammar
+// context of its subordinate tags. A max of zero means there is no limit.
+type tagRule struct {
+	min, max int
+	ctx      string
+}
+
+func ruleOpt(ctx string) tagRule         { return tagRule{min: 0, max: 1, ctx: ctx} }
+func ruleReq(ctx string) tagRule         { return tagRule{min: 1, max: 1, ctx: ctx} }
+func ruleMany(ctx string) tagRule        { return tagRule{min: 0, max: 0, ctx: ctx} }
+func ruleUpTo(n int, ctx string) tagRule { return tagRule{min: 0, max: n, ctx: ctx} }
+func ruleAtLeast(ctx string) tagRule     { return tagRule{min: 1, max: 0, ctx: ctx} }
+
+func mergeRules(ms ...map[string]tagRule) map[string]tagRule {
+	out := make(map[string]tagRule)
+	for _, m := range ms {
+		for k, v := range m {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+func rulesFor(tags string, r tagRule) map[string]tagRule {
+	m := make(map[string]tagRule)
+	for _, t := range strings.Fields(tags) {
+		m[t] = r
+	}
+	return m
+}
+
+var (
+	contactRules = map[string]tagRule{
+		"ADDR": ruleOpt("ADDR"), "PHON": ruleUpTo(3, ""), "EMAIL": ruleUpTo(3, ""), "FAX": ruleUpTo(3, ""), "WWW": ruleUpTo(3, ""),
+	}
+
+	eventDetailRules = mergeRules(contactRules, map[string]tagRule{
+		"TYPE": ruleOpt(""), "DATE": ruleOpt(""), "PLAC": ruleOpt("PLAC"), "AGNC": ruleOpt(""), "RELI": ruleOpt(""),
+		"CAUS": ruleOpt(""), "RESN": ruleOpt(""), "NOTE": ruleMany("NOTE"), "SOUR": ruleMany("CITE"), "OBJE": ruleMany("OBJEREF"),
+	})
+
+	individualEventTags = "BIRT CHR DEAT BURI CREM ADOP BAPM BARM BASM BLES CHRA CONF FCOM ORDN NATU EMIG IMMI CENS PROB WILL GRAD RETI EVEN"
+	individualAttrTags  = "CAST DSCR EDUC IDNO NATI NCHI NMR OCCU PROP RELI RESI SSN TITL FACT"
+	familyEventTags     = "ANUL CENS DIV DIVF ENGA MARB MARC MARR MARL MARS RESI EVEN"
+	namePieceTags       = "NPFX GIVN NICK SPFX SURN NSFX"
+	textRules           = map[string]tagRule{"CONT": ruleMany(""), "CONC": ruleMany("")}
+)
+
+// tagsAdded551 are the tags of the GEDCOM 5.5.1 grammar that are not defined by GEDCOM 5.5
+var tagsAdded551 = map[string]bool{
+	"ADR3": true, "EMAIL": true, "FACT": true, "FAX": true, "FONE": true, "LATI": true, "LONG": true, "MAP": true, "ROMN": true, "WWW": true,
+}
+
+// tags55 are the tags defined by GEDCOM 5.5 that were removed from GEDCOM 5.5.1
+var tags55 = map[string]bool{
+	"BLOB": true,
+}
+
+// grammar551 gives the tags allowed within each structure of the GEDCOM 5.5.1 grammar,
+// keyed by the grammar context of the structure. Tags with no standard subordinate tags
+// use the empty context, which allows only continuation lines.
+var grammar551 = map[string]map[string]tagRule{
+	"": textRules,
+	"root": {
+		"HEAD": ruleReq("HEAD"), "SUBN": ruleOpt("SUBN"), "TRLR": ruleReq(""),
+		"INDI": ruleMany("INDI"), "FAM": ruleMany("FAM"), "OBJE": ruleMany("OBJE"), "NOTE": ruleMany("NOTEREC"),
+		"REPO": ruleMany("REPO"), "SOUR": ruleMany("SOUR"), "SUBM": ruleMany("SUBM"),
+	},
+	"HEAD": {
+		"SOUR": ruleReq("HEAD.SOUR"), "DEST": ruleOpt(""), "DATE": ruleOpt("HEAD.DATE"), "SUBM": ruleReq(""), "SUBN": ruleOpt(""),
+		"FILE": ruleOpt(""), "COPR": ruleOpt(""), "GEDC": ruleReq("GEDC"), "CHAR": ruleReq("CHAR"), "LANG": ruleOpt(""),
+		"PLAC": ruleOpt("HEAD.PLAC"), "NOTE": ruleOpt("TEXT"),
+	},
+	"HEAD.SOUR":      {"VERS": ruleOpt(""), "NAME": ruleOpt(""), "CORP": ruleOpt("CORP"), "DATA": ruleOpt("HEAD.SOUR.DATA")},
+	"CORP":           contactRules,
+	"HEAD.SOUR.DATA": {"DATE": ruleOpt(""), "COPR": ruleOpt("TEXT")},
+	"HEAD.DATE":      {"TIME": ruleOpt("")},
+	"GEDC":           {"VERS": ruleReq(""), "FORM": ruleReq("")},
+	"CHAR":           {"VERS": ruleOpt("")},
+	"HEAD.PLAC":      {"FORM": ruleReq("")},
+	"TEXT":           textRules,
+
+	"INDI": mergeRules(
+		rulesFor(individualEventTags, ruleMany("EVENT")),
+		rulesFor(individualAttrTags, ruleMany("EVENT")),
+		rulesFor("BAPL CONL ENDL SLGC", ruleMany("LDS")),
+		map[string]tagRule{
+			"RESN": ruleOpt(""), "NAME": ruleMany("NAME"), "SEX": ruleOpt(""), "FAMC": ruleMany("FAMC"), "FAMS": ruleMany("FAMS"),
+			"SUBM": ruleMany(""), "ASSO": ruleMany("ASSO"), "ALIA": ruleMany(""), "ANCI": ruleMany(""), "DESI": ruleMany(""),
+			"RFN": ruleOpt(""), "AFN": ruleOpt(""), "REFN": ruleMany("REFN"), "RIN": ruleOpt(""), "CHAN": ruleOpt("CHAN"),
+			"NOTE": ruleMany("NOTE"), "SOUR": ruleMany("CITE"), "OBJE": ruleMany("OBJEREF"),
+		},
+	),
+	"FAM": mergeRules(
+		rulesFor(familyEventTags, ruleMany("FAMEVENT")),
+		rulesFor("SLGS", ruleMany("LDS")),
+		map[string]tagRule{
+			"RESN": ruleOpt(""), "HUSB": ruleOpt(""), "WIFE": ruleOpt(""), "CHIL": ruleMany(""), "NCHI": ruleOpt(""),
+			"SUBM": ruleMany(""), "REFN": ruleMany("REFN"), "RIN": ruleOpt(""), "CHAN": ruleOpt("CHAN"),
+			"NOTE": ruleMany("NOTE"), "SOUR": ruleMany("CITE"), "OBJE": ruleMany("OBJEREF"),
+		},
+	),
+	"EVENT":      mergeRules(eventDetailRules, map[string]tagRule{"AGE": ruleOpt(""), "FAMC": ruleOpt("EVENT.FAMC")}),
+	"FAMEVENT":   mergeRules(eventDetailRules, map[string]tagRule{"HUSB": ruleOpt("AGEOF"), "WIFE": ruleOpt("AGEOF")}),
+	"AGEOF":      {"AGE": ruleReq("")},
+	"EVENT.FAMC": {"ADOP": ruleOpt("")},
+	"LDS": {
+		"DATE": ruleOpt(""), "TEMP": ruleOpt(""), "PLAC": ruleOpt(""), "STAT": ruleOpt("LDS.STAT"), "FAMC": ruleOpt(""),
+		"NOTE": ruleMany("NOTE"), "SOUR": ruleMany("CITE"),
+	},
+	"LDS.STAT": {"DATE": ruleReq("")},
+	"NAME": mergeRules(rulesFor(namePieceTags, ruleOpt("")), map[string]tagRule{
+		"TYPE": ruleOpt(""), "FONE": ruleMany("NAMEVAR"), "ROMN": ruleMany("NAMEVAR"), "NOTE": ruleMany("NOTE"), "SOUR": ruleMany("CITE"),
+	}),
+	"NAMEVAR": mergeRules(rulesFor(namePieceTags, ruleOpt("")), map[string]tagRule{
+		"TYPE": ruleReq(""), "NOTE": ruleMany("NOTE"), "SOUR": ruleMany("CITE"),
+	}),
+	"PLAC": {
+		"FORM": ruleOpt(""), "FONE": ruleMany("PLACVAR"), "ROMN": ruleMany("PLACVAR"), "MAP": ruleOpt("MAP"), "NOTE": ruleMany("NOTE"),
+	},
+	"PLACVAR":   {"TYPE": ruleReq("")},
+	"MAP":       {"LATI": ruleReq(""), "LONG": ruleReq("")},
+	"ADDR":      mergeRules(textRules, rulesFor("ADR1 ADR2 ADR3 CITY STAE POST CTRY", ruleOpt(""))),
+	"FAMC":      {"PEDI": ruleOpt(""), "STAT": ruleOpt(""), "NOTE": ruleMany("NOTE")},
+	"FAMS":      {"NOTE": ruleMany("NOTE")},
+	"ASSO":      {"RELA": ruleReq(""), "SOUR": ruleMany("CITE"), "NOTE": ruleMany("NOTE")},
+	"REFN":      {"TYPE": ruleOpt("")},
+	"CHAN":      {"DATE": ruleReq("CHAN.DATE"), "NOTE": ruleMany("NOTE")},
+	"CHAN.DATE": {"TIME": ruleOpt("")},
+	"NOTE":      mergeRules(textRules, map[string]tagRule{"SOUR": ruleMany("CITE")}),
+	"NOTEREC": mergeRules(textRules, map[string]tagRule{
+		"REFN": ruleMany("REFN"), "RIN": ruleOpt(""), "SOUR": ruleMany("CITE"), "CHAN": ruleOpt("CHAN"),
+	}),
+	"CITE": mergeRules(textRules, map[string]tagRule{
+		"PAGE": ruleOpt(""), "EVEN": ruleOpt("CITE.EVEN"), "DATA": ruleOpt("CITE.DATA"), "QUAY": ruleOpt(""),
+		"OBJE": ruleMany("OBJEREF"), "NOTE": ruleMany("NOTE"), "TEXT": ruleMany("TEXT"),
+	}),
+	"CITE.EVEN": {"ROLE": ruleOpt("")},
+	"CITE.DATA": {"DATE": ruleOpt(""), "TEXT": ruleMany("TEXT")},
+	"OBJEREF":   {"FILE": ruleMany("OBJEFILE"), "TITL": ruleOpt(""), "FORM": ruleOpt("")},
+	"OBJEFILE":  {"FORM": ruleOpt("FORM"), "TITL": ruleOpt("")},
+	"FORM":      {"TYPE": ruleOpt(""), "MEDI": ruleOpt("")},
+	"OBJE": {
+		"FILE": ruleAtLeast("OBJEFILE"), "REFN": ruleMany("REFN"), "RIN": ruleOpt(""), "NOTE": ruleMany("NOTE"),
+		"SOUR": ruleMany("CITE"), "CHAN": ruleOpt("CHAN"),
+	},
+	"REPO": mergeRules(contactRules, map[string]tagRule{
+		"NAME": ruleReq(""), "NOTE": ruleMany("NOTE"), "REFN": ruleMany("REFN"), "RIN": ruleOpt(""), "CHAN": ruleOpt("CHAN"),
+	}),
+	"SOUR": {
+		"DATA": ruleOpt("SOUR.DATA"), "AUTH": ruleOpt("TEXT"), "TITL": ruleOpt("TEXT"), "ABBR": ruleOpt(""), "PUBL": ruleOpt("TEXT"),
+		"TEXT": ruleOpt("TEXT"), "REPO": ruleMany("REPOREF"), "REFN": ruleMany("REFN"), "RIN": ruleOpt(""), "CHAN": ruleOpt("CHAN"),
+		"NOTE": ruleMany("NOTE"), "OBJE": ruleMany("OBJEREF"),
+	},
+	"SOUR.DATA":      {"EVEN": ruleMany("SOUR.DATA.EVEN"), "AGNC": ruleOpt(""), "NOTE": ruleMany("NOTE")},
+	"SOUR.DATA.EVEN": {"DATE": ruleOpt(""), "PLAC": ruleOpt("")},
+	"REPOREF":        {"NOTE": ruleMany("NOTE"), "CALN": ruleMany("CALN")},
+	"CALN":           {"MEDI": ruleOpt("")},
+	"SUBM": mergeRules(contactRules, map[string]tagRule{
+		"NAME": ruleReq(""), "OBJE": ruleMany("OBJEREF"), "LANG": ruleUpTo(3, ""), "RFN": ruleOpt(""), "RIN": ruleOpt(""),
+		"NOTE": ruleMany("NOTE"), "CHAN": ruleOpt("CHAN"),
+	}),
+	"SUBN": {
+		"SUBM": ruleOpt(""), "FAMF": ruleOpt(""), "TEMP": ruleOpt(""), "ANCE": ruleOpt(""), "DESC": ruleOpt(""), "ORDI": ruleOpt(""),
+		"RIN": ruleOpt(""), "NOTE": ruleMany("NOTE"), "CHAN": ruleOpt("CHAN"),
+	},
+}
+
+// grammar55 gives the tags allowed within each structure of the GEDCOM 5.5 grammar. It
+// differs from grammar551 in the structures that GEDCOM 5.5.1 changed: multimedia
+// records hold their data in a BLOB, multimedia links name a single FILE, and names,
+// places, events and links to families lack the tags that were added by GEDCOM 5.5.1.
+// Tags that GEDCOM 5.5 does not define at all are reported by version instead.
+var grammar55 = mergeGrammars(grammar551, map[string]map[string]tagRule{
+	"EVENT":    mergeRules(eventDetailRules55, map[string]tagRule{"AGE": ruleOpt(""), "FAMC": ruleOpt("EVENT.FAMC")}),
+	"FAMEVENT": mergeRules(eventDetailRules55, map[string]tagRule{"HUSB": ruleOpt("AGEOF"), "WIFE": ruleOpt("AGEOF")}),
+	"NAME":     mergeRules(rulesFor(namePieceTags, ruleOpt("")), map[string]tagRule{"NOTE": ruleMany("NOTE"), "SOUR": ruleMany("CITE")}),
+	"PLAC":     {"FORM": ruleOpt(""), "SOUR": ruleMany("CITE"), "NOTE": ruleMany("NOTE")},
+	"FAMC":     {"PEDI": ruleOpt(""), "NOTE": ruleMany("NOTE")},
+	"OBJEREF":  {"FORM": ruleOpt(""), "TITL": ruleOpt(""), "FILE": ruleOpt(""), "NOTE": ruleMany("NOTE")},
+	"OBJE": {
+		"FORM": ruleReq(""), "TITL": ruleOpt(""), "NOTE": ruleMany("NOTE"), "BLOB": ruleReq("TEXT"), "OBJE": ruleOpt(""),
+		"REFN": ruleMany("REFN"), "RIN": ruleOpt(""), "CHAN": ruleOpt("CHAN"),
+	},
+})
+
+// eventDetailRules55 are the tags of the GEDCOM 5.5 event detail structure
+var eventDetailRules55 = mergeRules(contactRules, map[string]tagRule{
+	"TYPE": ruleOpt(""), "DATE": ruleOpt(""), "PLAC": ruleOpt("PLAC"), "AGNC": ruleOpt(""), "CAUS": ruleOpt(""),
+	"NOTE": ruleMany("NOTE"), "SOUR": ruleMany("CITE"), "OBJE": ruleMany("OBJEREF"),
+})
+
+// mergeGrammars returns a grammar holding the contexts of base, replacing those that are
+// also in overrides
+func mergeGrammars(base map[string]map[string]tagRule, overrides map[string]map[string]tagRule) map[string]map[string]tagRule {
+	out := make(map[string]map[string]tagRule, len(base))
+	for ctx, rules := range base {
+		out[ctx] = rules
+	}
+	for ctx, rules := range overrides {
+		out[ctx] = rules
+	}
+	return out
+}
@@ -0,0 +1,349 @@
+package gedcom
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestValidate(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input string
+		want  []Finding
+	}{
+		{
+			name: "valid",
+			input: `0 HEAD
+1 SOUR test
+1 SUBM @U1@
+1 GEDC
+2 VERS 5.5.1
+2 FORM LINEAGE-LINKED
+1 CHAR UTF-8
+0 @U1@ SUBM
+1 NAME Jane
+0 @I1@ INDI
+1 NAME John /Smith/
+1 SEX M
+1 BIRT
+2 DATE 1 JAN 1900
+2 _CUSTOM anything
+3 WHATEVER here
+1 CHAN
+2 DATE 2 FEB 2000
+3 TIME 10:00
+0 TRLR
+`,
+		},
+		{
+			name: "cardinality",
+			input: `0 HEAD
+1 SOUR test
+1 SUBM @U1@
+1 GEDC
+2 VERS 5.5.1
+2 FORM LINEAGE-LINKED
+1 GEDC
+2 VERS 5.5
+1 CHAR UTF-8
+0 @I1@ INDI
+1 SEX M
+1 SEX F
+1 CHAN
+2 NOTE changed
+1 BOGUS x
+0 @F1@ FAM
+1 NCHI 2
+1 NCHI 3
+0 TRLR
+`,
+			want: []Finding{
+				{Line: 7, Path: "HEAD.GEDC", Rule: RuleTooMany, Message: "GEDC occurs more than 1 times"},
+				{Line: 7, Path: "HEAD.GEDC", Rule: RuleMissing, Message: "missing required FORM tag"},
+				{Line: 12, Path: "INDI.SEX", Xref: "I1", Rule: RuleTooMany, Message: "SEX occurs more than 1 times"},
+				{Line: 13, Path: "INDI.CHAN", Xref: "I1", Rule: RuleMissing, Message: "missing required DATE tag"},
+				{Line: 15, Path: "INDI.BOGUS", Xref: "I1", Rule: RuleUnexpectedTag, Message: "BOGUS is not allowed here"},
+				{Line: 18, Path: "FAM.NCHI", Xref: "F1", Rule: RuleTooMany, Message: "NCHI occurs more than 1 times"},
+			},
+		},
+		{
+			name: "missing header",
+			input: `0 @I1@ INDI
+1 NAME John /Smith/
+3 DATE 1900
+`,
+			want: []Finding{
+				{Line: 3, Path: "INDI.NAME.DATE", Xref: "I1", Rule: RuleInvalidLevel, Message: "level 3 is not subordinate to level 1"},
+				{Line: 3, Path: "root", Rule: RuleMissing, Message: "missing required HEAD tag"},
+				{Line: 3, Path: "root", Rule: RuleMissing, Message: "missing required TRLR tag"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Validate(strings.NewReader(tc.input))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("findings mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestValidateConflictingVitals(t *testing.T) {
+	input := `0 @I1@ INDI
+1 NAME John /Smith/
+1 BIRT
+2 DATE 1 JAN 1900
+2 PLAC London
+2 SOUR @S1@
+1 BIRT
+2 DATE 1 jan  1900
+1 BIRT
+2 DATE 1901
+2 SOUR @S2@
+3 PAGE 4
+1 DEAT
+2 DATE 1950
+1 DEAT
+2 PLAC Paris
+0 @I2@ INDI
+1 DEAT
+2 PLAC Leeds
+1 DEAT
+2 PLAC York
+0 TRLR
+`
+	findings, err := Validate(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got []Finding
+	for _, f := range findings {
+		if f.Rule == RuleConflicting {
+			got = append(got, f)
+		}
+	}
+
+	want := []Finding{
+		{
+			Line:    3,
+			Path:    "INDI.BIRT",
+			Xref:    "I1",
+			Rule:    RuleConflicting,
+			Message: "conflicting BIRT events: line 3 (date 1 JAN 1900, place London, sources @S1@); line 7 (date 1 jan  1900); line 9 (date 1901, sources @S2@)",
+		},
+		{
+			Line:    18,
+			Path:    "INDI.DEAT",
+			Xref:    "I2",
+			Rule:    RuleConflicting,
+			Message: "conflicting DEAT events: line 18 (place Leeds); line 20 (place York)",
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("findings mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestValidateChildOrder(t *testing.T) {
+	input := `0 @F1@ FAM
+1 CHIL @I1@
+1 CHIL @I2@
+1 CHIL @I3@
+1 CHIL @I4@
+1 CHIL @I5@
+0 @F2@ FAM
+1 CHIL @I4@
+1 CHIL @I6@
+0 @I1@ INDI
+1 BIRT
+2 DATE 3 MAR 1900
+0 @I2@ INDI
+1 BIRT
+2 DATE ABT 1902
+0 @I3@ INDI
+1 BIRT
+2 DATE 1899
+0 @I4@ INDI
+1 CHR
+2 DATE 1905
+0 @I5@ INDI
+1 BIRT
+2 DATE BEF 1901
+0 @I6@ INDI
+1 BIRT
+2 DATE 1880
+0 TRLR
+`
+	findings, err := Validate(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got []Finding
+	for _, f := range findings {
+		if f.Rule == RuleChildOrder {
+			got = append(got, f)
+		}
+	}
+
+	want := []Finding{
+		{Line: 4, Path: "FAM.CHIL", Xref: "F1", Rule: RuleChildOrder, Message: "child @I3@ born 1899 is listed after @I2@ born ABT 1902"},
+		{Line: 6, Path: "FAM.CHIL", Xref: "F1", Rule: RuleChildOrder, Message: "child @I5@ born BEF 1901 is listed after @I4@ born 1905"},
+		{Line: 9, Path: "FAM.CHIL", Xref: "F2", Rule: RuleChildOrder, Message: "child @I6@ born 1880 is listed after @I4@ born 1905"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("findings mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestValidateVersion(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input string
+		want  []Finding
+	}{
+		{
+			name: "5.5",
+			input: `0 HEAD
+1 SOUR test
+1 SUBM @U1@
+1 GEDC
+2 VERS 5.5
+2 FORM LINEAGE-LINKED
+1 CHAR ANSEL
+0 @U1@ SUBM
+1 NAME Jane
+1 EMAIL jane@example.com
+0 @I1@ INDI
+1 NAME John /Smith/
+2 ROMN John /Smith/
+3 TYPE pinyin
+1 BIRT
+2 PLAC London
+3 MAP
+4 LATI N51.5
+4 LONG W0.1
+0 TRLR
+`,
+			want: []Finding{
+				{Line: 10, Path: "SUBM.EMAIL", Xref: "U1", Rule: RuleVersion, Message: "EMAIL is not defined in GEDCOM 5.5"},
+				{Line: 13, Path: "INDI.NAME.ROMN", Xref: "I1", Rule: RuleVersion, Message: "ROMN is not defined in GEDCOM 5.5"},
+				{Line: 17, Path: "INDI.BIRT.PLAC.MAP", Xref: "I1", Rule: RuleVersion, Message: "MAP is not defined in GEDCOM 5.5"},
+				{Line: 18, Path: "INDI.BIRT.PLAC.MAP.LATI", Xref: "I1", Rule: RuleVersion, Message: "LATI is not defined in GEDCOM 5.5"},
+				{Line: 19, Path: "INDI.BIRT.PLAC.MAP.LONG", Xref: "I1", Rule: RuleVersion, Message: "LONG is not defined in GEDCOM 5.5"},
+			},
+		},
+		{
+			name: "5.5 multimedia",
+			input: `0 HEAD
+1 SOUR test
+1 SUBM @U1@
+1 GEDC
+2 VERS 5.5
+2 FORM LINEAGE-LINKED
+1 CHAR ANSEL
+0 @U1@ SUBM
+1 NAME Jane
+0 @I1@ INDI
+1 NAME John /Smith/
+1 OBJE
+2 FORM jpeg
+2 FILE john.jpg
+2 NOTE A photograph
+1 OBJE @M1@
+0 @M1@ OBJE
+1 FORM bmp
+1 TITL A picture
+1 NOTE A note
+1 BLOB
+2 CONT .HM.......k.1..F.jwA.Dzzzzw............A....1.........0U.66..E.8
+0 @M2@ OBJE
+1 FILE photo.jpg
+0 TRLR
+`,
+			want: []Finding{
+				{Line: 24, Path: "OBJE.FILE", Xref: "M2", Rule: RuleUnexpectedTag, Message: "FILE is not allowed here"},
+				{Line: 23, Path: "OBJE", Xref: "M2", Rule: RuleMissing, Message: "missing required BLOB tag"},
+				{Line: 23, Path: "OBJE", Xref: "M2", Rule: RuleMissing, Message: "missing required FORM tag"},
+			},
+		},
+		{
+			name: "5.5.1",
+			input: `0 HEAD
+1 SOUR test
+1 SUBM @U1@
+1 GEDC
+2 VERS 5.5.1
+2 FORM LINEAGE-LINKED
+1 CHAR UTF-8
+0 @U1@ SUBM
+1 NAME Jane
+1 EMAIL jane@example.com
+0 @I1@ INDI
+1 NAME John /Smith/
+1 EXID 123
+0 TRLR
+`,
+			want: []Finding{
+				{Line: 13, Path: "INDI.EXID", Xref: "I1", Rule: RuleVersion, Message: "EXID is not defined in GEDCOM 5.5.1"},
+			},
+		},
+		{
+			name: "7.0",
+			input: `0 HEAD
+1 GEDC
+2 VERS 7.0
+0 @I1@ INDI
+1 NAME John /Smith/
+2 ROMN Jon /Smith/
+1 SNOTE @N1@
+1 RIN 42
+0 @N1@ SNOTE A note
+1 CONC continued
+0 TRLR
+`,
+			want: []Finding{
+				{Line: 6, Path: "INDI.NAME.ROMN", Xref: "I1", Rule: RuleVersion, Message: "ROMN is not defined in GEDCOM 7.0"},
+				{Line: 8, Path: "INDI.RIN", Xref: "I1", Rule: RuleVersion, Message: "RIN is not defined in GEDCOM 7.0"},
+				{Line: 10, Path: "SNOTE.CONC", Xref: "N1", Rule: RuleVersion, Message: "CONC is not defined in GEDCOM 7.0"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Validate(strings.NewReader(tc.input))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("findings mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestValidateAllged(t *testing.T) {
+	f, err := os.Open("testdata/allged.ged")
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer f.Close()
+
+	got, err := Validate(f)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, f := range got {
+		t.Errorf("unexpected finding: %s", f)
+	}
+}
@@ -0,0 +1,86 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+
+package gedcom
+
+import (
+	"fmt"
+	"strings"
+)
+
+// A Warning describes a problem in the input that did not stop decoding.
+type Warning struct {
+	Line       int    // line number of the input
+	Kind       string // one of the Warning constants
+	Tag        string
+	Value      string
+	Record     string // tag of the level 0 record containing the line
+	RecordXref string // xref of the level 0 record containing the line, if any
+	Message    string
+}
+
+func (w Warning) String() string {
+	if w.RecordXref != "" {
+		return fmt.Sprintf("line %d: @%s@ %s: %s", w.Line, w.RecordXref, w.Tag, w.Message)
+	}
+	return fmt.Sprintf("line %d: %s: %s", w.Line, w.Tag, w.Message)
+}
+
+// Warnings returns the problems found in the input by the most recent call to Decode, in
+// the order they were found. Each is also reported to the Metrics configured with
+// WithMetrics.
+func (d *Decoder) Warnings() []Warning {
+	return d.warnings
+}
+
+// warn records a warning about the current line
+func (d *Decoder) warn(kind string, tag string, value string, format string, args ...any) {
+	if d.metrics != nil {
+		d.metrics.AddWarning(kind)
+	}
+	d.warnings = append(d.warnings, Warning{
+		Line:       d.line,
+		Kind:       kind,
+		Tag:        tag,
+		Value:      value,
+		Record:     d.record,
+		RecordXref: d.recordXref,
+		Message:    fmt.Sprintf(format, args...),
+	})
+}
+
+// checkLine records warnings for problems with the current line of s
+func (d *Decoder) checkLine(s *Scanner) {
+	if !strings.HasPrefix(s.tag, "_") && !isStandardTag(s.tag, d.version7) {
+		d.warn(WarningUnknownTag, s.tag, s.value, "%s is not a standard tag", s.tag)
+	}
+	if s.level == 0 && s.xref == "" && recordTags[s.tag] {
+		d.warn(WarningEmptyXref, s.tag, s.value, "%s record has no xref", s.tag)
+	}
+	if len(s.value) >= 2 && s.value[0] == '@' && s.value[len(s.value)-1] == '@' && strings.TrimSpace(stripXref(s.value)) == "" {
+		d.warn(WarningEmptyXref, s.tag, s.value, "pointer has an empty xref")
+	}
+	if s.tag == "DATE" && !validDate(s.value) {
+		d.warn(WarningInvalidDate, s.tag, s.value, "%q is not a valid date", s.value)
+	}
+}
+
+// validDate reports whether s is a date value that can be interpreted and placed in time.
+// Date phrases are assumed to be valid.
+func validDate(s string) bool {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return true
+	}
+	dv, ok := ParseDate(s)
+	if !ok {
+		return false
+	}
+	if dv.Date1.IsZero() {
+		return true
+	}
+	_, _, ok = dv.bounds()
+	return ok
+}
@@ -0,0 +1,89 @@
+package gedcom
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestDecoderWarnings(t *testing.T) {
+	input := `0 HEAD
+1 CHAR UTF-8
+0 @I1@ INDI
+1 BIRT
+3 DATE 1 JAN 1900
+1 DEAT
+2 DATE 31 FOO 1950
+1 BURI
+2 DATE (after the war)
+1 FAMC @@
+1 HIST Served in the navy
+1 _MILT Navy
+0 INDI
+0 TRLR
+`
+	m := &MetricsCounts{}
+	d := NewDecoder(strings.NewReader(input), WithMetrics(m))
+	if _, err := d.Decode(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []Warning{
+		{Line: 5, Kind: WarningInvalidLevel, Tag: "DATE", Value: "1 JAN 1900", Record: "INDI", RecordXref: "I1"},
+		{Line: 7, Kind: WarningInvalidDate, Tag: "DATE", Value: "31 FOO 1950", Record: "INDI", RecordXref: "I1"},
+		{Line: 10, Kind: WarningEmptyXref, Tag: "FAMC", Value: "@@", Record: "INDI", RecordXref: "I1"},
+		{Line: 11, Kind: WarningUnknownTag, Tag: "HIST", Value: "Served in the navy", Record: "INDI", RecordXref: "I1"},
+		{Line: 13, Kind: WarningEmptyXref, Tag: "INDI", Record: "INDI"},
+	}
+	if diff := cmp.Diff(want, d.Warnings(), cmpopts.IgnoreFields(Warning{}, "Message")); diff != "" {
+		t.Errorf("warnings mismatch (-want +got):\n%s", diff)
+	}
+	for _, w := range d.Warnings() {
+		if w.Message == "" {
+			t.Errorf("warning on line %d has no message", w.Line)
+		}
+	}
+
+	wantCounts := map[string]int64{
+		WarningInvalidLevel: 1,
+		WarningInvalidDate:  1,
+		WarningEmptyXref:    2,
+		WarningUnknownTag:   1,
+	}
+	if diff := cmp.Diff(wantCounts, m.Warnings()); diff != "" {
+		t.Errorf("metrics warnings mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestValidDate(t *testing.T) {
+	testCases := []struct {
+		date string
+		want bool
+	}{
+		{date: "1 JAN 1900", want: true},
+		{date: "JAN 1900", want: true},
+		{date: "ABT 1900", want: true},
+		{date: "BET 1900 AND 1910", want: true},
+		{date: "INT 1900 (about the turn of the century)", want: true},
+		{date: "(unknown)", want: true},
+		{date: "@#DJULIAN@ 1 JAN 1700", want: true},
+		{date: "@#DHEBREW@ 1 TSH 5700", want: true},
+		{date: "44 B.C.", want: true},
+		{date: "1732/33", want: true},
+		{date: "31 FOO 1950", want: false},
+		{date: "last summer", want: false},
+		{date: "@#DGREGORIAN@ sometime", want: false},
+		{date: "@#DJULIAN@ 30 FEB 1700", want: false},
+		{date: "@#DHEBREW@ 1 FOO 5700", want: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.date, func(t *testing.T) {
+			if got := validDate(tc.date); got != tc.want {
+				t.Errorf("got %v, wanted %v", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+/*
+This is free and unencumbered software released into the public domain. For more
+information, see <http://unlicense.org/> or the accompanying UNLICENSE file.
+*/
+
+package gedcom
+
+import (
+	"reflect"
+	"strconv"
+)
+
+// WithGeneratedXrefs configures the encoder to give an xref to each record of the Gedcom
+// that has none, such as one built in code, instead of failing. Generated xrefs are
+// unique across the Gedcom and are numbered in the order records are held, with a letter
+// for the type of record: I1, I2 for individuals, then F for families, M for media, R for
+// repositories, S for sources, U for submitters, B for submissions and N for shared
+// notes. Numbers already used by an existing xref are skipped. Every pointer to a record,
+// such as a family's HUSB or a citation's SOUR, is written with the record's generated
+// xref. The Gedcom being encoded is not modified.
+func WithGeneratedXrefs() EncoderOption {
+	return encoderOptionFunc(func(e *Encoder) {
+		e.generateXrefs = true
+	})
+}
+
+// assignXrefs chooses xrefs for the records of g that have none when the encoder generates
+// xrefs
+func (e *Encoder) assignXrefs(g *Gedcom) {
+	if !e.generateXrefs {
+		return
+	}
+	used := make(map[string]bool)
+	markXrefs(used, g.Individual)
+	markXrefs(used, g.Family)
+	markXrefs(used, g.Media)
+	markXrefs(used, g.Repository)
+	markXrefs(used, g.Source)
+	markXrefs(used, g.Submitter)
+	markXrefs(used, g.Submission)
+	markXrefs(used, g.Note)
+
+	e.xrefs = make(map[Record]string)
+	generateXrefs(e, used, "I", g.Individual)
+	generateXrefs(e, used, "F", g.Family)
+	generateXrefs(e, used, "M", g.Media)
+	generateXrefs(e, used, "R", g.Repository)
+	generateXrefs(e, used, "S", g.Source)
+	generateXrefs(e, used, "U", g.Submitter)
+	generateXrefs(e, used, "B", g.Submission)
+	generateXrefs(e, used, "N", g.Note)
+}
+
+// markXrefs records the xrefs of rs as used
+func markXrefs[T Record](used map[string]bool, rs []T) {
+	for _, r := range rs {
+		if xref := xrefOf(r); xref != "" {
+			used[xref] = true
+		}
+	}
+}
+
+// generateXrefs assigns an unused xref beginning with prefix to each record of rs that
+// has none
+func generateXrefs[T Record](e *Encoder, used map[string]bool, prefix string, rs []T) {
+	n := 0
+	for _, r := range rs {
+		if reflect.ValueOf(r).IsNil() || xrefOf(r) != "" || e.xrefs[r] != "" {
+			continue
+		}
+		var xref string
+		for {
+			n++
+			xref = prefix + strconv.Itoa(n)
+			if !used[xref] {
+				break
+			}
+		}
+		used[xref] = true
+		e.xrefs[r] = xref
+	}
+}
+
+// xref returns the xref of record r, which is xref unless the record has none and the
+// encoder generated one for it
+func (e *Encoder) xref(r Record, xref string) string {
+	if xref != "" || e.xrefs == nil {
+		return xref
+	}
+	return e.xrefs[r]
+}